@@ -0,0 +1,560 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package bundler provides tools for collapsing a multi-file OpenAPI 3+ specification into a single,
+// self-contained document.
+//
+// Every external reference that has been located by the index.Rolodex is followed, the target is copied
+// (hoisted) into the 'components' section of the root document under a collision-free name, and the reference
+// is re-written to point to the new local component. Circular references are always kept as local references,
+// so bundling never recurses forever.
+package bundler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// BundleConfig is used to configure how a document is bundled.
+type BundleConfig struct {
+	// InlineRefs will inline the content of every external reference, rather than hoisting it into components.
+	// Circular references cannot be inlined, so they will still be hoisted into components and referenced
+	// locally. Local references in the root document are never inlined.
+	InlineRefs bool
+}
+
+// BundleBytes will create a new document from the supplied specification bytes and configuration, and then
+// bundle it using BundleDocument. The configuration should contain a BasePath (or BaseURL) so the rolodex
+// is able to locate external references.
+func BundleBytes(specBytes []byte, docConfig *datamodel.DocumentConfiguration, config *BundleConfig) ([]byte, error) {
+	doc, err := libopenapi.NewDocumentWithConfiguration(specBytes, docConfig)
+	if err != nil {
+		return nil, err
+	}
+	return BundleDocument(doc, config)
+}
+
+// BundleDocument will collapse every external reference in a Document into the components section of the root
+// document, returning the rendered bytes of the bundled specification (in the same format, YAML or JSON, as the
+// original document).
+//
+// If the model has not yet been built, it will be built to populate the rolodex. Errors returned when building the
+// model that are circular reference errors are ignored, they will be handled by keeping the references local.
+//
+// Any references that cannot be located are left untouched, and all of them are returned (joined) as the error.
+// The bundled bytes are still returned in that case.
+func BundleDocument(doc libopenapi.Document, config *BundleConfig) ([]byte, error) {
+	if doc == nil {
+		return nil, errors.New("unable to bundle, document is nil")
+	}
+	info := doc.GetSpecInfo()
+	if info == nil || info.RootNode == nil {
+		return nil, errors.New("unable to bundle, document has not been initialized")
+	}
+	if info.SpecFormat != datamodel.OAS3 {
+		return nil, fmt.Errorf("unable to bundle, only OpenAPI 3+ documents are supported, not '%s'", info.SpecFormat)
+	}
+	if config == nil {
+		config = &BundleConfig{}
+	}
+
+	if doc.GetRolodex() == nil {
+		if _, errs := doc.BuildV3Model(); doc.GetRolodex() == nil {
+			return nil, errors.Join(errs...)
+		}
+	}
+
+	b := newBundler(doc.GetRolodex(), info, config)
+	if err := b.bundle(); err != nil {
+		return nil, err
+	}
+	bundled, err := json.RenderNode(b.root, info.SpecFileType == datamodel.JSONFileType, info.OriginalIndentation)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render bundled document: %w", err)
+	}
+	return bundled, errors.Join(b.errors...)
+}
+
+// component names must match this expression according to the OpenAPI specification.
+var componentNameExp = regexp.MustCompile(`[^a-zA-Z0-9.\-_]`)
+
+// schema keywords that contain a single schema as their value.
+var schemaKeywords = map[string]bool{
+	"schema": true, "items": true, "additionalProperties": true, "not": true, "contains": true, "if": true,
+	"then": true, "else": true, "propertyNames": true, "unevaluatedItems": true, "unevaluatedProperties": true,
+	"additionalItems": true, "contentSchema": true,
+}
+
+// schema keywords that contain a map or sequence of schemas as their value.
+var schemaCollectionKeywords = map[string]bool{
+	"properties": true, "patternProperties": true, "dependentSchemas": true, "$defs": true, "definitions": true,
+	"allOf": true, "oneOf": true, "anyOf": true, "prefixItems": true,
+}
+
+// maps the parent key of a reference to the component type it should be hoisted into.
+var componentCollectionKeywords = map[string]string{
+	"parameters":      "parameters",
+	"responses":       "responses",
+	"headers":         "headers",
+	"examples":        "examples",
+	"links":           "links",
+	"callbacks":       "callbacks",
+	"securitySchemes": "securitySchemes",
+}
+
+type bundler struct {
+	config     *BundleConfig
+	rolodex    *index.Rolodex
+	root       *yaml.Node
+	rootPath   string
+	baseURL    *url.URL
+	hoisted    map[string]string
+	names      map[string]map[string]bool
+	inProgress map[string]bool
+	circular   map[string]bool
+	errors     []error
+}
+
+func newBundler(rolodex *index.Rolodex, info *datamodel.SpecInfo, config *BundleConfig) *bundler {
+	b := &bundler{
+		config:     config,
+		rolodex:    rolodex,
+		hoisted:    make(map[string]string),
+		names:      make(map[string]map[string]bool),
+		inProgress: make(map[string]bool),
+		circular:   make(map[string]bool),
+	}
+	root := utils.CopyNode(info.RootNode)
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		b.root = root.Content[0]
+	} else {
+		b.root = root
+	}
+	if rolodex != nil && rolodex.GetRootIndex() != nil {
+		idx := rolodex.GetRootIndex()
+		b.rootPath = idx.GetSpecAbsolutePath()
+		if idx.GetConfig() != nil {
+			b.baseURL = idx.GetConfig().BaseURL
+		}
+
+		// circular references reported by the resolvers can never be inlined.
+		var circles []*index.CircularReferenceResult
+		circles = append(circles, idx.GetCircularReferences()...)
+		circles = append(circles, rolodex.GetIgnoredCircularReferences()...)
+		for _, c := range circles {
+			if c.LoopPoint != nil {
+				b.circular[c.LoopPoint.FullDefinition] = true
+			}
+		}
+	}
+	return b
+}
+
+func (b *bundler) bundle() error {
+	if !utils.IsNodeMap(b.root) {
+		return errors.New("unable to bundle, root of the document is not an object")
+	}
+
+	// record all existing component names, so hoisted components never collide with them. Components that are
+	// references to other files are recorded too, so every other reference to those files re-uses them.
+	_, comps := utils.FindKeyNodeTop("components", b.root.Content)
+	if comps != nil {
+		for i := 0; i < len(comps.Content)-1; i += 2 {
+			cType := comps.Content[i].Value
+			for j := 0; j < len(comps.Content[i+1].Content)-1; j += 2 {
+				name := comps.Content[i+1].Content[j].Value
+				b.reserve(cType, name)
+				if isRef, _, ref := utils.IsNodeRefValue(comps.Content[i+1].Content[j+1]); isRef {
+					if file, fragment := splitReference(ref); file != "" {
+						_, definition := b.resolveReference(b.rootPath, file, fragment)
+						if _, ok := b.hoisted[definition]; !ok {
+							b.hoisted[definition] = componentReference(cType, name)
+						}
+					}
+				}
+			}
+		}
+	}
+	b.walk(b.root, b.rootPath, nil)
+	return nil
+}
+
+// walk will recurse through every node, re-writing (and hoisting) every reference found. The location is the
+// absolute path of the file the node originated from, and keys is the path of keys leading to the node.
+func (b *bundler) walk(node *yaml.Node, location string, keys []string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			if node.Content[i].Value == "$ref" && utils.IsNodeStringValue(node.Content[i+1]) {
+				// keys next to the reference (like 'description' in 3.1) are walked from where they are written.
+				sibs := siblings(node)
+				for j := 0; j < len(sibs); j += 2 {
+					b.walk(sibs[j+1], location, appendKey(keys, sibs[j].Value))
+				}
+				b.handleReference(node, node.Content[i+1], location, keys)
+				return
+			}
+		}
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			b.walk(node.Content[i+1], location, appendKey(keys, node.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			b.walk(n, location, appendKey(keys, strconv.Itoa(i)))
+		}
+	}
+}
+
+func (b *bundler) handleReference(node, refNode *yaml.Node, location string, keys []string) {
+	ref := refNode.Value
+	file, fragment := splitReference(ref)
+
+	// local references in the root document stay exactly as they are.
+	if file == "" && location == b.rootPath {
+		return
+	}
+
+	target, definition := b.resolveReference(location, file, fragment)
+
+	// references from other files that point back into the root document become local references.
+	if target == b.rootPath && fragment != "" {
+		refNode.Value = "#" + fragment
+		return
+	}
+
+	// if the reference sits directly in a component slot, the content replaces the reference.
+	slot := len(keys) == 3 && keys[0] == "components" && location == b.rootPath
+	local, hoisted := b.hoisted[definition]
+	if hoisted && (!slot || local != componentReference(keys[1], keys[2])) {
+		refNode.Value = local
+		return
+	}
+
+	targetNode, err := b.locate(target, fragment)
+	if err != nil {
+		b.errors = append(b.errors, fmt.Errorf("unable to bundle reference '%s' (line %d, column %d): %w",
+			ref, refNode.Line, refNode.Column, err))
+		return
+	}
+
+	if slot {
+		sibs := siblings(node)
+		b.hoisted[definition] = componentReference(keys[1], keys[2])
+		b.replace(node, targetNode, target, keys)
+		mergeSiblings(node, sibs)
+		return
+	}
+
+	cType, name := b.componentTypeAndName(target, fragment, keys)
+	if cType == "" || (b.config.InlineRefs && !b.circular[definition]) {
+		if b.inProgress[definition] {
+			if cType == "" {
+				b.errors = append(b.errors, fmt.Errorf("unable to bundle circular reference '%s' (line %d, column %d): "+
+					"no component type can hold it", ref, refNode.Line, refNode.Column))
+				return
+			}
+			// the reference is circular, it will be hoisted once the outer inlining completes.
+			b.circular[definition] = true
+			local := componentReference(cType, b.reserve(cType, name))
+			b.hoisted[definition] = local
+			refNode.Value = local
+			return
+		}
+		b.inProgress[definition] = true
+		inlined := utils.CopyNode(targetNode)
+		b.walk(inlined, target, keys)
+		delete(b.inProgress, definition)
+
+		if local, ok := b.hoisted[definition]; ok {
+			// the inlined content looped back on itself, so hoist it and keep the reference.
+			_, cName := splitComponentReference(local)
+			b.addComponent(cType, cName, inlined)
+			refNode.Value = local
+			return
+		}
+		sibs := siblings(node)
+		*node = *inlined
+		mergeSiblings(node, sibs)
+		return
+	}
+
+	cName := b.reserve(cType, name)
+	b.hoisted[definition] = componentReference(cType, cName)
+	refNode.Value = b.hoisted[definition]
+
+	component := utils.CopyNode(targetNode)
+	b.walk(component, target, []string{"components", cType, cName})
+	b.addComponent(cType, cName, component)
+}
+
+// siblings returns the keys and values next to the '$ref' of a reference node.
+func siblings(node *yaml.Node) []*yaml.Node {
+	var sibs []*yaml.Node
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value != "$ref" {
+			sibs = append(sibs, node.Content[i], node.Content[i+1])
+		}
+	}
+	return sibs
+}
+
+// mergeSiblings sets the keys that were next to a '$ref' on the content that replaced it. They override the keys
+// of the referenced content, the way 'summary' and 'description' do in 3.1.
+func mergeSiblings(node *yaml.Node, sibs []*yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i < len(sibs); i += 2 {
+		replaced := false
+		for j := 0; j < len(node.Content)-1; j += 2 {
+			if node.Content[j].Value == sibs[i].Value {
+				node.Content[j+1] = sibs[i+1]
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			node.Content = append(node.Content, sibs[i], sibs[i+1])
+		}
+	}
+}
+
+// resolveReference returns the absolute location of the file a reference points to, and the full definition
+// of the reference (the absolute location and the fragment) that is used to track hoisted references.
+func (b *bundler) resolveReference(location, file, fragment string) (string, string) {
+	target := location
+	if file != "" {
+		target = b.resolveLocation(location, file)
+	}
+	if fragment != "" {
+		return target, fmt.Sprintf("%s#%s", target, fragment)
+	}
+	return target, target
+}
+
+// replace will swap out the reference node for a processed copy of the target.
+func (b *bundler) replace(node, target *yaml.Node, location string, keys []string) {
+	replacement := utils.CopyNode(target)
+	*node = *replacement
+	b.walk(node, location, keys)
+}
+
+// resolveLocation will resolve a file reference against the location of the file that contains it.
+func (b *bundler) resolveLocation(location, file string) string {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") || filepath.IsAbs(file) {
+		return file
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		base, err := url.Parse(location)
+		if err == nil {
+			if rel, rErr := url.Parse(file); rErr == nil {
+				return base.ResolveReference(rel).String()
+			}
+		}
+	}
+	if location == "" {
+		if b.baseURL != nil {
+			if rel, rErr := url.Parse(file); rErr == nil {
+				return b.baseURL.ResolveReference(rel).String()
+			}
+		}
+		abs, _ := filepath.Abs(file)
+		return abs
+	}
+	abs, _ := filepath.Abs(filepath.Join(filepath.Dir(location), file))
+	return abs
+}
+
+// locate will find the node a reference points to, by opening the file in the rolodex and following the pointer.
+func (b *bundler) locate(location, fragment string) (*yaml.Node, error) {
+	var root *yaml.Node
+	if location == b.rootPath {
+		root = b.root
+	} else {
+		if b.rolodex == nil {
+			return nil, errors.New("no rolodex is available to look up external files")
+		}
+		f, err := b.rolodex.Open(location)
+		if f == nil {
+			if err == nil {
+				err = fmt.Errorf("file '%s' cannot be found", location)
+			}
+			return nil, err
+		}
+		root, err = f.GetContentAsYAMLNode()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	found := findNodeByPointer(root, fragment)
+	if found == nil {
+		return nil, fmt.Errorf("'%s' cannot be found in '%s'", fragment, location)
+	}
+	return found, nil
+}
+
+// componentTypeAndName determines which component type a referenced node should be hoisted into and the name it
+// should be given. If the type cannot be determined, the type returned is empty and the content must be inlined.
+func (b *bundler) componentTypeAndName(location, fragment string, keys []string) (string, string) {
+	segs := splitPointer(fragment)
+	var name string
+	if len(segs) > 0 {
+		name = segs[len(segs)-1]
+	} else {
+		name = strings.TrimSuffix(filepath.Base(location), filepath.Ext(location))
+	}
+	name = componentNameExp.ReplaceAllString(name, "_")
+
+	if len(segs) == 3 && segs[0] == "components" && segs[1] != "pathItems" {
+		return segs[1], name
+	}
+	if len(segs) == 2 && (segs[0] == "definitions" || segs[0] == "$defs") {
+		return "schemas", name
+	}
+	return inferComponentType(keys), name
+}
+
+// inferComponentType will look at the keys leading up to a reference and decide which type of component
+// the reference represents.
+func inferComponentType(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	last := keys[len(keys)-1]
+	var parent string
+	if len(keys) > 1 {
+		parent = keys[len(keys)-2]
+	}
+	if len(keys) == 3 && keys[0] == "components" {
+		if keys[1] == "pathItems" {
+			return ""
+		}
+		return keys[1]
+	}
+	if schemaKeywords[last] || schemaCollectionKeywords[parent] {
+		return "schemas"
+	}
+	if last == "requestBody" {
+		return "requestBodies"
+	}
+	if cType, ok := componentCollectionKeywords[parent]; ok {
+		return cType
+	}
+	return ""
+}
+
+// reserve will find a free name for a component of the supplied type and mark it as used.
+func (b *bundler) reserve(cType, name string) string {
+	if b.names[cType] == nil {
+		b.names[cType] = make(map[string]bool)
+	}
+	candidate := name
+	for i := 1; b.names[cType][candidate]; i++ {
+		candidate = fmt.Sprintf("%s__%d", name, i)
+	}
+	b.names[cType][candidate] = true
+	return candidate
+}
+
+// addComponent will add a hoisted node to the components section of the root document, creating the
+// components object and component type map if required.
+func (b *bundler) addComponent(cType, name string, node *yaml.Node) {
+	_, comps := utils.FindKeyNodeTop("components", b.root.Content)
+	if comps == nil {
+		comps = utils.CreateEmptyMapNode()
+		b.root.Content = append(b.root.Content, utils.CreateStringNode("components"), comps)
+	}
+	_, typeMap := utils.FindKeyNodeTop(cType, comps.Content)
+	if typeMap == nil {
+		typeMap = utils.CreateEmptyMapNode()
+		comps.Content = append(comps.Content, utils.CreateStringNode(cType), typeMap)
+	}
+	typeMap.Content = append(typeMap.Content, utils.CreateStringNode(name), node)
+}
+
+func componentReference(cType, name string) string {
+	return fmt.Sprintf("#/components/%s/%s", cType, utils.EscapePointerSegment(name))
+}
+
+func splitComponentReference(ref string) (string, string) {
+	segs := splitPointer(strings.TrimPrefix(ref, "#"))
+	return segs[1], segs[2]
+}
+
+// splitReference breaks a reference into a file and a JSON pointer fragment.
+func splitReference(ref string) (string, string) {
+	if i := strings.Index(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// splitPointer breaks a JSON pointer into unescaped segments.
+func splitPointer(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+	segs := strings.Split(pointer, "/")
+	for i := range segs {
+		if unescaped, err := url.PathUnescape(segs[i]); err == nil {
+			segs[i] = unescaped
+		}
+		segs[i] = utils.UnescapePointerSegment(segs[i])
+	}
+	return segs
+}
+
+// findNodeByPointer follows a JSON pointer from the root node, returns nil if nothing can be found.
+func findNodeByPointer(root *yaml.Node, pointer string) *yaml.Node {
+	node := root
+	for _, seg := range splitPointer(pointer) {
+		if node == nil {
+			return nil
+		}
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i < len(node.Content)-1; i += 2 {
+				if node.Content[i].Value == seg {
+					next = node.Content[i+1]
+					break
+				}
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[i]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+func appendKey(keys []string, key string) []string {
+	k := make([]string, len(keys), len(keys)+1)
+	copy(k, keys)
+	return append(k, key)
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package bundler
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func loadBundlerSpec(t *testing.T) []byte {
+	spec, err := os.ReadFile("test/specs/main.yaml")
+	require.NoError(t, err)
+	return spec
+}
+
+func bundlerConfig() *datamodel.DocumentConfiguration {
+	return &datamodel.DocumentConfiguration{
+		BasePath: "test/specs",
+	}
+}
+
+func TestBundleDocument(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration(loadBundlerSpec(t), bundlerConfig())
+	require.NoError(t, err)
+
+	bundled, err := BundleDocument(doc, nil)
+	require.NoError(t, err)
+
+	// the bundled result must not contain any external references.
+	assert.NotContains(t, string(bundled), ".yaml")
+
+	// the result must be a valid document that builds without any errors.
+	bundledDoc, err := libopenapi.NewDocument(bundled)
+	require.NoError(t, err)
+	m, errs := bundledDoc.BuildV3Model()
+	require.NotNil(t, m)
+
+	for _, e := range errs {
+		assert.Contains(t, e.Error(), "circular reference")
+	}
+
+	schemas := m.Model.Components.Schemas
+	assert.Equal(t, "string", schemas.GetOrZero("Pet").Schema().Type[0])
+	assert.Equal(t, "object", schemas.GetOrZero("pet").Schema().Type[0])
+	assert.Equal(t, "object", schemas.GetOrZero("owner").Schema().Type[0])
+	assert.Equal(t, "#/components/schemas/pet",
+		schemas.GetOrZero("owner").Schema().Properties.GetOrZero("pets").Schema().Items.A.GetReference())
+	assert.Equal(t, "#/components/schemas/Category",
+		schemas.GetOrZero("pet").Schema().Properties.GetOrZero("category").GetReference())
+	assert.NotNil(t, schemas.GetOrZero("Limit"))
+	assert.NotNil(t, schemas.GetOrZero("Error"))
+
+	assert.Equal(t, "limit", m.Model.Components.Parameters.GetOrZero("Limit").Name)
+	assert.Equal(t, "offset", m.Model.Components.Parameters.GetOrZero("Offset").Name)
+	assert.Equal(t, "Something went wrong", m.Model.Components.Responses.GetOrZero("Error").Description)
+
+	// path items are always inlined into the paths object.
+	owners := m.Model.Paths.PathItems.GetOrZero("/owners")
+	require.NotNil(t, owners)
+	assert.Equal(t, "listOwners", owners.Get.OperationId)
+}
+
+func TestBundleDocument_Collision(t *testing.T) {
+	spec := strings.Replace(string(loadBundlerSpec(t)), "Pet:", "pet:", 1)
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(spec), bundlerConfig())
+	require.NoError(t, err)
+
+	bundled, err := BundleDocument(doc, nil)
+	require.NoError(t, err)
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal(bundled, &root))
+	schemas := findNodeByPointer(root.Content[0], "/components/schemas")
+	require.NotNil(t, schemas)
+	assert.NotNil(t, findNodeByPointer(schemas, "/pet"))
+	assert.Equal(t, "string", findNodeByPointer(schemas, "/pet/type").Value)
+	assert.Equal(t, "object", findNodeByPointer(schemas, "/pet__1/type").Value)
+	assert.Equal(t, "#/components/schemas/pet__1",
+		findNodeByPointer(schemas, "/owner/properties/pets/items/$ref").Value)
+}
+
+func TestBundleDocument_Inline(t *testing.T) {
+	doc, err := libopenapi.NewDocumentWithConfiguration(loadBundlerSpec(t), bundlerConfig())
+	require.NoError(t, err)
+
+	bundled, err := BundleDocument(doc, &BundleConfig{InlineRefs: true})
+	require.NoError(t, err)
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal(bundled, &root))
+	r := root.Content[0]
+
+	// the limit parameter is inlined, as well as the schema it references.
+	assert.Equal(t, "limit", findNodeByPointer(r, "/paths/~1pets/get/parameters/0/name").Value)
+	assert.Equal(t, "100", findNodeByPointer(r, "/paths/~1pets/get/parameters/0/schema/maximum").Value)
+
+	// local references in the root document are kept.
+	assert.Equal(t, "#/components/parameters/Offset",
+		findNodeByPointer(r, "/paths/~1pets/get/parameters/1/$ref").Value)
+
+	// pet and owner are circular, so they must stay as references.
+	assert.Nil(t, findNodeByPointer(r, "/components/parameters/Limit"))
+	assert.NotNil(t, findNodeByPointer(r, "/components/schemas/pet"))
+	assert.NotContains(t, string(bundled), ".yaml")
+
+	bundledDoc, err := libopenapi.NewDocument(bundled)
+	require.NoError(t, err)
+	m, _ := bundledDoc.BuildV3Model()
+	assert.NotNil(t, m)
+}
+
+func TestBundleDocument_MissingReference(t *testing.T) {
+	spec := strings.Replace(string(loadBundlerSpec(t)), "schemas/pet.yaml", "schemas/missing.yaml", 1)
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(spec), bundlerConfig())
+	require.NoError(t, err)
+
+	bundled, err := BundleDocument(doc, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.yaml")
+	assert.NotNil(t, bundled)
+}
+
+func TestBundleDocument_Swagger(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`swagger: 2.0`))
+	require.NoError(t, err)
+	_, err = BundleDocument(doc, nil)
+	assert.Error(t, err)
+}
+
+func TestBundleBytes_JSON(t *testing.T) {
+	spec := `{"openapi": "3.0.3", "info": {"title": "json", "version": "1"},
+"paths": {"/pets": {"get": {"responses": {"200": {"description": "OK",
+"content": {"application/json": {"schema": {"$ref": "components.yaml#/components/schemas/Error"}}}}}}}}}`
+
+	bundled, err := BundleBytes([]byte(spec), bundlerConfig(), nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(bundled), "{"))
+	assert.Contains(t, string(bundled), `"$ref": "#/components/schemas/Error"`)
+}
+
+func TestBundleBytes_ComponentReferences(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/pet.yaml", []byte("type: object\nproperties:\n  name:\n    type: string\n"), 0o644))
+	spec := `openapi: 3.1.0
+info:
+  title: components
+  version: "1"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: pet.yaml
+components:
+  schemas:
+    Pet:
+      $ref: pet.yaml`
+
+	bundled, err := BundleBytes([]byte(spec), &datamodel.DocumentConfiguration{BasePath: dir}, nil)
+	require.NoError(t, err)
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal(bundled, &root))
+	r := root.Content[0]
+
+	// the component slot gets the content of the file, and every other reference to the file re-uses it.
+	assert.Equal(t, "object", findNodeByPointer(r, "/components/schemas/Pet/type").Value)
+	assert.Equal(t, "#/components/schemas/Pet",
+		findNodeByPointer(r, "/paths/~1pets/get/responses/200/content/application~1json/schema/$ref").Value)
+	assert.Equal(t, 2, len(findNodeByPointer(r, "/components/schemas").Content))
+}
+
+func TestBundleBytes_ReferenceSiblings(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/pet.yaml", []byte("type: object\ndescription: A pet.\n"), 0o644))
+	require.NoError(t, os.WriteFile(dir+"/name.yaml", []byte("type: string\n"), 0o644))
+	spec := `openapi: 3.1.0
+info:
+  title: siblings
+  version: "1"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: pet.yaml
+                description: The pet that was found.
+                properties:
+                  name:
+                    $ref: name.yaml`
+	config := &datamodel.DocumentConfiguration{BasePath: dir}
+
+	bundled, err := BundleBytes([]byte(spec), config, &BundleConfig{InlineRefs: true})
+	require.NoError(t, err)
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal(bundled, &root))
+	schema := findNodeByPointer(root.Content[0], "/paths/~1pets/get/responses/200/content/application~1json/schema")
+	require.NotNil(t, schema)
+
+	// keys next to an inlined reference are kept, and override the keys of the referenced content.
+	assert.Equal(t, "object", findNodeByPointer(schema, "/type").Value)
+	assert.Equal(t, "The pet that was found.", findNodeByPointer(schema, "/description").Value)
+	assert.Equal(t, "string", findNodeByPointer(schema, "/properties/name/type").Value)
+	assert.Nil(t, findNodeByPointer(schema, "/$ref"))
+
+	// the same goes for a reference that sits in a component slot.
+	bundled, err = BundleBytes([]byte(spec+`
+components:
+  schemas:
+    Pet:
+      $ref: pet.yaml
+      title: Pet`), config, nil)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(bundled, &root))
+	pet := findNodeByPointer(root.Content[0], "/components/schemas/Pet")
+	require.NotNil(t, pet)
+	assert.Equal(t, "Pet", findNodeByPointer(pet, "/title").Value)
+	assert.Equal(t, "A pet.", findNodeByPointer(pet, "/description").Value)
+}
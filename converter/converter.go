@@ -0,0 +1,123 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package converter translates documents between specification versions. Every conversion builds a new
+// document, rendered from the source model, which is then re-loaded and built, in the same way as
+// libopenapi.Document.RenderAndReload does. The source document is never modified.
+//
+// Not everything can be translated between versions without losing information, so every lossy conversion is
+// reported as a Warning, pointing at the construct in the source document that could not be converted cleanly.
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Warning describes a construct in the source document that could not be converted without losing information.
+type Warning struct {
+	// Path is a JSON pointer to the construct in the source document, for example '/paths/~1pets/get'.
+	Path string
+
+	// Message explains what was lost (or changed) during the conversion.
+	Message string
+
+	// Line and Column of the construct in the source document, if known.
+	Line   int
+	Column int
+}
+
+func (w *Warning) Error() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("%s (line %d, column %d): %s", w.Path, w.Line, w.Column, w.Message)
+	}
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// Result contains the outcome of a conversion.
+type Result struct {
+	// Bytes are the rendered bytes of the converted document.
+	Bytes []byte
+
+	// Document is the new Document, created from the rendered Bytes.
+	Document libopenapi.Document
+
+	// Model is the OpenAPI 3+ model built from the new Document.
+	Model *libopenapi.DocumentModel[v3high.Document]
+
+	// Warnings contains every lossy conversion, in the order they were encountered.
+	Warnings []*Warning
+}
+
+// reload will render the root node into bytes (in the requested format) and then create and build a new
+// OpenAPI 3+ document from those bytes.
+func reload(root *yaml.Node, fileType string, indent int, config *datamodel.DocumentConfiguration,
+	warnings []*Warning,
+) (*Result, error) {
+	rendered, err := json.RenderNode(root, fileType == datamodel.JSONFileType, indent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render converted document: %w", err)
+	}
+	if config == nil {
+		config = datamodel.NewDocumentConfiguration()
+	}
+	doc, err := libopenapi.NewDocumentWithConfiguration(rendered, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reload converted document: %w", err)
+	}
+	result := &Result{Bytes: rendered, Document: doc, Warnings: warnings}
+	m, errs := doc.BuildV3Model()
+	result.Model = m
+	if len(errs) > 0 {
+		return result, fmt.Errorf("converted document built with errors: %w", joinErrors(errs))
+	}
+	return result, nil
+}
+
+func joinErrors(errs []error) error {
+	var msgs []string
+	for _, e := range errs {
+		if e != nil {
+			msgs = append(msgs, e.Error())
+		}
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// addString adds a string value to a map node, empty values are skipped.
+func addString(m *yaml.Node, key, value string) {
+	if value != "" {
+		m.Content = append(m.Content, utils.CreateStringNode(key), utils.CreateStringNode(value))
+	}
+}
+
+// addNode adds a value node to a map node, nil values are skipped.
+func addNode(m *yaml.Node, key string, value *yaml.Node) {
+	if value != nil {
+		m.Content = append(m.Content, utils.CreateStringNode(key), value)
+	}
+}
+
+// addExtensions copies every extension into a map node.
+func addExtensions(m *yaml.Node, ext *orderedmap.Map[string, *yaml.Node]) {
+	for pair := orderedmap.First(ext); pair != nil; pair = pair.Next() {
+		addNode(m, pair.Key(), utils.CopyNode(pair.Value()))
+	}
+}
+
+// marshal renders a high-level object into a node that is safe to modify.
+func marshal(v any) *yaml.Node {
+	n := utils.CreateYamlNode(v)
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	return utils.CopyNode(n)
+}
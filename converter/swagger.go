@@ -0,0 +1,935 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2high "github.com/pb33f/libopenapi/datamodel/high/v2"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	v2low "github.com/pb33f/libopenapi/datamodel/low/v2"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIVersion is the version of OpenAPI that a Swagger document is converted into.
+const OpenAPIVersion = "3.0.3"
+
+const (
+	defaultMediaType = "application/json"
+	formMediaType    = "application/x-www-form-urlencoded"
+	multipartType    = "multipart/form-data"
+)
+
+var invalidComponentName = regexp.MustCompile(`[^a-zA-Z0-9.\-_]`)
+
+// ConvertSwaggerDocument will build a Swagger model from the supplied Document and convert it into an
+// OpenAPI 3.0 document, using ConvertSwagger. The configuration of the supplied Document is used when
+// re-loading the converted document.
+func ConvertSwaggerDocument(doc libopenapi.Document) (*Result, error) {
+	if doc == nil || doc.GetSpecInfo() == nil {
+		return nil, errors.New("unable to convert, document has not been initialized")
+	}
+	if doc.GetSpecInfo().SpecFormat != datamodel.OAS2 {
+		return nil, errors.New("unable to convert, document is not a Swagger (OpenAPI 2) document")
+	}
+	m, errs := doc.BuildV2Model()
+	if m == nil {
+		return nil, fmt.Errorf("unable to build Swagger model: %w", joinErrors(errs))
+	}
+	return ConvertSwagger(&m.Model, doc.GetConfiguration())
+}
+
+// ConvertSwagger will convert a Swagger (OpenAPI 2) model into an OpenAPI 3.0 document.
+//
+//   - 'body' and 'formData' parameters become a 'requestBody'.
+//   - 'definitions' become 'components.schemas' and every reference is re-written to match.
+//   - shared 'parameters' and 'responses' become 'components.parameters', 'components.requestBodies' and
+//     'components.responses'.
+//   - 'consumes' and 'produces' become the media types of request bodies and responses.
+//   - 'securityDefinitions' become 'components.securitySchemes'.
+//   - 'host', 'basePath' and 'schemes' become 'servers'.
+//
+// The Swagger model must have been built from a document, as the low-level model is used to locate references
+// and the values that were set. If the configuration is nil, a default configuration is used to re-load the
+// converted document. Every lossy conversion is reported in the Warnings of the Result.
+func ConvertSwagger(swagger *v2high.Swagger, config *datamodel.DocumentConfiguration) (*Result, error) {
+	if swagger == nil || swagger.GoLow() == nil {
+		return nil, errors.New("unable to convert, swagger model has not been built from a document")
+	}
+	c := &swaggerConverter{
+		swagger:  swagger,
+		consumes: swagger.Consumes,
+		produces: swagger.Produces,
+		names:    make(map[string]map[string]string),
+	}
+	root := c.convert()
+
+	fileType, indent := datamodel.YAMLFileType, 2
+	if info := swagger.GoLow().SpecInfo; info != nil {
+		fileType, indent = info.SpecFileType, info.OriginalIndentation
+	}
+	return reload(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, fileType, indent, config, c.warnings)
+}
+
+type swaggerConverter struct {
+	swagger  *v2high.Swagger
+	consumes []string
+	produces []string
+	warnings []*Warning
+
+	// names maps a definition, parameter or response name to the component name it was converted into.
+	names map[string]map[string]string
+}
+
+func (c *swaggerConverter) warn(pointer string, node *yaml.Node, message string, args ...any) {
+	w := &Warning{Path: pointer, Message: fmt.Sprintf(message, args...)}
+	if node != nil {
+		w.Line, w.Column = node.Line, node.Column
+	}
+	c.warnings = append(c.warnings, w)
+}
+
+func (c *swaggerConverter) convert() *yaml.Node {
+	s := c.swagger
+	c.registerNames()
+
+	root := utils.CreateEmptyMapNode()
+	addString(root, "openapi", OpenAPIVersion)
+	if s.Info != nil {
+		addNode(root, "info", marshal(s.Info))
+	}
+	addNode(root, "servers", c.servers(s.Schemes, "/schemes"))
+	if len(s.Tags) > 0 {
+		addNode(root, "tags", marshal(s.Tags))
+	}
+	if s.ExternalDocs != nil {
+		addNode(root, "externalDocs", marshal(s.ExternalDocs))
+	}
+	if s.GoLow().Security.ValueNode != nil {
+		addNode(root, "security", c.security(s.Security))
+	}
+	paths := utils.CreateEmptyMapNode()
+	if s.Paths != nil {
+		for pair := orderedmap.First(s.Paths.PathItems); pair != nil; pair = pair.Next() {
+			addNode(paths, pair.Key(), c.pathItem(pair.Value(), "/paths/"+utils.EscapePointerSegment(pair.Key())))
+		}
+		addExtensions(paths, s.Paths.Extensions)
+	}
+	addNode(root, "paths", paths)
+	if components := c.components(); len(components.Content) > 0 {
+		addNode(root, "components", components)
+	}
+	addExtensions(root, s.Extensions)
+	return root
+}
+
+// registerNames records the component name of every definition, parameter and response, so references can be
+// re-written before the components themselves are converted. Names that are not valid component names are
+// sanitized.
+func (c *swaggerConverter) registerNames() {
+	register := func(section, name string) {
+		if c.names[section] == nil {
+			c.names[section] = make(map[string]string)
+		}
+		clean := invalidComponentName.ReplaceAllString(name, "_")
+		if clean != name {
+			c.warn(fmt.Sprintf("/%s/%s", section, utils.EscapePointerSegment(name)), nil,
+				"'%s' is not a valid component name, it has been renamed to '%s'", name, clean)
+		}
+		c.names[section][name] = clean
+	}
+	s := c.swagger
+	if s.Definitions != nil {
+		for pair := orderedmap.First(s.Definitions.Definitions); pair != nil; pair = pair.Next() {
+			register("definitions", pair.Key())
+		}
+	}
+	if s.Parameters != nil {
+		for pair := orderedmap.First(s.Parameters.Definitions); pair != nil; pair = pair.Next() {
+			register("parameters", pair.Key())
+		}
+	}
+	if s.Responses != nil {
+		for pair := orderedmap.First(s.Responses.Definitions); pair != nil; pair = pair.Next() {
+			register("responses", pair.Key())
+		}
+	}
+}
+
+func (c *swaggerConverter) components() *yaml.Node {
+	s := c.swagger
+	components := utils.CreateEmptyMapNode()
+
+	if s.Definitions != nil && s.Definitions.Definitions.Len() > 0 {
+		schemas := utils.CreateEmptyMapNode()
+		for pair := orderedmap.First(s.Definitions.Definitions); pair != nil; pair = pair.Next() {
+			addNode(schemas, c.names["definitions"][pair.Key()],
+				c.schema(pair.Value(), "/definitions/"+utils.EscapePointerSegment(pair.Key())))
+		}
+		addNode(components, "schemas", schemas)
+	}
+
+	if s.Responses != nil && s.Responses.Definitions.Len() > 0 {
+		responses := utils.CreateEmptyMapNode()
+		for pair := orderedmap.First(s.Responses.Definitions); pair != nil; pair = pair.Next() {
+			addNode(responses, c.names["responses"][pair.Key()],
+				c.response(pair.Value(), c.produces, "/responses/"+utils.EscapePointerSegment(pair.Key())))
+		}
+		addNode(components, "responses", responses)
+	}
+
+	if s.Parameters != nil && s.Parameters.Definitions.Len() > 0 {
+		parameters, bodies := utils.CreateEmptyMapNode(), utils.CreateEmptyMapNode()
+		for pair := orderedmap.First(s.Parameters.Definitions); pair != nil; pair = pair.Next() {
+			p, name := pair.Value(), c.names["parameters"][pair.Key()]
+			pointer := "/parameters/" + utils.EscapePointerSegment(pair.Key())
+			switch p.In {
+			case "body":
+				addNode(bodies, name, c.requestBody(p, c.consumes, pointer))
+			case "formData":
+				// form parameters are properties of a request body schema, so they cannot be shared on their own.
+				// they are in-lined into every operation that references them instead.
+				c.warn(pointer, keyNode(p.GoLow().Name), "shared formData parameter '%s' has been in-lined into "+
+					"every operation that references it, it is not a component", pair.Key())
+			default:
+				addNode(parameters, name, c.parameter(p, pointer))
+			}
+		}
+		if len(parameters.Content) > 0 {
+			addNode(components, "parameters", parameters)
+		}
+		if len(bodies.Content) > 0 {
+			addNode(components, "requestBodies", bodies)
+		}
+	}
+
+	if s.SecurityDefinitions != nil && s.SecurityDefinitions.Definitions.Len() > 0 {
+		schemes := utils.CreateEmptyMapNode()
+		for pair := orderedmap.First(s.SecurityDefinitions.Definitions); pair != nil; pair = pair.Next() {
+			addNode(schemes, pair.Key(), c.securityScheme(pair.Value(),
+				"/securityDefinitions/"+utils.EscapePointerSegment(pair.Key())))
+		}
+		addNode(components, "securitySchemes", schemes)
+	}
+	return components
+}
+
+// servers converts the host, basePath and schemes into a list of servers. Without any schemes, the server URL is
+// scheme relative, which means the same scheme used to access the document.
+func (c *swaggerConverter) servers(schemes []string, pointer string) *yaml.Node {
+	s := c.swagger
+	if s.Host == "" && s.BasePath == "" && len(schemes) == 0 {
+		return nil
+	}
+	var urls []string
+	switch {
+	case s.Host == "":
+		if len(schemes) > 0 {
+			c.warn(pointer, nil, "schemes cannot be used without a host, they have been dropped")
+		}
+		urls = append(urls, defaultString(s.BasePath, "/"))
+	case len(schemes) == 0:
+		urls = append(urls, "//"+s.Host+s.BasePath)
+	default:
+		for _, scheme := range schemes {
+			urls = append(urls, fmt.Sprintf("%s://%s%s", scheme, s.Host, s.BasePath))
+		}
+	}
+	servers := utils.CreateEmptySequenceNode()
+	for _, u := range urls {
+		server := utils.CreateEmptyMapNode()
+		addString(server, "url", u)
+		servers.Content = append(servers.Content, server)
+	}
+	return servers
+}
+
+func (c *swaggerConverter) security(requirements []*base.SecurityRequirement) *yaml.Node {
+	seq := utils.CreateEmptySequenceNode()
+	for _, r := range requirements {
+		seq.Content = append(seq.Content, marshal(r))
+	}
+	return seq
+}
+
+func (c *swaggerConverter) pathItem(p *v2high.PathItem, pointer string) *yaml.Node {
+	item := utils.CreateEmptyMapNode()
+	if p.Ref != "" {
+		// local references point into the swagger document, their targets do not exist once converted.
+		if strings.HasPrefix(p.Ref, "#") {
+			c.warn(pointer, p.GoLow().Ref.ValueNode, "local path item reference '%s' cannot be kept, "+
+				"it has been in-lined", p.Ref)
+		} else {
+			c.warn(pointer, p.GoLow().Ref.ValueNode, "path item reference '%s' has been kept as-is, "+
+				"the referenced document is not converted", p.Ref)
+			addString(item, "$ref", p.Ref)
+		}
+	}
+
+	// body and form parameters declared for every operation are moved into the request body of each operation.
+	var shared []*v2high.Parameter
+	var sharedLow []low.ValueReference[*v2low.Parameter]
+	var params []*yaml.Node
+	for i, param := range p.Parameters {
+		lp := p.GoLow().Parameters.Value[i]
+		if param.In == "body" || param.In == "formData" {
+			shared = append(shared, param)
+			sharedLow = append(sharedLow, lp)
+			continue
+		}
+		params = append(params, c.parameterOrRef(param, lp, fmt.Sprintf("%s/parameters/%d", pointer, i)))
+	}
+
+	// operations and parameters are emitted in the order they were declared in the source document.
+	lp := p.GoLow()
+	type entry struct {
+		key  string
+		line int
+		node func() *yaml.Node
+	}
+	var entries []entry
+	ops := []struct {
+		method string
+		op     *v2high.Operation
+		ref    low.NodeReference[*v2low.Operation]
+	}{
+		{"get", p.Get, lp.Get}, {"put", p.Put, lp.Put}, {"post", p.Post, lp.Post},
+		{"delete", p.Delete, lp.Delete}, {"options", p.Options, lp.Options},
+		{"head", p.Head, lp.Head}, {"patch", p.Patch, lp.Patch},
+	}
+	for _, o := range ops {
+		if o.op == nil {
+			continue
+		}
+		o := o
+		entries = append(entries, entry{o.method, keyLine(o.ref.KeyNode), func() *yaml.Node {
+			return c.operation(o.op, shared, sharedLow, pointer+"/"+o.method)
+		}})
+	}
+	if len(params) > 0 {
+		entries = append(entries, entry{"parameters", keyLine(lp.Parameters.KeyNode), func() *yaml.Node {
+			return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: params}
+		}})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].line < entries[j].line })
+	for _, e := range entries {
+		addNode(item, e.key, e.node())
+	}
+	addExtensions(item, p.Extensions)
+	return item
+}
+
+// keyLine returns the source line of a key, models built in code have no lines and sort after everything else.
+func keyLine(key *yaml.Node) int {
+	if key == nil || key.Line == 0 {
+		return math.MaxInt
+	}
+	return key.Line
+}
+
+func (c *swaggerConverter) operation(op *v2high.Operation, shared []*v2high.Parameter,
+	sharedLow []low.ValueReference[*v2low.Parameter], pointer string,
+) *yaml.Node {
+	o := utils.CreateEmptyMapNode()
+	if len(op.Tags) > 0 {
+		addNode(o, "tags", utils.CreateYamlNode(op.Tags))
+	}
+	addString(o, "summary", op.Summary)
+	addString(o, "description", op.Description)
+	if op.ExternalDocs != nil {
+		addNode(o, "externalDocs", marshal(op.ExternalDocs))
+	}
+	addString(o, "operationId", op.OperationId)
+
+	consumes, produces := c.consumes, c.produces
+	if len(op.Consumes) > 0 {
+		consumes = op.Consumes
+	}
+	if len(op.Produces) > 0 {
+		produces = op.Produces
+	}
+
+	// operation parameters override path item parameters with the same name and location.
+	var body, form []*v2high.Parameter
+	var bodyLow []low.ValueReference[*v2low.Parameter]
+	var bodyPointers []string
+	params := utils.CreateEmptySequenceNode()
+	overridden := make(map[string]bool)
+	for i, param := range op.Parameters {
+		lp := op.GoLow().Parameters.Value[i]
+		pp := fmt.Sprintf("%s/parameters/%d", pointer, i)
+		overridden[param.In+":"+param.Name] = true
+		switch param.In {
+		case "body":
+			body, bodyLow, bodyPointers = append(body, param), append(bodyLow, lp), append(bodyPointers, pp)
+		case "formData":
+			form = append(form, param)
+			c.formReference(lp, pp)
+		default:
+			params.Content = append(params.Content, c.parameterOrRef(param, lp, pp))
+		}
+	}
+	for i, param := range shared {
+		if overridden[param.In+":"+param.Name] {
+			continue
+		}
+		pp := fmt.Sprintf("%s/parameters/%d", path.Dir(pointer), i)
+		if param.In == "body" {
+			body, bodyLow, bodyPointers = append(body, param), append(bodyLow, sharedLow[i]), append(bodyPointers, pp)
+		} else {
+			form = append(form, param)
+			c.formReference(sharedLow[i], pp)
+		}
+	}
+	if len(params.Content) > 0 {
+		addNode(o, "parameters", params)
+	}
+
+	switch {
+	case len(body) > 0:
+		if len(form) > 0 {
+			c.warn(pointer, nil, "operation has both body and formData parameters, the formData parameters "+
+				"have been dropped")
+		}
+		if ref := c.localReference(bodyLow[0], "parameters", bodyPointers[0]); ref != "" {
+			addNode(o, "requestBody", utils.CreateRefNode("#/components/requestBodies/"+ref))
+		} else {
+			addNode(o, "requestBody", c.requestBody(body[0], consumes, bodyPointers[0]))
+		}
+	case len(form) > 0:
+		addNode(o, "requestBody", c.formBody(form, consumes, pointer))
+	}
+
+	if op.Responses != nil {
+		addNode(o, "responses", c.responses(op.Responses, produces, pointer+"/responses"))
+	}
+	if op.Schemes != nil {
+		addNode(o, "servers", c.servers(op.Schemes, pointer+"/schemes"))
+	}
+	if op.Deprecated {
+		addNode(o, "deprecated", utils.CreateBoolNode("true"))
+	}
+	if op.GoLow().Security.ValueNode != nil {
+		addNode(o, "security", c.security(op.Security))
+	}
+	addExtensions(o, op.Extensions)
+	return o
+}
+
+// formReference reports a reference to a shared formData parameter, those are in-lined.
+func (c *swaggerConverter) formReference(lp low.ValueReference[*v2low.Parameter], pointer string) {
+	if lp.IsReference() && !strings.HasPrefix(lp.GetReference(), "#/") {
+		c.warn(pointer, lp.GetReferenceNode(), "external reference '%s' has been in-lined", lp.GetReference())
+	}
+}
+
+// localReference returns the name of the component a value references, if it is a local reference into the
+// supplied section of the Swagger document. External references are reported and in-lined.
+func (c *swaggerConverter) localReference(ref low.IsReferenced, section, pointer string) string {
+	if !ref.IsReference() {
+		return ""
+	}
+	r := ref.GetReference()
+	prefix := "#/" + section + "/"
+	if strings.HasPrefix(r, prefix) {
+		name := utils.UnescapePointerSegment(strings.TrimPrefix(r, prefix))
+		if n, ok := c.names[section][name]; ok {
+			return utils.EscapePointerSegment(n)
+		}
+	}
+	c.warn(pointer, ref.GetReferenceNode(), "reference '%s' cannot be kept, the referenced object has been "+
+		"in-lined", r)
+	return ""
+}
+
+func (c *swaggerConverter) parameterOrRef(p *v2high.Parameter, lp low.ValueReference[*v2low.Parameter],
+	pointer string,
+) *yaml.Node {
+	if ref := c.localReference(lp, "parameters", pointer); ref != "" {
+		return utils.CreateRefNode("#/components/parameters/" + ref)
+	}
+	return c.parameter(p, pointer)
+}
+
+// parameter converts a query, header or path parameter. The type information moves into a schema and the
+// collection format is translated into a style.
+func (c *swaggerConverter) parameter(p *v2high.Parameter, pointer string) *yaml.Node {
+	lp := p.GoLow()
+	param := utils.CreateEmptyMapNode()
+	addString(param, "name", p.Name)
+	addString(param, "in", p.In)
+	addString(param, "description", p.Description)
+	if p.Required != nil {
+		addNode(param, "required", boolNode(*p.Required))
+	}
+	if p.AllowEmptyValue != nil {
+		if p.In == "query" {
+			addNode(param, "allowEmptyValue", boolNode(*p.AllowEmptyValue))
+		} else {
+			c.warn(pointer, keyNode(lp.AllowEmptyValue), "allowEmptyValue is only supported for query "+
+				"parameters, it has been dropped")
+		}
+	}
+	if p.Type == "array" {
+		style, explode := c.collectionFormat(p.CollectionFormat, p.In, pointer, keyNode(lp.CollectionFormat))
+		addString(param, "style", style)
+		if explode != nil {
+			addNode(param, "explode", boolNode(*explode))
+		}
+	}
+	if p.Type == "file" {
+		c.warn(pointer, keyNode(lp.Type), "file parameters are only supported as formData, the type has been "+
+			"converted to a binary string")
+	}
+	addNode(param, "schema", c.simpleSchema(parameterFields(lp), lp.Items.Value, pointer))
+	addExtensions(param, p.Extensions)
+	return param
+}
+
+// collectionFormat translates a Swagger collection format into an OpenAPI 3 style and explode value.
+func (c *swaggerConverter) collectionFormat(format, in, pointer string, node *yaml.Node) (string, *bool) {
+	f, t := false, true
+	switch format {
+	case "", "csv":
+		if in == "query" {
+			return "form", &f
+		}
+		return "simple", nil
+	case "multi":
+		if in == "query" {
+			return "form", &t
+		}
+	case "ssv":
+		if in == "query" {
+			return "spaceDelimited", nil
+		}
+	case "pipes":
+		if in == "query" {
+			return "pipeDelimited", nil
+		}
+	}
+	c.warn(pointer, node, "collectionFormat '%s' cannot be expressed for '%s' parameters, the default style "+
+		"is used", format, in)
+	return "", nil
+}
+
+// requestBody converts a body parameter into a request body, with a media type for every consumed type. The name
+// of a body parameter has no meaning on the wire, so it is not kept.
+func (c *swaggerConverter) requestBody(p *v2high.Parameter, consumes []string, pointer string) *yaml.Node {
+	rb := utils.CreateEmptyMapNode()
+	addString(rb, "description", p.Description)
+	content := utils.CreateEmptyMapNode()
+	for _, mt := range mediaTypes(consumes) {
+		media := utils.CreateEmptyMapNode()
+		if p.Schema != nil {
+			addNode(media, "schema", c.schema(p.Schema, pointer+"/schema"))
+		}
+		addNode(content, mt, media)
+	}
+	addNode(rb, "content", content)
+	if p.Required != nil && *p.Required {
+		addNode(rb, "required", boolNode(true))
+	}
+	addExtensions(rb, p.Extensions)
+	return rb
+}
+
+// formBody converts formData parameters into a request body with an object schema, each parameter is a property.
+func (c *swaggerConverter) formBody(params []*v2high.Parameter, consumes []string, pointer string) *yaml.Node {
+	schema := utils.CreateEmptyMapNode()
+	addString(schema, "type", "object")
+	props := utils.CreateEmptyMapNode()
+	var required []string
+	file := false
+	for _, p := range params {
+		lp := p.GoLow()
+		prop := c.simpleSchema(parameterFields(lp), lp.Items.Value, pointer)
+		addString(prop, "description", p.Description)
+		addNode(props, p.Name, prop)
+		if p.Required != nil && *p.Required {
+			required = append(required, p.Name)
+		}
+		if p.Type == "file" {
+			file = true
+		}
+		if p.AllowEmptyValue != nil {
+			c.warn(pointer, keyNode(lp.AllowEmptyValue), "allowEmptyValue cannot be expressed for form "+
+				"property '%s', it has been dropped", p.Name)
+		}
+		if p.Type == "array" && p.CollectionFormat != "" && p.CollectionFormat != "multi" {
+			c.warn(pointer, keyNode(lp.CollectionFormat), "collectionFormat '%s' of form property '%s' has been "+
+				"dropped", p.CollectionFormat, p.Name)
+		}
+	}
+	addNode(schema, "properties", props)
+	if len(required) > 0 {
+		addNode(schema, "required", utils.CreateYamlNode(required))
+	}
+
+	var types []string
+	for _, mt := range consumes {
+		if mt == formMediaType || mt == multipartType {
+			types = append(types, mt)
+		}
+	}
+	if len(types) == 0 {
+		if file {
+			types = []string{multipartType}
+		} else {
+			types = []string{formMediaType}
+		}
+	}
+	content := utils.CreateEmptyMapNode()
+	for _, mt := range types {
+		media := utils.CreateEmptyMapNode()
+		addNode(media, "schema", utils.CopyNode(schema))
+		addNode(content, mt, media)
+	}
+	rb := utils.CreateEmptyMapNode()
+	addNode(rb, "content", content)
+	if len(required) > 0 {
+		addNode(rb, "required", boolNode(true))
+	}
+	return rb
+}
+
+func (c *swaggerConverter) responses(r *v2high.Responses, produces []string, pointer string) *yaml.Node {
+	responses := utils.CreateEmptyMapNode()
+	lr := r.GoLow()
+	for pair := orderedmap.First(r.Codes); pair != nil; pair = pair.Next() {
+		rp := pointer + "/" + utils.EscapePointerSegment(pair.Key())
+		code := utils.CreateStringNode(pair.Key())
+		var value *yaml.Node
+		for lp := orderedmap.First(lr.Codes); lp != nil; lp = lp.Next() {
+			if lp.Key().Value == pair.Key() {
+				v := lp.Value()
+				if ref := c.localReference(&v, "responses", rp); ref != "" {
+					value = utils.CreateRefNode("#/components/responses/" + ref)
+				}
+				break
+			}
+		}
+		if value == nil {
+			value = c.response(pair.Value(), produces, rp)
+		}
+		responses.Content = append(responses.Content, code, value)
+	}
+	if r.Default != nil {
+		if ref := c.localReference(&lr.Default, "responses", pointer+"/default"); ref != "" {
+			addNode(responses, "default", utils.CreateRefNode("#/components/responses/"+ref))
+		} else {
+			addNode(responses, "default", c.response(r.Default, produces, pointer+"/default"))
+		}
+	}
+	addExtensions(responses, r.Extensions)
+	return responses
+}
+
+// response converts a response, the schema and examples are moved into a media type for every produced type.
+func (c *swaggerConverter) response(r *v2high.Response, produces []string, pointer string) *yaml.Node {
+	resp := utils.CreateEmptyMapNode()
+	addNode(resp, "description", utils.CreateStringNode(r.Description))
+
+	if orderedmap.Len(r.Headers) > 0 {
+		headers := utils.CreateEmptyMapNode()
+		for pair := orderedmap.First(r.Headers); pair != nil; pair = pair.Next() {
+			addNode(headers, pair.Key(), c.header(pair.Value(), pointer+"/headers/"+utils.EscapePointerSegment(pair.Key())))
+		}
+		addNode(resp, "headers", headers)
+	}
+
+	var examples *orderedmap.Map[string, *yaml.Node]
+	if r.Examples != nil {
+		examples = r.Examples.Values
+	}
+	if r.Schema != nil || orderedmap.Len(examples) > 0 {
+		content := utils.CreateEmptyMapNode()
+		types := mediaTypes(produces)
+		for pair := orderedmap.First(examples); pair != nil; pair = pair.Next() {
+			found := false
+			for _, mt := range types {
+				found = found || mt == pair.Key()
+			}
+			if !found {
+				types = append(types, pair.Key())
+			}
+		}
+		for _, mt := range types {
+			media := utils.CreateEmptyMapNode()
+			if r.Schema != nil {
+				addNode(media, "schema", c.schema(r.Schema, pointer+"/schema"))
+			}
+			if examples != nil {
+				addNode(media, "example", utils.CopyNode(examples.GetOrZero(mt)))
+			}
+			addNode(content, mt, media)
+		}
+		addNode(resp, "content", content)
+	}
+	addExtensions(resp, r.Extensions)
+	return resp
+}
+
+func (c *swaggerConverter) header(h *v2high.Header, pointer string) *yaml.Node {
+	lh := h.GoLow()
+	header := utils.CreateEmptyMapNode()
+	addString(header, "description", h.Description)
+	if h.Type == "array" && h.CollectionFormat != "" && h.CollectionFormat != "csv" {
+		c.warn(pointer, keyNode(lh.CollectionFormat), "collectionFormat '%s' cannot be expressed for headers, "+
+			"the default style is used", h.CollectionFormat)
+	}
+	addNode(header, "schema", c.simpleSchema(headerFields(lh), lh.Items.Value, pointer))
+	addExtensions(header, h.Extensions)
+	return header
+}
+
+func (c *swaggerConverter) securityScheme(ss *v2high.SecurityScheme, pointer string) *yaml.Node {
+	scheme := utils.CreateEmptyMapNode()
+	switch ss.Type {
+	case "basic":
+		addString(scheme, "type", "http")
+		addString(scheme, "scheme", "basic")
+	case "apiKey":
+		addString(scheme, "type", "apiKey")
+		addString(scheme, "name", ss.Name)
+		addString(scheme, "in", ss.In)
+	case "oauth2":
+		addString(scheme, "type", "oauth2")
+		flow := utils.CreateEmptyMapNode()
+		addString(flow, "authorizationUrl", ss.AuthorizationUrl)
+		addString(flow, "tokenUrl", ss.TokenUrl)
+		scopes := utils.CreateEmptyMapNode()
+		if ss.Scopes != nil {
+			for pair := orderedmap.First(ss.Scopes.Values); pair != nil; pair = pair.Next() {
+				addNode(scopes, pair.Key(), utils.CreateStringNode(pair.Value()))
+			}
+		}
+		addNode(flow, "scopes", scopes)
+
+		flowName := map[string]string{
+			"implicit":    "implicit",
+			"password":    "password",
+			"application": "clientCredentials",
+			"accessCode":  "authorizationCode",
+		}[ss.Flow]
+		if flowName == "" {
+			c.warn(pointer, keyNode(ss.GoLow().Flow), "unknown oauth2 flow '%s', it has been dropped", ss.Flow)
+		} else {
+			flows := utils.CreateEmptyMapNode()
+			addNode(flows, flowName, flow)
+			addNode(scheme, "flows", flows)
+		}
+	default:
+		c.warn(pointer, keyNode(ss.GoLow().Type), "unknown security scheme type '%s' has been kept as-is", ss.Type)
+		addString(scheme, "type", ss.Type)
+	}
+	addString(scheme, "description", ss.Description)
+	addExtensions(scheme, ss.Extensions)
+	return scheme
+}
+
+// schema copies a schema from the source document and converts it into an OpenAPI 3.0 schema. The node of the
+// low-level schema is used, as Swagger schemas can contain values the OpenAPI 3 model cannot hold.
+func (c *swaggerConverter) schema(sp *base.SchemaProxy, pointer string) *yaml.Node {
+	var node *yaml.Node
+	if sp.IsReference() {
+		node = utils.CreateRefNode(sp.GetReference())
+	} else if sp.GoLow() != nil && sp.GoLow().GetValueNode() != nil {
+		node = utils.CopyNode(sp.GoLow().GetValueNode())
+	} else {
+		n, err := sp.MarshalYAML()
+		if err != nil {
+			c.warn(pointer, nil, "schema cannot be converted, it has been replaced with an empty schema: %s", err)
+			return utils.CreateEmptyMapNode()
+		}
+		node = utils.CopyNode(n.(*yaml.Node))
+	}
+	c.convertSchema(node, pointer)
+	return node
+}
+
+// convertSchema walks through a schema node and converts everything that changed between Swagger and
+// OpenAPI 3.0 schemas.
+//
+//   - references are re-written to point to components.
+//   - a 'discriminator' property name becomes a discriminator object.
+//   - the 'file' type becomes a binary string.
+//   - the 'x-nullable' extension becomes 'nullable'.
+func (c *swaggerConverter) convertSchema(node *yaml.Node, pointer string) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			c.convertSchema(n, fmt.Sprintf("%s/%d", pointer, i))
+		}
+	case yaml.MappingNode:
+		isFile, hasFormat := false, false
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			switch key.Value {
+			case "$ref":
+				value.Value = c.reference(value.Value, pointer)
+				continue
+			case "discriminator":
+				if value.Kind == yaml.ScalarNode {
+					d := utils.CreateEmptyMapNode()
+					addString(d, "propertyName", value.Value)
+					node.Content[i+1] = d
+				}
+				continue
+			case "type":
+				isFile = value.Value == "file"
+				continue
+			case "format":
+				hasFormat = true
+				continue
+			case "x-nullable":
+				key.Value = "nullable"
+				continue
+			case "example", "default", "enum":
+				continue
+			}
+			if strings.HasPrefix(key.Value, "x-") {
+				continue
+			}
+			c.convertSchema(value, pointer+"/"+utils.EscapePointerSegment(key.Value))
+		}
+		if isFile {
+			k, t := utils.FindKeyNodeTop("type", node.Content)
+			c.warn(pointer, k, "the 'file' type cannot be expressed in a schema, it has been converted into a "+
+				"binary string")
+			t.Value = "string"
+			if !hasFormat {
+				addString(node, "format", "binary")
+			}
+		}
+	}
+}
+
+// reference re-writes a reference into the Swagger document, into a reference to a component.
+func (c *swaggerConverter) reference(ref, pointer string) string {
+	if !strings.HasPrefix(ref, "#/") {
+		c.warn(pointer, nil, "external reference '%s' has been kept as-is, the referenced document is not "+
+			"converted", ref)
+		return ref
+	}
+	segs := strings.SplitN(strings.TrimPrefix(ref, "#/"), "/", 3)
+	if len(segs) < 2 {
+		return ref
+	}
+	section, name := segs[0], utils.UnescapePointerSegment(segs[1])
+	var target string
+	switch section {
+	case "definitions":
+		target = "schemas"
+	case "responses":
+		target = "responses"
+	case "parameters":
+		target = "parameters"
+		if c.swagger.Parameters != nil {
+			if p := c.swagger.Parameters.Definitions.GetOrZero(name); p != nil && p.In == "body" {
+				target = "requestBodies"
+			}
+		}
+	default:
+		c.warn(pointer, nil, "reference '%s' cannot be converted, it has been kept as-is", ref)
+		return ref
+	}
+	if n, ok := c.names[section][name]; ok {
+		name = n
+	}
+	converted := fmt.Sprintf("#/components/%s/%s", target, utils.EscapePointerSegment(name))
+	if len(segs) == 3 {
+		converted += "/" + segs[2]
+	}
+	return converted
+}
+
+type field struct {
+	key   string
+	value *yaml.Node
+}
+
+func parameterFields(p *v2low.Parameter) []field {
+	return []field{
+		{"type", p.Type.ValueNode}, {"format", p.Format.ValueNode}, {"default", p.Default.ValueNode},
+		{"maximum", p.Maximum.ValueNode}, {"exclusiveMaximum", p.ExclusiveMaximum.ValueNode},
+		{"minimum", p.Minimum.ValueNode}, {"exclusiveMinimum", p.ExclusiveMinimum.ValueNode},
+		{"maxLength", p.MaxLength.ValueNode}, {"minLength", p.MinLength.ValueNode},
+		{"pattern", p.Pattern.ValueNode}, {"maxItems", p.MaxItems.ValueNode}, {"minItems", p.MinItems.ValueNode},
+		{"uniqueItems", p.UniqueItems.ValueNode}, {"enum", p.Enum.ValueNode}, {"multipleOf", p.MultipleOf.ValueNode},
+	}
+}
+
+func headerFields(h *v2low.Header) []field {
+	return []field{
+		{"type", h.Type.ValueNode}, {"format", h.Format.ValueNode}, {"default", h.Default.ValueNode},
+		{"maximum", h.Maximum.ValueNode}, {"exclusiveMaximum", h.ExclusiveMaximum.ValueNode},
+		{"minimum", h.Minimum.ValueNode}, {"exclusiveMinimum", h.ExclusiveMinimum.ValueNode},
+		{"maxLength", h.MaxLength.ValueNode}, {"minLength", h.MinLength.ValueNode},
+		{"pattern", h.Pattern.ValueNode}, {"maxItems", h.MaxItems.ValueNode}, {"minItems", h.MinItems.ValueNode},
+		{"uniqueItems", h.UniqueItems.ValueNode}, {"enum", h.Enum.ValueNode}, {"multipleOf", h.MultipleOf.ValueNode},
+	}
+}
+
+func itemsFields(i *v2low.Items) []field {
+	return []field{
+		{"type", i.Type.ValueNode}, {"format", i.Format.ValueNode}, {"default", i.Default.ValueNode},
+		{"maximum", i.Maximum.ValueNode}, {"exclusiveMaximum", i.ExclusiveMaximum.ValueNode},
+		{"minimum", i.Minimum.ValueNode}, {"exclusiveMinimum", i.ExclusiveMinimum.ValueNode},
+		{"maxLength", i.MaxLength.ValueNode}, {"minLength", i.MinLength.ValueNode},
+		{"pattern", i.Pattern.ValueNode}, {"maxItems", i.MaxItems.ValueNode}, {"minItems", i.MinItems.ValueNode},
+		{"uniqueItems", i.UniqueItems.ValueNode}, {"enum", i.Enum.ValueNode}, {"multipleOf", i.MultipleOf.ValueNode},
+	}
+}
+
+// simpleSchema creates a schema from the type information of a parameter, header or items object. Only the
+// values that were set in the source document are copied.
+func (c *swaggerConverter) simpleSchema(fields []field, items *v2low.Items, pointer string) *yaml.Node {
+	schema := utils.CreateEmptyMapNode()
+	for _, f := range fields {
+		addNode(schema, f.key, utils.CopyNode(f.value))
+	}
+	if items != nil {
+		if items.CollectionFormat.ValueNode != nil {
+			c.warn(pointer, items.CollectionFormat.KeyNode, "collectionFormat of nested items cannot be "+
+				"expressed, it has been dropped")
+		}
+		addNode(schema, "items", c.simpleSchema(itemsFields(items), items.Items.Value, pointer+"/items"))
+	}
+	c.convertSchema(schema, pointer)
+	return schema
+}
+
+func mediaTypes(types []string) []string {
+	if len(types) == 0 {
+		return []string{defaultMediaType}
+	}
+	return append([]string{}, types...)
+}
+
+func keyNode[T any](ref low.NodeReference[T]) *yaml.Node {
+	return ref.KeyNode
+}
+
+func boolNode(b bool) *yaml.Node {
+	return utils.CreateBoolNode(fmt.Sprint(b))
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,243 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package converter
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSwaggerDocument_Petstore(t *testing.T) {
+	spec, err := os.ReadFile("../test_specs/petstorev2-complete.yaml")
+	require.NoError(t, err)
+	doc, err := libopenapi.NewDocument(spec)
+	require.NoError(t, err)
+
+	result, err := ConvertSwaggerDocument(doc)
+	require.NoError(t, err)
+	m := result.Model.Model
+
+	assert.Equal(t, OpenAPIVersion, m.Version)
+	assert.Equal(t, "Swagger Petstore", m.Info.Title)
+	require.Len(t, m.Servers, 2)
+	assert.Equal(t, "https://petstore.swagger.io/v2", m.Servers[0].URL)
+
+	// definitions are components, and references have been re-written.
+	assert.Equal(t, 6, m.Components.Schemas.Len())
+	assert.NotContains(t, string(result.Bytes), "#/definitions")
+
+	// body parameters are request bodies, with a media type for every consumed type.
+	addPet := m.Paths.PathItems.GetOrZero("/pet").Post
+	require.NotNil(t, addPet.RequestBody)
+	assert.True(t, *addPet.RequestBody.Required)
+	assert.Equal(t, 2, addPet.RequestBody.Content.Len())
+	assert.Equal(t, "#/components/schemas/Pet",
+		addPet.RequestBody.Content.GetOrZero("application/xml").Schema.GetReference())
+	assert.Empty(t, addPet.Parameters)
+
+	// form data parameters are properties of the request body schema, files are binary strings.
+	upload := m.Paths.PathItems.GetOrZero("/pet/{petId}/uploadImage").Post
+	form := upload.RequestBody.Content.GetOrZero("multipart/form-data").Schema.Schema()
+	require.NotNil(t, form)
+	assert.Equal(t, "binary", form.Properties.GetOrZero("file").Schema().Format)
+	assert.Equal(t, "string", form.Properties.GetOrZero("file").Schema().Type[0])
+
+	// collection formats are styles.
+	status := m.Paths.PathItems.GetOrZero("/pet/findByStatus").Get.Parameters[0]
+	assert.Equal(t, "form", status.Style)
+	assert.True(t, *status.Explode)
+	assert.Equal(t, "array", status.Schema.Schema().Type[0])
+
+	// security definitions are security schemes.
+	oauth := m.Components.SecuritySchemes.GetOrZero("petstore_auth")
+	require.NotNil(t, oauth)
+	assert.Equal(t, "oauth2", oauth.Type)
+	require.NotNil(t, oauth.Flows.Implicit)
+	assert.Equal(t, "https://petstore.swagger.io/oauth/authorize", oauth.Flows.Implicit.AuthorizationUrl)
+	assert.Equal(t, "apiKey", m.Components.SecuritySchemes.GetOrZero("api_key").Type)
+
+	// the allowEmptyValue of a form property cannot be expressed.
+	require.NotEmpty(t, result.Warnings)
+	assert.Contains(t, result.Warnings[0].Error(), "allowEmptyValue")
+	assert.Equal(t, 94, result.Warnings[0].Line)
+
+	// the local path item reference cannot be kept, the target is not part of the converted document.
+	var messages []string
+	for _, w := range result.Warnings {
+		messages = append(messages, w.Error())
+	}
+	assert.Contains(t, strings.Join(messages, "\n"), "local path item reference '#/externalPaths/test' cannot be kept")
+}
+
+var lossySwagger = `swagger: "2.0"
+info:
+  title: lossy
+  version: "1"
+host: example.com
+basePath: /api
+consumes:
+  - application/json
+produces:
+  - application/json
+parameters:
+  Body:
+    name: thing
+    in: body
+    schema:
+      $ref: '#/definitions/Thing«Generic»'
+  Limit:
+    name: limit
+    in: query
+    type: integer
+  Token:
+    name: token
+    in: formData
+    type: string
+responses:
+  Error:
+    description: error
+    schema:
+      type: string
+    examples:
+      text/plain: bad things happened
+securityDefinitions:
+  basic:
+    type: basic
+  code:
+    type: oauth2
+    flow: accessCode
+    authorizationUrl: https://example.com/auth
+    tokenUrl: https://example.com/token
+    scopes:
+      read: read things
+paths:
+  /things:
+    parameters:
+      - $ref: '#/parameters/Body'
+    post:
+      parameters:
+        - $ref: '#/parameters/Limit'
+        - name: ids
+          in: header
+          type: array
+          collectionFormat: tsv
+          items:
+            type: string
+      responses:
+        default:
+          $ref: '#/responses/Error'
+  /login:
+    post:
+      schemes: [https]
+      parameters:
+        - $ref: '#/parameters/Token'
+      responses:
+        200:
+          description: ok
+  /ordered:
+    post:
+      responses:
+        200:
+          description: ok
+    parameters:
+      - name: q
+        in: query
+        type: string
+    put:
+      responses:
+        200:
+          description: ok
+definitions:
+  Upload:
+    type: file
+  Thing«Generic»:
+    type: object
+    discriminator: kind
+    required: [kind]
+    properties:
+      kind:
+        type: string
+        x-nullable: true
+      self:
+        $ref: '#/definitions/Thing«Generic»/properties/kind'
+`
+
+func TestConvertSwagger_Lossy(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(lossySwagger))
+	require.NoError(t, err)
+	v2, errs := doc.BuildV2Model()
+	require.Empty(t, errs)
+
+	result, err := ConvertSwagger(&v2.Model, nil)
+	require.NoError(t, err)
+	out := string(result.Bytes)
+
+	var messages []string
+	for _, w := range result.Warnings {
+		messages = append(messages, w.Error())
+	}
+	warnings := strings.Join(messages, "\n")
+	assert.Contains(t, warnings, "/definitions/Thing«Generic»: 'Thing«Generic»' is not a valid component name")
+	assert.Contains(t, warnings, "shared formData parameter 'Token' has been in-lined")
+	assert.Contains(t, warnings, "collectionFormat 'tsv' cannot be expressed for 'header' parameters")
+	assert.Contains(t, warnings, "/definitions/Upload (line 81, column 5): the 'file' type cannot be expressed in a schema")
+	assert.Len(t, result.Warnings, 4)
+
+	assert.Contains(t, out, "Thing_Generic_:")
+	assert.Contains(t, out, "$ref: '#/components/schemas/Thing_Generic_'")
+	assert.Contains(t, out, "$ref: '#/components/schemas/Thing_Generic_/properties/kind'")
+	assert.Contains(t, out, "$ref: '#/components/requestBodies/Body'")
+	assert.Contains(t, out, "$ref: '#/components/parameters/Limit'")
+	assert.Contains(t, out, "$ref: '#/components/responses/Error'")
+	assert.Contains(t, out, "- url: https://example.com/api")
+	assert.Contains(t, out, "- url: //example.com/api")
+	assert.Contains(t, out, "application/x-www-form-urlencoded:")
+
+	// operations and path parameters keep the order of the source document.
+	ordered := out[strings.Index(out, "/ordered:"):]
+	post, params, put := strings.Index(ordered, "post:"), strings.Index(ordered, "parameters:"),
+		strings.Index(ordered, "put:")
+	assert.True(t, post < params && params < put, ordered)
+
+	m := result.Model.Model
+	basic := m.Components.SecuritySchemes.GetOrZero("basic")
+	assert.Equal(t, "http", basic.Type)
+	assert.Equal(t, "basic", basic.Scheme)
+	code := m.Components.SecuritySchemes.GetOrZero("code").Flows.AuthorizationCode
+	require.NotNil(t, code)
+	assert.Equal(t, "https://example.com/token", code.TokenUrl)
+	assert.Equal(t, "read things", code.Scopes.GetOrZero("read"))
+
+	thing := m.Components.Schemas.GetOrZero("Thing_Generic_").Schema()
+	require.NotNil(t, thing)
+	assert.Equal(t, "kind", thing.Discriminator.PropertyName)
+	assert.True(t, *thing.Properties.GetOrZero("kind").Schema().Nullable)
+	upload := m.Components.Schemas.GetOrZero("Upload").Schema()
+	assert.Equal(t, "string", upload.Type[0])
+	assert.Equal(t, "binary", upload.Format)
+
+	errResponse := m.Components.Responses.GetOrZero("Error")
+	assert.Equal(t, "bad things happened", errResponse.Content.GetOrZero("text/plain").Example.Value)
+	assert.NotNil(t, errResponse.Content.GetOrZero("application/json").Schema)
+
+	assert.Equal(t, "https://example.com/api", m.Paths.PathItems.GetOrZero("/login").Post.Servers[0].URL)
+}
+
+func TestConvertSwaggerDocument_Invalid(t *testing.T) {
+	_, err := ConvertSwaggerDocument(nil)
+	assert.Error(t, err)
+
+	doc, err := libopenapi.NewDocument([]byte("openapi: 3.1.0"))
+	require.NoError(t, err)
+	_, err = ConvertSwaggerDocument(doc)
+	assert.Error(t, err)
+
+	_, err = ConvertSwagger(nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,443 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPI31Version is the version of OpenAPI that a 3.0 document is upgraded into.
+const OpenAPI31Version = "3.1.0"
+
+// schema keywords that hold a single schema, a list of schemas or a map of schemas.
+var (
+	schemaKeywords     = []string{"items", "additionalProperties", "not", "contains", "if", "then", "else", "propertyNames", "unevaluatedItems", "unevaluatedProperties", "additionalItems"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "dependentSchemas", "$defs", "definitions"}
+)
+
+// schema keywords that were added in OpenAPI 3.1 (JSON Schema 2020-12), none of them can be expressed in 3.0.
+var unsupported30Keywords = []string{
+	"$schema", "$id", "$anchor", "$dynamicRef", "$dynamicAnchor", "$defs", "$comment", "$vocabulary",
+	"prefixItems", "contains", "minContains", "maxContains", "if", "then", "else", "dependentSchemas",
+	"dependentRequired", "patternProperties", "propertyNames", "unevaluatedItems", "unevaluatedProperties",
+	"contentEncoding", "contentMediaType", "contentSchema",
+}
+
+// UpgradeDocument will build an OpenAPI 3.0 model from the supplied Document and upgrade it to OpenAPI 3.1,
+// using Upgrade. The configuration of the supplied Document is used when re-loading the upgraded document.
+func UpgradeDocument(doc libopenapi.Document) (*Result, error) {
+	m, err := buildV3(doc)
+	if err != nil {
+		return nil, err
+	}
+	return Upgrade(&m.Model, doc.GetConfiguration())
+}
+
+// Upgrade will upgrade an OpenAPI 3.0 model into an OpenAPI 3.1 document.
+//
+//   - 'nullable' becomes a "null" type, nullable schemas without a type (such as 'allOf' or '$ref' schemas) become
+//     an 'anyOf' of the schema and a "null" type.
+//   - boolean 'exclusiveMinimum' and 'exclusiveMaximum' become numeric values.
+//   - a schema 'example' becomes 'examples'.
+//   - the 'openapi' version becomes 3.1.0.
+//
+// Exclusive bounds without a limit cannot be upgraded, they are reported as warnings. If the configuration is nil,
+// a default configuration is used to re-load the upgraded document.
+func Upgrade(doc *v3high.Document, config *datamodel.DocumentConfiguration) (*Result, error) {
+	root, err := renderV3(doc, "3.0")
+	if err != nil {
+		return nil, err
+	}
+	u := &versionConverter{}
+	setVersion(root, OpenAPI31Version)
+	walkSchemas(root, "", u.upgradeSchema)
+	fileType, indent := specFormat(doc)
+	return reload(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, fileType, indent, config, u.warnings)
+}
+
+// DowngradeDocument will build an OpenAPI 3.1 model from the supplied Document and downgrade it to OpenAPI 3.0,
+// using Downgrade. The configuration of the supplied Document is used when re-loading the downgraded document.
+func DowngradeDocument(doc libopenapi.Document) (*Result, error) {
+	m, err := buildV3(doc)
+	if err != nil {
+		return nil, err
+	}
+	return Downgrade(&m.Model, doc.GetConfiguration())
+}
+
+// Downgrade will downgrade an OpenAPI 3.1 model into an OpenAPI 3.0 document, so it can be published to
+// consumers that do not support 3.1 yet.
+//
+//   - a "null" type becomes 'nullable', multiple types become 'anyOf'.
+//   - numeric 'exclusiveMinimum' and 'exclusiveMaximum' become boolean values.
+//   - schema 'examples' become an 'example' and 'const' becomes a single value 'enum'.
+//   - the 'openapi' version becomes 3.0.3.
+//
+// Everything that cannot be expressed in 3.0 is removed and reported as a warning, this includes webhooks,
+// path item components and the JSON Schema keywords added in 3.1, such as 'prefixItems' and 'if/then/else'.
+// If the configuration is nil, a default configuration is used to re-load the downgraded document.
+func Downgrade(doc *v3high.Document, config *datamodel.DocumentConfiguration) (*Result, error) {
+	root, err := renderV3(doc, "3.1")
+	if err != nil {
+		return nil, err
+	}
+	d := &versionConverter{}
+	setVersion(root, OpenAPIVersion)
+	d.downgradeDocument(root)
+	walkSchemas(root, "", d.downgradeSchema)
+	fileType, indent := specFormat(doc)
+	return reload(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, fileType, indent, config, d.warnings)
+}
+
+type versionConverter struct {
+	warnings []*Warning
+}
+
+func (v *versionConverter) warn(pointer string, node *yaml.Node, message string, args ...any) {
+	w := &Warning{Path: pointer, Message: fmt.Sprintf(message, args...)}
+	if node != nil {
+		w.Line, w.Column = node.Line, node.Column
+	}
+	v.warnings = append(v.warnings, w)
+}
+
+func (v *versionConverter) upgradeSchema(schema *yaml.Node, pointer string) {
+	_, typeNode := findKey(schema, "type")
+	if _, nullable := findKey(schema, "nullable"); nullable != nil {
+		removeKey(schema, "nullable")
+		if nullable.Value == "true" {
+			switch {
+			case typeNode != nil && typeNode.Kind == yaml.ScalarNode:
+				seq := utils.CreateEmptySequenceNode()
+				seq.Content = append(seq.Content, utils.CreateStringNode(typeNode.Value), utils.CreateStringNode("null"))
+				setKey(schema, "type", seq)
+				allowNullEnum(schema)
+			case typeNode != nil && typeNode.Kind == yaml.SequenceNode:
+				if !containsValue(typeNode, "null") {
+					typeNode.Content = append(typeNode.Content, utils.CreateStringNode("null"))
+				}
+				allowNullEnum(schema)
+			default:
+				// without a type (such as 'allOf' or '$ref' schemas) null is allowed as an alternative to the schema.
+				original := utils.CreateEmptyMapNode()
+				original.Content = schema.Content
+				null := utils.CreateEmptyMapNode()
+				addString(null, "type", "null")
+				anyOf := utils.CreateEmptySequenceNode()
+				anyOf.Content = []*yaml.Node{original, null}
+				schema.Content = nil
+				setKey(schema, "anyOf", anyOf)
+			}
+		}
+	}
+
+	for _, bound := range []struct{ exclusive, limit string }{
+		{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"},
+	} {
+		k, exclusive := findKey(schema, bound.exclusive)
+		if exclusive == nil || exclusive.Tag != "!!bool" {
+			continue
+		}
+		removeKey(schema, bound.exclusive)
+		if exclusive.Value != "true" {
+			continue
+		}
+		_, limit := findKey(schema, bound.limit)
+		if limit == nil {
+			v.warn(pointer+"/"+bound.exclusive, k, "'%s' has no '%s' to apply to, it has been dropped",
+				bound.exclusive, bound.limit)
+			continue
+		}
+		removeKey(schema, bound.limit)
+		setKey(schema, bound.exclusive, utils.CopyNode(limit))
+	}
+
+	if _, example := findKey(schema, "example"); example != nil {
+		removeKey(schema, "example")
+		if _, examples := findKey(schema, "examples"); examples == nil {
+			seq := utils.CreateEmptySequenceNode()
+			seq.Content = append(seq.Content, example)
+			setKey(schema, "examples", seq)
+		}
+	}
+}
+
+func (v *versionConverter) downgradeDocument(root *yaml.Node) {
+	if k, n := findKey(root, "webhooks"); n != nil {
+		v.warn("/webhooks", k, "webhooks cannot be expressed in OpenAPI 3.0, they have been removed")
+		removeKey(root, "webhooks")
+	}
+	if k, n := findKey(root, "jsonSchemaDialect"); n != nil {
+		v.warn("/jsonSchemaDialect", k, "jsonSchemaDialect cannot be expressed in OpenAPI 3.0, it has been removed")
+		removeKey(root, "jsonSchemaDialect")
+	}
+	if _, info := findKey(root, "info"); info != nil {
+		if k, n := findKey(info, "summary"); n != nil {
+			v.warn("/info/summary", k, "an info summary cannot be expressed in OpenAPI 3.0, it has been removed")
+			removeKey(info, "summary")
+		}
+		if _, license := findKey(info, "license"); license != nil {
+			if k, n := findKey(license, "identifier"); n != nil {
+				v.warn("/info/license/identifier", k, "a license identifier cannot be expressed in OpenAPI 3.0, "+
+					"it has been removed")
+				removeKey(license, "identifier")
+			}
+		}
+	}
+	if _, components := findKey(root, "components"); components != nil {
+		if k, n := findKey(components, "pathItems"); n != nil {
+			v.warn("/components/pathItems", k, "path item components cannot be expressed in OpenAPI 3.0, "+
+				"they have been removed")
+			removeKey(components, "pathItems")
+		}
+	}
+	if _, paths := findKey(root, "paths"); paths == nil {
+		setKey(root, "paths", utils.CreateEmptyMapNode())
+	}
+}
+
+func (v *versionConverter) downgradeSchema(schema *yaml.Node, pointer string) {
+	if k, typeNode := findKey(schema, "type"); typeNode != nil && typeNode.Kind == yaml.SequenceNode {
+		var types []string
+		nullable := false
+		for _, t := range typeNode.Content {
+			if t.Value == "null" {
+				nullable = true
+			} else {
+				types = append(types, t.Value)
+			}
+		}
+		removeKey(schema, "type")
+		switch len(types) {
+		case 0:
+			if nullable {
+				v.warn(pointer+"/type", k, "a schema that can only be null cannot be expressed in OpenAPI 3.0, "+
+					"the type has been removed")
+			}
+		case 1:
+			setKey(schema, "type", utils.CreateStringNode(types[0]))
+		default:
+			anyOf := utils.CreateEmptySequenceNode()
+			for _, t := range types {
+				s := utils.CreateEmptyMapNode()
+				addString(s, "type", t)
+				anyOf.Content = append(anyOf.Content, s)
+			}
+			if _, existing := findKey(schema, "anyOf"); existing != nil {
+				v.warn(pointer+"/type", k, "multiple types cannot be expressed in a schema that already uses "+
+					"'anyOf', the types have been removed")
+			} else {
+				setKey(schema, "anyOf", anyOf)
+			}
+		}
+		if nullable && len(types) > 0 {
+			setKey(schema, "nullable", utils.CreateBoolNode("true"))
+		}
+	} else if typeNode != nil && typeNode.Value == "null" {
+		removeKey(schema, "type")
+		v.warn(pointer+"/type", k, "a schema that can only be null cannot be expressed in OpenAPI 3.0, "+
+			"the type has been removed")
+	}
+
+	for _, bound := range []struct{ exclusive, limit string }{
+		{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"},
+	} {
+		k, exclusive := findKey(schema, bound.exclusive)
+		if exclusive == nil || exclusive.Tag == "!!bool" {
+			continue
+		}
+		if _, limit := findKey(schema, bound.limit); limit != nil {
+			v.warn(pointer+"/"+bound.exclusive, k, "'%s' and '%s' cannot both be expressed in OpenAPI 3.0, "+
+				"'%s' has been replaced", bound.exclusive, bound.limit, bound.limit)
+		}
+		setKey(schema, bound.limit, utils.CopyNode(exclusive))
+		setKey(schema, bound.exclusive, utils.CreateBoolNode("true"))
+	}
+
+	if k, examples := findKey(schema, "examples"); examples != nil && examples.Kind == yaml.SequenceNode {
+		removeKey(schema, "examples")
+		if len(examples.Content) > 1 {
+			v.warn(pointer+"/examples", k, "only a single schema example can be expressed in OpenAPI 3.0, "+
+				"%d examples have been dropped", len(examples.Content)-1)
+		}
+		if _, example := findKey(schema, "example"); example == nil && len(examples.Content) > 0 {
+			setKey(schema, "example", examples.Content[0])
+		}
+	}
+
+	if k, c := findKey(schema, "const"); c != nil {
+		removeKey(schema, "const")
+		if _, enum := findKey(schema, "enum"); enum == nil {
+			seq := utils.CreateEmptySequenceNode()
+			seq.Content = append(seq.Content, c)
+			setKey(schema, "enum", seq)
+			v.warn(pointer+"/const", k, "'const' cannot be expressed in OpenAPI 3.0, it has been replaced with "+
+				"a single value 'enum'")
+		} else {
+			v.warn(pointer+"/const", k, "'const' cannot be expressed in OpenAPI 3.0, it has been removed")
+		}
+	}
+
+	for _, keyword := range unsupported30Keywords {
+		if k, n := findKey(schema, keyword); n != nil {
+			v.warn(pointer+"/"+utils.EscapePointerSegment(keyword), k, "'%s' cannot be expressed in OpenAPI 3.0, "+
+				"it has been removed", keyword)
+			removeKey(schema, keyword)
+		}
+	}
+}
+
+// allowNullEnum adds null to the enum of a schema, if it has one.
+func allowNullEnum(schema *yaml.Node) {
+	if _, enum := findKey(schema, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		enum.Content = append(enum.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+	}
+}
+
+// containsValue reports if a sequence contains a scalar value.
+func containsValue(seq *yaml.Node, value string) bool {
+	for _, n := range seq.Content {
+		if n.Kind == yaml.ScalarNode && n.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// walkSchemas will call the supplied function for every schema in the document, deepest schemas first. A schema
+// is the value of a 'schema' key, a value in 'components.schemas', or any sub-schema of those.
+func walkSchemas(node *yaml.Node, pointer string, fn func(schema *yaml.Node, pointer string)) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			walkSchemas(n, fmt.Sprintf("%s/%d", pointer, i), fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			p := pointer + "/" + utils.EscapePointerSegment(key)
+			switch {
+			case key == "schema":
+				walkSchema(value, p, fn)
+			case key == "schemas" && pointer == "/components":
+				for j := 0; j < len(value.Content)-1; j += 2 {
+					walkSchema(value.Content[j+1], p+"/"+utils.EscapePointerSegment(value.Content[j].Value), fn)
+				}
+			case key == "example" || key == "examples" || key == "default" || strings.HasPrefix(key, "x-"):
+				continue
+			default:
+				walkSchemas(value, p, fn)
+			}
+		}
+	}
+}
+
+func walkSchema(schema *yaml.Node, pointer string, fn func(schema *yaml.Node, pointer string)) {
+	if !utils.IsNodeMap(schema) {
+		return
+	}
+	for _, k := range schemaKeywords {
+		if _, n := findKey(schema, k); n != nil {
+			walkSchema(n, pointer+"/"+k, fn)
+		}
+	}
+	for _, k := range schemaListKeywords {
+		if _, n := findKey(schema, k); n != nil && n.Kind == yaml.SequenceNode {
+			for i, s := range n.Content {
+				walkSchema(s, fmt.Sprintf("%s/%s/%d", pointer, k, i), fn)
+			}
+		}
+	}
+	for _, k := range schemaMapKeywords {
+		if _, n := findKey(schema, k); n != nil && utils.IsNodeMap(n) {
+			for i := 0; i < len(n.Content)-1; i += 2 {
+				walkSchema(n.Content[i+1], pointer+"/"+utils.EscapePointerSegment(k)+"/"+utils.EscapePointerSegment(n.Content[i].Value), fn)
+			}
+		}
+	}
+	fn(schema, pointer)
+}
+
+func buildV3(doc libopenapi.Document) (*libopenapi.DocumentModel[v3high.Document], error) {
+	if doc == nil || doc.GetSpecInfo() == nil {
+		return nil, errors.New("unable to convert, document has not been initialized")
+	}
+	if doc.GetSpecInfo().SpecFormat != datamodel.OAS3 && doc.GetSpecInfo().SpecFormat != datamodel.OAS31 {
+		return nil, errors.New("unable to convert, document is not an OpenAPI 3+ document")
+	}
+	m, errs := doc.BuildV3Model()
+	if m == nil {
+		return nil, fmt.Errorf("unable to build OpenAPI model: %w", joinErrors(errs))
+	}
+	return m, nil
+}
+
+// renderV3 renders a copy of the document, checking the version is the expected one.
+func renderV3(doc *v3high.Document, version string) (*yaml.Node, error) {
+	if doc == nil {
+		return nil, errors.New("unable to convert, document is nil")
+	}
+	if !strings.HasPrefix(doc.Version, version) {
+		return nil, fmt.Errorf("unable to convert, document version '%s' is not OpenAPI %s", doc.Version, version)
+	}
+	rendered, err := doc.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := rendered.(*yaml.Node)
+	if !ok || !utils.IsNodeMap(root) {
+		return nil, errors.New("unable to convert, document did not render into an object")
+	}
+	return utils.CopyNode(root), nil
+}
+
+func specFormat(doc *v3high.Document) (string, int) {
+	if doc.GoLow() != nil && doc.GoLow().Index != nil && doc.GoLow().Index.GetConfig().SpecInfo != nil {
+		info := doc.GoLow().Index.GetConfig().SpecInfo
+		return info.SpecFileType, info.OriginalIndentation
+	}
+	return datamodel.YAMLFileType, 2
+}
+
+func setVersion(root *yaml.Node, version string) {
+	setKey(root, "openapi", utils.CreateStringNode(version))
+}
+
+func findKey(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// setKey replaces the value of a key in a map node, or adds the key if it does not exist.
+func setKey(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	addNode(m, key, value)
+}
+
+func removeKey(m *yaml.Node, key string) {
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var openAPI30 = `openapi: 3.0.3
+info:
+  title: upgrade
+  version: "1"
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          example: 10
+          schema:
+            type: integer
+            minimum: 1
+            exclusiveMinimum: true
+            maximum: 100
+            exclusiveMaximum: false
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      example:
+        name: fluffy
+      properties:
+        name:
+          type: string
+          nullable: true
+        status:
+          type: string
+          nullable: true
+          enum: [sold, available]
+        owner:
+          nullable: true
+          allOf:
+            - $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        age:
+          type: integer
+          exclusiveMaximum: true
+`
+
+func stringify(warnings []*Warning) string {
+	var messages []string
+	for _, w := range warnings {
+		messages = append(messages, w.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func TestUpgradeDocument(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(openAPI30))
+	require.NoError(t, err)
+
+	result, err := UpgradeDocument(doc)
+	require.NoError(t, err)
+	m := result.Model.Model
+	assert.Equal(t, OpenAPI31Version, m.Version)
+
+	limit := m.Paths.PathItems.GetOrZero("/pets").Get.Parameters[0]
+	assert.Equal(t, "10", limit.Example.Value)
+	schema := limit.Schema.Schema()
+	assert.True(t, schema.ExclusiveMinimum.IsB())
+	assert.Equal(t, float64(1), schema.ExclusiveMinimum.B)
+	assert.Nil(t, schema.Minimum)
+	assert.Nil(t, schema.ExclusiveMaximum)
+	assert.Equal(t, float64(100), *schema.Maximum)
+
+	pet := m.Components.Schemas.GetOrZero("Pet").Schema()
+	assert.Equal(t, []string{"string", "null"}, pet.Properties.GetOrZero("name").Schema().Type)
+	assert.Nil(t, pet.Properties.GetOrZero("name").Schema().Nullable)
+	status := pet.Properties.GetOrZero("status").Schema()
+	require.Len(t, status.Enum, 3)
+	assert.Equal(t, "!!null", status.Enum[2].Tag)
+	require.Len(t, pet.Examples, 1)
+	assert.Nil(t, pet.Example)
+
+	// a nullable schema without a type allows null as an alternative.
+	owner := pet.Properties.GetOrZero("owner").Schema()
+	assert.Nil(t, owner.AllOf)
+	require.Len(t, owner.AnyOf, 2)
+	require.Len(t, owner.AnyOf[0].Schema().AllOf, 1)
+	assert.Equal(t, "#/components/schemas/Owner", owner.AnyOf[0].Schema().AllOf[0].GetReference())
+	assert.Equal(t, []string{"null"}, owner.AnyOf[1].Schema().Type)
+
+	// an exclusive bound without a limit cannot be upgraded.
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, stringify(result.Warnings), "/components/schemas/Owner/properties/age/exclusiveMaximum")
+}
+
+var openAPI31 = `openapi: 3.1.0
+info:
+  title: downgrade
+  summary: a summary
+  version: "1"
+  license:
+    name: MIT
+    identifier: MIT
+webhooks:
+  newPet:
+    post:
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Pet:
+      type: [string, "null"]
+      examples: [fluffy, spot]
+      minimum: 1
+      exclusiveMinimum: 5
+    Mixed:
+      type: [string, integer, "null"]
+      const: hello
+    Tuple:
+      type: array
+      prefixItems:
+        - type: string
+      if:
+        type: string
+      then:
+        minLength: 1
+`
+
+func TestUpgradeDocument_Nullable(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: nullable
+  version: "1"
+components:
+  schemas:
+    Name:
+      type: string
+    Owner:
+      nullable: true
+      allOf:
+        - $ref: '#/components/schemas/Name'
+    Mixed:
+      type: [string, integer]
+      nullable: true`
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	require.NoError(t, err)
+
+	result, err := UpgradeDocument(doc)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	schemas := result.Model.Model.Components.Schemas
+	null := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+
+	// schemas without a type still accept null, and nothing else changes.
+	owner := schemas.GetOrZero("Owner").Schema()
+	require.Len(t, owner.AnyOf, 2)
+	assert.Empty(t, owner.ValidateNode(null, nil))
+	assert.Empty(t, owner.ValidateNode(utils.CreateStringNode("fluffy"), nil))
+	assert.NotEmpty(t, owner.ValidateNode(utils.CreateIntNode("1"), nil))
+
+	// null is added to a list of types.
+	mixed := schemas.GetOrZero("Mixed").Schema()
+	assert.Equal(t, []string{"string", "integer", "null"}, mixed.Type)
+	assert.Empty(t, mixed.ValidateNode(null, nil))
+}
+
+func TestDowngradeDocument(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(openAPI31))
+	require.NoError(t, err)
+
+	result, err := DowngradeDocument(doc)
+	require.NoError(t, err)
+	m := result.Model.Model
+	assert.Equal(t, OpenAPIVersion, m.Version)
+	assert.Equal(t, 0, orderedmap.Len(m.Webhooks))
+	assert.Empty(t, m.Info.Summary)
+	assert.NotNil(t, m.Paths)
+
+	pet := m.Components.Schemas.GetOrZero("Pet").Schema()
+	assert.Equal(t, []string{"string"}, pet.Type)
+	assert.True(t, *pet.Nullable)
+	assert.Equal(t, "fluffy", pet.Example.Value)
+	assert.Equal(t, float64(5), *pet.Minimum)
+	assert.True(t, pet.ExclusiveMinimum.IsA())
+	assert.True(t, pet.ExclusiveMinimum.A)
+
+	mixed := m.Components.Schemas.GetOrZero("Mixed").Schema()
+	assert.Empty(t, mixed.Type)
+	require.Len(t, mixed.AnyOf, 2)
+	assert.Equal(t, "integer", mixed.AnyOf[1].Schema().Type[0])
+	assert.Equal(t, "hello", mixed.Enum[0].Value)
+
+	tuple := m.Components.Schemas.GetOrZero("Tuple").Schema()
+	assert.Nil(t, tuple.PrefixItems)
+	assert.Nil(t, tuple.If)
+	assert.Nil(t, tuple.Then)
+
+	warnings := stringify(result.Warnings)
+	for _, w := range []string{
+		"/webhooks", "/info/summary", "/info/license/identifier", "/components/schemas/Pet/examples",
+		"/components/schemas/Pet/exclusiveMinimum", "/components/schemas/Mixed/const",
+		"/components/schemas/Tuple/prefixItems", "/components/schemas/Tuple/if", "/components/schemas/Tuple/then",
+	} {
+		assert.Contains(t, warnings, w+":")
+	}
+	assert.Len(t, result.Warnings, 9)
+}
+
+func TestUpgrade_WrongVersion(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(openAPI31))
+	require.NoError(t, err)
+	_, err = UpgradeDocument(doc)
+	assert.Error(t, err)
+
+	doc, err = libopenapi.NewDocument([]byte(openAPI30))
+	require.NoError(t, err)
+	_, err = DowngradeDocument(doc)
+	assert.Error(t, err)
+
+	doc, err = libopenapi.NewDocument([]byte(`swagger: "2.0"`))
+	require.NoError(t, err)
+	_, err = UpgradeDocument(doc)
+	assert.Error(t, err)
+
+	_, err = Upgrade(nil, nil)
+	assert.Error(t, err)
+}
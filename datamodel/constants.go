@@ -29,6 +29,9 @@ const (
 
 	// OAS31 represents OpenAPI 3.1+ Documents
 	OAS31 = "oas3_1"
+
+	// Arazzo1 represents Arazzo 1.0+ workflow Documents
+	Arazzo1 = "arazzo1"
 )
 
 // OpenAPI3SchemaData is an embedded version of the OpenAPI 3 Schema
@@ -46,6 +49,12 @@ var OpenAPI31SchemaData string // embedded OAS31 schema
 //go:embed schemas/swagger2-schema.json
 var OpenAPI2SchemaData string // embedded OAS3 schema
 
+// JSONSchemaDraft04Data is an embedded version of the JSON Schema draft-04 meta-schema, referenced by the
+// OpenAPI 2 (Swagger) Schema.
+//
+//go:embed schemas/draft-04-schema.json
+var JSONSchemaDraft04Data string // embedded draft-04 meta-schema
+
 // OAS3_1Format defines documents that can only be version 3.1
 var OAS3_1Format = []string{OAS31}
 
@@ -101,6 +101,11 @@ type DocumentConfiguration struct {
 	// Logger is a structured logger that will be used for logging errors and warnings. If not set, a default logger
 	// will be used, set to the Error level.
 	Logger *slog.Logger
+
+	// Limits protect against hostile (or just enormous) specifications, by limiting the size of the input, the files
+	// loaded by the rolodex and the references followed by the resolver. If not set, nothing is limited. Exceeding
+	// a limit returns a *LimitError.
+	Limits *Limits
 }
 
 func NewDocumentConfiguration() *DocumentConfiguration {
@@ -0,0 +1,121 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// SuccessAction represents a high-level Arazzo Success Action object, that is backed by a low-level one.
+//
+// A single success action which describes an action to take upon success of a workflow step. A SuccessAction can
+// also be a Reusable object, that references an action defined in components (with Reference).
+//   - https://spec.openapis.org/arazzo/v1.0.0#success-action-object
+type SuccessAction struct {
+	Name       string                              `json:"name,omitempty" yaml:"name,omitempty"`
+	Type       string                              `json:"type,omitempty" yaml:"type,omitempty"`
+	WorkflowId string                              `json:"workflowId,omitempty" yaml:"workflowId,omitempty"`
+	StepId     string                              `json:"stepId,omitempty" yaml:"stepId,omitempty"`
+	Criteria   []*Criterion                        `json:"criteria,omitempty" yaml:"criteria,omitempty"`
+	Reference  string                              `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low        *low.SuccessAction
+}
+
+// NewSuccessAction creates a new high-level SuccessAction instance from a low-level one.
+func NewSuccessAction(action *low.SuccessAction) *SuccessAction {
+	s := new(SuccessAction)
+	s.low = action
+	s.Name = action.Name.Value
+	s.Type = action.Type.Value
+	s.WorkflowId = action.WorkflowId.Value
+	s.StepId = action.StepId.Value
+	s.Criteria = buildSlice(action.Criteria.Value, NewCriterion)
+	s.Reference = action.Reference.Value
+	s.Extensions = high.ExtractExtensions(action.Extensions)
+	return s
+}
+
+// IsReusable returns true if the SuccessAction is a Reusable object, that references an action of the components.
+func (s *SuccessAction) IsReusable() bool {
+	return s.Reference != ""
+}
+
+// GoLow returns the low-level SuccessAction instance used to create the high-level one.
+func (s *SuccessAction) GoLow() *low.SuccessAction {
+	return s.low
+}
+
+// Render will return a YAML representation of the SuccessAction object as a byte slice.
+func (s *SuccessAction) Render() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the SuccessAction object.
+func (s *SuccessAction) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(s, s.low)
+	return nb.Render(), nil
+}
+
+// FailureAction represents a high-level Arazzo Failure Action object, that is backed by a low-level one.
+//
+// A single failure action which describes an action to take upon failure of a workflow step. A FailureAction can
+// also be a Reusable object, that references an action defined in components (with Reference).
+//   - https://spec.openapis.org/arazzo/v1.0.0#failure-action-object
+type FailureAction struct {
+	Name       string                              `json:"name,omitempty" yaml:"name,omitempty"`
+	Type       string                              `json:"type,omitempty" yaml:"type,omitempty"`
+	WorkflowId string                              `json:"workflowId,omitempty" yaml:"workflowId,omitempty"`
+	StepId     string                              `json:"stepId,omitempty" yaml:"stepId,omitempty"`
+	RetryAfter *float64                            `json:"retryAfter,omitempty" yaml:"retryAfter,omitempty"`
+	RetryLimit *int64                              `json:"retryLimit,omitempty" yaml:"retryLimit,omitempty"`
+	Criteria   []*Criterion                        `json:"criteria,omitempty" yaml:"criteria,omitempty"`
+	Reference  string                              `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low        *low.FailureAction
+}
+
+// NewFailureAction creates a new high-level FailureAction instance from a low-level one.
+func NewFailureAction(action *low.FailureAction) *FailureAction {
+	f := new(FailureAction)
+	f.low = action
+	f.Name = action.Name.Value
+	f.Type = action.Type.Value
+	f.WorkflowId = action.WorkflowId.Value
+	f.StepId = action.StepId.Value
+	if !action.RetryAfter.IsEmpty() {
+		f.RetryAfter = &action.RetryAfter.Value
+	}
+	if !action.RetryLimit.IsEmpty() {
+		f.RetryLimit = &action.RetryLimit.Value
+	}
+	f.Criteria = buildSlice(action.Criteria.Value, NewCriterion)
+	f.Reference = action.Reference.Value
+	f.Extensions = high.ExtractExtensions(action.Extensions)
+	return f
+}
+
+// IsReusable returns true if the FailureAction is a Reusable object, that references an action of the components.
+func (f *FailureAction) IsReusable() bool {
+	return f.Reference != ""
+}
+
+// GoLow returns the low-level FailureAction instance used to create the high-level one.
+func (f *FailureAction) GoLow() *low.FailureAction {
+	return f.low
+}
+
+// Render will return a YAML representation of the FailureAction object as a byte slice.
+func (f *FailureAction) Render() ([]byte, error) {
+	return yaml.Marshal(f)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the FailureAction object.
+func (f *FailureAction) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(f, f.low)
+	return nb.Render(), nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package arazzo contains the high-level model of the Arazzo workflows specification. Steps of a workflow can be
+// linked to the operations of the OpenAPI documents they call using Arazzo.LinkOperations.
+//   - https://spec.openapis.org/arazzo/v1.0.0
+package arazzo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	lowv3 "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const sourceDescriptionsPrefix = "$sourceDescriptions."
+
+// Arazzo represents a high-level Arazzo document, that is backed by a low-level one.
+//
+// An Arazzo description describes sequences of API calls (workflows) across the OpenAPI documents listed as
+// source descriptions.
+//   - https://spec.openapis.org/arazzo/v1.0.0#arazzo-description
+type Arazzo struct {
+	Arazzo             string                              `json:"arazzo,omitempty" yaml:"arazzo,omitempty"`
+	Info               *base.Info                          `json:"info,omitempty" yaml:"info,omitempty"`
+	SourceDescriptions []*SourceDescription                `json:"sourceDescriptions,omitempty" yaml:"sourceDescriptions,omitempty"`
+	Workflows          []*Workflow                         `json:"workflows,omitempty" yaml:"workflows,omitempty"`
+	Components         *Components                         `json:"components,omitempty" yaml:"components,omitempty"`
+	Extensions         *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low                *low.Arazzo
+}
+
+// NewArazzo creates a new high-level Arazzo document from a low-level one.
+func NewArazzo(arazzo *low.Arazzo) *Arazzo {
+	a := new(Arazzo)
+	a.low = arazzo
+	a.Arazzo = arazzo.Arazzo.Value
+	if !arazzo.Info.IsEmpty() {
+		a.Info = base.NewInfo(arazzo.Info.Value)
+	}
+	a.SourceDescriptions = buildSlice(arazzo.SourceDescriptions.Value, NewSourceDescription)
+	a.Workflows = buildSlice(arazzo.Workflows.Value, NewWorkflow)
+	if !arazzo.Components.IsEmpty() {
+		a.Components = NewComponents(arazzo.Components.Value)
+	}
+	a.Extensions = high.ExtractExtensions(arazzo.Extensions)
+	return a
+}
+
+// FindSourceDescription will attempt to locate a SourceDescription by name.
+func (a *Arazzo) FindSourceDescription(name string) *SourceDescription {
+	for _, s := range a.SourceDescriptions {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// FindWorkflow will attempt to locate a Workflow by its workflowId.
+func (a *Arazzo) FindWorkflow(workflowId string) *Workflow {
+	for _, w := range a.Workflows {
+		if w.WorkflowId == workflowId {
+			return w
+		}
+	}
+	return nil
+}
+
+// LinkOperations will link every step that calls an API operation (using an operationId or an operationPath) to the
+// v3.Operation it calls. The sources map holds the OpenAPI documents of the Arazzo document, keyed by the name of
+// their source description.
+//
+// Unqualified operationIds are looked up in the sources in the order the source descriptions are listed. Steps
+// that call another workflow are ignored. An error is returned for every step that could not be linked, the rest
+// of the steps are still linked.
+func (a *Arazzo) LinkOperations(sources map[string]*v3.Document) error {
+	var errs []error
+	for _, w := range a.Workflows {
+		for _, s := range w.Steps {
+			var err error
+			switch {
+			case s.OperationId != "":
+				s.Source, s.Operation, err = a.findOperationById(s.OperationId, sources)
+			case s.OperationPath != "":
+				s.Source, s.Operation, err = a.findOperationByPath(s.OperationPath, sources)
+			default:
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("unable to link step '%s' of workflow '%s': %w",
+					s.StepId, w.WorkflowId, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Arazzo) findOperationById(operationId string, sources map[string]*v3.Document,
+) (*SourceDescription, *v3.Operation, error) {
+	candidates := a.SourceDescriptions
+	if strings.HasPrefix(operationId, sourceDescriptionsPrefix) {
+		name, id, found := strings.Cut(strings.TrimPrefix(operationId, sourceDescriptionsPrefix), ".")
+		source := a.FindSourceDescription(name)
+		if !found || source == nil {
+			return nil, nil, fmt.Errorf("source description of operationId '%s' cannot be found", operationId)
+		}
+		candidates, operationId = []*SourceDescription{source}, id
+	}
+	for _, source := range candidates {
+		doc := sources[source.Name]
+		if doc == nil {
+			continue
+		}
+		for _, items := range []*orderedmap.Map[string, *v3.PathItem]{pathItems(doc), doc.Webhooks} {
+			for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+				for op := orderedmap.First(pair.Value().GetOperations()); op != nil; op = op.Next() {
+					if op.Value().OperationId == operationId {
+						return source, op.Value(), nil
+					}
+				}
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("operationId '%s' cannot be found", operationId)
+}
+
+func (a *Arazzo) findOperationByPath(operationPath string, sources map[string]*v3.Document,
+) (*SourceDescription, *v3.Operation, error) {
+	location, pointer, found := strings.Cut(operationPath, "#")
+	if !found {
+		return nil, nil, fmt.Errorf("operationPath '%s' does not contain a JSON pointer", operationPath)
+	}
+	location = strings.TrimSuffix(strings.TrimPrefix(location, "{"), "}")
+
+	var source *SourceDescription
+	if strings.HasPrefix(location, sourceDescriptionsPrefix) {
+		source = a.FindSourceDescription(strings.TrimSuffix(strings.TrimPrefix(location, sourceDescriptionsPrefix), ".url"))
+	} else {
+		for _, s := range a.SourceDescriptions {
+			if s.URL == location {
+				source = s
+			}
+		}
+	}
+	if source == nil || sources[source.Name] == nil {
+		return nil, nil, fmt.Errorf("source description of operationPath '%s' cannot be found", operationPath)
+	}
+	doc := sources[source.Name]
+
+	segments := strings.Split(pointer, "/")
+	if len(segments) != 4 || segments[0] != "" {
+		return nil, nil, fmt.Errorf("operationPath '%s' does not point to an operation", operationPath)
+	}
+	for i := range segments {
+		if unescaped, err := url.PathUnescape(segments[i]); err == nil {
+			segments[i] = unescaped
+		}
+		segments[i] = utils.UnescapePointerSegment(segments[i])
+	}
+
+	var items *orderedmap.Map[string, *v3.PathItem]
+	switch segments[1] {
+	case lowv3.PathsLabel:
+		items = pathItems(doc)
+	case lowv3.WebhooksLabel:
+		items = doc.Webhooks
+	}
+	if items == nil {
+		return nil, nil, fmt.Errorf("operationPath '%s' does not point to an operation", operationPath)
+	}
+	if item := items.GetOrZero(segments[2]); item != nil {
+		if op := item.GetOperations().GetOrZero(strings.ToLower(segments[3])); op != nil {
+			return source, op, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("operationPath '%s' cannot be found", operationPath)
+}
+
+func pathItems(doc *v3.Document) *orderedmap.Map[string, *v3.PathItem] {
+	if doc.Paths == nil {
+		return nil
+	}
+	return doc.Paths.PathItems
+}
+
+// GoLow returns the low-level Arazzo document used to create the high-level one.
+func (a *Arazzo) GoLow() *low.Arazzo {
+	return a.low
+}
+
+// Render will return a YAML representation of the Arazzo document as a byte slice.
+func (a *Arazzo) Render() ([]byte, error) {
+	return yaml.Marshal(a)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Arazzo document.
+func (a *Arazzo) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(a, a.low)
+	return nb.Render(), nil
+}
+
+// buildSlice creates high-level objects from a slice of low-level ones.
+func buildSlice[L, H any](items []lowmodel.ValueReference[L], build func(L) H) []H {
+	if len(items) == 0 {
+		return nil
+	}
+	s := make([]H, 0, len(items))
+	for _, item := range items {
+		s = append(s, build(item.Value))
+	}
+	return s
+}
+
+// buildMap creates an ordered map of high-level objects from an ordered map of low-level ones.
+func buildMap[L, H any](items *orderedmap.Map[lowmodel.KeyReference[string], lowmodel.ValueReference[L]],
+	build func(L) H,
+) *orderedmap.Map[string, H] {
+	if items == nil {
+		return nil
+	}
+	m := orderedmap.New[string, H]()
+	for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+		m.Set(pair.Key().Value, build(pair.Value().Value))
+	}
+	return m
+}
+
+// buildStrings creates an ordered map of strings from an ordered map of low-level string values.
+func buildStrings(items *orderedmap.Map[lowmodel.KeyReference[string], lowmodel.ValueReference[string]],
+) *orderedmap.Map[string, string] {
+	if items == nil {
+		return nil
+	}
+	m := orderedmap.New[string, string]()
+	for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+		m.Set(pair.Key().Value, pair.Value().Value)
+	}
+	return m
+}
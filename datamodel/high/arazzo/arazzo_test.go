@@ -0,0 +1,221 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var petWorkflows = `arazzo: 1.0.0
+info:
+  title: adopt a pet
+  version: 1.0.0
+sourceDescriptions:
+  - name: petstore
+    url: ./petstore.yaml
+    type: openapi
+workflows:
+  - workflowId: adoptPet
+    inputs:
+      type: object
+      properties:
+        name:
+          type: string
+    steps:
+      - stepId: findPet
+        operationId: findPets
+        successCriteria:
+          - condition: $statusCode == 200
+          - context: $response.body
+            condition: ^pet
+            type:
+              type: jsonpath
+              version: draft-goessner-dispatch-jsonpath-00
+        onFailure:
+          - name: retry
+            type: retry
+            retryAfter: 1.5
+            retryLimit: 3
+        outputs:
+          petId: $response.body#/pets/0/id
+      - stepId: adopt
+        operationPath: '{$sourceDescriptions.petstore.url}#/paths/~1pets~1{petId}/post'
+        parameters:
+          - name: petId
+            in: path
+            value: $steps.findPet.outputs.petId
+        requestBody:
+          contentType: application/json
+          payload:
+            adopter: $inputs.name
+      - stepId: notify
+        operationId: $sourceDescriptions.petstore.petAdopted
+      - stepId: login
+        workflowId: login
+    outputs:
+      petId: $steps.findPet.outputs.petId
+    x-team: qa
+components:
+  parameters:
+    apiKey:
+      name: api_key
+      in: header
+  successActions:
+    done:
+      name: done
+      type: end
+x-owner: pb33f
+`
+
+var petstore = `openapi: 3.1.0
+info:
+  title: petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: findPets
+      responses:
+        "200":
+          description: ok
+  /pets/{petId}:
+    post:
+      operationId: adoptPet
+      responses:
+        "200":
+          description: ok
+webhooks:
+  adopted:
+    post:
+      operationId: petAdopted
+      responses:
+        "200":
+          description: ok
+`
+
+func createArazzo(t *testing.T, spec string) *Arazzo {
+	info, err := datamodel.ExtractSpecInfo([]byte(spec))
+	require.NoError(t, err)
+	lowDoc, err := low.CreateDocument(info)
+	require.NoError(t, err)
+	return NewArazzo(lowDoc)
+}
+
+func createPetstore(t *testing.T) *v3.Document {
+	doc, err := libopenapi.NewDocument([]byte(petstore))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	return &model.Model
+}
+
+func TestNewArazzo(t *testing.T) {
+	a := createArazzo(t, petWorkflows)
+	assert.Equal(t, "1.0.0", a.Arazzo)
+	assert.Equal(t, "adopt a pet", a.Info.Title)
+	assert.Equal(t, "pb33f", a.Extensions.GetOrZero("x-owner").Value)
+	assert.Equal(t, "./petstore.yaml", a.FindSourceDescription("petstore").URL)
+	assert.Nil(t, a.FindSourceDescription("nope"))
+	assert.NotNil(t, a.GoLow())
+
+	w := a.FindWorkflow("adoptPet")
+	require.NotNil(t, w)
+	assert.Nil(t, a.FindWorkflow("nope"))
+	assert.Equal(t, "qa", w.Extensions.GetOrZero("x-team").Value)
+	assert.Equal(t, "$steps.findPet.outputs.petId", w.Outputs.GetOrZero("petId"))
+	assert.Equal(t, "string", w.Inputs.Schema().Properties.GetOrZero("name").Schema().Type[0])
+	require.Len(t, w.Steps, 4)
+
+	find := w.FindStep("findPet")
+	assert.Nil(t, w.FindStep("nope"))
+	assert.Equal(t, "$response.body#/pets/0/id", find.Outputs.GetOrZero("petId"))
+	assert.Equal(t, "jsonpath", find.SuccessCriteria[1].ExpressionType.Type)
+	assert.Equal(t, 1.5, *find.OnFailure[0].RetryAfter)
+	assert.Equal(t, int64(3), *find.OnFailure[0].RetryLimit)
+
+	adopt := w.FindStep("adopt")
+	assert.Equal(t, "path", adopt.Parameters[0].In)
+	assert.Equal(t, "$steps.findPet.outputs.petId", adopt.Parameters[0].Value.Value)
+	assert.Equal(t, "application/json", adopt.RequestBody.ContentType)
+
+	assert.Equal(t, "header", a.Components.Parameters.GetOrZero("apiKey").In)
+	assert.Equal(t, "end", a.Components.SuccessActions.GetOrZero("done").Type)
+}
+
+func TestArazzo_Render(t *testing.T) {
+	a := createArazzo(t, petWorkflows)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	require.NoError(t, enc.Encode(a))
+	assert.Equal(t, petWorkflows, buf.String())
+
+	// criterion expression types are rendered under the type key.
+	out, err := a.Workflows[0].Steps[0].SuccessCriteria[1].Render()
+	require.NoError(t, err)
+	assert.Equal(t, `context: $response.body
+condition: ^pet
+type:
+    type: jsonpath
+    version: draft-goessner-dispatch-jsonpath-00
+`, string(out))
+}
+
+func TestArazzo_LinkOperations(t *testing.T) {
+	a := createArazzo(t, petWorkflows)
+	petstoreDoc := createPetstore(t)
+	require.NoError(t, a.LinkOperations(map[string]*v3.Document{"petstore": petstoreDoc}))
+
+	steps := a.Workflows[0].Steps
+	assert.Equal(t, "findPets", steps[0].Operation.OperationId)
+	assert.Equal(t, "petstore", steps[0].Source.Name)
+	assert.Same(t, petstoreDoc.Paths.PathItems.GetOrZero("/pets/{petId}").Post, steps[1].Operation)
+	assert.Equal(t, "petAdopted", steps[2].Operation.OperationId)
+	assert.Nil(t, steps[3].Operation)
+}
+
+func TestArazzo_LinkOperations_Errors(t *testing.T) {
+	a := createArazzo(t, `arazzo: 1.0.0
+sourceDescriptions:
+  - name: petstore
+    url: ./petstore.yaml
+workflows:
+  - workflowId: broken
+    steps:
+      - stepId: unknownId
+        operationId: nope
+      - stepId: unknownSource
+        operationId: $sourceDescriptions.nope.findPets
+      - stepId: unknownPath
+        operationPath: ./petstore.yaml#/paths/~1nope/get
+      - stepId: notAnOperation
+        operationPath: ./petstore.yaml#/paths/~1pets
+      - stepId: noPointer
+        operationPath: ./petstore.yaml
+      - stepId: linked
+        operationPath: ./petstore.yaml#/paths/~1pets/GET
+`)
+	err := a.LinkOperations(map[string]*v3.Document{"petstore": createPetstore(t)})
+	require.Error(t, err)
+	assert.Equal(t, `unable to link step 'unknownId' of workflow 'broken': operationId 'nope' cannot be found
+unable to link step 'unknownSource' of workflow 'broken': source description of operationId '$sourceDescriptions.nope.findPets' cannot be found
+unable to link step 'unknownPath' of workflow 'broken': operationPath './petstore.yaml#/paths/~1nope/get' cannot be found
+unable to link step 'notAnOperation' of workflow 'broken': operationPath './petstore.yaml#/paths/~1pets' does not point to an operation
+unable to link step 'noPointer' of workflow 'broken': operationPath './petstore.yaml' does not contain a JSON pointer`,
+		err.Error())
+	assert.Equal(t, "findPets", a.Workflows[0].FindStep("linked").Operation.OperationId)
+
+	// a source without a document cannot be linked.
+	err = a.LinkOperations(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// Components represents a high-level Arazzo Components object, that is backed by a low-level one.
+//
+// Holds a set of reusable objects for different aspects of the Arazzo Specification.
+//   - https://spec.openapis.org/arazzo/v1.0.0#components-object
+type Components struct {
+	Inputs         *orderedmap.Map[string, *base.SchemaProxy] `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Parameters     *orderedmap.Map[string, *Parameter]        `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	SuccessActions *orderedmap.Map[string, *SuccessAction]    `json:"successActions,omitempty" yaml:"successActions,omitempty"`
+	FailureActions *orderedmap.Map[string, *FailureAction]    `json:"failureActions,omitempty" yaml:"failureActions,omitempty"`
+	Extensions     *orderedmap.Map[string, *yaml.Node]        `json:"-" yaml:"-"`
+	low            *low.Components
+}
+
+// NewComponents creates a new high-level Components instance from a low-level one.
+func NewComponents(components *low.Components) *Components {
+	c := new(Components)
+	c.low = components
+	if components.Inputs.Value != nil {
+		c.Inputs = orderedmap.New[string, *base.SchemaProxy]()
+		for pair := orderedmap.First(components.Inputs.Value); pair != nil; pair = pair.Next() {
+			c.Inputs.Set(pair.Key().Value, base.NewSchemaProxy(&lowmodel.NodeReference[*lowbase.SchemaProxy]{
+				Value:     pair.Value().Value,
+				ValueNode: pair.Value().ValueNode,
+			}))
+		}
+	}
+	c.Parameters = buildMap(components.Parameters.Value, NewParameter)
+	c.SuccessActions = buildMap(components.SuccessActions.Value, NewSuccessAction)
+	c.FailureActions = buildMap(components.FailureActions.Value, NewFailureAction)
+	c.Extensions = high.ExtractExtensions(components.Extensions)
+	return c
+}
+
+// GoLow returns the low-level Components instance used to create the high-level one.
+func (c *Components) GoLow() *low.Components {
+	return c.low
+}
+
+// Render will return a YAML representation of the Components object as a byte slice.
+func (c *Components) Render() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Components object.
+func (c *Components) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(c, c.low)
+	return nb.Render(), nil
+}
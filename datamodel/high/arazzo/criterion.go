@@ -0,0 +1,103 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Criterion represents a high-level Arazzo Criterion object, that is backed by a low-level one.
+//
+// An object used to specify the context, conditions, and condition types that can be used to prove or satisfy
+// assertions. The type of a Criterion is either a string (Type), such as 'simple', 'regex', 'jsonpath' or 'xpath',
+// or a Criterion Expression Type object (ExpressionType), only one of them should be set.
+//   - https://spec.openapis.org/arazzo/v1.0.0#criterion-object
+type Criterion struct {
+	Context        string                              `json:"context,omitempty" yaml:"context,omitempty"`
+	Condition      string                              `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Type           string                              `json:"type,omitempty" yaml:"type,omitempty"`
+	ExpressionType *CriterionExpressionType            `json:"-" yaml:"-"`
+	Extensions     *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low            *low.Criterion
+}
+
+// NewCriterion creates a new high-level Criterion instance from a low-level one.
+func NewCriterion(criterion *low.Criterion) *Criterion {
+	c := new(Criterion)
+	c.low = criterion
+	c.Context = criterion.Context.Value
+	c.Condition = criterion.Condition.Value
+	c.Type = criterion.Type.Value
+	if !criterion.ExpressionType.IsEmpty() {
+		c.ExpressionType = NewCriterionExpressionType(criterion.ExpressionType.Value)
+	}
+	c.Extensions = high.ExtractExtensions(criterion.Extensions)
+	return c
+}
+
+// GoLow returns the low-level Criterion instance used to create the high-level one.
+func (c *Criterion) GoLow() *low.Criterion {
+	return c.low
+}
+
+// Render will return a YAML representation of the Criterion object as a byte slice.
+func (c *Criterion) Render() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Criterion object. An ExpressionType is
+// rendered as the type.
+func (c *Criterion) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(c, c.low)
+	rendered := nb.Render()
+	if c.ExpressionType != nil && c.Type == "" {
+		expressionType, _ := c.ExpressionType.MarshalYAML()
+		if n, ok := expressionType.(*yaml.Node); ok {
+			rendered.Content = append(rendered.Content, utils.CreateStringNode(low.TypeLabel), n)
+		}
+	}
+	return rendered, nil
+}
+
+// CriterionExpressionType represents a high-level Arazzo Criterion Expression Type object, that is backed by a
+// low-level one.
+//
+// An object used to describe the type and version of an expression used within a Criterion Object.
+//   - https://spec.openapis.org/arazzo/v1.0.0#criterion-expression-type-object
+type CriterionExpressionType struct {
+	Type       string                              `json:"type,omitempty" yaml:"type,omitempty"`
+	Version    string                              `json:"version,omitempty" yaml:"version,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low        *low.CriterionExpressionType
+}
+
+// NewCriterionExpressionType creates a new high-level CriterionExpressionType instance from a low-level one.
+func NewCriterionExpressionType(expressionType *low.CriterionExpressionType) *CriterionExpressionType {
+	c := new(CriterionExpressionType)
+	c.low = expressionType
+	c.Type = expressionType.Type.Value
+	c.Version = expressionType.Version.Value
+	c.Extensions = high.ExtractExtensions(expressionType.Extensions)
+	return c
+}
+
+// GoLow returns the low-level CriterionExpressionType instance used to create the high-level one.
+func (c *CriterionExpressionType) GoLow() *low.CriterionExpressionType {
+	return c.low
+}
+
+// Render will return a YAML representation of the CriterionExpressionType object as a byte slice.
+func (c *CriterionExpressionType) Render() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the CriterionExpressionType object.
+func (c *CriterionExpressionType) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(c, c.low)
+	return nb.Render(), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// Parameter represents a high-level Arazzo Parameter object, that is backed by a low-level one.
+//
+// Describes a single step parameter. A Parameter can also be a Reusable object, that references a parameter defined
+// in components (with Reference), and may override its value.
+//   - https://spec.openapis.org/arazzo/v1.0.0#parameter-object
+//   - https://spec.openapis.org/arazzo/v1.0.0#reusable-object
+type Parameter struct {
+	Name       string                              `json:"name,omitempty" yaml:"name,omitempty"`
+	In         string                              `json:"in,omitempty" yaml:"in,omitempty"`
+	Value      *yaml.Node                          `json:"value,omitempty" yaml:"value,omitempty"`
+	Reference  string                              `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low        *low.Parameter
+}
+
+// NewParameter creates a new high-level Parameter instance from a low-level one.
+func NewParameter(param *low.Parameter) *Parameter {
+	p := new(Parameter)
+	p.low = param
+	p.Name = param.Name.Value
+	p.In = param.In.Value
+	p.Value = param.Value.Value
+	p.Reference = param.Reference.Value
+	p.Extensions = high.ExtractExtensions(param.Extensions)
+	return p
+}
+
+// IsReusable returns true if the Parameter is a Reusable object, that references a parameter of the components.
+func (p *Parameter) IsReusable() bool {
+	return p.Reference != ""
+}
+
+// GoLow returns the low-level Parameter instance used to create the high-level one.
+func (p *Parameter) GoLow() *low.Parameter {
+	return p.low
+}
+
+// Render will return a YAML representation of the Parameter object as a byte slice.
+func (p *Parameter) Render() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Parameter object.
+func (p *Parameter) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(p, p.low)
+	return nb.Render(), nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceDescription represents a high-level Arazzo Source Description object, that is backed by a low-level one.
+//
+// Describes a source description (such as an OpenAPI description) that will be referenced by one or more workflows
+// described within an Arazzo description.
+//   - https://spec.openapis.org/arazzo/v1.0.0#source-description-object
+type SourceDescription struct {
+	Name       string                              `json:"name,omitempty" yaml:"name,omitempty"`
+	URL        string                              `json:"url,omitempty" yaml:"url,omitempty"`
+	Type       string                              `json:"type,omitempty" yaml:"type,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low        *low.SourceDescription
+}
+
+// NewSourceDescription creates a new high-level SourceDescription instance from a low-level one.
+func NewSourceDescription(source *low.SourceDescription) *SourceDescription {
+	s := new(SourceDescription)
+	s.low = source
+	s.Name = source.Name.Value
+	s.URL = source.URL.Value
+	s.Type = source.Type.Value
+	s.Extensions = high.ExtractExtensions(source.Extensions)
+	return s
+}
+
+// GoLow returns the low-level SourceDescription instance used to create the high-level one.
+func (s *SourceDescription) GoLow() *low.SourceDescription {
+	return s.low
+}
+
+// Render will return a YAML representation of the SourceDescription object as a byte slice.
+func (s *SourceDescription) Render() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the SourceDescription object.
+func (s *SourceDescription) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(s, s.low)
+	return nb.Render(), nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// Step represents a high-level Arazzo Step object, that is backed by a low-level one.
+//
+// Describes a single workflow step which may be a call to an API operation (operationId or operationPath) or
+// another workflow (workflowId).
+//   - https://spec.openapis.org/arazzo/v1.0.0#step-object
+type Step struct {
+	StepId          string                              `json:"stepId,omitempty" yaml:"stepId,omitempty"`
+	Description     string                              `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationId     string                              `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	OperationPath   string                              `json:"operationPath,omitempty" yaml:"operationPath,omitempty"`
+	WorkflowId      string                              `json:"workflowId,omitempty" yaml:"workflowId,omitempty"`
+	Parameters      []*Parameter                        `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody     *RequestBody                        `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	SuccessCriteria []*Criterion                        `json:"successCriteria,omitempty" yaml:"successCriteria,omitempty"`
+	OnSuccess       []*SuccessAction                    `json:"onSuccess,omitempty" yaml:"onSuccess,omitempty"`
+	OnFailure       []*FailureAction                    `json:"onFailure,omitempty" yaml:"onFailure,omitempty"`
+	Outputs         *orderedmap.Map[string, string]     `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Extensions      *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+
+	// Operation is the OpenAPI operation called by the step, it is only set once the step has been linked with
+	// Arazzo.LinkOperations.
+	Operation *v3.Operation `json:"-" yaml:"-"`
+
+	// Source is the source description of the OpenAPI document that contains the Operation.
+	Source *SourceDescription `json:"-" yaml:"-"`
+
+	low *low.Step
+}
+
+// NewStep creates a new high-level Step instance from a low-level one.
+func NewStep(step *low.Step) *Step {
+	s := new(Step)
+	s.low = step
+	s.StepId = step.StepId.Value
+	s.Description = step.Description.Value
+	s.OperationId = step.OperationId.Value
+	s.OperationPath = step.OperationPath.Value
+	s.WorkflowId = step.WorkflowId.Value
+	s.Parameters = buildSlice(step.Parameters.Value, NewParameter)
+	if !step.RequestBody.IsEmpty() {
+		s.RequestBody = NewRequestBody(step.RequestBody.Value)
+	}
+	s.SuccessCriteria = buildSlice(step.SuccessCriteria.Value, NewCriterion)
+	s.OnSuccess = buildSlice(step.OnSuccess.Value, NewSuccessAction)
+	s.OnFailure = buildSlice(step.OnFailure.Value, NewFailureAction)
+	s.Outputs = buildStrings(step.Outputs.Value)
+	s.Extensions = high.ExtractExtensions(step.Extensions)
+	return s
+}
+
+// GoLow returns the low-level Step instance used to create the high-level one.
+func (s *Step) GoLow() *low.Step {
+	return s.low
+}
+
+// Render will return a YAML representation of the Step object as a byte slice.
+func (s *Step) Render() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Step object.
+func (s *Step) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(s, s.low)
+	return nb.Render(), nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"github.com/pb33f/libopenapi/datamodel/high"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	low "github.com/pb33f/libopenapi/datamodel/low/arazzo"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow represents a high-level Arazzo Workflow object, that is backed by a low-level one.
+//
+// Describes the steps to be taken across one or more APIs to achieve an objective. The workflow object may define
+// inputs needed in order to execute workflow steps, where the defined steps represent a call to an API operation
+// or another workflow, and a set of outputs.
+//   - https://spec.openapis.org/arazzo/v1.0.0#workflow-object
+type Workflow struct {
+	WorkflowId     string                              `json:"workflowId,omitempty" yaml:"workflowId,omitempty"`
+	Summary        string                              `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description    string                              `json:"description,omitempty" yaml:"description,omitempty"`
+	Inputs         *base.SchemaProxy                   `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	DependsOn      []string                            `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	Steps          []*Step                             `json:"steps,omitempty" yaml:"steps,omitempty"`
+	SuccessActions []*SuccessAction                    `json:"successActions,omitempty" yaml:"successActions,omitempty"`
+	FailureActions []*FailureAction                    `json:"failureActions,omitempty" yaml:"failureActions,omitempty"`
+	Outputs        *orderedmap.Map[string, string]     `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Parameters     []*Parameter                        `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Extensions     *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low            *low.Workflow
+}
+
+// NewWorkflow creates a new high-level Workflow instance from a low-level one.
+func NewWorkflow(workflow *low.Workflow) *Workflow {
+	w := new(Workflow)
+	w.low = workflow
+	w.WorkflowId = workflow.WorkflowId.Value
+	w.Summary = workflow.Summary.Value
+	w.Description = workflow.Description.Value
+	if !workflow.Inputs.IsEmpty() {
+		w.Inputs = base.NewSchemaProxy(&workflow.Inputs)
+	}
+	for _, d := range workflow.DependsOn.Value {
+		w.DependsOn = append(w.DependsOn, d.Value)
+	}
+	w.Steps = buildSlice(workflow.Steps.Value, NewStep)
+	w.SuccessActions = buildSlice(workflow.SuccessActions.Value, NewSuccessAction)
+	w.FailureActions = buildSlice(workflow.FailureActions.Value, NewFailureAction)
+	w.Outputs = buildStrings(workflow.Outputs.Value)
+	w.Parameters = buildSlice(workflow.Parameters.Value, NewParameter)
+	w.Extensions = high.ExtractExtensions(workflow.Extensions)
+	return w
+}
+
+// FindStep will attempt to locate a Step of the Workflow by its stepId.
+func (w *Workflow) FindStep(stepId string) *Step {
+	for _, s := range w.Steps {
+		if s.StepId == stepId {
+			return s
+		}
+	}
+	return nil
+}
+
+// GoLow returns the low-level Workflow instance used to create the high-level one.
+func (w *Workflow) GoLow() *low.Workflow {
+	return w.low
+}
+
+// Render will return a YAML representation of the Workflow object as a byte slice.
+func (w *Workflow) Render() ([]byte, error) {
+	return yaml.Marshal(w)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Workflow object.
+func (w *Workflow) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(w, w.low)
+	return nb.Render(), nil
+}
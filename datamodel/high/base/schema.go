@@ -72,6 +72,22 @@ type Schema struct {
 	// 3.1 only, part of the JSON Schema spec provides a way to identify a sub-schema
 	Anchor string `json:"$anchor,omitempty" yaml:"$anchor,omitempty"`
 
+	// 3.1 only, a plain name for the schema that a '$dynamicRef' can be resolved to.
+	DynamicAnchor string `json:"$dynamicAnchor,omitempty" yaml:"$dynamicAnchor,omitempty"`
+
+	// 3.1 only, a reference resolved using the dynamic scope of the schema, used for recursive and generic schemas.
+	DynamicRef string `json:"$dynamicRef,omitempty" yaml:"$dynamicRef,omitempty"`
+
+	// DynamicRefSchema is the schema DynamicRef resolved to, in the dynamic scope the schema was built in. It is
+	// not rendered.
+	DynamicRefSchema *SchemaProxy `json:"-" yaml:"-"`
+
+	// 3.1 only, the base URI of the schema, used to resolve relative references within it.
+	Id string `json:"$id,omitempty" yaml:"$id,omitempty"`
+
+	// 3.1 only, re-usable schemas that are defined within the schema, label is '$defs'.
+	Defs *orderedmap.Map[string, *SchemaProxy] `json:"$defs,omitempty" yaml:"$defs,omitempty"`
+
 	// Compatible with all versions
 	Not                  *SchemaProxy                          `json:"not,omitempty" yaml:"not,omitempty"`
 	Properties           *orderedmap.Map[string, *SchemaProxy] `json:"properties,omitempty" yaml:"properties,omitempty"`
@@ -301,6 +317,22 @@ func NewSchema(schema *base.Schema) *Schema {
 	if !schema.Anchor.IsEmpty() {
 		s.Anchor = schema.Anchor.Value
 	}
+	if !schema.DynamicAnchor.IsEmpty() {
+		s.DynamicAnchor = schema.DynamicAnchor.Value
+	}
+	if !schema.DynamicRef.IsEmpty() {
+		s.DynamicRef = schema.DynamicRef.Value
+	}
+	if !schema.DynamicRefSchema.IsEmpty() {
+		s.DynamicRefSchema = NewSchemaProxy(&lowmodel.NodeReference[*base.SchemaProxy]{
+			ValueNode: schema.DynamicRefSchema.ValueNode,
+			KeyNode:   schema.DynamicRefSchema.KeyNode,
+			Value:     schema.DynamicRefSchema.Value,
+		})
+	}
+	if !schema.Id.IsEmpty() {
+		s.Id = schema.Id.Value
+	}
 
 	var enum []*yaml.Node
 	for i := range schema.Enum.Value {
@@ -370,6 +402,8 @@ func NewSchema(schema *base.Schema) *Schema {
 			s.DependentSchemas = props
 		case 2:
 			s.PatternProperties = props
+		case 3:
+			s.Defs = props
 		}
 	}
 
@@ -387,6 +421,11 @@ func NewSchema(schema *base.Schema) *Schema {
 		buildProps(pair.Key(), pair.Value(), patternProps, 2)
 	}
 
+	defs := orderedmap.New[string, *SchemaProxy]()
+	for pair := orderedmap.First(schema.Defs.Value); pair != nil; pair = pair.Next() {
+		buildProps(pair.Key(), pair.Value(), defs, 3)
+	}
+
 	var allOf []*SchemaProxy
 	var oneOf []*SchemaProxy
 	var anyOf []*SchemaProxy
@@ -484,8 +523,8 @@ func (s *Schema) MarshalYAML() (interface{}, error) {
 	nb := high.NewNodeBuilder(s, s.low)
 
 	// determine index version
-	idx := s.GoLow().Index
-	if idx != nil {
+	if s.low != nil && s.low.Index != nil {
+		idx := s.low.Index
 		if idx.GetConfig().SpecInfo != nil {
 			nb.Version = idx.GetConfig().SpecInfo.VersionNumeric
 		}
@@ -497,8 +536,8 @@ func (s *Schema) MarshalYAMLInline() (interface{}, error) {
 	nb := high.NewNodeBuilder(s, s.low)
 	nb.Resolve = true
 	// determine index version
-	idx := s.GoLow().Index
-	if idx != nil {
+	if s.low != nil && s.low.Index != nil {
+		idx := s.low.Index
 		if idx.GetConfig().SpecInfo != nil {
 			nb.Version = idx.GetConfig().SpecInfo.VersionNumeric
 		}
@@ -0,0 +1,633 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// maxCompatibilityDepth limits how deep schemas are compared, compatibility of deeper schemas cannot be proven.
+const maxCompatibilityDepth = 64
+
+// SchemaIncompatibility describes instances that are valid against one schema, but not against another.
+type SchemaIncompatibility struct {
+	// Message explains which instances are rejected.
+	Message string
+
+	// Keyword is the keyword of the other schema that rejects the instances, for example 'maxLength'. It is empty when
+	// the schemas are nested too deeply to be compared.
+	Keyword string
+
+	// InstancePath is a JSON pointer to the rejected value in a counterexample instance, for example '/owner/name'.
+	// Array items are pointed at with index 0, and '*' stands for any property not named by the schemas.
+	InstancePath string
+
+	// Node is the value of the rejecting keyword, nil if the schema was created from scratch (or the keyword was
+	// merged from an 'allOf').
+	Node *yaml.Node
+
+	// Line and Column of the rejecting keyword.
+	Line   int
+	Column int
+
+	// Location is the file (or URL) that contains the rejecting keyword, when it is known.
+	Location string
+}
+
+func (i *SchemaIncompatibility) Error() string {
+	p := i.InstancePath
+	if p == "" {
+		p = "/"
+	}
+	if i.Node == nil {
+		return fmt.Sprintf("%s: %s", p, i.Message)
+	}
+	return fmt.Sprintf("%s: %s [%d:%d]", p, i.Message, i.Line, i.Column)
+}
+
+// IsSubsetOf checks if every instance that is valid against s is also valid against other, and returns every
+// counterexample found, no incompatibilities means s is a subset of other. This is the check for a change that must
+// not break consumers: a request schema can be replaced by a superset, a response schema by a subset.
+//
+// The comparison is semantic, both schemas are flattened first (see Flatten), so moving keywords between 'allOf'
+// members and references does not matter. Types, numeric, string, array and object constraints, enums and const,
+// 'required', 'additionalProperties', 'anyOf', 'oneOf' and 'not' are compared. The check is conservative: when a
+// relationship cannot be proven an incompatibility is reported, for example for two different patterns, a 'oneOf'
+// that more than one branch may match, or schemas nested too deeply. 'if', 'then', 'else', 'prefixItems', 'contains',
+// 'patternProperties', 'propertyNames', 'dependentRequired', 'dependentSchemas' and the 'unevaluated' keywords are
+// not compared, other must either not use them or use them exactly as s does.
+func (s *Schema) IsSubsetOf(other *Schema) []*SchemaIncompatibility {
+	c := &schemaComparer{seen: make(map[[2]schemaKey]bool), keys: make(map[*Schema]schemaKey)}
+	c.subset(s, other, "", 0)
+	return c.issues
+}
+
+// IsSupersetOf checks if every instance that is valid against other is also valid against s, it is the reverse of
+// IsSubsetOf.
+func (s *Schema) IsSupersetOf(other *Schema) []*SchemaIncompatibility {
+	return other.IsSubsetOf(s)
+}
+
+type schemaComparer struct {
+	issues []*SchemaIncompatibility
+	seen   map[[2]schemaKey]bool
+	keys   map[*Schema]schemaKey
+}
+
+// schemaKey identifies a schema while comparing, built schemas are identified by their content (references are
+// hashed by name) and the index they were found by, so every use of a recursive reference is the same schema.
+type schemaKey struct {
+	hash   [32]byte
+	idx    *index.SpecIndex
+	schema *Schema
+}
+
+func (c *schemaComparer) key(s *Schema) schemaKey {
+	if k, ok := c.keys[s]; ok {
+		return k
+	}
+	k := schemaKey{schema: s}
+	if s.low != nil {
+		k = schemaKey{hash: s.low.Hash(), idx: s.low.Index}
+	}
+	c.keys[s] = k
+	return k
+}
+
+// flat pairs a flattened schema with the schema it was flattened from, and its low-level schema for positions.
+type flat struct {
+	*Schema
+	source *Schema
+	origin *base.Schema
+}
+
+func flatten(s *Schema) (flat, bool) {
+	f, err := s.Flatten()
+	return flat{Schema: f, source: s, origin: s.low}, err == nil
+}
+
+func (c *schemaComparer) report(b flat, keyword, path, format string, args ...any) {
+	i := &SchemaIncompatibility{Message: fmt.Sprintf(format, args...), Keyword: keyword, InstancePath: path}
+	origin := b.low
+	if origin == nil {
+		origin = b.origin
+	}
+	if origin != nil {
+		if i.Node = keywordNode(origin, keyword); i.Node != nil {
+			i.Line, i.Column = i.Node.Line, i.Node.Column
+			i.Location = nodeLocation(origin.Index, i.Node)
+		}
+	}
+	c.issues = append(c.issues, i)
+}
+
+// subset records why instances of a are not valid against b.
+func (c *schemaComparer) subset(sa, sb *Schema, path string, depth int) {
+	if sa == nil || sb == nil {
+		return
+	}
+	pair := [2]schemaKey{c.key(sa), c.key(sb)}
+	if c.seen[pair] {
+		return
+	}
+	if depth > maxCompatibilityDepth {
+		c.report(flat{Schema: sb, source: sb, origin: sb.low}, "", path, "schemas are nested too deeply, compatibility cannot be proven")
+		return
+	}
+	// schemas referencing themselves are assumed compatible, until proven otherwise.
+	c.seen[pair] = true
+	defer delete(c.seen, pair)
+
+	a, ok := flatten(sa)
+	if !ok {
+		// no instance can be valid against a schema with conflicting members.
+		return
+	}
+	b, _ := flatten(sb)
+
+	// members of b that could not be merged must each be satisfied.
+	for _, m := range b.AllOf {
+		c.subset(sa, m.Schema(), path, depth+1)
+	}
+
+	// every branch of a must fit into b.
+	for _, branches := range [][]*SchemaProxy{a.AnyOf, a.OneOf} {
+		if len(branches) == 0 {
+			continue
+		}
+		rest := *a.Schema
+		rest.AnyOf, rest.OneOf = nil, nil
+		for _, br := range branches {
+			if bs := br.Schema(); bs != nil {
+				c.subset(&Schema{AllOf: []*SchemaProxy{CreateSchemaProxy(&rest), br}}, sb, path, depth+1)
+			}
+		}
+		return
+	}
+
+	// a must fit into one branch of b.
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches := b.AnyOf
+		if keyword == "oneOf" {
+			branches = b.OneOf
+		}
+		if len(branches) == 0 {
+			continue
+		}
+		matched := -1
+		for i, br := range branches {
+			bs := br.Schema()
+			if bs == nil {
+				continue
+			}
+			sub := &schemaComparer{seen: c.seen, keys: c.keys}
+			sub.subset(a.Schema, bs, path, depth+1)
+			if len(sub.issues) == 0 {
+				matched = i
+				break
+			}
+		}
+		if matched < 0 {
+			c.report(b, keyword, path, "value is not guaranteed to match any of the %s schemas", keyword)
+			continue
+		}
+		// oneOf also rejects values that match more than one branch, only differing types rule that out.
+		if keyword == "oneOf" {
+			for i, br := range branches {
+				if i != matched && !c.disjoint(a, br.Schema()) {
+					c.report(b, keyword, path,
+						"value may match more than one of the oneOf schemas, compatibility cannot be proven")
+					break
+				}
+			}
+		}
+	}
+
+	// enum and const list every valid value, so each one is checked against b.
+	if values := a.values(); values != nil {
+		for _, v := range values {
+			for _, e := range b.ValidateNode(v, nil) {
+				c.report(b, e.Keyword, path+e.InstancePath, "value %s is not valid: %s", displayValue(v), e.Message)
+			}
+		}
+		return
+	}
+	if b.Not != nil {
+		if nb := b.Not.Schema(); nb != nil && !c.disjoint(a, nb) {
+			c.report(b, "not", path, "value may match the schema it must not match")
+		}
+	}
+
+	if b.Const != nil {
+		c.report(b, "const", path, "value must be %s", displayValue(b.Const))
+	} else if len(b.Enum) > 0 {
+		c.report(b, "enum", path, "value is not limited to the enum values")
+	}
+
+	if (b.If != nil || b.Then != nil || b.Else != nil) &&
+		!(sameSchema(a.If, b.If) && sameSchema(a.Then, b.Then) && sameSchema(a.Else, b.Else)) {
+		c.report(b, "if", path, "conditional schemas are not compared, compatibility cannot be proven")
+	}
+
+	c.types(a, b, path)
+	c.numbers(a, b, path)
+	c.strings(a, b, path)
+	c.arrays(a, b, path, depth)
+	c.objects(a, b, path, depth)
+}
+
+// values returns the values a schema is limited to by 'enum' or 'const', nil if it is not limited.
+func (s flat) values() []*yaml.Node {
+	if s.Const != nil {
+		return []*yaml.Node{s.Const}
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum
+	}
+	return nil
+}
+
+// allowedTypes returns the types a schema allows, nil means every type.
+func (s flat) allowedTypes() []string {
+	types := s.Type
+	if len(types) > 0 && s.Nullable != nil && *s.Nullable && !containsString(types, "null") {
+		types = append(append([]string(nil), types...), "null")
+	}
+	return types
+}
+
+func allowsType(types []string, t string) bool {
+	return types == nil || containsString(types, t) || t == "integer" && containsString(types, "number")
+}
+
+func (c *schemaComparer) types(a, b flat, path string) {
+	bt := b.allowedTypes()
+	if bt == nil {
+		return
+	}
+	at := a.allowedTypes()
+	if at == nil {
+		c.report(b, "type", path, "value can be of any type, but must be %s", strings.Join(bt, " or "))
+		return
+	}
+	for _, t := range at {
+		if !allowsType(bt, t) {
+			c.report(b, "type", path, "value can be %s, but must be %s", t, strings.Join(bt, " or "))
+		}
+	}
+}
+
+// disjoint reports if no instance can be valid against both schemas, judged by their types.
+func (c *schemaComparer) disjoint(a flat, other *Schema) bool {
+	if other == nil {
+		return false
+	}
+	n, ok := flatten(other)
+	if !ok {
+		return true
+	}
+	at, nt := a.allowedTypes(), n.allowedTypes()
+	if at == nil || nt == nil {
+		return false
+	}
+	for _, t := range at {
+		if allowsType(nt, t) || t == "number" && containsString(nt, "integer") {
+			return false
+		}
+	}
+	return true
+}
+
+// bound is a numeric limit, which can be exclusive.
+type bound struct {
+	value     float64
+	exclusive bool
+	set       bool
+}
+
+func lowerBound(s flat) bound {
+	var b bound
+	if s.Minimum != nil {
+		b = bound{value: *s.Minimum, set: true}
+	}
+	if e := s.ExclusiveMinimum; e != nil {
+		if e.IsB() && (!b.set || e.B >= b.value) {
+			b = bound{value: e.B, exclusive: true, set: true}
+		} else if e.IsA() && e.A && b.set {
+			b.exclusive = true
+		}
+	}
+	return b
+}
+
+func upperBound(s flat) bound {
+	var b bound
+	if s.Maximum != nil {
+		b = bound{value: *s.Maximum, set: true}
+	}
+	if e := s.ExclusiveMaximum; e != nil {
+		if e.IsB() && (!b.set || e.B <= b.value) {
+			b = bound{value: e.B, exclusive: true, set: true}
+		} else if e.IsA() && e.A && b.set {
+			b.exclusive = true
+		}
+	}
+	return b
+}
+
+func (c *schemaComparer) numbers(a, b flat, path string) {
+	at := a.allowedTypes()
+	if !allowsType(at, "number") && !allowsType(at, "integer") {
+		return
+	}
+	keyword := func(bd bound, inclusive, exclusive string) string {
+		if bd.exclusive {
+			return exclusive
+		}
+		return inclusive
+	}
+	if bl, al := lowerBound(b), lowerBound(a); bl.set {
+		if !al.set || al.value < bl.value || al.value == bl.value && bl.exclusive && !al.exclusive {
+			c.report(b, keyword(bl, "minimum", "exclusiveMinimum"), path,
+				"numbers can be smaller than the %s of %v", keyword(bl, "minimum", "exclusiveMinimum"), bl.value)
+		}
+	}
+	if bu, au := upperBound(b), upperBound(a); bu.set {
+		if !au.set || au.value > bu.value || au.value == bu.value && bu.exclusive && !au.exclusive {
+			c.report(b, keyword(bu, "maximum", "exclusiveMaximum"), path,
+				"numbers can be larger than the %s of %v", keyword(bu, "maximum", "exclusiveMaximum"), bu.value)
+		}
+	}
+	if b.MultipleOf != nil && (a.MultipleOf == nil || !isMultiple(*a.MultipleOf, *b.MultipleOf)) {
+		// every integer is a multiple of one.
+		if *b.MultipleOf != 1 || at == nil || containsString(at, "number") {
+			c.report(b, "multipleOf", path, "numbers are not guaranteed to be a multiple of %v", *b.MultipleOf)
+		}
+	}
+}
+
+func (c *schemaComparer) strings(a, b flat, path string) {
+	if !allowsType(a.allowedTypes(), "string") {
+		return
+	}
+	c.counts(a, b, path, "Length", "strings", a.MinLength, a.MaxLength, b.MinLength, b.MaxLength)
+	if b.Pattern != "" && a.Pattern != b.Pattern {
+		c.report(b, "pattern", path, "strings are not guaranteed to match the pattern '%s'", b.Pattern)
+	}
+	if b.Format != "" && a.Format != b.Format {
+		c.report(b, "format", path, "strings are not guaranteed to have the format '%s'", b.Format)
+	}
+}
+
+// counts compares minimum and maximum counts (lengths, items or properties).
+func (c *schemaComparer) counts(a, b flat, path, suffix, what string, aMin, aMax, bMin, bMax *int64) {
+	if bMin != nil && *bMin > 0 && (aMin == nil || *aMin < *bMin) {
+		c.report(b, "min"+suffix, path, "%s can be shorter than the min%s of %d", what, suffix, *bMin)
+	}
+	if bMax != nil && (aMax == nil || *aMax > *bMax) {
+		c.report(b, "max"+suffix, path, "%s can be longer than the max%s of %d", what, suffix, *bMax)
+	}
+}
+
+func (c *schemaComparer) arrays(a, b flat, path string, depth int) {
+	if !allowsType(a.allowedTypes(), "array") {
+		return
+	}
+	c.counts(a, b, path, "Items", "arrays", a.MinItems, a.MaxItems, b.MinItems, b.MaxItems)
+	if b.UniqueItems != nil && *b.UniqueItems && (a.UniqueItems == nil || !*a.UniqueItems) {
+		c.report(b, "uniqueItems", path, "array items are not guaranteed to be unique")
+	}
+	if b.Contains != nil && !(sameSchema(a.Contains, b.Contains) &&
+		sameInt(a.MinContains, b.MinContains) && sameInt(a.MaxContains, b.MaxContains)) {
+		c.report(b, "contains", path, "contained items are not compared, compatibility cannot be proven")
+	}
+	if b.UnevaluatedItems != nil && !sameSchema(a.UnevaluatedItems, b.UnevaluatedItems) {
+		c.report(b, "unevaluatedItems", path, "unevaluated items are not compared, compatibility cannot be proven")
+	}
+	if a.MaxItems != nil && *a.MaxItems == 0 {
+		return
+	}
+	if !sameSchemas(a.PrefixItems, b.PrefixItems) {
+		c.report(b, "prefixItems", path, "tuple items are not compared, compatibility cannot be proven")
+		return
+	}
+	if b.Items == nil {
+		return
+	}
+	i := len(b.PrefixItems)
+	if b.Items.IsB() {
+		if !b.Items.B && (a.MaxItems == nil || *a.MaxItems > int64(i)) && itemSchema(a, i) != nil {
+			c.report(b, "items", fmt.Sprintf("%s/%d", path, i), "arrays can have more than %d items", i)
+		}
+		return
+	}
+	if ai := itemSchema(a, i); ai != nil {
+		c.subset(ai, b.Items.A.Schema(), fmt.Sprintf("%s/%d", path, i), depth+1)
+	}
+}
+
+// itemSchema returns the schema of an array item at an index, nil if the item is not allowed. An empty schema
+// (allowing everything) is returned if the item is not constrained.
+func itemSchema(s flat, i int) *Schema {
+	if i < len(s.PrefixItems) {
+		return s.PrefixItems[i].Schema()
+	}
+	if s.Items == nil {
+		return &Schema{}
+	}
+	if s.Items.IsB() {
+		if s.Items.B {
+			return &Schema{}
+		}
+		return nil
+	}
+	return s.Items.A.Schema()
+}
+
+func (c *schemaComparer) objects(a, b flat, path string, depth int) {
+	if !allowsType(a.allowedTypes(), "object") {
+		return
+	}
+	c.counts(a, b, path, "Properties", "objects", a.MinProperties, a.MaxProperties, b.MinProperties, b.MaxProperties)
+	if (a.PatternProperties != nil || b.PatternProperties != nil) &&
+		!sameSchemaMap(a.PatternProperties, b.PatternProperties) {
+		c.report(b, "patternProperties", path,
+			"properties matching patterns are not compared, compatibility cannot be proven")
+	}
+	if b.PropertyNames != nil && !sameSchema(a.PropertyNames, b.PropertyNames) {
+		c.report(b, "propertyNames", path, "property names are not compared, compatibility cannot be proven")
+	}
+	if b.DependentSchemas != nil && !sameSchemaMap(a.DependentSchemas, b.DependentSchemas) {
+		c.report(b, "dependentSchemas", path, "dependent schemas are not compared, compatibility cannot be proven")
+	}
+	if !coversNodes(unmodelled(a.source, "dependentRequired", 0), unmodelled(b.source, "dependentRequired", 0)) {
+		c.report(b, "dependentRequired", path,
+			"dependent required properties are not compared, compatibility cannot be proven")
+	}
+	if b.UnevaluatedProperties != nil && !sameDynamicSchema(a.UnevaluatedProperties, b.UnevaluatedProperties) {
+		c.report(b, "unevaluatedProperties", path,
+			"unevaluated properties are not compared, compatibility cannot be proven")
+	}
+	for _, r := range b.Required {
+		if !containsString(a.Required, r) {
+			c.report(b, "required", path+"/"+utils.EscapePointerSegment(r), "property '%s' is required, but can be missing", r)
+		}
+	}
+
+	// every property a allows must be allowed by b, with a compatible schema.
+	if a.Properties != nil {
+		for pair := a.Properties.First(); pair != nil; pair = pair.Next() {
+			name := pair.Key()
+			as := pair.Value().Schema()
+			ppath := path + "/" + utils.EscapePointerSegment(name)
+			bs, allowed := propertySchema(b, name)
+			if !allowed {
+				c.report(b, "additionalProperties", ppath, "property '%s' is not allowed", name)
+				continue
+			}
+			c.subset(as, bs, ppath, depth+1)
+		}
+	}
+	if b.Properties != nil {
+		for pair := b.Properties.First(); pair != nil; pair = pair.Next() {
+			if a.Properties != nil {
+				if _, ok := a.Properties.Get(pair.Key()); ok {
+					continue
+				}
+			}
+			if as, allowed := propertySchema(a, pair.Key()); allowed {
+				c.subset(as, pair.Value().Schema(), path+"/"+utils.EscapePointerSegment(pair.Key()), depth+1)
+			}
+		}
+	}
+
+	// properties a allows without naming them.
+	ap := a.AdditionalProperties
+	if ap != nil && ap.IsB() && !ap.B || b.AdditionalProperties == nil {
+		return
+	}
+	bp := b.AdditionalProperties
+	if bp.IsB() {
+		if !bp.B {
+			c.report(b, "additionalProperties", path+"/*", "objects can have properties that are not allowed")
+		}
+		return
+	}
+	as := &Schema{}
+	if ap != nil && ap.IsA() {
+		as = ap.A.Schema()
+	}
+	c.subset(as, bp.A.Schema(), path+"/*", depth+1)
+}
+
+// propertySchema returns the schema of a property, and if the property is allowed at all.
+func propertySchema(s flat, name string) (*Schema, bool) {
+	if s.Properties != nil {
+		if p, ok := s.Properties.Get(name); ok {
+			return p.Schema(), true
+		}
+	}
+	if s.AdditionalProperties == nil {
+		return &Schema{}, true
+	}
+	if s.AdditionalProperties.IsB() {
+		return &Schema{}, s.AdditionalProperties.B
+	}
+	return s.AdditionalProperties.A.Schema(), true
+}
+
+func sameSchemas(x, y []*SchemaProxy) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !sameSchema(x[i], y[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSchemaMap(x, y *orderedmap.Map[string, *SchemaProxy]) bool {
+	if orderedmap.Len(x) != orderedmap.Len(y) {
+		return false
+	}
+	for pair := orderedmap.First(x); pair != nil; pair = pair.Next() {
+		if v, ok := y.Get(pair.Key()); !ok || !sameSchema(pair.Value(), v) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameDynamicSchema(x, y *DynamicValue[*SchemaProxy, bool]) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	if x.IsB() || y.IsB() {
+		return x.IsB() && y.IsB() && x.B == y.B
+	}
+	return sameSchema(x.A, y.A)
+}
+
+func sameInt(x, y *int64) bool {
+	return x == nil && y == nil || x != nil && y != nil && *x == *y
+}
+
+// unmodelled returns the values of a keyword the schema model does not hold (such as 'dependentRequired'), found in
+// the YAML of s and of its 'allOf' members.
+func unmodelled(s *Schema, keyword string, depth int) []*yaml.Node {
+	if s == nil || depth > maxCompatibilityDepth {
+		return nil
+	}
+	var found []*yaml.Node
+	if s.low != nil {
+		node, origin := s.validationNode()
+		resolve := newReferenceResolver(origin)
+		var o any = origin
+		for i := 0; i < maxCompatibilityDepth; i++ {
+			node = unwrapNode(node)
+			if node == nil || node.Kind != yaml.MappingNode {
+				break
+			}
+			if _, ref := utils.FindKeyNodeTop("$ref", node.Content); ref != nil {
+				node, o = resolve(node, o)
+				continue
+			}
+			if _, v := utils.FindKeyNodeTop(keyword, node.Content); v != nil {
+				found = append(found, v)
+			}
+			break
+		}
+	}
+	for _, m := range s.AllOf {
+		found = append(found, unmodelled(m.Schema(), keyword, depth+1)...)
+	}
+	return found
+}
+
+// coversNodes reports if every node of y has an equal node in x.
+func coversNodes(x, y []*yaml.Node) bool {
+	for _, yn := range y {
+		want, _ := yaml.Marshal(yn)
+		covered := false
+		for _, xn := range x {
+			if got, _ := yaml.Marshal(xn); string(got) == string(want) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
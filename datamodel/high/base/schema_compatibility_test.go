@@ -0,0 +1,233 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var compatibilitySpec = `openapi: 3.1.0
+components:
+  schemas:
+    Name:
+      type: string
+      maxLength: 50
+    Pet:
+      type: object
+      required: [name]
+      additionalProperties: false
+      properties:
+        name:
+          $ref: '#/components/schemas/Name'
+        age:
+          type: integer
+          minimum: 0
+    PetComposed:
+      allOf:
+        - type: object
+          required: [name]
+          additionalProperties: false
+          properties:
+            name:
+              type: string
+              maxLength: 50
+        - properties:
+            age:
+              type: integer
+              minimum: 0
+    PetWide:
+      type: object
+      properties:
+        name:
+          type: string
+          maxLength: 100
+        age:
+          type: number
+        tag:
+          type: string
+    Status:
+      type: string
+      enum: [active, inactive]
+    StatusWide:
+      anyOf:
+        - type: string
+          enum: [active, inactive, pending]
+        - type: integer
+    Count:
+      type: integer
+      exclusiveMinimum: 0
+      multipleOf: 4
+    Items:
+      type: array
+      maxItems: 10
+      items:
+        $ref: '#/components/schemas/Count'
+    Node:
+      type: object
+      properties:
+        next:
+          $ref: '#/components/schemas/Node'`
+
+func TestSchema_IsSubsetOf(t *testing.T) {
+	pet := validationSchema(t, compatibilitySpec, "Pet")
+	composed := validationSchema(t, compatibilitySpec, "PetComposed")
+	wide := validationSchema(t, compatibilitySpec, "PetWide")
+
+	// moving properties into allOf members, and behind references, does not change the schema.
+	assert.Empty(t, pet.IsSubsetOf(composed))
+	assert.Empty(t, composed.IsSubsetOf(pet))
+
+	// widening is compatible one way only.
+	assert.Empty(t, pet.IsSubsetOf(wide))
+	assert.Empty(t, wide.IsSupersetOf(pet))
+
+	issues := pet.IsSupersetOf(wide)
+	require.Len(t, issues, 6)
+	assert.Equal(t, "required", issues[0].Keyword)
+	assert.Equal(t, "/name", issues[0].InstancePath)
+	assert.Equal(t, 9, issues[0].Line)
+	assert.Equal(t, "maxLength", issues[1].Keyword)
+	assert.Equal(t, "/name", issues[1].InstancePath)
+	assert.Equal(t, "/name: strings can be longer than the maxLength of 50 [6:18]", issues[1].Error())
+	assert.Equal(t, "type", issues[2].Keyword)
+	assert.Equal(t, "/age", issues[2].InstancePath)
+	assert.Equal(t, "/age: value can be number, but must be integer [15:17]", issues[2].Error())
+	assert.Equal(t, "minimum", issues[3].Keyword)
+	assert.Equal(t, "additionalProperties", issues[4].Keyword)
+	assert.Equal(t, "/tag", issues[4].InstancePath)
+	assert.Equal(t, "/*", issues[5].InstancePath)
+}
+
+func TestSchema_IsSubsetOf_EnumsAndCompositions(t *testing.T) {
+	status := validationSchema(t, compatibilitySpec, "Status")
+	statusWide := validationSchema(t, compatibilitySpec, "StatusWide")
+
+	assert.Empty(t, status.IsSubsetOf(statusWide))
+	issues := statusWide.IsSubsetOf(status)
+	require.NotEmpty(t, issues)
+	assert.Equal(t, "enum", issues[0].Keyword)
+	assert.Contains(t, issues[0].Message, "'pending'")
+
+	count := validationSchema(t, compatibilitySpec, "Count")
+	assert.Empty(t, count.IsSubsetOf(&Schema{Type: []string{"number"}, Minimum: ptrFloat(0), MultipleOf: ptrFloat(2)}))
+	issues = count.IsSubsetOf(&Schema{Type: []string{"integer"}, Minimum: ptrFloat(2), MultipleOf: ptrFloat(3)})
+	require.Len(t, issues, 2)
+	assert.Equal(t, "minimum", issues[0].Keyword)
+	assert.Equal(t, "multipleOf", issues[1].Keyword)
+	assert.Nil(t, issues[0].Node, "schemas created from scratch have no positions")
+
+	items := validationSchema(t, compatibilitySpec, "Items")
+	issues = items.IsSubsetOf(&Schema{
+		Type:     []string{"array"},
+		MaxItems: ptrInt(5),
+		Items:    &DynamicValue[*SchemaProxy, bool]{A: CreateSchemaProxy(&Schema{Type: []string{"string"}})},
+	})
+	require.Len(t, issues, 2)
+	assert.Equal(t, "maxItems", issues[0].Keyword)
+	assert.Equal(t, "/0", issues[1].InstancePath)
+
+	// not, and recursive schemas.
+	assert.Empty(t, status.IsSubsetOf(&Schema{Not: CreateSchemaProxy(&Schema{Type: []string{"null"}})}))
+	assert.Len(t, status.IsSubsetOf(&Schema{Not: CreateSchemaProxy(&Schema{Type: []string{"string"}})}), 2)
+	node := validationSchema(t, compatibilitySpec, "Node")
+	assert.Empty(t, node.IsSubsetOf(node))
+}
+
+var unprovenSpec = `openapi: 3.1.0
+components:
+  schemas:
+    Circle:
+      type: object
+      required: [radius]
+      properties:
+        radius:
+          type: number
+    Shape:
+      oneOf:
+        - type: object
+          properties:
+            radius:
+              type: number
+        - type: object
+          properties:
+            side:
+              type: number
+    Id:
+      oneOf:
+        - type: string
+        - type: integer
+    Conditional:
+      type: object
+      if:
+        required: [kind]
+      then:
+        required: [name]
+    Pair:
+      type: array
+      prefixItems:
+        - type: string
+        - type: integer
+    Dependent:
+      type: object
+      dependentRequired:
+        radius: [unit]`
+
+func TestSchema_IsSubsetOf_Unproven(t *testing.T) {
+	circle := validationSchema(t, unprovenSpec, "Circle")
+
+	// a circle matches both shapes, and oneOf rejects values matching more than one.
+	shape := validationSchema(t, unprovenSpec, "Shape")
+	issues := circle.IsSubsetOf(shape)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "oneOf", issues[0].Keyword)
+	assert.Equal(t, "", issues[0].InstancePath)
+	assert.Contains(t, issues[0].Message, "cannot be proven")
+	assert.Equal(t, 12, issues[0].Line)
+
+	// branches of different types cannot both match.
+	id := validationSchema(t, unprovenSpec, "Id")
+	assert.Empty(t, (&Schema{Type: []string{"string"}, MaxLength: ptrInt(10)}).IsSubsetOf(id))
+
+	conditional := validationSchema(t, unprovenSpec, "Conditional")
+	issues = circle.IsSubsetOf(conditional)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "if", issues[0].Keyword)
+	assert.Contains(t, issues[0].Message, "cannot be proven")
+	assert.Equal(t, 27, issues[0].Line)
+	assert.Empty(t, conditional.IsSubsetOf(conditional))
+
+	pair := validationSchema(t, unprovenSpec, "Pair")
+	issues = (&Schema{Type: []string{"array"}}).IsSubsetOf(pair)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "prefixItems", issues[0].Keyword)
+	assert.Empty(t, pair.IsSubsetOf(pair))
+
+	dependent := validationSchema(t, unprovenSpec, "Dependent")
+	issues = circle.IsSubsetOf(dependent)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "dependentRequired", issues[0].Keyword)
+	assert.Equal(t, 38, issues[0].Line)
+	assert.Empty(t, dependent.IsSubsetOf(dependent))
+
+	// schemas nested deeper than can be compared.
+	deep := &Schema{Type: []string{"string"}}
+	for i := 0; i <= maxCompatibilityDepth; i++ {
+		deep = &Schema{
+			Type:       []string{"object"},
+			Properties: orderedmap.ToOrderedMap(map[string]*SchemaProxy{"next": CreateSchemaProxy(deep)}),
+		}
+	}
+	issues = deep.IsSubsetOf(deep)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "", issues[0].Keyword)
+	assert.Contains(t, issues[0].Message, "nested too deeply")
+}
+
+func ptrFloat(f float64) *float64 { return &f }
+
+func ptrInt(i int64) *int64 { return &i }
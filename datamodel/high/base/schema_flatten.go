@@ -0,0 +1,557 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/jsonschema"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// maxFlattenDepth limits how deep Flatten will merge schemas, this catches schemas that reference themselves
+// through the properties being merged.
+const maxFlattenDepth = 64
+
+// SchemaConflict is returned by Flatten when the members of an 'allOf' are incompatible, so no value can be valid
+// against all of them.
+type SchemaConflict struct {
+	// Message explains the conflict.
+	Message string
+
+	// Keyword is the keyword that conflicts, for example 'type'.
+	Keyword string
+
+	// SchemaPath is a JSON pointer to the schema that conflicts, from the schema being flattened, for example
+	// '/allOf/1' or '/properties/name/allOf/0'.
+	SchemaPath string
+
+	// Node is the value of the conflicting keyword, nil if the schema was created from scratch.
+	Node *yaml.Node
+
+	// Line and Column of the conflicting keyword.
+	Line   int
+	Column int
+
+	// Location is the file (or URL) that contains the conflicting keyword, when it is known.
+	Location string
+}
+
+func (c *SchemaConflict) Error() string {
+	if c.Node == nil {
+		return fmt.Sprintf("allOf conflict at '%s': %s", c.SchemaPath, c.Message)
+	}
+	return fmt.Sprintf("allOf conflict at '%s': %s [%d:%d]", c.SchemaPath, c.Message, c.Line, c.Column)
+}
+
+// Flatten computes the effective schema of s, by merging the members of its 'allOf' into it. Members are
+// flattened first, so nested 'allOf' schemas are merged recursively. s is not modified.
+//
+// Properties and required properties are combined. Numeric, length, item and property count constraints take the
+// tightest value, enums are intersected and types are intersected. A property defined by more than one member is
+// flattened from all of its definitions. Annotations such as 'title' and 'description' are taken from the first
+// schema that sets them, starting with s.
+//
+// Keywords that cannot be combined into a single value (such as two different 'oneOf' or 'pattern' values) are
+// kept in the 'allOf' of the effective schema. If members are incompatible, every conflict is returned as a
+// *SchemaConflict, joined into a single error, along with the effective schema built from everything else.
+func (s *Schema) Flatten() (*Schema, error) {
+	f := &schemaFlattener{visiting: make(map[*base.Schema]bool), origins: make(map[*Schema]*base.Schema)}
+	flat := f.flatten(s, "", 0)
+	return flat, errors.Join(f.errs...)
+}
+
+// Flatten builds the schema of the proxy, and computes its effective schema using Schema.Flatten.
+func (sp *SchemaProxy) Flatten() (*Schema, error) {
+	if sp.schema == nil && sp.rendered == nil {
+		return nil, fmt.Errorf("unable to flatten schema, the reference '%s' has not been resolved", sp.refStr)
+	}
+	s := sp.Schema()
+	if s == nil {
+		if err := sp.GetBuildError(); err != nil {
+			return nil, fmt.Errorf("unable to flatten schema: %w", err)
+		}
+		return nil, errors.New("unable to flatten schema, it cannot be built")
+	}
+	return s.Flatten()
+}
+
+type schemaFlattener struct {
+	errs     []error
+	visiting map[*base.Schema]bool
+	origins  map[*Schema]*base.Schema // low-level schema each flattened schema was created from.
+}
+
+// flatten returns s if it has no 'allOf', otherwise a copy of s with every member merged into it.
+func (f *schemaFlattener) flatten(s *Schema, path string, depth int) *Schema {
+	if len(s.AllOf) == 0 {
+		return s
+	}
+	if depth > maxFlattenDepth {
+		f.conflict(s, "allOf", path, "schema is nested too deeply to be flattened")
+		return s
+	}
+	flat := *s
+	flat.AllOf = nil
+	flat.low = nil
+	flat.ParentProxy = nil
+	flat.Required = append([]string(nil), s.Required...)
+	if s.Extensions != nil {
+		flat.Extensions = orderedmap.New[string, *yaml.Node]()
+		for pair := s.Extensions.First(); pair != nil; pair = pair.Next() {
+			flat.Extensions.Set(pair.Key(), pair.Value())
+		}
+	}
+	if o := f.origin(s); o != nil {
+		f.origins[&flat] = o
+	}
+
+	for i, member := range s.AllOf {
+		memberPath := fmt.Sprintf("%s/allOf/%d", path, i)
+		m := member.Schema()
+		if m == nil {
+			f.errs = append(f.errs, &SchemaConflict{
+				Message:    "member cannot be built",
+				Keyword:    "allOf",
+				SchemaPath: memberPath,
+			})
+			continue
+		}
+		if o := m.low; o != nil {
+			if f.visiting[o] {
+				f.conflict(m, "allOf", memberPath, "member is circular, it contains itself")
+				continue
+			}
+			f.visiting[o] = true
+		}
+		merged := f.flatten(m, memberPath, depth+1)
+		if m.low != nil {
+			delete(f.visiting, m.low)
+		}
+		f.merge(&flat, merged, memberPath, depth)
+	}
+	return &flat
+}
+
+// merge combines the keywords of the member m into the schema being flattened.
+func (f *schemaFlattener) merge(flat, m *Schema, path string, depth int) {
+	rest := new(Schema)
+
+	// annotations, the first schema to set one wins.
+	flat.Title = firstString(flat.Title, m.Title)
+	flat.Description = firstString(flat.Description, m.Description)
+	flat.Discriminator = first(flat.Discriminator, m.Discriminator)
+	flat.XML = first(flat.XML, m.XML)
+	flat.ExternalDocs = first(flat.ExternalDocs, m.ExternalDocs)
+	flat.Example = first(flat.Example, m.Example)
+	flat.Default = first(flat.Default, m.Default)
+	if len(flat.Examples) == 0 {
+		flat.Examples = m.Examples
+	}
+	flat.Deprecated = either(flat.Deprecated, m.Deprecated)
+	flat.ReadOnly = either(flat.ReadOnly, m.ReadOnly)
+	flat.WriteOnly = either(flat.WriteOnly, m.WriteOnly)
+	flat.UniqueItems = either(flat.UniqueItems, m.UniqueItems)
+	if m.Extensions != nil {
+		if flat.Extensions == nil {
+			flat.Extensions = orderedmap.New[string, *yaml.Node]()
+		}
+		for pair := m.Extensions.First(); pair != nil; pair = pair.Next() {
+			if _, ok := flat.Extensions.Get(pair.Key()); !ok {
+				flat.Extensions.Set(pair.Key(), pair.Value())
+			}
+		}
+	}
+
+	// 3.0 documents mark a schema that only holds an 'allOf' as nullable, so it is treated like an annotation.
+	flat.Nullable = first(flat.Nullable, m.Nullable)
+
+	f.mergeType(flat, m, path)
+	f.mergeEnum(flat, m, path)
+	if flat.Const == nil {
+		flat.Const = m.Const
+	} else if m.Const != nil && !jsonschema.Equal(flat.Const, m.Const) {
+		f.conflict(m, "const", path, "const values %s and %s are different",
+			displayValue(flat.Const), displayValue(m.Const))
+	}
+	if flat.Format == "" {
+		flat.Format = m.Format
+	} else if m.Format != "" && m.Format != flat.Format {
+		f.conflict(m, "format", path, "formats '%s' and '%s' are different", flat.Format, m.Format)
+	}
+
+	// numeric and count constraints, the tightest value wins.
+	flat.Maximum = tightest(flat.Maximum, m.Maximum, math.Min)
+	flat.Minimum = tightest(flat.Minimum, m.Minimum, math.Max)
+	flat.ExclusiveMaximum = f.mergeExclusive(flat.ExclusiveMaximum, m.ExclusiveMaximum, math.Min)
+	flat.ExclusiveMinimum = f.mergeExclusive(flat.ExclusiveMinimum, m.ExclusiveMinimum, math.Max)
+	flat.MaxLength = tightestInt(flat.MaxLength, m.MaxLength, false)
+	flat.MinLength = tightestInt(flat.MinLength, m.MinLength, true)
+	flat.MaxItems = tightestInt(flat.MaxItems, m.MaxItems, false)
+	flat.MinItems = tightestInt(flat.MinItems, m.MinItems, true)
+	flat.MaxProperties = tightestInt(flat.MaxProperties, m.MaxProperties, false)
+	flat.MinProperties = tightestInt(flat.MinProperties, m.MinProperties, true)
+	flat.MaxContains = tightestInt(flat.MaxContains, m.MaxContains, false)
+	flat.MinContains = tightestInt(flat.MinContains, m.MinContains, true)
+	f.mergeMultipleOf(flat, m, path)
+	f.checkRanges(flat, m, path)
+
+	// objects and arrays.
+	for _, r := range m.Required {
+		if !containsString(flat.Required, r) {
+			flat.Required = append(flat.Required, r)
+		}
+	}
+	flat.Properties = f.mergeSchemaMap(flat.Properties, m.Properties, path+"/properties", depth)
+	flat.PatternProperties = f.mergeSchemaMap(flat.PatternProperties, m.PatternProperties,
+		path+"/patternProperties", depth)
+	flat.DependentSchemas = f.mergeSchemaMap(flat.DependentSchemas, m.DependentSchemas,
+		path+"/dependentSchemas", depth)
+	flat.AdditionalProperties = f.mergeDynamic(flat.AdditionalProperties, m.AdditionalProperties,
+		path+"/additionalProperties", depth)
+	flat.Items = f.mergeDynamic(flat.Items, m.Items, path+"/items", depth)
+
+	// keywords that cannot be combined into one value are kept in allOf.
+	keep := func(a, b *SchemaProxy, set func(*Schema, *SchemaProxy)) {
+		if b == nil {
+			return
+		}
+		if a == nil {
+			set(flat, b)
+		} else if a != b {
+			set(rest, b)
+		}
+	}
+	keep(flat.Not, m.Not, func(s *Schema, sp *SchemaProxy) { s.Not = sp })
+	keep(flat.Contains, m.Contains, func(s *Schema, sp *SchemaProxy) { s.Contains = sp })
+	keep(flat.PropertyNames, m.PropertyNames, func(s *Schema, sp *SchemaProxy) { s.PropertyNames = sp })
+	keep(flat.UnevaluatedItems, m.UnevaluatedItems, func(s *Schema, sp *SchemaProxy) { s.UnevaluatedItems = sp })
+	if flat.If == nil && flat.Then == nil && flat.Else == nil {
+		flat.If, flat.Then, flat.Else = m.If, m.Then, m.Else
+	} else {
+		rest.If, rest.Then, rest.Else = m.If, m.Then, m.Else
+	}
+	keepSlice := func(a, b []*SchemaProxy, set func(*Schema, []*SchemaProxy)) {
+		if len(b) == 0 {
+			return
+		}
+		if len(a) == 0 {
+			set(flat, b)
+		} else {
+			set(rest, b)
+		}
+	}
+	keepSlice(flat.OneOf, m.OneOf, func(s *Schema, sp []*SchemaProxy) { s.OneOf = sp })
+	keepSlice(flat.AnyOf, m.AnyOf, func(s *Schema, sp []*SchemaProxy) { s.AnyOf = sp })
+	keepSlice(flat.PrefixItems, m.PrefixItems, func(s *Schema, sp []*SchemaProxy) { s.PrefixItems = sp })
+	if flat.UnevaluatedProperties == nil {
+		flat.UnevaluatedProperties = m.UnevaluatedProperties
+	} else if m.UnevaluatedProperties != nil {
+		rest.UnevaluatedProperties = m.UnevaluatedProperties
+	}
+	if flat.Pattern == "" {
+		flat.Pattern = m.Pattern
+	} else if m.Pattern != "" && m.Pattern != flat.Pattern {
+		rest.Pattern = m.Pattern
+	}
+	if flat.DynamicRef == "" {
+		flat.DynamicRef, flat.DynamicRefSchema = m.DynamicRef, m.DynamicRefSchema
+	} else if m.DynamicRef != "" && m.DynamicRef != flat.DynamicRef {
+		rest.DynamicRef, rest.DynamicRefSchema = m.DynamicRef, m.DynamicRefSchema
+	}
+
+	// a flattened member only keeps what it could not combine in allOf.
+	flat.AllOf = append(flat.AllOf, m.AllOf...)
+	if rest.Not != nil || rest.Contains != nil || rest.PropertyNames != nil || rest.UnevaluatedItems != nil ||
+		rest.If != nil || rest.Then != nil || rest.Else != nil || len(rest.OneOf) > 0 || len(rest.AnyOf) > 0 ||
+		len(rest.PrefixItems) > 0 || rest.UnevaluatedProperties != nil || rest.Pattern != "" ||
+		rest.DynamicRef != "" {
+		flat.AllOf = append(flat.AllOf, CreateSchemaProxy(rest))
+	}
+}
+
+func (f *schemaFlattener) mergeType(flat, m *Schema, path string) {
+	if len(m.Type) == 0 {
+		return
+	}
+	if len(flat.Type) == 0 {
+		flat.Type = m.Type
+		return
+	}
+	var types []string
+	for _, a := range flat.Type {
+		for _, b := range m.Type {
+			switch {
+			case a == b:
+				types = append(types, a)
+			case a == "number" && b == "integer", a == "integer" && b == "number":
+				types = append(types, "integer")
+			}
+		}
+	}
+	if len(types) == 0 {
+		f.conflict(m, "type", path, "types %s and %s have nothing in common",
+			strings.Join(flat.Type, ", "), strings.Join(m.Type, ", "))
+		return
+	}
+	flat.Type = types
+}
+
+func (f *schemaFlattener) mergeEnum(flat, m *Schema, path string) {
+	if len(m.Enum) == 0 {
+		return
+	}
+	if len(flat.Enum) == 0 {
+		flat.Enum = m.Enum
+		return
+	}
+	var enum []*yaml.Node
+	for _, a := range flat.Enum {
+		for _, b := range m.Enum {
+			if jsonschema.Equal(a, b) {
+				enum = append(enum, a)
+				break
+			}
+		}
+	}
+	if len(enum) == 0 {
+		f.conflict(m, "enum", path, "enums have no values in common")
+		return
+	}
+	flat.Enum = enum
+}
+
+func (f *schemaFlattener) mergeMultipleOf(flat, m *Schema, path string) {
+	if m.MultipleOf == nil {
+		return
+	}
+	if flat.MultipleOf == nil {
+		flat.MultipleOf = m.MultipleOf
+		return
+	}
+	a, b := *flat.MultipleOf, *m.MultipleOf
+	switch {
+	case a == b:
+	case isMultiple(a, b):
+		// a is already a multiple of b.
+	case isMultiple(b, a):
+		flat.MultipleOf = m.MultipleOf
+	case a == math.Trunc(a) && b == math.Trunc(b):
+		lcm := a / float64(gcd(int64(a), int64(b))) * b
+		flat.MultipleOf = &lcm
+	default:
+		f.conflict(m, "multipleOf", path, "multipleOf values %v and %v cannot be combined", a, b)
+	}
+}
+
+// mergeExclusive combines exclusive bounds, numbers (3.1) take the tightest value, booleans (3.0) are exclusive if
+// either is.
+func (f *schemaFlattener) mergeExclusive(a, b *DynamicValue[bool, float64],
+	pick func(float64, float64) float64,
+) *DynamicValue[bool, float64] {
+	switch {
+	case b == nil:
+		return a
+	case a == nil:
+		return b
+	case a.IsB() && b.IsB():
+		return &DynamicValue[bool, float64]{N: 1, B: pick(a.B, b.B)}
+	case a.IsA() && b.IsA():
+		return &DynamicValue[bool, float64]{A: a.A || b.A}
+	}
+	return a
+}
+
+// checkRanges reports minimums that are larger than maximums, when the member being merged sets one of them. The
+// conflict is positioned at the keyword the member sets.
+func (f *schemaFlattener) checkRanges(flat, m *Schema, path string) {
+	keyword := func(min string, memberMin bool) string {
+		if memberMin {
+			return min
+		}
+		return strings.Replace(min, "min", "max", 1)
+	}
+	if flat.Minimum != nil && flat.Maximum != nil && *flat.Minimum > *flat.Maximum &&
+		(m.Minimum != nil || m.Maximum != nil) {
+		f.conflict(m, keyword("minimum", m.Minimum != nil), path, "minimum %v is larger than maximum %v",
+			*flat.Minimum, *flat.Maximum)
+	}
+	if flat.ExclusiveMinimum != nil && flat.ExclusiveMaximum != nil && flat.ExclusiveMinimum.IsB() &&
+		flat.ExclusiveMaximum.IsB() && flat.ExclusiveMinimum.B >= flat.ExclusiveMaximum.B &&
+		(m.ExclusiveMinimum != nil || m.ExclusiveMaximum != nil) {
+		f.conflict(m, keyword("exclusiveMinimum", m.ExclusiveMinimum != nil), path,
+			"exclusiveMinimum %v is not smaller than exclusiveMaximum %v",
+			flat.ExclusiveMinimum.B, flat.ExclusiveMaximum.B)
+	}
+	check := func(min string, flatMin, flatMax, memberMin, memberMax *int64) {
+		if flatMin != nil && flatMax != nil && *flatMin > *flatMax && (memberMin != nil || memberMax != nil) {
+			f.conflict(m, keyword(min, memberMin != nil), path, "%s %d is larger than %s %d", min, *flatMin,
+				keyword(min, false), *flatMax)
+		}
+	}
+	check("minLength", flat.MinLength, flat.MaxLength, m.MinLength, m.MaxLength)
+	check("minItems", flat.MinItems, flat.MaxItems, m.MinItems, m.MaxItems)
+	check("minProperties", flat.MinProperties, flat.MaxProperties, m.MinProperties, m.MaxProperties)
+	check("minContains", flat.MinContains, flat.MaxContains, m.MinContains, m.MaxContains)
+}
+
+// mergeSchemaMap combines maps of schemas, schemas under the same key in both maps are merged together.
+func (f *schemaFlattener) mergeSchemaMap(a, b *orderedmap.Map[string, *SchemaProxy], path string,
+	depth int,
+) *orderedmap.Map[string, *SchemaProxy] {
+	if b == nil || b.Len() == 0 {
+		return a
+	}
+	if a == nil || a.Len() == 0 {
+		return b
+	}
+	merged := orderedmap.New[string, *SchemaProxy]()
+	for pair := a.First(); pair != nil; pair = pair.Next() {
+		merged.Set(pair.Key(), pair.Value())
+	}
+	for pair := b.First(); pair != nil; pair = pair.Next() {
+		existing, ok := merged.Get(pair.Key())
+		if !ok {
+			merged.Set(pair.Key(), pair.Value())
+			continue
+		}
+		merged.Set(pair.Key(), f.mergeProxies(existing, pair.Value(), path+"/"+utils.EscapePointerSegment(pair.Key()), depth))
+	}
+	return merged
+}
+
+// mergeDynamic combines 'items' or 'additionalProperties', false wins over everything, a schema wins over true.
+func (f *schemaFlattener) mergeDynamic(a, b *DynamicValue[*SchemaProxy, bool], path string,
+	depth int,
+) *DynamicValue[*SchemaProxy, bool] {
+	switch {
+	case b == nil:
+		return a
+	case a == nil:
+		return b
+	case a.IsB() && !a.B, b.IsB() && b.B:
+		return a
+	case b.IsB() && !b.B, a.IsB() && a.B:
+		return b
+	}
+	return &DynamicValue[*SchemaProxy, bool]{A: f.mergeProxies(a.A, b.A, path, depth)}
+}
+
+// mergeProxies flattens two schemas into one, unless they are the same schema.
+func (f *schemaFlattener) mergeProxies(a, b *SchemaProxy, path string, depth int) *SchemaProxy {
+	if a == b || sameSchema(a, b) {
+		return a
+	}
+	both := &Schema{AllOf: []*SchemaProxy{a, b}}
+	if depth >= maxFlattenDepth {
+		return CreateSchemaProxy(both)
+	}
+	return CreateSchemaProxy(f.flatten(both, path, depth+1))
+}
+
+// conflict records a conflict, positioned at the keyword of the schema that was being merged.
+func (f *schemaFlattener) conflict(s *Schema, keyword, path, format string, args ...any) {
+	c := &SchemaConflict{Message: fmt.Sprintf(format, args...), Keyword: keyword, SchemaPath: path}
+	if o := f.origin(s); o != nil {
+		if c.Node = keywordNode(o, keyword); c.Node != nil {
+			c.Line, c.Column = c.Node.Line, c.Node.Column
+			c.Location = nodeLocation(o.Index, c.Node)
+		}
+	}
+	f.errs = append(f.errs, c)
+}
+
+// origin returns the low-level schema a schema (or the schema it was flattened from) was built from.
+func (f *schemaFlattener) origin(s *Schema) *base.Schema {
+	if s.low != nil {
+		return s.low
+	}
+	return f.origins[s]
+}
+
+// sameSchema reports if two proxies hold the same schema, comparing the hashes of their low-level schemas.
+func sameSchema(a, b *SchemaProxy) bool {
+	if a == nil || b == nil || a == b {
+		return a == b
+	}
+	la, lb := a.GoLow(), b.GoLow()
+	if la == nil || lb == nil {
+		return false
+	}
+	return la.Hash() == lb.Hash()
+}
+
+func first[T any](a, b *T) *T {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+func firstString(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// either returns true if either value is true.
+func either(a, b *bool) *bool {
+	if b != nil && *b {
+		return b
+	}
+	return first(a, b)
+}
+
+func tightest(a, b *float64, pick func(float64, float64) float64) *float64 {
+	if a == nil || b == nil {
+		return first(a, b)
+	}
+	v := pick(*a, *b)
+	return &v
+}
+
+func tightestInt(a, b *int64, larger bool) *int64 {
+	if a == nil || b == nil {
+		return first(a, b)
+	}
+	if (*b > *a) == larger {
+		return b
+	}
+	return a
+}
+
+// isMultiple reports if a is a multiple of b.
+func isMultiple(a, b float64) bool {
+	if b == 0 {
+		return false
+	}
+	q := a / b
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var flattenSpec = `openapi: 3.1.0
+components:
+  schemas:
+    Named:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          maxLength: 50
+    Pet:
+      description: A pet
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          required: [name, kind]
+          properties:
+            name:
+              minLength: 1
+              maxLength: 20
+            kind:
+              type: string
+              enum: [cat, dog, bird]
+        - allOf:
+            - properties:
+                kind:
+                  enum: [dog, cat, fish]
+            - oneOf:
+                - required: [bark]
+                - required: [meow]
+            - minProperties: 1
+              maxProperties: 10
+              multipleOf: 4
+            - maxProperties: 5
+              multipleOf: 6
+            - oneOf:
+                - required: [fly]
+    Broken:
+      allOf:
+        - type: string
+          format: date
+          minimum: 10
+        - type: integer
+          format: time
+          maximum: 5
+          const: 1
+        - enum: [a]
+        - enum: [b]
+          const: 2
+    Circular:
+      allOf:
+        - $ref: '#/components/schemas/Circular'`
+
+func TestSchema_Flatten(t *testing.T) {
+	pet := validationSchema(t, flattenSpec, "Pet")
+	flat, err := pet.Flatten()
+	require.NoError(t, err)
+
+	assert.Equal(t, "A pet", flat.Description)
+	assert.Equal(t, []string{"object"}, flat.Type)
+	assert.Equal(t, []string{"name", "kind"}, flat.Required)
+	assert.Equal(t, 2, flat.Properties.Len())
+	assert.Len(t, pet.AllOf, 3, "the schema is not modified")
+
+	// properties defined by several members are merged.
+	name := flat.Properties.GetOrZero("name").Schema()
+	assert.Equal(t, []string{"string"}, name.Type)
+	assert.Equal(t, int64(1), *name.MinLength)
+	assert.Equal(t, int64(20), *name.MaxLength)
+
+	kind := flat.Properties.GetOrZero("kind").Schema()
+	require.Len(t, kind.Enum, 2)
+	assert.Equal(t, "cat", kind.Enum[0].Value)
+	assert.Equal(t, "dog", kind.Enum[1].Value)
+
+	// constraints take the tightest value, and keywords that cannot be combined stay in allOf.
+	assert.Equal(t, int64(1), *flat.MinProperties)
+	assert.Equal(t, int64(5), *flat.MaxProperties)
+	assert.Equal(t, float64(12), *flat.MultipleOf)
+	assert.Len(t, flat.OneOf, 2)
+	require.Len(t, flat.AllOf, 1)
+	assert.Len(t, flat.AllOf[0].Schema().OneOf, 1)
+
+	// the effective schema can be rendered.
+	out, err := flat.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "maxProperties: 5")
+	assert.NotContains(t, string(out), "$ref")
+
+	// schemas without allOf are already flat.
+	named := validationSchema(t, flattenSpec, "Named")
+	same, err := named.Flatten()
+	require.NoError(t, err)
+	assert.Same(t, named, same)
+}
+
+func TestSchema_Flatten_Conflicts(t *testing.T) {
+	flat, err := validationSchema(t, flattenSpec, "Broken").Flatten()
+	require.Error(t, err)
+	require.NotNil(t, flat)
+
+	var conflicts []*SchemaConflict
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var c *SchemaConflict
+		require.True(t, errors.As(e, &c))
+		conflicts = append(conflicts, c)
+	}
+	require.Len(t, conflicts, 5)
+
+	assert.Equal(t, "type", conflicts[0].Keyword)
+	assert.Equal(t, "/allOf/1", conflicts[0].SchemaPath)
+	assert.Equal(t, 43, conflicts[0].Line)
+	assert.Equal(t, "allOf conflict at '/allOf/1': types string and integer have nothing in common [43:17]",
+		conflicts[0].Error())
+	assert.Equal(t, "format", conflicts[1].Keyword)
+	assert.Equal(t, "maximum", conflicts[2].Keyword)
+	assert.Equal(t, "minimum 10 is larger than maximum 5", conflicts[2].Message)
+	assert.Equal(t, 45, conflicts[2].Line)
+	assert.Equal(t, "enum", conflicts[3].Keyword)
+	assert.Equal(t, "/allOf/3", conflicts[3].SchemaPath)
+	assert.Equal(t, "const", conflicts[4].Keyword)
+	assert.Equal(t, "const values 1 and 2 are different", conflicts[4].Message)
+}
+
+func TestSchema_Flatten_Circular(t *testing.T) {
+	_, err := validationSchema(t, flattenSpec, "Circular").Flatten()
+	assert.Error(t, err)
+}
+
+func TestSchemaProxy_Flatten(t *testing.T) {
+	created := CreateSchemaProxy(&Schema{
+		AllOf: []*SchemaProxy{
+			CreateSchemaProxy(&Schema{Type: []string{"number"}, Items: &DynamicValue[*SchemaProxy, bool]{N: 1, B: true}}),
+			CreateSchemaProxy(&Schema{Type: []string{"integer", "string"}, Items: &DynamicValue[*SchemaProxy, bool]{N: 1}}),
+		},
+	})
+	flat, err := created.Flatten()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"integer"}, flat.Type)
+	assert.False(t, flat.Items.B)
+
+	_, err = CreateSchemaProxyRef("#/components/schemas/Nope").Flatten()
+	assert.Error(t, err)
+}
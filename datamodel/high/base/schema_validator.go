@@ -0,0 +1,315 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/jsonschema"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaValidationOptions are used to configure how a value is validated against a Schema.
+type SchemaValidationOptions struct {
+	// Formats assert the 'format' of strings, keyed by format name. Formats that are not in the map are not
+	// checked. If nil, jsonschema.DefaultFormats are used.
+	Formats map[string]jsonschema.FormatChecker
+
+	// Request means the value is sent in a request, so properties marked 'readOnly' are not required, and are
+	// not allowed.
+	Request bool
+
+	// Response means the value is sent in a response, so properties marked 'writeOnly' are not required, and
+	// are not allowed.
+	Response bool
+}
+
+// Validate checks a value against the schema, and returns every problem found. No errors means the value is valid.
+//
+// The value can be a *yaml.Node, or anything that can be marshalled into JSON (maps, slices, structs and so on).
+// An error is only returned if the value cannot be marshalled.
+func (s *Schema) Validate(value any) ([]*jsonschema.ValidationError, error) {
+	if node, ok := value.(*yaml.Node); ok {
+		return s.ValidateNode(node, nil), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to validate value: %w", err)
+	}
+	return s.ValidateBytes(data)
+}
+
+// ValidateBytes parses a JSON or YAML document, and checks it against the schema. An error is only returned if
+// the document cannot be parsed.
+func (s *Schema) ValidateBytes(data []byte) ([]*jsonschema.ValidationError, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("unable to validate value, it cannot be parsed: %w", err)
+	}
+	return s.ValidateNode(&node, nil), nil
+}
+
+// ValidateNode checks a node against the schema, using the supplied options (which can be nil).
+//
+// The schema is evaluated by the jsonschema package. Schemas that belong to an OpenAPI 3.1 document are evaluated
+// as JSON Schema 2020-12. Schemas that belong to OpenAPI 3.0 (or Swagger) documents are evaluated as draft-04, and
+// also honour 'nullable'. References are resolved using the index (and rolodex) of the schema, so a schema can use
+// anything its document can reach.
+//
+// Every error carries a JSON pointer to the rejected value, and the path, line and column of the schema keyword
+// that rejected it.
+func (s *Schema) ValidateNode(node *yaml.Node, opts *SchemaValidationOptions) []*jsonschema.ValidationError {
+	root, origin := s.validationNode()
+	version := schemaVersion(s)
+	draft := jsonschema.Draft2020
+	if version < 3.1 {
+		draft = jsonschema.Draft4
+	}
+	compileOpts := &jsonschema.Options{
+		Draft: draft,
+		OpenAPI: &jsonschema.OpenAPIOptions{
+			Nullable:         version < 3.1,
+			ResolveReference: newReferenceResolver(origin),
+		},
+	}
+	validateOpts := &jsonschema.ValidateOptions{}
+	if opts != nil {
+		compileOpts.Formats = opts.Formats
+		validateOpts.Request, validateOpts.Response = opts.Request, opts.Response
+	}
+	inst := unwrapNode(node)
+	if inst == nil {
+		inst = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+
+	compiled, err := jsonschema.Compile(root, compileOpts)
+	if err != nil {
+		return []*jsonschema.ValidationError{{
+			Message:    fmt.Sprintf("schema cannot be compiled: %s", err.Error()),
+			Keyword:    "$schema",
+			Node:       inst,
+			SchemaNode: root,
+			Line:       inst.Line,
+			Column:     inst.Column,
+		}}
+	}
+	errs := compiled.ValidateWithOptions(inst, validateOpts)
+	for _, e := range errs {
+		if e.SchemaNode != nil && origin.idx != nil {
+			e.SchemaLocation = nodeLocation(origin.idx, e.SchemaNode)
+		}
+	}
+	return errs
+}
+
+// validationNode returns the node that defines the schema, and where its references are resolved from. Schemas
+// built from a document use the node they were built from. Schemas created in code are rendered first.
+func (s *Schema) validationNode() (*yaml.Node, *refOrigin) {
+	origin := &refOrigin{ctx: context.Background()}
+	if s.low != nil {
+		origin.idx = s.low.Index
+		if sp := s.low.ParentProxy; sp != nil && sp.GetValueNode() != nil {
+			if ctx := sp.GetContext(); ctx != nil {
+				origin.ctx = ctx
+			}
+			return sp.GetValueNode(), origin
+		}
+	}
+	rendered, _ := s.MarshalYAML()
+	node, _ := rendered.(*yaml.Node)
+	return node, origin
+}
+
+// refOrigin is where a schema was found, references inside the schema are resolved from there.
+type refOrigin struct {
+	ctx context.Context
+	idx *index.SpecIndex
+}
+
+// newReferenceResolver returns a jsonschema.OpenAPIOptions.ResolveReference function, that locates references
+// using the index (and rolodex) of the schema being validated. Located references are remembered, each reference
+// is only located once per validation.
+func newReferenceResolver(root *refOrigin) func(*yaml.Node, any) (*yaml.Node, any) {
+	type located struct {
+		node   *yaml.Node
+		origin *refOrigin
+	}
+	seen := make(map[*yaml.Node]located)
+	return func(schema *yaml.Node, o any) (*yaml.Node, any) {
+		origin, _ := o.(*refOrigin)
+		if origin == nil {
+			origin = root
+		}
+		if origin.idx == nil {
+			return nil, nil
+		}
+		if l, ok := seen[schema]; ok {
+			return l.node, l.origin
+		}
+		node, idx, _, ctx := low.LocateRefNodeWithContext(origin.ctx, schema, origin.idx)
+		l := located{node: node}
+		if node != nil {
+			if idx == nil {
+				idx = origin.idx
+			}
+			l.origin = &refOrigin{ctx: ctx, idx: idx}
+		}
+		seen[schema] = l
+		if l.node == nil {
+			return nil, nil
+		}
+		return l.node, l.origin
+	}
+}
+
+// schemaVersion returns the version of the document a schema belongs to, 3.1 is assumed when it is not known.
+func schemaVersion(s *Schema) float32 {
+	if s == nil || s.low == nil || s.low.Index == nil {
+		return 3.1
+	}
+	if config := s.low.Index.GetConfig(); config != nil && config.SpecInfo != nil && config.SpecInfo.VersionNumeric > 0 {
+		return config.SpecInfo.VersionNumeric
+	}
+	return 3.1
+}
+
+// nodeLocation finds the file (or URL) that contains a node, using the index (and rolodex) it was found by.
+func nodeLocation(idx *index.SpecIndex, node *yaml.Node) string {
+	if idx == nil {
+		return ""
+	}
+	if origin := idx.FindNodeOrigin(node); origin != nil {
+		return origin.AbsoluteLocation
+	}
+	if rolodex := idx.GetRolodex(); rolodex != nil {
+		if origin := rolodex.FindNodeOrigin(node); origin != nil {
+			return origin.AbsoluteLocation
+		}
+	}
+	return idx.GetSpecAbsolutePath()
+}
+
+// keywordNode returns the value node of a keyword in a low-level schema.
+func keywordNode(s *base.Schema, keyword string) *yaml.Node {
+	var ref low.HasValueUnTyped
+	switch keyword {
+	case "type":
+		ref = s.Type
+	case "enum":
+		ref = s.Enum
+	case "const":
+		ref = s.Const
+	case "not":
+		ref = s.Not
+	case "anyOf":
+		ref = s.AnyOf
+	case "oneOf":
+		ref = s.OneOf
+	case "additionalProperties":
+		ref = s.AdditionalProperties
+	case "unevaluatedProperties":
+		ref = s.UnevaluatedProperties
+	case "required":
+		ref = s.Required
+	case "maxProperties":
+		ref = s.MaxProperties
+	case "minProperties":
+		ref = s.MinProperties
+	case "items":
+		ref = s.Items
+	case "contains":
+		ref = s.Contains
+	case "maxContains":
+		ref = s.MaxContains
+	case "maxItems":
+		ref = s.MaxItems
+	case "minItems":
+		ref = s.MinItems
+	case "uniqueItems":
+		ref = s.UniqueItems
+	case "maxLength":
+		ref = s.MaxLength
+	case "minLength":
+		ref = s.MinLength
+	case "pattern":
+		ref = s.Pattern
+	case "format":
+		ref = s.Format
+	case "multipleOf":
+		ref = s.MultipleOf
+	case "maximum":
+		ref = s.Maximum
+	case "minimum":
+		ref = s.Minimum
+	case "exclusiveMaximum":
+		ref = s.ExclusiveMaximum
+	case "exclusiveMinimum":
+		ref = s.ExclusiveMinimum
+	case "readOnly":
+		ref = s.ReadOnly
+	case "writeOnly":
+		ref = s.WriteOnly
+	case "if":
+		ref = s.If
+	case "prefixItems":
+		ref = s.PrefixItems
+	case "patternProperties":
+		ref = s.PatternProperties
+	case "propertyNames":
+		ref = s.PropertyNames
+	case "dependentSchemas":
+		ref = s.DependentSchemas
+	case "unevaluatedItems":
+		ref = s.UnevaluatedItems
+	default:
+		// keywords the model does not hold (such as 'dependentRequired') are found in the YAML of the schema.
+		if s.ParentProxy != nil {
+			if n := unwrapNode(s.ParentProxy.GetValueNode()); n != nil && n.Kind == yaml.MappingNode {
+				_, v := utils.FindKeyNodeTop(keyword, n.Content)
+				return v
+			}
+		}
+		return nil
+	}
+	return ref.GetValueNode()
+}
+
+// unwrapNode steps through document and alias nodes, to the node that holds the value.
+func unwrapNode(node *yaml.Node) *yaml.Node {
+	for node != nil {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				return nil
+			}
+			node = node.Content[0]
+		case yaml.AliasNode:
+			node = node.Alias
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+// displayValue renders a value for an error message.
+func displayValue(node *yaml.Node) string {
+	node = unwrapNode(node)
+	switch {
+	case node == nil:
+		return "null"
+	case node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str":
+		return "'" + node.Value + "'"
+	case node.Kind == yaml.ScalarNode:
+		return node.Value
+	default:
+		return jsonschema.TypeOf(node)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package base
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/jsonschema"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// validationSchema builds a schema from the components of a specification, so references can be resolved.
+func validationSchema(t *testing.T, spec, name string) *Schema {
+	info, err := datamodel.ExtractSpecInfo([]byte(spec))
+	require.NoError(t, err)
+	config := index.CreateOpenAPIIndexConfig()
+	config.SpecInfo = info
+	idx := index.NewSpecIndexWithConfig(info.RootNode, config)
+
+	_, components := utils.FindKeyNodeTop("components", info.RootNode.Content[0].Content)
+	_, schemas := utils.FindKeyNodeTop("schemas", components.Content)
+	key, value := utils.FindKeyNodeTop(name, schemas.Content)
+	require.NotNil(t, value)
+
+	sp := new(lowbase.SchemaProxy)
+	require.NoError(t, sp.Build(context.Background(), key, value, idx))
+	schema := NewSchemaProxy(&low.NodeReference[*lowbase.SchemaProxy]{Value: sp, KeyNode: key, ValueNode: value}).Schema()
+	require.NotNil(t, schema)
+	return schema
+}
+
+func validationKeywords(t *testing.T, schema *Schema, instance string) []string {
+	errs, err := schema.ValidateBytes([]byte(instance))
+	require.NoError(t, err)
+	var keywords []string
+	for _, e := range errs {
+		keywords = append(keywords, e.Keyword)
+	}
+	return keywords
+}
+
+func TestSchema_Validate_Keywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		keywords []string
+	}{
+		{"type", `type: string`, `1`, []string{"type"}},
+		{"type list", `type: [string, "null"]`, `null`, nil},
+		{"enum", `enum: [a, 1]`, `"1"`, []string{"enum"}},
+		{"const", `const: {a: [1, 2]}`, `{a: [1, 2.0]}`, nil},
+		{"numbers", `{minimum: 1, exclusiveMaximum: 10, multipleOf: 0.5}`, `10`, []string{"exclusiveMaximum"}},
+		{"strings", `{minLength: 2, maxLength: 3, pattern: "^a"}`, `bcde`, []string{"maxLength", "pattern"}},
+		{"format", `format: date-time`, `not a date`, []string{"format"}},
+		{"required", `{required: [a, b], properties: {a: {type: string}}}`, `{a: 1}`, []string{"type", "required"}},
+		{"additionalProperties", `{properties: {a: true}, patternProperties: {"^x-": true}, additionalProperties: false}`,
+			`{a: 1, x-b: 2, c: 3}`, []string{"additionalProperties"}},
+		{"propertyNames", `propertyNames: {maxLength: 2}`, `{abc: 1}`, []string{"maxLength"}},
+		{"dependentSchemas", `dependentSchemas: {a: {required: [b]}}`, `{a: 1}`, []string{"required"}},
+		{"arrays", `{minItems: 3, uniqueItems: true, items: {type: integer}}`, `[1, 1]`, []string{"minItems", "uniqueItems"}},
+		{"prefixItems", `{prefixItems: [{type: string}], items: false}`, `[a, b]`, []string{"items"}},
+		{"contains", `{contains: {const: 1}, maxContains: 1}`, `[1, 1]`, []string{"maxContains"}},
+		{"allOf", `allOf: [{type: object}, {required: [a]}]`, `{}`, []string{"required"}},
+		{"anyOf", `anyOf: [{type: string}, {type: integer}]`, `1`, nil},
+		{"oneOf many", `oneOf: [{type: number}, {type: integer}]`, `1`, []string{"oneOf"}},
+		{"not", `{not: {type: string}}`, `a`, []string{"not"}},
+		{"if then else", `{if: {type: string}, then: {minLength: 2}, else: {minimum: 5}}`, `1`, []string{"minimum"}},
+		{"unevaluatedProperties", `{allOf: [{properties: {a: true}}], unevaluatedProperties: false}`, `{a: 1, b: 2}`,
+			[]string{"unevaluatedProperties"}},
+		{"unevaluatedItems", `{prefixItems: [true], unevaluatedItems: {type: string}}`, `[1, 2]`, []string{"type"}},
+		{"false", `{properties: {a: false}}`, `{a: 1}`, []string{"false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := fmt.Sprintf("openapi: 3.1.0\ncomponents:\n  schemas:\n    Test:\n      %s", tt.schema)
+			assert.Equal(t, tt.keywords, validationKeywords(t, validationSchema(t, spec, "Test"), tt.instance))
+		})
+	}
+}
+
+func TestSchema_Validate_OpenAPI30(t *testing.T) {
+	spec := `openapi: 3.0.3
+components:
+  schemas:
+    Burger:
+      type: object
+      nullable: true
+      properties:
+        patties:
+          type: integer
+          minimum: 1
+          exclusiveMinimum: true
+        name:
+          type: string`
+	schema := validationSchema(t, spec, "Burger")
+	assert.Empty(t, validationKeywords(t, schema, `null`))
+	assert.Equal(t, []string{"minimum", "type"}, validationKeywords(t, schema, `{patties: 1, name: null}`))
+
+	// 'nullable' means nothing in 3.1.
+	schema = validationSchema(t, "openapi: 3.1.0\ncomponents:\n  schemas:\n    Burger: {type: object, nullable: true}",
+		"Burger")
+	assert.Equal(t, []string{"type"}, validationKeywords(t, schema, `null`))
+}
+
+func TestSchema_Validate_References(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        meal:
+          oneOf:
+            - $ref: '#/components/schemas/Burger'
+            - $ref: '#/components/schemas/Fries'
+          discriminator:
+            propertyName: kind
+            mapping:
+              fries: '#/components/schemas/Fries'
+        extras:
+          type: array
+          items:
+            $ref: '#/components/schemas/Order'
+    Burger:
+      type: object
+      required: [kind, patties]
+      properties:
+        kind:
+          const: Burger
+        patties:
+          type: integer
+    Fries:
+      type: object
+      required: [kind]
+      properties:
+        kind:
+          const: fries
+        salted:
+          type: boolean`
+	schema := validationSchema(t, spec, "Order")
+
+	errs, err := schema.ValidateBytes([]byte(`{"meal": {"kind": "fries", "patties": 2, "salted": "yes"}}`))
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/meal/salted", errs[0].InstancePath)
+	assert.Equal(t, "/properties/meal/oneOf/1/$ref/properties/salted/type", errs[0].SchemaPath)
+	assert.Equal(t, 34, errs[0].SchemaLine)
+	assert.Equal(t, 17, errs[0].SchemaColumn)
+	assert.Equal(t, 1, errs[0].Line)
+
+	// recursive schemas are followed as deep as the value goes.
+	errs, err = schema.Validate(map[string]any{
+		"extras": []any{map[string]any{"extras": []any{map[string]any{"meal": map[string]any{"kind": "Burger"}}}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/extras/0/extras/0/meal", errs[0].InstancePath)
+	assert.Equal(t, "missing property 'patties'", errs[0].Message)
+}
+
+func TestSchema_Validate_Circular(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Loop:
+      allOf:
+        - $ref: '#/components/schemas/Loop'
+      type: string`
+	schema := validationSchema(t, spec, "Loop")
+
+	// the schema is evaluated again through the reference, but never a third time.
+	assert.Equal(t, []string{"type", "type"}, validationKeywords(t, schema, `1`))
+}
+
+func TestSchema_Validate_Created(t *testing.T) {
+	min := int64(2)
+	props := orderedmap.New[string, *SchemaProxy]()
+	props.Set("name", CreateSchemaProxy(&Schema{Type: []string{"string"}, MinLength: &min}))
+	props.Set("ref", CreateSchemaProxyRef("#/components/schemas/Nope"))
+	schema := &Schema{
+		Type:       []string{"object"},
+		Required:   []string{"name"},
+		MinLength:  &min,
+		Items:      &DynamicValue[*SchemaProxy, bool]{N: 1, B: true},
+		Properties: props,
+	}
+	errs, err := schema.Validate(struct {
+		Name string `json:"name"`
+		Ref  int    `json:"ref"`
+	}{Name: "a", Ref: 1})
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "string must be at least 2 characters long, but is 1", errs[0].Message)
+	assert.Equal(t, 0, errs[0].SchemaLine)
+	assert.Equal(t, "reference '#/components/schemas/Nope' cannot be resolved, '/components/schemas/Nope' cannot be found",
+		errs[1].Message)
+
+	_, err = schema.Validate(make(chan int))
+	assert.Error(t, err)
+	_, err = schema.ValidateBytes([]byte(`{`))
+	assert.Error(t, err)
+
+	errs = schema.ValidateNode(nil, &SchemaValidationOptions{Formats: map[string]jsonschema.FormatChecker{}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "expected object, but got null", errs[0].Message)
+}
+
+func TestSchema_Validate_ReadWriteOnly(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [id, secret]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        secret:
+          type: string
+          writeOnly: true`
+
+	schema := validationSchema(t, spec, "Burger")
+	node := func(instance string) *yaml.Node {
+		var n yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte(instance), &n))
+		return &n
+	}
+
+	assert.Empty(t, schema.ValidateNode(node(`{"id": 1, "secret": "sauce"}`), nil))
+	assert.Empty(t, schema.ValidateNode(node(`{"secret": "sauce"}`), &SchemaValidationOptions{Request: true}))
+	assert.Empty(t, schema.ValidateNode(node(`{"id": 1}`), &SchemaValidationOptions{Response: true}))
+
+	errs := schema.ValidateNode(node(`{"id": 1, "secret": "sauce"}`), &SchemaValidationOptions{Request: true})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "readOnly", errs[0].Keyword)
+	assert.Equal(t, "property 'id' is readOnly, it cannot be sent in a request", errs[0].Message)
+
+	errs = schema.ValidateNode(node(`{"id": 1, "secret": "sauce"}`), &SchemaValidationOptions{Response: true})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "writeOnly", errs[0].Keyword)
+	assert.Equal(t, "/secret", errs[0].InstancePath)
+}
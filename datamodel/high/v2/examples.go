@@ -4,6 +4,7 @@
 package v2
 
 import (
+	"github.com/pb33f/libopenapi/datamodel/high"
 	low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	"github.com/pb33f/libopenapi/orderedmap"
 	"gopkg.in/yaml.v3"
@@ -13,7 +14,7 @@ import (
 // Allows sharing examples for operation responses
 //   - https://swagger.io/specification/v2/#exampleObject
 type Example struct {
-	Values *orderedmap.Map[string, *yaml.Node]
+	Values *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
 	low    *low.Examples
 }
 
@@ -35,3 +36,18 @@ func NewExample(examples *low.Examples) *Example {
 func (e *Example) GoLow() *low.Examples {
 	return e.low
 }
+
+// Render will return a YAML representation of the Example object as a byte slice.
+func (e *Example) Render() ([]byte, error) {
+	return yaml.Marshal(e)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Example object.
+func (e *Example) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(e, e.low)
+	var lowKeys map[string]lowMapKey
+	if e.low != nil {
+		lowKeys = lowMapKeys(e.low.Values)
+	}
+	return renderMap(e.Values, lowKeys, nb.Render()), nil
+}
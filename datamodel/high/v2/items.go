@@ -4,6 +4,7 @@
 package v2
 
 import (
+	"github.com/pb33f/libopenapi/datamodel/high"
 	low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	"gopkg.in/yaml.v3"
 )
@@ -13,23 +14,23 @@ import (
 // located in "body"
 //   - https://swagger.io/specification/v2/#itemsObject
 type Items struct {
-	Type             string
-	Format           string
-	CollectionFormat string
-	Items            *Items
-	Default          *yaml.Node
-	Maximum          int
-	ExclusiveMaximum bool
-	Minimum          int
-	ExclusiveMinimum bool
-	MaxLength        int
-	MinLength        int
-	Pattern          string
-	MaxItems         int
-	MinItems         int
-	UniqueItems      bool
-	Enum             []*yaml.Node
-	MultipleOf       int
+	Type             string       `json:"type,omitempty" yaml:"type,omitempty"`
+	Format           string       `json:"format,omitempty" yaml:"format,omitempty"`
+	CollectionFormat string       `json:"collectionFormat,omitempty" yaml:"collectionFormat,omitempty"`
+	Items            *Items       `json:"items,omitempty" yaml:"items,omitempty"`
+	Default          *yaml.Node   `json:"default,omitempty" yaml:"default,omitempty"`
+	Maximum          int          `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMaximum bool         `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	Minimum          int          `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	ExclusiveMinimum bool         `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	MaxLength        int          `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	MinLength        int          `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	Pattern          string       `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MaxItems         int          `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	MinItems         int          `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	UniqueItems      bool         `json:"uniqueItems,omitempty" yaml:"uniqueItems,omitempty"`
+	Enum             []*yaml.Node `json:"enum,omitempty" yaml:"enum,omitempty"`
+	MultipleOf       int          `json:"multipleOf,omitempty" yaml:"multipleOf,omitempty"`
 	low              *low.Items
 }
 
@@ -99,3 +100,14 @@ func NewItems(items *low.Items) *Items {
 func (i *Items) GoLow() *low.Items {
 	return i.low
 }
+
+// Render will return a YAML representation of the Items object as a byte slice.
+func (i *Items) Render() ([]byte, error) {
+	return yaml.Marshal(i)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Items object.
+func (i *Items) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(i, i.low)
+	return nb.Render(), nil
+}
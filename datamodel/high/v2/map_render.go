@@ -0,0 +1,97 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package v2
+
+import (
+	"sort"
+
+	"github.com/pb33f/libopenapi/datamodel/high"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// lowMapKey is the original key (and value) of an entry in a low-level map.
+type lowMapKey struct {
+	keyNode   *yaml.Node
+	valueNode *yaml.Node
+}
+
+// lowMapKeys returns the original keys of a low-level map, looked up by name.
+func lowMapKeys[L any](lowValues *orderedmap.Map[low.KeyReference[string], low.ValueReference[L]]) map[string]lowMapKey {
+	keys := make(map[string]lowMapKey)
+	for pair := orderedmap.First(lowValues); pair != nil; pair = pair.Next() {
+		keys[pair.Key().Value] = lowMapKey{keyNode: pair.Key().KeyNode, valueNode: pair.Value().ValueNode}
+	}
+	return keys
+}
+
+// renderMap renders a map of high-level values (like definitions, paths or response codes) as a YAML mapping. Values
+// are rendered in the order of the low-level map they were built from (looked up with lowMapKeys), with the style of
+// the original keys, and new values are weighted to the bottom. Anything else already rendered for the object
+// (like extensions) is merged in, in its original place.
+func renderMap[V any](values *orderedmap.Map[string, V], lowKeys map[string]lowMapKey, rendered *yaml.Node) *yaml.Node {
+	type mapEntry struct {
+		key   string
+		line  int
+		style yaml.Style
+		value *yaml.Node
+	}
+
+	var entries []*mapEntry
+	for pair := orderedmap.First(values); pair != nil; pair = pair.Next() {
+		entry := &mapEntry{key: pair.Key(), line: 9999} // default to a high value to weight new content to the bottom.
+		var lowValue *yaml.Node
+		if lowKey, ok := lowKeys[pair.Key()]; ok && lowKey.keyNode != nil {
+			entry.line = lowKey.keyNode.Line
+			entry.style = lowKey.keyNode.Style
+			lowValue = lowKey.valueNode
+		}
+		if entry.value = renderMapValue(pair.Value(), lowValue); entry.value != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if rendered != nil {
+		for i := 1; i < len(rendered.Content); i += 2 {
+			entries = append(entries, &mapEntry{
+				key:   rendered.Content[i-1].Value,
+				line:  rendered.Content[i].Line,
+				style: rendered.Content[i-1].Style,
+				value: rendered.Content[i],
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].line < entries[j].line
+	})
+	m := utils.CreateEmptyMapNode()
+	for _, entry := range entries {
+		kn := utils.CreateStringNode(entry.key)
+		kn.Style = entry.style
+		m.Content = append(m.Content, kn, entry.value)
+	}
+	return m
+}
+
+// renderMapValue renders a single value of a map, lowValue is the original node of the value (if there is one).
+func renderMapValue(value any, lowValue *yaml.Node) *yaml.Node {
+	switch v := value.(type) {
+	case *yaml.Node:
+		return v
+	case string:
+		n := utils.CreateStringNode(v)
+		if lowValue != nil {
+			n.Style = lowValue.Style
+		}
+		return n
+	case high.Renderable:
+		rendered, _ := v.MarshalYAML()
+		if n, ok := rendered.(*yaml.Node); ok {
+			return n
+		}
+	}
+	return nil
+}
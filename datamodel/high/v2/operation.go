@@ -15,19 +15,19 @@ import (
 // It describes a single API operation on a path.
 //   - https://swagger.io/specification/v2/#operationObject
 type Operation struct {
-	Tags         []string
-	Summary      string
-	Description  string
-	ExternalDocs *base.ExternalDoc
-	OperationId  string
-	Consumes     []string
-	Produces     []string
-	Parameters   []*Parameter
-	Responses    *Responses
-	Schemes      []string
-	Deprecated   bool
-	Security     []*base.SecurityRequirement
-	Extensions   *orderedmap.Map[string, *yaml.Node]
+	Tags         []string                            `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary      string                              `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description  string                              `json:"description,omitempty" yaml:"description,omitempty"`
+	ExternalDocs *base.ExternalDoc                   `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	OperationId  string                              `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Consumes     []string                            `json:"consumes,omitempty" yaml:"consumes,omitempty"`
+	Produces     []string                            `json:"produces,omitempty" yaml:"produces,omitempty"`
+	Parameters   []*Parameter                        `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Responses    *Responses                          `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Schemes      []string                            `json:"schemes,omitempty" yaml:"schemes,omitempty"`
+	Deprecated   bool                                `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Security     []*base.SecurityRequirement         `json:"security,omitempty" yaml:"security,omitempty"`
+	Extensions   *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
 	low          *low.Operation
 }
 
@@ -103,3 +103,14 @@ func NewOperation(operation *low.Operation) *Operation {
 func (o *Operation) GoLow() *low.Operation {
 	return o.low
 }
+
+// Render will return a YAML representation of the Operation object as a byte slice.
+func (o *Operation) Render() ([]byte, error) {
+	return yaml.Marshal(o)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Operation object.
+func (o *Operation) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(o, o.low)
+	return nb.Render(), nil
+}
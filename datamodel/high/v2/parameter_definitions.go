@@ -5,9 +5,11 @@ package v2
 
 import (
 	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high"
 	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
 	low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
 )
 
 // ParameterDefinitions is a high-level representation of a Swagger / OpenAPI 2 Parameters Definitions object
@@ -17,7 +19,7 @@ import (
 // referenced to the ones defined here. It does not define global operation parameters
 //   - https://swagger.io/specification/v2/#parametersDefinitionsObject
 type ParameterDefinitions struct {
-	Definitions *orderedmap.Map[string, *Parameter]
+	Definitions *orderedmap.Map[string, *Parameter] `json:"-" yaml:"-"`
 	low         *low.ParameterDefinitions
 }
 
@@ -46,3 +48,18 @@ func NewParametersDefinitions(parametersDefinitions *low.ParameterDefinitions) *
 func (p *ParameterDefinitions) GoLow() *low.ParameterDefinitions {
 	return p.low
 }
+
+// Render will return a YAML representation of the ParameterDefinitions object as a byte slice.
+func (p *ParameterDefinitions) Render() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the ParameterDefinitions object.
+func (p *ParameterDefinitions) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(p, p.low)
+	var lowKeys map[string]lowMapKey
+	if p.low != nil {
+		lowKeys = lowMapKeys(p.low.Definitions)
+	}
+	return renderMap(p.Definitions, lowKeys, nb.Render()), nil
+}
@@ -22,16 +22,16 @@ import (
 // are available.
 //   - https://swagger.io/specification/v2/#pathItemObject
 type PathItem struct {
-	Ref        string
-	Get        *Operation
-	Put        *Operation
-	Post       *Operation
-	Delete     *Operation
-	Options    *Operation
-	Head       *Operation
-	Patch      *Operation
-	Parameters []*Parameter
-	Extensions *orderedmap.Map[string, *yaml.Node]
+	Ref        string                              `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Get        *Operation                          `json:"get,omitempty" yaml:"get,omitempty"`
+	Put        *Operation                          `json:"put,omitempty" yaml:"put,omitempty"`
+	Post       *Operation                          `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete     *Operation                          `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options    *Operation                          `json:"options,omitempty" yaml:"options,omitempty"`
+	Head       *Operation                          `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch      *Operation                          `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Parameters []*Parameter                        `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
 	low        *lowV2.PathItem
 }
 
@@ -40,6 +40,9 @@ func NewPathItem(pathItem *lowV2.PathItem) *PathItem {
 	p := new(PathItem)
 	p.low = pathItem
 	p.Extensions = high.ExtractExtensions(pathItem.Extensions)
+	if !pathItem.Ref.IsEmpty() {
+		p.Ref = pathItem.Ref.Value
+	}
 	if !pathItem.Parameters.IsEmpty() {
 		var params []*Parameter
 		for k := range pathItem.Parameters.Value {
@@ -168,3 +171,14 @@ func (p *PathItem) GetOperations() *orderedmap.Map[string, *Operation] {
 
 	return o
 }
+
+// Render will return a YAML representation of the PathItem object as a byte slice.
+func (p *PathItem) Render() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the PathItem object.
+func (p *PathItem) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(p, p.low)
+	return nb.Render(), nil
+}
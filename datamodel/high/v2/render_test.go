@@ -0,0 +1,180 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	v2 "github.com/pb33f/libopenapi/datamodel/low/v2"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var renderSpec = `swagger: "2.0"
+info:
+  title: burgers
+  version: 1.0.0
+host: burgers.com
+basePath: /v1
+schemes:
+  - https
+paths:
+  x-paths: yummy
+  /burgers:
+    x-path: tasty
+    get:
+      operationId: listBurgers
+      parameters:
+        - $ref: '#/parameters/limit'
+        - name: size
+          in: query
+          type: array
+          items:
+            type: string
+            default: large
+          collectionFormat: csv
+      responses:
+        "200":
+          description: burgers
+          headers:
+            X-Rate-Limit:
+              type: integer
+              format: int32
+          schema:
+            type: array
+            items:
+              $ref: '#/definitions/Burger'
+          examples:
+            application/json:
+              - name: big mac
+        "404":
+          $ref: '#/responses/NotFound'
+        default:
+          description: unexpected error
+        x-responses: fries
+parameters:
+  limit:
+    name: limit
+    in: query
+    type: integer
+responses:
+  NotFound:
+    description: not found
+definitions:
+  Fries:
+    type: string
+  Burger:
+    type: object
+    properties:
+      name:
+        type: string
+securityDefinitions:
+  oauth:
+    type: oauth2
+    flow: implicit
+    authorizationUrl: https://burgers.com/auth
+    scopes:
+      eat: eat burgers
+      cook: cook burgers
+      x-scopes: grill
+security:
+  - oauth:
+      - eat
+tags:
+  - name: burgers
+externalDocs:
+  url: https://burgers.com/docs
+x-burger: tasty
+`
+
+func buildRenderSpec(t *testing.T) *Swagger {
+	info, err := datamodel.ExtractSpecInfo([]byte(renderSpec))
+	require.NoError(t, err)
+	lowDoc, err := v2.CreateDocumentFromConfig(info, datamodel.NewDocumentConfiguration())
+	require.NoError(t, err)
+	return NewSwaggerDocument(lowDoc)
+}
+
+func TestSwagger_Render(t *testing.T) {
+	highDoc := buildRenderSpec(t)
+
+	rendered, err := highDoc.Render()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(rendered), "swagger: \"2.0\"\ninfo:\n    title: burgers\n"))
+	assert.Equal(t, renderSpec, string(highDoc.RenderWithIndention(2)))
+}
+
+func TestSwagger_RenderJSON(t *testing.T) {
+	highDoc := buildRenderSpec(t)
+
+	rendered := highDoc.RenderJSON("  ")
+	info, err := datamodel.ExtractSpecInfo(rendered)
+	require.NoError(t, err)
+	assert.Equal(t, datamodel.JSONFileType, info.SpecFileType)
+
+	lowDoc, err := v2.CreateDocumentFromConfig(info, datamodel.NewDocumentConfiguration())
+	require.NoError(t, err)
+	reloaded := NewSwaggerDocument(lowDoc)
+	assert.Equal(t, string(rendered), string(reloaded.RenderJSON("  ")))
+	assert.Equal(t, "yummy", reloaded.Paths.Extensions.GetOrZero("x-paths").Value)
+}
+
+func TestSwagger_Render_Mutations(t *testing.T) {
+	highDoc := buildRenderSpec(t)
+
+	burgers := highDoc.Paths.PathItems.GetOrZero("/burgers")
+	burgers.Post = &Operation{
+		OperationId: "makeBurger",
+		Responses: &Responses{
+			Codes: orderedmap.ToOrderedMap(map[string]*Response{"201": {Description: "made"}}),
+		},
+	}
+	burgers.Get.Responses.Codes.Delete("404")
+	highDoc.Definitions.Definitions.Delete("Fries")
+	highDoc.SecurityDefinitions.Definitions.GetOrZero("oauth").Scopes.Values.Set("sell", "sell burgers")
+	highDoc.Parameters.Definitions.GetOrZero("limit").Description = "how many burgers"
+
+	rendered := string(highDoc.RenderWithIndention(2))
+	assert.Contains(t, rendered, `        default:
+          description: unexpected error
+        x-responses: fries
+    post:
+      operationId: makeBurger
+      responses:
+        "201":
+          description: made
+parameters:
+  limit:
+    name: limit
+    in: query
+    type: integer
+    description: how many burgers
+responses:`)
+	assert.NotContains(t, rendered, "404")
+	assert.Contains(t, rendered, `definitions:
+  Burger:`)
+	assert.Contains(t, rendered, `    scopes:
+      eat: eat burgers
+      cook: cook burgers
+      x-scopes: grill
+      sell: sell burgers
+security:`)
+}
+
+func TestParameter_Render_Reference(t *testing.T) {
+	highDoc := buildRenderSpec(t)
+	params := highDoc.Paths.PathItems.GetOrZero("/burgers").Get.Parameters
+
+	assert.True(t, params[0].GoLow().IsReference())
+	rendered, err := params[0].Render()
+	require.NoError(t, err)
+	assert.Equal(t, "$ref: '#/parameters/limit'\n", string(rendered))
+
+	rendered, err = params[1].Items.Render()
+	require.NoError(t, err)
+	assert.Equal(t, "type: string\ndefault: large\n", string(rendered))
+}
@@ -15,11 +15,11 @@ import (
 // Response describes a single response from an API Operation
 //   - https://swagger.io/specification/v2/#responseObject
 type Response struct {
-	Description string
-	Schema      *base.SchemaProxy
-	Headers     *orderedmap.Map[string, *Header]
-	Examples    *Example
-	Extensions  *orderedmap.Map[string, *yaml.Node]
+	Description string                              `json:"description,omitempty" yaml:"description,omitempty"`
+	Schema      *base.SchemaProxy                   `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Headers     *orderedmap.Map[string, *Header]    `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Examples    *Example                            `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Extensions  *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
 	low         *low.Response
 }
 
@@ -51,3 +51,19 @@ func NewResponse(response *low.Response) *Response {
 func (r *Response) GoLow() *low.Response {
 	return r.low
 }
+
+// GoLowUntyped will return the low-level Response instance that was used to create the high-level one, with no type
+func (r *Response) GoLowUntyped() any {
+	return r.low
+}
+
+// Render will return a YAML representation of the Response object as a byte slice.
+func (r *Response) Render() ([]byte, error) {
+	return yaml.Marshal(r)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Response object.
+func (r *Response) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(r, r.low)
+	return nb.Render(), nil
+}
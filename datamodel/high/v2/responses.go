@@ -14,9 +14,9 @@ import (
 
 // Responses is a high-level representation of a Swagger / OpenAPI 2 Responses object, backed by a low level one.
 type Responses struct {
-	Codes      *orderedmap.Map[string, *Response]
-	Default    *Response
-	Extensions *orderedmap.Map[string, *yaml.Node]
+	Codes      *orderedmap.Map[string, *Response]  `json:"-" yaml:"-"`
+	Default    *Response                           `json:"default,omitempty" yaml:"default,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
 	low        *low.Responses
 }
 
@@ -53,3 +53,27 @@ func NewResponses(responses *low.Responses) *Responses {
 func (r *Responses) GoLow() *low.Responses {
 	return r.low
 }
+
+// Render will return a YAML representation of the Responses object as a byte slice.
+func (r *Responses) Render() ([]byte, error) {
+	return yaml.Marshal(r)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Responses object.
+func (r *Responses) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(r, r.low)
+	rendered := nb.Render()
+	var lowKeys map[string]lowMapKey
+	if r.low != nil {
+		lowKeys = lowMapKeys(r.low.Codes)
+
+		// default is rendered by the node builder, keep it where it was.
+		for i := 1; i < len(rendered.Content); i += 2 {
+			if rendered.Content[i-1].Value == low.DefaultLabel && rendered.Content[i].Line == 0 &&
+				r.low.Default.KeyNode != nil {
+				rendered.Content[i].Line = r.low.Default.KeyNode.Line
+			}
+		}
+	}
+	return renderMap(r.Codes, lowKeys, rendered), nil
+}
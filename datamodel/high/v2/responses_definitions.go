@@ -5,9 +5,11 @@ package v2
 
 import (
 	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high"
 	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
 	low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
 )
 
 // ResponsesDefinitions is a high-level representation of a Swagger / OpenAPI 2 Responses Definitions object.
@@ -17,7 +19,7 @@ import (
 // referenced to the ones defined here. It does not define global operation responses
 //   - https://swagger.io/specification/v2/#responsesDefinitionsObject
 type ResponsesDefinitions struct {
-	Definitions *orderedmap.Map[string, *Response]
+	Definitions *orderedmap.Map[string, *Response] `json:"-" yaml:"-"`
 	low         *low.ResponsesDefinitions
 }
 
@@ -46,3 +48,18 @@ func NewResponsesDefinitions(responsesDefinitions *low.ResponsesDefinitions) *Re
 func (r *ResponsesDefinitions) GoLow() *low.ResponsesDefinitions {
 	return r.low
 }
+
+// Render will return a YAML representation of the ResponsesDefinitions object as a byte slice.
+func (r *ResponsesDefinitions) Render() ([]byte, error) {
+	return yaml.Marshal(r)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the ResponsesDefinitions object.
+func (r *ResponsesDefinitions) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(r, r.low)
+	var lowKeys map[string]lowMapKey
+	if r.low != nil {
+		lowKeys = lowMapKeys(r.low.Definitions)
+	}
+	return renderMap(r.Definitions, lowKeys, nb.Render()), nil
+}
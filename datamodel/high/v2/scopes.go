@@ -4,8 +4,10 @@
 package v2
 
 import (
+	"github.com/pb33f/libopenapi/datamodel/high"
 	low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
 )
 
 // Scopes is a high-level representation of a Swagger / OpenAPI 2 OAuth2 Scopes object, that is backed by a low-level one.
@@ -13,14 +15,16 @@ import (
 // Scopes lists the available scopes for an OAuth2 security scheme.
 //   - https://swagger.io/specification/v2/#scopesObject
 type Scopes struct {
-	Values *orderedmap.Map[string, string]
-	low    *low.Scopes
+	Values     *orderedmap.Map[string, string]     `json:"-" yaml:"-"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+	low        *low.Scopes
 }
 
 // NewScopes creates a new high-level instance of Scopes from a low-level one.
 func NewScopes(scopes *low.Scopes) *Scopes {
 	s := new(Scopes)
 	s.low = scopes
+	s.Extensions = high.ExtractExtensions(scopes.Extensions)
 	scopeValues := orderedmap.New[string, string]()
 	for pair := orderedmap.First(scopes.Values); pair != nil; pair = pair.Next() {
 		scopeValues.Set(pair.Key().Value, pair.Value().Value)
@@ -33,3 +37,18 @@ func NewScopes(scopes *low.Scopes) *Scopes {
 func (s *Scopes) GoLow() *low.Scopes {
 	return s.low
 }
+
+// Render will return a YAML representation of the Scopes object as a byte slice.
+func (s *Scopes) Render() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the Scopes object.
+func (s *Scopes) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(s, s.low)
+	var lowKeys map[string]lowMapKey
+	if s.low != nil {
+		lowKeys = lowMapKeys(s.low.Values)
+	}
+	return renderMap(s.Values, lowKeys, nb.Render()), nil
+}
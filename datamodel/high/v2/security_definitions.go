@@ -5,9 +5,11 @@ package v2
 
 import (
 	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high"
 	lowmodel "github.com/pb33f/libopenapi/datamodel/low"
 	low "github.com/pb33f/libopenapi/datamodel/low/v2"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
 )
 
 // SecurityDefinitions is a high-level representation of a Swagger / OpenAPI 2 Security Definitions object, that
@@ -17,7 +19,7 @@ import (
 // schemes on the operations and only serves to provide the relevant details for each scheme
 //   - https://swagger.io/specification/v2/#securityDefinitionsObject
 type SecurityDefinitions struct {
-	Definitions *orderedmap.Map[string, *SecurityScheme]
+	Definitions *orderedmap.Map[string, *SecurityScheme] `json:"-" yaml:"-"`
 	low         *low.SecurityDefinitions
 }
 
@@ -46,3 +48,18 @@ func NewSecurityDefinitions(definitions *low.SecurityDefinitions) *SecurityDefin
 func (sd *SecurityDefinitions) GoLow() *low.SecurityDefinitions {
 	return sd.low
 }
+
+// Render will return a YAML representation of the SecurityDefinitions object as a byte slice.
+func (sd *SecurityDefinitions) Render() ([]byte, error) {
+	return yaml.Marshal(sd)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the SecurityDefinitions object.
+func (sd *SecurityDefinitions) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(sd, sd.low)
+	var lowKeys map[string]lowMapKey
+	if sd.low != nil {
+		lowKeys = lowMapKeys(sd.low.Definitions)
+	}
+	return renderMap(sd.Definitions, lowKeys, nb.Render()), nil
+}
@@ -18,15 +18,15 @@ import (
 // (implicit, password, application and access code)
 //   - https://swagger.io/specification/v2/#securityDefinitionsObject
 type SecurityScheme struct {
-	Type             string
-	Description      string
-	Name             string
-	In               string
-	Flow             string
-	AuthorizationUrl string
-	TokenUrl         string
-	Scopes           *Scopes
-	Extensions       *orderedmap.Map[string, *yaml.Node]
+	Type             string                              `json:"type,omitempty" yaml:"type,omitempty"`
+	Description      string                              `json:"description,omitempty" yaml:"description,omitempty"`
+	Name             string                              `json:"name,omitempty" yaml:"name,omitempty"`
+	In               string                              `json:"in,omitempty" yaml:"in,omitempty"`
+	Flow             string                              `json:"flow,omitempty" yaml:"flow,omitempty"`
+	AuthorizationUrl string                              `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"`
+	TokenUrl         string                              `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	Scopes           *Scopes                             `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Extensions       *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
 	low              *low.SecurityScheme
 }
 
@@ -66,3 +66,14 @@ func NewSecurityScheme(securityScheme *low.SecurityScheme) *SecurityScheme {
 func (s *SecurityScheme) GoLow() *low.SecurityScheme {
 	return s.low
 }
+
+// Render will return a YAML representation of the SecurityScheme object as a byte slice.
+func (s *SecurityScheme) Render() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// MarshalYAML will create a ready to render YAML representation of the SecurityScheme object.
+func (s *SecurityScheme) MarshalYAML() (interface{}, error) {
+	nb := high.NewNodeBuilder(s, s.low)
+	return nb.Render(), nil
+}
@@ -31,6 +31,7 @@ type Components struct {
 	SecuritySchemes *orderedmap.Map[string, *SecurityScheme]       `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
 	Links           *orderedmap.Map[string, *Link]                 `json:"links,omitempty" yaml:"links,omitempty"`
 	Callbacks       *orderedmap.Map[string, *Callback]             `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	PathItems       *orderedmap.Map[string, *PathItem]             `json:"pathItems,omitempty" yaml:"pathItems,omitempty"`
 	Extensions      *orderedmap.Map[string, *yaml.Node]            `json:"-" yaml:"-"`
 	low             *low.Components
 }
@@ -53,10 +54,11 @@ func NewComponents(comp *low.Components) *Components {
 	headerMap := orderedmap.New[string, *Header]()
 	securitySchemeMap := orderedmap.New[string, *SecurityScheme]()
 	schemas := orderedmap.New[string, *highbase.SchemaProxy]()
+	pathItemMap := orderedmap.New[string, *PathItem]()
 
 	// build all components asynchronously.
 	var wg sync.WaitGroup
-	wg.Add(9)
+	wg.Add(10)
 	go func() {
 		buildComponent[*low.Callback, *Callback](comp.Callbacks.Value, cbMap, NewCallback)
 		wg.Done()
@@ -93,6 +95,10 @@ func NewComponents(comp *low.Components) *Components {
 		buildSchema(comp.Schemas.Value, schemas)
 		wg.Done()
 	}()
+	go func() {
+		buildComponent[*low.PathItem, *PathItem](comp.PathItems.Value, pathItemMap, NewPathItem)
+		wg.Done()
+	}()
 
 	wg.Wait()
 	c.Schemas = schemas
@@ -104,6 +110,7 @@ func NewComponents(comp *low.Components) *Components {
 	c.RequestBodies = requestBodyMap
 	c.Examples = exampleMap
 	c.SecuritySchemes = securitySchemeMap
+	c.PathItems = pathItemMap
 	return c
 }
 
@@ -0,0 +1,248 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package v3
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/jsonschema"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// ExampleError is an example that is not valid against the schema it belongs to.
+type ExampleError struct {
+	// Path is the path to the example, in the same style as the paths of the index, such as
+	// $.paths['/pets'].get.responses['200'].content['application/json'].examples.cat
+	Path string
+
+	// Node is the value of the example.
+	Node *yaml.Node
+
+	// Line and Column of the value of the example.
+	Line   int
+	Column int
+
+	// Errors are the reasons the example is not valid, each one points at the rejected value in the example, and
+	// the keyword of the schema that rejected it.
+	Errors []*jsonschema.ValidationError
+}
+
+func (e *ExampleError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%s (line %d, column %d): example does not match its schema: %s", e.Path, e.Line, e.Column,
+		strings.Join(messages, "; "))
+}
+
+// ValidateExamples checks every example of the document against the schema it belongs to, and returns an
+// ExampleError for every example that is not valid. No errors means every example matches its schema.
+//
+// The 'example' and 'examples' of parameters, headers and media types are checked against their schema, and so are
+// the 'example' and (3.1) 'examples' of schemas, including inline schemas nested in other schemas. Examples with an
+// 'externalValue' are not checked. Examples of request bodies and request parameters are validated as requests
+// (readOnly properties are not allowed), examples of responses as responses (writeOnly properties are not allowed).
+func (d *Document) ValidateExamples() []*ExampleError {
+	v := &exampleValidator{seen: make(map[*base.Schema]bool)}
+	if d.Components != nil {
+		c := d.Components
+		for pair := orderedmap.First(c.Schemas); pair != nil; pair = pair.Next() {
+			v.schema(pair.Value(), "$.components.schemas"+pathKey(pair.Key()))
+		}
+		for pair := orderedmap.First(c.Responses); pair != nil; pair = pair.Next() {
+			v.response(pair.Value(), "$.components.responses"+pathKey(pair.Key()))
+		}
+		for pair := orderedmap.First(c.Parameters); pair != nil; pair = pair.Next() {
+			v.parameter(pair.Value(), "$.components.parameters"+pathKey(pair.Key()))
+		}
+		for pair := orderedmap.First(c.RequestBodies); pair != nil; pair = pair.Next() {
+			v.content(pair.Value().Content, "$.components.requestBodies"+pathKey(pair.Key())+".content", request)
+		}
+		for pair := orderedmap.First(c.Headers); pair != nil; pair = pair.Next() {
+			v.header(pair.Value(), "$.components.headers"+pathKey(pair.Key()))
+		}
+		for pair := orderedmap.First(c.Callbacks); pair != nil; pair = pair.Next() {
+			v.pathItems(pair.Value().Expression, "$.components.callbacks"+pathKey(pair.Key()))
+		}
+		v.pathItems(c.PathItems, "$.components.pathItems")
+	}
+	if d.Paths != nil {
+		v.pathItems(d.Paths.PathItems, "$.paths")
+	}
+	v.pathItems(d.Webhooks, "$.webhooks")
+	return v.errs
+}
+
+// direction is the direction an example is sent in, it decides how readOnly and writeOnly are checked.
+type direction int
+
+const (
+	anyDirection direction = iota
+	request
+	response
+)
+
+type exampleValidator struct {
+	errs []*ExampleError
+	seen map[*base.Schema]bool
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+
+// pathKey returns the path segment of a key.
+func pathKey(key string) string {
+	if plainKey.MatchString(key) {
+		return "." + key
+	}
+	return "['" + strings.ReplaceAll(key, "'", "\\'") + "']"
+}
+
+func (v *exampleValidator) pathItems(items *orderedmap.Map[string, *PathItem], path string) {
+	for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+		pi := pair.Value()
+		itemPath := path + pathKey(pair.Key())
+		for i, p := range pi.Parameters {
+			v.parameter(p, fmt.Sprintf("%s.parameters[%d]", itemPath, i))
+		}
+		for op := orderedmap.First(pi.GetOperations()); op != nil; op = op.Next() {
+			o := op.Value()
+			opPath := itemPath + "." + op.Key()
+			for i, p := range o.Parameters {
+				v.parameter(p, fmt.Sprintf("%s.parameters[%d]", opPath, i))
+			}
+			if o.RequestBody != nil {
+				v.content(o.RequestBody.Content, opPath+".requestBody.content", request)
+			}
+			if o.Responses != nil {
+				for r := orderedmap.First(o.Responses.Codes); r != nil; r = r.Next() {
+					v.response(r.Value(), opPath+".responses"+pathKey(r.Key()))
+				}
+				if o.Responses.Default != nil {
+					v.response(o.Responses.Default, opPath+".responses.default")
+				}
+			}
+			for cb := orderedmap.First(o.Callbacks); cb != nil; cb = cb.Next() {
+				v.pathItems(cb.Value().Expression, opPath+".callbacks"+pathKey(cb.Key()))
+			}
+		}
+	}
+}
+
+func (v *exampleValidator) response(r *Response, path string) {
+	for pair := orderedmap.First(r.Headers); pair != nil; pair = pair.Next() {
+		v.header(pair.Value(), path+".headers"+pathKey(pair.Key()))
+	}
+	v.content(r.Content, path+".content", response)
+}
+
+func (v *exampleValidator) parameter(p *Parameter, path string) {
+	v.examples(p.Schema, p.Example, p.Examples, path, request)
+	v.schema(p.Schema, path+".schema")
+	v.content(p.Content, path+".content", request)
+}
+
+func (v *exampleValidator) header(h *Header, path string) {
+	v.examples(h.Schema, h.Example, h.Examples, path, response)
+	v.schema(h.Schema, path+".schema")
+	v.content(h.Content, path+".content", response)
+}
+
+func (v *exampleValidator) content(content *orderedmap.Map[string, *MediaType], path string, dir direction) {
+	for pair := orderedmap.First(content); pair != nil; pair = pair.Next() {
+		mt := pair.Value()
+		mtPath := path + pathKey(pair.Key())
+		v.examples(mt.Schema, mt.Example, mt.Examples, mtPath, dir)
+		v.schema(mt.Schema, mtPath+".schema")
+	}
+}
+
+// examples checks the 'example' and 'examples' of a parameter, header or media type.
+func (v *exampleValidator) examples(sp *base.SchemaProxy, example *yaml.Node,
+	examples *orderedmap.Map[string, *base.Example], path string, dir direction,
+) {
+	if sp == nil {
+		return
+	}
+	s := sp.Schema()
+	if s == nil {
+		return
+	}
+	if example != nil {
+		v.check(s, example, path+".example", dir)
+	}
+	for pair := orderedmap.First(examples); pair != nil; pair = pair.Next() {
+		if ex := pair.Value(); ex != nil && ex.Value != nil && ex.ExternalValue == "" {
+			v.check(s, ex.Value, path+".examples"+pathKey(pair.Key())+".value", dir)
+		}
+	}
+}
+
+// schema checks the examples of an inline schema, and of the inline schemas it contains. References are checked
+// where they point to.
+func (v *exampleValidator) schema(sp *base.SchemaProxy, path string) {
+	if sp == nil || sp.IsReference() {
+		return
+	}
+	s := sp.Schema()
+	if s == nil || v.seen[s] {
+		return
+	}
+	v.seen[s] = true
+	if s.Example != nil {
+		v.check(s, s.Example, path+".example", anyDirection)
+	}
+	for i, ex := range s.Examples {
+		v.check(s, ex, fmt.Sprintf("%s.examples[%d]", path, i), anyDirection)
+	}
+
+	for pair := orderedmap.First(s.Properties); pair != nil; pair = pair.Next() {
+		v.schema(pair.Value(), path+".properties"+pathKey(pair.Key()))
+	}
+	for pair := orderedmap.First(s.PatternProperties); pair != nil; pair = pair.Next() {
+		v.schema(pair.Value(), path+".patternProperties"+pathKey(pair.Key()))
+	}
+	for pair := orderedmap.First(s.DependentSchemas); pair != nil; pair = pair.Next() {
+		v.schema(pair.Value(), path+".dependentSchemas"+pathKey(pair.Key()))
+	}
+	for _, list := range []struct {
+		keyword string
+		schemas []*base.SchemaProxy
+	}{{"allOf", s.AllOf}, {"oneOf", s.OneOf}, {"anyOf", s.AnyOf}, {"prefixItems", s.PrefixItems}} {
+		for i, item := range list.schemas {
+			v.schema(item, fmt.Sprintf("%s.%s[%d]", path, list.keyword, i))
+		}
+	}
+	if s.Items != nil && s.Items.IsA() {
+		v.schema(s.Items.A, path+".items")
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.IsA() {
+		v.schema(s.AdditionalProperties.A, path+".additionalProperties")
+	}
+	for _, single := range []struct {
+		keyword string
+		schema  *base.SchemaProxy
+	}{{"not", s.Not}, {"if", s.If}, {"then", s.Then}, {"else", s.Else}, {"contains", s.Contains},
+		{"propertyNames", s.PropertyNames}} {
+		v.schema(single.schema, path+"."+single.keyword)
+	}
+}
+
+func (v *exampleValidator) check(s *base.Schema, example *yaml.Node, path string, dir direction) {
+	errs := s.ValidateNode(example, &base.SchemaValidationOptions{Request: dir == request, Response: dir == response})
+	if len(errs) == 0 {
+		return
+	}
+	v.errs = append(v.errs, &ExampleError{
+		Path:   path,
+		Node:   example,
+		Line:   example.Line,
+		Column: example.Column,
+		Errors: errs,
+	})
+}
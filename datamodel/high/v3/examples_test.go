@@ -0,0 +1,133 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package v3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_ValidateExamples(t *testing.T) {
+	doc := flattenDocument(t, `openapi: 3.1.0
+paths:
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        schema:
+          type: integer
+        example: abc
+    get:
+      parameters:
+        - name: X-Trace
+          in: header
+          schema:
+            type: string
+            format: uuid
+          examples:
+            good:
+              value: 9b2c6c4e-8f0e-4c8a-9a3f-2f6d1c1e5b7a
+            external:
+              externalValue: https://example.com/trace.txt
+      responses:
+        '200':
+          description: A pet.
+          headers:
+            X-Rate-Limit:
+              schema:
+                type: integer
+                minimum: 0
+              example: -1
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+              examples:
+                cat:
+                  value:
+                    id: 1
+                    name: Tom
+                dog:
+                  value:
+                    id: two
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+            example:
+              id: 1
+              name: Rex
+      responses:
+        '204':
+          description: Updated.
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+          examples: [Tom, 42]
+        tags:
+          type: array
+          items:
+            type: string
+            example: 7
+      example:
+        id: 1
+        name: Tom`)
+
+	errs := doc.ValidateExamples()
+	require.Len(t, errs, 6)
+
+	assert.Equal(t, "$.components.schemas.Pet.properties.name.examples[1]", errs[0].Path)
+	assert.Equal(t, "$.components.schemas.Pet.properties.tags.items.example", errs[1].Path)
+	assert.Equal(t, 71, errs[1].Line)
+	assert.Equal(t, 22, errs[1].Column)
+
+	assert.Equal(t, "$.paths['/pets/{id}'].parameters[0].example", errs[2].Path)
+	assert.Equal(t, "$.paths['/pets/{id}'].parameters[0].example (line 9, column 18): example does not match "+
+		"its schema: / (line 9, column 18): expected integer, but got string", errs[2].Error())
+
+	assert.Equal(t, "$.paths['/pets/{id}'].get.responses['200'].headers.X-Rate-Limit.example", errs[3].Path)
+	assert.Equal(t, "$.paths['/pets/{id}'].get.responses['200'].content['application/json'].examples.dog.value",
+		errs[4].Path)
+	require.Len(t, errs[4].Errors, 2)
+	assert.Equal(t, "/id", errs[4].Errors[0].InstancePath)
+	assert.Equal(t, "required", errs[4].Errors[1].Keyword)
+
+	// readOnly properties are not allowed in requests.
+	assert.Equal(t, "$.paths['/pets/{id}'].put.requestBody.content['application/json'].example", errs[5].Path)
+	assert.Equal(t, "/id", errs[5].Errors[0].InstancePath)
+}
+
+func TestDocument_ValidateExamples_ComponentPathItems(t *testing.T) {
+	doc := flattenDocument(t, `openapi: 3.1.0
+components:
+  pathItems:
+    Pets:
+      get:
+        parameters:
+          - name: limit
+            in: query
+            schema:
+              type: integer
+            example: ten
+        responses:
+          '204':
+            description: No pets.`)
+
+	errs := doc.ValidateExamples()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "$.components.pathItems.Pets.get.parameters[0].example", errs[0].Path)
+	assert.Equal(t, 11, errs[0].Line)
+}
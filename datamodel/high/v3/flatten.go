@@ -0,0 +1,147 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package v3
+
+import (
+	"errors"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// FlattenAllOf rewrites the document, replacing every schema that has an 'allOf' with its effective schema, as
+// computed by base.Schema.Flatten. Component schemas and inline schemas (including nested properties, items and
+// polymorphic members) are flattened, references are kept as they are, because the schemas they point to are
+// flattened too.
+//
+// Schemas with incompatible 'allOf' members are left unchanged, and every conflict found is returned as a single
+// joined error. Render the document to get the flattened specification.
+func (d *Document) FlattenAllOf() error {
+	f := &allOfFlattener{seen: make(map[*base.Schema]bool)}
+	if d.Components != nil {
+		f.schemas(d.Components.Schemas)
+		f.responses(d.Components.Responses)
+		for pair := orderedmap.First(d.Components.Parameters); pair != nil; pair = pair.Next() {
+			f.parameter(pair.Value())
+		}
+		for pair := orderedmap.First(d.Components.RequestBodies); pair != nil; pair = pair.Next() {
+			f.content(pair.Value().Content)
+		}
+		f.headers(d.Components.Headers)
+		for pair := orderedmap.First(d.Components.Callbacks); pair != nil; pair = pair.Next() {
+			f.pathItems(pair.Value().Expression)
+		}
+	}
+	if d.Paths != nil {
+		f.pathItems(d.Paths.PathItems)
+	}
+	f.pathItems(d.Webhooks)
+	return errors.Join(f.errs...)
+}
+
+type allOfFlattener struct {
+	errs []error
+	seen map[*base.Schema]bool
+}
+
+func (f *allOfFlattener) pathItems(items *orderedmap.Map[string, *PathItem]) {
+	for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+		pi := pair.Value()
+		for _, p := range pi.Parameters {
+			f.parameter(p)
+		}
+		for op := orderedmap.First(pi.GetOperations()); op != nil; op = op.Next() {
+			o := op.Value()
+			for _, p := range o.Parameters {
+				f.parameter(p)
+			}
+			if o.RequestBody != nil {
+				f.content(o.RequestBody.Content)
+			}
+			if o.Responses != nil {
+				f.responses(o.Responses.Codes)
+				if o.Responses.Default != nil {
+					f.response(o.Responses.Default)
+				}
+			}
+			for cb := orderedmap.First(o.Callbacks); cb != nil; cb = cb.Next() {
+				f.pathItems(cb.Value().Expression)
+			}
+		}
+	}
+}
+
+func (f *allOfFlattener) responses(responses *orderedmap.Map[string, *Response]) {
+	for pair := orderedmap.First(responses); pair != nil; pair = pair.Next() {
+		f.response(pair.Value())
+	}
+}
+
+func (f *allOfFlattener) response(r *Response) {
+	f.headers(r.Headers)
+	f.content(r.Content)
+}
+
+func (f *allOfFlattener) headers(headers *orderedmap.Map[string, *Header]) {
+	for pair := orderedmap.First(headers); pair != nil; pair = pair.Next() {
+		h := pair.Value()
+		h.Schema = f.proxy(h.Schema)
+		f.content(h.Content)
+	}
+}
+
+func (f *allOfFlattener) parameter(p *Parameter) {
+	p.Schema = f.proxy(p.Schema)
+	f.content(p.Content)
+}
+
+func (f *allOfFlattener) content(content *orderedmap.Map[string, *MediaType]) {
+	for pair := orderedmap.First(content); pair != nil; pair = pair.Next() {
+		pair.Value().Schema = f.proxy(pair.Value().Schema)
+	}
+}
+
+func (f *allOfFlattener) schemas(schemas *orderedmap.Map[string, *base.SchemaProxy]) {
+	for pair := orderedmap.First(schemas); pair != nil; pair = pair.Next() {
+		schemas.Set(pair.Key(), f.proxy(pair.Value()))
+	}
+}
+
+// proxy returns the proxy of the flattened schema, or the proxy itself if it does not need to be flattened.
+func (f *allOfFlattener) proxy(sp *base.SchemaProxy) *base.SchemaProxy {
+	if sp == nil || sp.IsReference() {
+		return sp
+	}
+	s := sp.Schema()
+	if s == nil || f.seen[s] {
+		return sp
+	}
+	f.seen[s] = true
+	if len(s.AllOf) > 0 {
+		flat, err := s.Flatten()
+		if err != nil {
+			f.errs = append(f.errs, err)
+		} else {
+			s = flat
+			f.seen[s] = true
+			sp = base.CreateSchemaProxy(s)
+		}
+	}
+
+	f.schemas(s.Properties)
+	f.schemas(s.PatternProperties)
+	if s.Items != nil && s.Items.IsA() {
+		s.Items.A = f.proxy(s.Items.A)
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.IsA() {
+		s.AdditionalProperties.A = f.proxy(s.AdditionalProperties.A)
+	}
+	for i := range s.OneOf {
+		s.OneOf[i] = f.proxy(s.OneOf[i])
+	}
+	for i := range s.AnyOf {
+		s.AnyOf[i] = f.proxy(s.AnyOf[i])
+	}
+	return sp
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package v3
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	v3 "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func flattenDocument(t *testing.T, spec string) *Document {
+	info, err := datamodel.ExtractSpecInfo([]byte(spec))
+	require.NoError(t, err)
+	lowDoc, err := v3.CreateDocumentFromConfig(info, &datamodel.DocumentConfiguration{})
+	require.NoError(t, err)
+	return NewDocument(lowDoc)
+}
+
+func TestDocument_FlattenAllOf(t *testing.T) {
+	doc := flattenDocument(t, `openapi: 3.1.0
+paths:
+  /pets:
+    get:
+      responses:
+        '200':
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  allOf:
+                    - $ref: '#/components/schemas/Pet'
+                    - required: [id]
+components:
+  schemas:
+    Named:
+      type: object
+      properties:
+        name:
+          type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - properties:
+            id:
+              type: integer
+            owner:
+              allOf:
+                - $ref: '#/components/schemas/Named'
+                - required: [name]`)
+
+	require.NoError(t, doc.FlattenAllOf())
+
+	pet := doc.Components.Schemas.GetOrZero("Pet").Schema()
+	assert.Empty(t, pet.AllOf)
+	assert.Equal(t, []string{"object"}, pet.Type)
+	assert.Equal(t, 3, pet.Properties.Len())
+	owner := pet.Properties.GetOrZero("owner").Schema()
+	assert.Empty(t, owner.AllOf)
+	assert.Equal(t, []string{"name"}, owner.Required)
+
+	items := doc.Paths.PathItems.GetOrZero("/pets").Get.Responses.Codes.GetOrZero("200").
+		Content.GetOrZero("application/json").Schema.Schema().Items.A.Schema()
+	assert.Empty(t, items.AllOf)
+	assert.Equal(t, []string{"id"}, items.Required)
+
+	out, err := doc.Render()
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "allOf")
+	assert.Contains(t, string(out), "        Named:\n")
+}
+
+func TestDocument_FlattenAllOf_Conflict(t *testing.T) {
+	doc := flattenDocument(t, `openapi: 3.1.0
+components:
+  schemas:
+    Broken:
+      allOf:
+        - type: string
+        - type: integer`)
+
+	err := doc.FlattenAllOf()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "types string and integer have nothing in common")
+	assert.Len(t, doc.Components.Schemas.GetOrZero("Broken").Schema().AllOf, 2)
+}
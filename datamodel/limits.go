@@ -0,0 +1,192 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package datamodel
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// names of the limits that can be exceeded, used by LimitError.
+const (
+	LimitMaxInputBytes       = "MaxInputBytes"
+	LimitMaxNodeCount        = "MaxNodeCount"
+	LimitMaxDepth            = "MaxDepth"
+	LimitMaxAliasExpansions  = "MaxAliasExpansions"
+	LimitMaxRolodexFiles     = "MaxRolodexFiles"
+	LimitMaxRemoteFetchBytes = "MaxRemoteFetchBytes"
+	LimitMaxReferenceJourney = "MaxReferenceJourney"
+)
+
+// ErrLimitExceeded is matched (using errors.Is) by every *LimitError.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits are used to protect against hostile (or just enormous) specifications, when parsing input that cannot be
+// trusted. Every limit is disabled when set to zero (the default). Limits are checked before the work they guard
+// is done, so a specification that exceeds a limit is rejected with a *LimitError, rather than exhausting memory.
+//
+// The input limits (MaxInputBytes, MaxNodeCount, MaxDepth and MaxAliasExpansions) apply to the specification, and
+// to every file the rolodex loads.
+type Limits struct {
+	// MaxInputBytes is the largest specification (or file) that will be parsed, in bytes.
+	MaxInputBytes int
+
+	// MaxNodeCount is the largest number of YAML nodes (keys, values, maps and sequences) a specification can hold.
+	MaxNodeCount int
+
+	// MaxDepth is the deepest that maps and sequences can be nested.
+	MaxDepth int
+
+	// MaxAliasExpansions is the largest number of aliases that would be expanded if a specification was decoded,
+	// including aliases inside of anchors that are expanded themselves. This guards against "billion laughs"
+	// documents, that are tiny when parsed, and enormous when expanded.
+	MaxAliasExpansions int
+
+	// MaxRolodexFiles is the largest number of files (local and remote) the rolodex will load.
+	MaxRolodexFiles int
+
+	// MaxRemoteFetchBytes is the largest remote file the rolodex will fetch, in bytes.
+	MaxRemoteFetchBytes int
+
+	// MaxReferenceJourney is the longest chain of references the resolver will follow.
+	MaxReferenceJourney int
+}
+
+// LimitError is returned when a specification exceeds one of the configured Limits.
+type LimitError struct {
+	// Limit is the name of the limit that was exceeded, for example 'MaxNodeCount'.
+	Limit string
+
+	// Max is the configured value of the limit.
+	Max int
+
+	// Location is the file or URL that exceeded the limit, it's empty for the root specification.
+	Location string
+}
+
+func (e *LimitError) Error() string {
+	if e.Location != "" {
+		return fmt.Sprintf("%s: '%s' exceeds %s (%d)", ErrLimitExceeded, e.Location, e.Limit, e.Max)
+	}
+	return fmt.Sprintf("%s: specification exceeds %s (%d)", ErrLimitExceeded, e.Limit, e.Max)
+}
+
+// Is returns true for ErrLimitExceeded, so every *LimitError can be matched using errors.Is.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// CheckBytes checks the size of a specification (or file) against MaxInputBytes. A nil *Limits never fails.
+func (l *Limits) CheckBytes(size int) error {
+	if l != nil && l.MaxInputBytes > 0 && size > l.MaxInputBytes {
+		return &LimitError{Limit: LimitMaxInputBytes, Max: l.MaxInputBytes}
+	}
+	return nil
+}
+
+// CheckNode checks a parsed specification (or file) against MaxNodeCount, MaxDepth and MaxAliasExpansions.
+// A nil *Limits never fails.
+func (l *Limits) CheckNode(root *yaml.Node) error {
+	if l == nil || root == nil {
+		return nil
+	}
+	if l.MaxNodeCount > 0 || l.MaxDepth > 0 {
+		// walk the tree without recursion, the depth is not known to be safe yet.
+		type entry struct {
+			node  *yaml.Node
+			depth int
+		}
+		count := 0
+		stack := []entry{{node: root}}
+		for len(stack) > 0 {
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			count++
+			if l.MaxNodeCount > 0 && count > l.MaxNodeCount {
+				return &LimitError{Limit: LimitMaxNodeCount, Max: l.MaxNodeCount}
+			}
+			depth := e.depth
+			if e.node.Kind == yaml.MappingNode || e.node.Kind == yaml.SequenceNode {
+				depth++
+				if l.MaxDepth > 0 && depth > l.MaxDepth {
+					return &LimitError{Limit: LimitMaxDepth, Max: l.MaxDepth}
+				}
+			}
+			for _, child := range e.node.Content {
+				stack = append(stack, entry{node: child, depth: depth})
+			}
+		}
+	}
+	if l.MaxAliasExpansions > 0 {
+		counted := make(map[*yaml.Node]int)
+		if countAliases(root, counted, l.MaxAliasExpansions) > l.MaxAliasExpansions {
+			return &LimitError{Limit: LimitMaxAliasExpansions, Max: l.MaxAliasExpansions}
+		}
+	}
+	return nil
+}
+
+// countAliases counts the aliases that would be expanded when decoding a node. Every node is only counted once
+// (so the count is cheap, even when the expansion is not), and counts stop growing once they pass the limit.
+// An alias that contains itself can never be expanded, so it's counted as exceeding the limit. Nodes are walked
+// with a stack rather than recursion, so deeply nested (or deeply aliased) input cannot exhaust the Go stack.
+func countAliases(root *yaml.Node, counted map[*yaml.Node]int, limit int) int {
+	type frame struct {
+		node    *yaml.Node
+		next    int  // the next child to count.
+		aliased bool // true once the target of an alias has been counted.
+		total   int
+	}
+	var stack []*frame
+
+	// visit returns the count of a node that is already known, or starts counting it.
+	visit := func(node *yaml.Node) (int, bool) {
+		if c, ok := counted[node]; ok {
+			if c < 0 {
+				return limit + 1, true // an alias that contains itself.
+			}
+			return c, true
+		}
+		counted[node] = -1
+		stack = append(stack, &frame{node: node})
+		return 0, false
+	}
+
+	if c, known := visit(root); known {
+		return c
+	}
+	total := 0
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		if f.node.Kind == yaml.AliasNode && f.node.Alias != nil && !f.aliased {
+			f.aliased = true
+			f.total++
+			if c, known := visit(f.node.Alias); known {
+				f.total += c
+			}
+			continue
+		}
+		if f.total <= limit && f.next < len(f.node.Content) {
+			child := f.node.Content[f.next]
+			f.next++
+			if c, known := visit(child); known {
+				f.total += c
+			}
+			continue
+		}
+		if f.total > limit {
+			f.total = limit + 1
+		}
+		counted[f.node] = f.total
+		stack = stack[:len(stack)-1]
+		if len(stack) > 0 {
+			stack[len(stack)-1].total += f.total
+		} else {
+			total = f.total
+		}
+	}
+	return total
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package datamodel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLimits_ExtractSpecInfo(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: burgers
+  contact:
+    name: big mac`
+
+	tests := []struct {
+		name   string
+		limits *Limits
+		limit  string
+	}{
+		{"none", nil, ""},
+		{"unlimited", &Limits{}, ""},
+		{"bytes", &Limits{MaxInputBytes: 20}, LimitMaxInputBytes},
+		{"bytes ok", &Limits{MaxInputBytes: len(spec)}, ""},
+		{"nodes", &Limits{MaxNodeCount: 10}, LimitMaxNodeCount},
+		{"nodes ok", &Limits{MaxNodeCount: 12}, ""},
+		{"depth", &Limits{MaxDepth: 2}, LimitMaxDepth},
+		{"depth ok", &Limits{MaxDepth: 3}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ExtractSpecInfoWithConfig([]byte(spec), &DocumentConfiguration{Limits: tt.limits})
+			if tt.limit == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "3.1.0", info.Version)
+				return
+			}
+			require.Error(t, err)
+			assert.Nil(t, info)
+			assert.True(t, errors.Is(err, ErrLimitExceeded))
+
+			var limitErr *LimitError
+			require.True(t, errors.As(err, &limitErr))
+			assert.Equal(t, tt.limit, limitErr.Limit)
+			assert.Equal(t, "limit exceeded: specification exceeds "+tt.limit, strings.Split(err.Error(), " (")[0])
+		})
+	}
+}
+
+func TestLimits_BillionLaughs(t *testing.T) {
+	spec := `openapi: 3.1.0
+a: &a ["lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f]
+h: &h [*g, *g, *g, *g, *g, *g, *g, *g, *g]
+i: &i [*h, *h, *h, *h, *h, *h, *h, *h, *h]`
+
+	_, err := ExtractSpecInfoWithConfig([]byte(spec), &DocumentConfiguration{
+		Limits: &Limits{MaxAliasExpansions: 1000},
+	})
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, LimitMaxAliasExpansions, limitErr.Limit)
+	assert.Equal(t, 1000, limitErr.Max)
+
+	// a few aliases are fine.
+	_, err = ExtractSpecInfoWithConfig([]byte("openapi: 3.1.0\na: &a [1]\nb: [*a, *a]"), &DocumentConfiguration{
+		Limits: &Limits{MaxAliasExpansions: 2},
+	})
+	assert.NoError(t, err)
+}
+
+func TestLimits_AliasesDeeplyNested(t *testing.T) {
+	// aliases are counted without recursion, so very deep nodes are fine.
+	anchor := &yaml.Node{Kind: yaml.ScalarNode, Value: "lol"}
+	root := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{anchor}}
+	node := root
+	for i := 0; i < 100000; i++ {
+		child := &yaml.Node{Kind: yaml.SequenceNode}
+		node.Content = append(node.Content, child)
+		node = child
+	}
+	node.Content = []*yaml.Node{{Kind: yaml.AliasNode, Alias: anchor}, {Kind: yaml.AliasNode, Alias: anchor}}
+
+	limits := &Limits{MaxAliasExpansions: 2}
+	assert.NoError(t, limits.CheckNode(root))
+
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.AliasNode, Alias: anchor})
+	assert.ErrorIs(t, limits.CheckNode(root), ErrLimitExceeded)
+
+	// an alias that contains itself can never be expanded.
+	loop := &yaml.Node{Kind: yaml.SequenceNode}
+	loop.Content = []*yaml.Node{{Kind: yaml.AliasNode, Alias: loop}}
+	assert.ErrorIs(t, limits.CheckNode(loop), ErrLimitExceeded)
+}
+
+func TestLimitError_Error(t *testing.T) {
+	err := &LimitError{Limit: LimitMaxRolodexFiles, Max: 3, Location: "burgers.yaml"}
+	assert.Equal(t, "limit exceeded: 'burgers.yaml' exceeds MaxRolodexFiles (3)", err.Error())
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+
+	var limits *Limits
+	assert.NoError(t, limits.CheckBytes(100))
+	assert.NoError(t, limits.CheckNode(nil))
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SuccessAction represents a low-level Arazzo Success Action object.
+//
+// A single success action which describes an action to take upon success of a workflow step. A SuccessAction can
+// also be a Reusable object, that references an action defined in components (with Reference).
+//   - https://spec.openapis.org/arazzo/v1.0.0#success-action-object
+type SuccessAction struct {
+	Name       low.NodeReference[string]
+	Type       low.NodeReference[string]
+	WorkflowId low.NodeReference[string]
+	StepId     low.NodeReference[string]
+	Criteria   low.NodeReference[[]low.ValueReference[*Criterion]]
+	Reference  low.NodeReference[string]
+	Extensions *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (s *SuccessAction) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, s.Extensions)
+}
+
+// GetExtensions returns all SuccessAction extensions and satisfies the low.HasExtensions interface.
+func (s *SuccessAction) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return s.Extensions
+}
+
+// Build will extract the criteria of the SuccessAction.
+func (s *SuccessAction) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	s.Extensions = low.ExtractExtensions(root)
+	var err error
+	s.Criteria, err = extractArray[*Criterion](ctx, CriteriaLabel, root, idx)
+	return err
+}
+
+// Hash will return a consistent SHA256 Hash of the SuccessAction object
+func (s *SuccessAction) Hash() [32]byte {
+	var f []string
+	for _, v := range []low.NodeReference[string]{s.Name, s.Type, s.WorkflowId, s.StepId, s.Reference} {
+		if !v.IsEmpty() {
+			f = append(f, v.Value)
+		}
+	}
+	f = append(f, hashArray(s.Criteria.Value)...)
+	f = append(f, low.HashExtensions(s.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
+
+// FailureAction represents a low-level Arazzo Failure Action object.
+//
+// A single failure action which describes an action to take upon failure of a workflow step. A FailureAction can
+// also be a Reusable object, that references an action defined in components (with Reference).
+//   - https://spec.openapis.org/arazzo/v1.0.0#failure-action-object
+type FailureAction struct {
+	Name       low.NodeReference[string]
+	Type       low.NodeReference[string]
+	WorkflowId low.NodeReference[string]
+	StepId     low.NodeReference[string]
+	RetryAfter low.NodeReference[float64]
+	RetryLimit low.NodeReference[int64]
+	Criteria   low.NodeReference[[]low.ValueReference[*Criterion]]
+	Reference  low.NodeReference[string]
+	Extensions *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (f *FailureAction) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, f.Extensions)
+}
+
+// GetExtensions returns all FailureAction extensions and satisfies the low.HasExtensions interface.
+func (f *FailureAction) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return f.Extensions
+}
+
+// Build will extract the criteria of the FailureAction.
+func (f *FailureAction) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	f.Extensions = low.ExtractExtensions(root)
+	var err error
+	f.Criteria, err = extractArray[*Criterion](ctx, CriteriaLabel, root, idx)
+	return err
+}
+
+// Hash will return a consistent SHA256 Hash of the FailureAction object
+func (f *FailureAction) Hash() [32]byte {
+	var h []string
+	for _, v := range []low.NodeReference[string]{f.Name, f.Type, f.WorkflowId, f.StepId, f.Reference} {
+		if !v.IsEmpty() {
+			h = append(h, v.Value)
+		}
+	}
+	if !f.RetryAfter.IsEmpty() {
+		h = append(h, fmt.Sprint(f.RetryAfter.Value))
+	}
+	if !f.RetryLimit.IsEmpty() {
+		h = append(h, fmt.Sprint(f.RetryLimit.Value))
+	}
+	h = append(h, hashArray(f.Criteria.Value)...)
+	h = append(h, low.HashExtensions(f.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(h, "|")))
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package arazzo contains the low-level model of the Arazzo workflows specification. Arazzo describes sequences of
+// API calls (workflows) and how they depend on each other, using operations of OpenAPI documents that are listed
+// as source descriptions.
+//   - https://spec.openapis.org/arazzo/v1.0.0
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Arazzo represents a low-level Arazzo document, the root of an Arazzo description.
+//   - https://spec.openapis.org/arazzo/v1.0.0#arazzo-description
+type Arazzo struct {
+	Arazzo             low.NodeReference[string]
+	Info               low.NodeReference[*base.Info]
+	SourceDescriptions low.NodeReference[[]low.ValueReference[*SourceDescription]]
+	Workflows          low.NodeReference[[]low.ValueReference[*Workflow]]
+	Components         low.NodeReference[*Components]
+	Extensions         *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+
+	// Index is the index of the Arazzo document, used to look up references inside JSON schemas.
+	Index *index.SpecIndex
+}
+
+// CreateDocument will create a new low-level Arazzo document from the provided SpecInfo.
+func CreateDocument(info *datamodel.SpecInfo) (*Arazzo, error) {
+	return CreateDocumentWithContext(context.Background(), info)
+}
+
+// CreateDocumentWithContext is the same as CreateDocument, with a context that is passed to every part of the model
+// as it is built.
+func CreateDocumentWithContext(ctx context.Context, info *datamodel.SpecInfo) (*Arazzo, error) {
+	if info == nil || info.RootNode == nil || len(info.RootNode.Content) == 0 {
+		return nil, errors.New("no arazzo document found, cannot create document")
+	}
+	root := info.RootNode.Content[0]
+	if _, v := utils.FindKeyNodeTop(ArazzoLabel, root.Content); v == nil {
+		return nil, errors.New("no arazzo version found, cannot create document")
+	}
+	idxConfig := index.CreateClosedAPIIndexConfig()
+	idxConfig.SpecInfo = info
+	idx := index.NewSpecIndexWithConfig(info.RootNode, idxConfig)
+
+	doc := new(Arazzo)
+	if err := low.BuildModel(root, doc); err != nil {
+		return nil, err
+	}
+	if err := doc.Build(ctx, nil, root, idx); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (a *Arazzo) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, a.Extensions)
+}
+
+// GetExtensions returns all Arazzo extensions and satisfies the low.HasExtensions interface.
+func (a *Arazzo) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return a.Extensions
+}
+
+// FindSourceDescription will attempt to locate a SourceDescription by name.
+func (a *Arazzo) FindSourceDescription(name string) *SourceDescription {
+	for _, s := range a.SourceDescriptions.Value {
+		if s.Value.Name.Value == name {
+			return s.Value
+		}
+	}
+	return nil
+}
+
+// FindWorkflow will attempt to locate a Workflow by its workflowId.
+func (a *Arazzo) FindWorkflow(workflowId string) *Workflow {
+	for _, w := range a.Workflows.Value {
+		if w.Value.WorkflowId.Value == workflowId {
+			return w.Value
+		}
+	}
+	return nil
+}
+
+// Build will extract the info, source descriptions, workflows and components of the Arazzo document.
+func (a *Arazzo) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	a.Index = idx
+	a.Extensions = low.ExtractExtensions(root)
+
+	info, err := low.ExtractObject[*base.Info](ctx, InfoLabel, root, idx)
+	if err != nil {
+		return err
+	}
+	a.Info = info
+
+	if a.SourceDescriptions, err = extractArray[*SourceDescription](ctx, SourceDescriptionsLabel, root, idx); err != nil {
+		return err
+	}
+	if a.Workflows, err = extractArray[*Workflow](ctx, WorkflowsLabel, root, idx); err != nil {
+		return err
+	}
+	components, err := low.ExtractObject[*Components](ctx, ComponentsLabel, root, idx)
+	if err != nil {
+		return err
+	}
+	a.Components = components
+	return nil
+}
+
+// Hash will return a consistent SHA256 Hash of the Arazzo object
+func (a *Arazzo) Hash() [32]byte {
+	var f []string
+	if !a.Arazzo.IsEmpty() {
+		f = append(f, a.Arazzo.Value)
+	}
+	if !a.Info.IsEmpty() {
+		f = append(f, low.GenerateHashString(a.Info.Value))
+	}
+	f = append(f, hashArray(a.SourceDescriptions.Value)...)
+	f = append(f, hashArray(a.Workflows.Value)...)
+	if !a.Components.IsEmpty() {
+		f = append(f, low.GenerateHashString(a.Components.Value))
+	}
+	f = append(f, low.HashExtensions(a.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
+
+// extractArray extracts a sequence of objects into a NodeReference, which is empty if the label does not exist.
+func extractArray[T low.Buildable[N], N any](ctx context.Context, label string, root *yaml.Node,
+	idx *index.SpecIndex,
+) (low.NodeReference[[]low.ValueReference[T]], error) {
+	items, ln, vn, err := low.ExtractArray[T](ctx, label, root, idx)
+	if err != nil || ln == nil {
+		return low.NodeReference[[]low.ValueReference[T]]{}, err
+	}
+	return low.NodeReference[[]low.ValueReference[T]]{Value: items, KeyNode: ln, ValueNode: vn}, nil
+}
+
+// extractMap extracts a map of objects into a NodeReference, which is empty if the label does not exist.
+func extractMap[T low.Buildable[N], N any](ctx context.Context, label string, root *yaml.Node,
+	idx *index.SpecIndex,
+) (low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[T]]], error) {
+	items, ln, vn, err := low.ExtractMap[T](ctx, label, root, idx)
+	if err != nil || ln == nil {
+		return low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[T]]]{}, err
+	}
+	return low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[T]]]{
+		Value: items, KeyNode: ln, ValueNode: vn,
+	}, nil
+}
+
+func hashArray[T any](items []low.ValueReference[T]) []string {
+	var f []string
+	for _, item := range items {
+		f = append(f, low.GenerateHashString(item.Value))
+	}
+	return f
+}
+
+func hashMap[T any](items *orderedmap.Map[low.KeyReference[string], low.ValueReference[T]]) []string {
+	var f []string
+	for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+		f = append(f, pair.Key().Value+"-"+low.GenerateHashString(pair.Value().Value))
+	}
+	return f
+}
+
+func hashStrings(items *orderedmap.Map[low.KeyReference[string], low.ValueReference[string]]) []string {
+	var f []string
+	for pair := orderedmap.First(items); pair != nil; pair = pair.Next() {
+		f = append(f, pair.Key().Value+"-"+pair.Value().Value)
+	}
+	return f
+}
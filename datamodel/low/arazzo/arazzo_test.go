@@ -0,0 +1,194 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var petWorkflows = `arazzo: 1.0.0
+info:
+  title: adopt a pet
+  version: 1.0.0
+sourceDescriptions:
+  - name: petstore
+    url: ./petstore.yaml
+    type: openapi
+workflows:
+  - workflowId: adoptPet
+    summary: find and adopt a pet
+    inputs:
+      type: object
+      properties:
+        name:
+          $ref: '#/components/inputs/petName'
+    dependsOn:
+      - login
+    parameters:
+      - reference: $components.parameters.apiKey
+        value: secret
+    steps:
+      - stepId: findPet
+        operationId: findPets
+        parameters:
+          - name: name
+            in: query
+            value: $inputs.name
+        successCriteria:
+          - condition: $statusCode == 200
+          - context: $response.body
+            condition: $[?count(@.pets) > 0]
+            type: jsonpath
+          - context: $response.body
+            condition: ^pet
+            type:
+              type: jsonpath
+              version: draft-goessner-dispatch-jsonpath-00
+        onFailure:
+          - name: retry
+            type: retry
+            retryAfter: 1.5
+            retryLimit: 3
+            criteria:
+              - condition: $statusCode == 503
+        outputs:
+          petId: $response.body#/pets/0/id
+      - stepId: adopt
+        operationPath: '{$sourceDescriptions.petstore.url}#/paths/~1pets~1{petId}/post'
+        requestBody:
+          contentType: application/json
+          payload:
+            adopter: $inputs.name
+          replacements:
+            - target: /adopter
+              value: someone
+        onSuccess:
+          - reference: $components.successActions.done
+    outputs:
+      petId: $steps.findPet.outputs.petId
+    x-team: qa
+components:
+  inputs:
+    petName:
+      type: string
+  parameters:
+    apiKey:
+      name: api_key
+      in: header
+  successActions:
+    done:
+      name: done
+      type: end
+  failureActions:
+    giveUp:
+      name: giveUp
+      type: end
+x-owner: pb33f
+`
+
+func createDocument(t *testing.T, spec string) *Arazzo {
+	info, err := datamodel.ExtractSpecInfo([]byte(spec))
+	require.NoError(t, err)
+	doc, err := CreateDocument(info)
+	require.NoError(t, err)
+	return doc
+}
+
+func TestCreateDocument(t *testing.T) {
+	doc := createDocument(t, petWorkflows)
+	assert.Equal(t, "1.0.0", doc.Arazzo.Value)
+	assert.Equal(t, "adopt a pet", doc.Info.Value.Title.Value)
+	assert.Equal(t, "pb33f", doc.FindExtension("x-owner").Value.Value)
+	assert.Equal(t, 1, orderedmap.Len(doc.GetExtensions()))
+	assert.NotNil(t, doc.Index)
+
+	source := doc.FindSourceDescription("petstore")
+	require.NotNil(t, source)
+	assert.Equal(t, "./petstore.yaml", source.URL.Value)
+	assert.Equal(t, "openapi", source.Type.Value)
+	assert.Equal(t, 6, source.Name.KeyNode.Line)
+	assert.Nil(t, doc.FindSourceDescription("nope"))
+
+	workflow := doc.FindWorkflow("adoptPet")
+	require.NotNil(t, workflow)
+	assert.Nil(t, doc.FindWorkflow("nope"))
+	assert.Equal(t, "find and adopt a pet", workflow.Summary.Value)
+	assert.Equal(t, "login", workflow.DependsOn.Value[0].Value)
+	assert.Equal(t, "qa", workflow.FindExtension("x-team").Value.Value)
+	assert.Equal(t, "$steps.findPet.outputs.petId", low.FindItemInOrderedMap("petId", workflow.Outputs.Value).Value)
+	assert.Equal(t, "$components.parameters.apiKey", workflow.Parameters.Value[0].Value.Reference.Value)
+	assert.Equal(t, "secret", workflow.Parameters.Value[0].Value.Value.Value.Value)
+
+	// inputs are JSON schemas, references are looked up in the Arazzo document.
+	inputs := workflow.Inputs.Value.Schema()
+	require.NotNil(t, inputs)
+	name := inputs.FindProperty("name").Value.Schema()
+	require.NotNil(t, name)
+	assert.Equal(t, "string", name.Type.Value.A)
+
+	find := workflow.FindStep("findPet")
+	require.NotNil(t, find)
+	assert.Nil(t, workflow.FindStep("nope"))
+	assert.Equal(t, "findPets", find.OperationId.Value)
+	assert.Equal(t, "query", find.Parameters.Value[0].Value.In.Value)
+	require.Len(t, find.SuccessCriteria.Value, 3)
+	assert.Equal(t, "$statusCode == 200", find.SuccessCriteria.Value[0].Value.Condition.Value)
+	assert.Equal(t, "jsonpath", find.SuccessCriteria.Value[1].Value.Type.Value)
+	assert.True(t, find.SuccessCriteria.Value[1].Value.ExpressionType.IsEmpty())
+	expression := find.SuccessCriteria.Value[2].Value
+	assert.True(t, expression.Type.IsEmpty())
+	assert.Equal(t, "jsonpath", expression.ExpressionType.Value.Type.Value)
+	assert.Equal(t, "draft-goessner-dispatch-jsonpath-00", expression.ExpressionType.Value.Version.Value)
+
+	retry := find.OnFailure.Value[0].Value
+	assert.Equal(t, 1.5, retry.RetryAfter.Value)
+	assert.Equal(t, int64(3), retry.RetryLimit.Value)
+	assert.Equal(t, "$statusCode == 503", retry.Criteria.Value[0].Value.Condition.Value)
+
+	adopt := workflow.FindStep("adopt")
+	assert.Equal(t, "{$sourceDescriptions.petstore.url}#/paths/~1pets~1{petId}/post", adopt.OperationPath.Value)
+	body := adopt.RequestBody.Value
+	assert.Equal(t, "application/json", body.ContentType.Value)
+	assert.Equal(t, "/adopter", body.Replacements.Value[0].Value.Target.Value)
+	assert.Equal(t, "someone", body.Replacements.Value[0].Value.Value.Value.Value)
+	assert.Equal(t, "$components.successActions.done", adopt.OnSuccess.Value[0].Value.Reference.Value)
+
+	components := doc.Components.Value
+	assert.Equal(t, 1, orderedmap.Len(components.Inputs.Value))
+	assert.Equal(t, "header", low.FindItemInOrderedMap("apiKey", components.Parameters.Value).Value.In.Value)
+	assert.Equal(t, "end", components.SuccessActions.Value.First().Value().Value.Type.Value)
+	assert.Equal(t, "giveUp", components.FailureActions.Value.First().Value().Value.Name.Value)
+}
+
+func TestArazzo_Hash(t *testing.T) {
+	left := createDocument(t, petWorkflows)
+	right := createDocument(t, petWorkflows)
+	assert.Equal(t, left.Hash(), right.Hash())
+
+	changed := createDocument(t, petWorkflows[:len(petWorkflows)-len("pb33f\n")]+"quobix\n")
+	assert.NotEqual(t, left.Hash(), changed.Hash())
+	assert.Equal(t, left.Workflows.Value[0].Value.Hash(), changed.Workflows.Value[0].Value.Hash())
+	assert.Equal(t, left.Components.Value.Hash(), changed.Components.Value.Hash())
+}
+
+func TestCreateDocument_Errors(t *testing.T) {
+	_, err := CreateDocument(nil)
+	assert.Error(t, err)
+
+	info, err := datamodel.ExtractSpecInfo([]byte("openapi: 3.1.0"))
+	require.NoError(t, err)
+	_, err = CreateDocument(info)
+	assert.Equal(t, "no arazzo version found, cannot create document", err.Error())
+
+	info, err = datamodel.ExtractSpecInfo([]byte("arazzo: 1.0.0\nworkflows:\n  - steps:\n      $ref: '#/nope'"))
+	require.NoError(t, err)
+	_, err = CreateDocument(info)
+	assert.Error(t, err)
+}
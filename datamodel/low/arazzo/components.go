@@ -0,0 +1,70 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Components represents a low-level Arazzo Components object.
+//
+// Holds a set of reusable objects for different aspects of the Arazzo Specification.
+//   - https://spec.openapis.org/arazzo/v1.0.0#components-object
+type Components struct {
+	Inputs         low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*base.SchemaProxy]]]
+	Parameters     low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*Parameter]]]
+	SuccessActions low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*SuccessAction]]]
+	FailureActions low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*FailureAction]]]
+	Extensions     *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (c *Components) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, c.Extensions)
+}
+
+// GetExtensions returns all Components extensions and satisfies the low.HasExtensions interface.
+func (c *Components) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return c.Extensions
+}
+
+// Build will extract the inputs, parameters and actions of the Components.
+func (c *Components) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	c.Extensions = low.ExtractExtensions(root)
+
+	var err error
+	if c.Inputs, err = extractMap[*base.SchemaProxy](ctx, InputsLabel, root, idx); err != nil {
+		return err
+	}
+	if c.Parameters, err = extractMap[*Parameter](ctx, ParametersLabel, root, idx); err != nil {
+		return err
+	}
+	if c.SuccessActions, err = extractMap[*SuccessAction](ctx, SuccessActionsLabel, root, idx); err != nil {
+		return err
+	}
+	c.FailureActions, err = extractMap[*FailureAction](ctx, FailureActionsLabel, root, idx)
+	return err
+}
+
+// Hash will return a consistent SHA256 Hash of the Components object
+func (c *Components) Hash() [32]byte {
+	var f []string
+	f = append(f, hashMap(c.Inputs.Value)...)
+	f = append(f, hashMap(c.Parameters.Value)...)
+	f = append(f, hashMap(c.SuccessActions.Value)...)
+	f = append(f, hashMap(c.FailureActions.Value)...)
+	f = append(f, low.HashExtensions(c.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
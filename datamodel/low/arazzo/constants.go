@@ -0,0 +1,25 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+// Constants for labels used to look up values within Arazzo documents.
+const (
+	ArazzoLabel             = "arazzo"
+	InfoLabel               = "info"
+	SourceDescriptionsLabel = "sourceDescriptions"
+	WorkflowsLabel          = "workflows"
+	ComponentsLabel         = "components"
+	InputsLabel             = "inputs"
+	StepsLabel              = "steps"
+	ParametersLabel         = "parameters"
+	SuccessActionsLabel     = "successActions"
+	FailureActionsLabel     = "failureActions"
+	SuccessCriteriaLabel    = "successCriteria"
+	OnSuccessLabel          = "onSuccess"
+	OnFailureLabel          = "onFailure"
+	RequestBodyLabel        = "requestBody"
+	ReplacementsLabel       = "replacements"
+	CriteriaLabel           = "criteria"
+	TypeLabel               = "type"
+)
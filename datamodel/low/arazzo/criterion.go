@@ -0,0 +1,113 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Criterion represents a low-level Arazzo Criterion object.
+//
+// An object used to specify the context, conditions, and condition types that can be used to prove or satisfy
+// assertions specified in Step Object successCriteria, Success Action Object criteria, and Failure Action Object
+// criteria. The type of a Criterion is either a string (Type) or a Criterion Expression Type object
+// (ExpressionType).
+//   - https://spec.openapis.org/arazzo/v1.0.0#criterion-object
+type Criterion struct {
+	Context        low.NodeReference[string]
+	Condition      low.NodeReference[string]
+	Type           low.NodeReference[string]
+	ExpressionType low.NodeReference[*CriterionExpressionType]
+	Extensions     *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (c *Criterion) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, c.Extensions)
+}
+
+// GetExtensions returns all Criterion extensions and satisfies the low.HasExtensions interface.
+func (c *Criterion) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return c.Extensions
+}
+
+// Build will extract the expression type of the Criterion, when the type is an object.
+func (c *Criterion) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	c.Extensions = low.ExtractExtensions(root)
+	if _, t := utils.FindKeyNodeTop(TypeLabel, root.Content); utils.IsNodeMap(t) {
+		c.Type = low.NodeReference[string]{}
+		expressionType, err := low.ExtractObject[*CriterionExpressionType](ctx, TypeLabel, root, idx)
+		if err != nil {
+			return err
+		}
+		c.ExpressionType = expressionType
+	}
+	return nil
+}
+
+// Hash will return a consistent SHA256 Hash of the Criterion object
+func (c *Criterion) Hash() [32]byte {
+	var f []string
+	for _, v := range []low.NodeReference[string]{c.Context, c.Condition, c.Type} {
+		if !v.IsEmpty() {
+			f = append(f, v.Value)
+		}
+	}
+	if !c.ExpressionType.IsEmpty() {
+		f = append(f, low.GenerateHashString(c.ExpressionType.Value))
+	}
+	f = append(f, low.HashExtensions(c.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
+
+// CriterionExpressionType represents a low-level Arazzo Criterion Expression Type object.
+//
+// An object used to describe the type and version of an expression used within a Criterion Object.
+//   - https://spec.openapis.org/arazzo/v1.0.0#criterion-expression-type-object
+type CriterionExpressionType struct {
+	Type       low.NodeReference[string]
+	Version    low.NodeReference[string]
+	Extensions *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (c *CriterionExpressionType) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, c.Extensions)
+}
+
+// GetExtensions returns all CriterionExpressionType extensions and satisfies the low.HasExtensions interface.
+func (c *CriterionExpressionType) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return c.Extensions
+}
+
+// Build will extract extensions for the CriterionExpressionType.
+func (c *CriterionExpressionType) Build(_ context.Context, _, root *yaml.Node, _ *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	c.Extensions = low.ExtractExtensions(root)
+	return nil
+}
+
+// Hash will return a consistent SHA256 Hash of the CriterionExpressionType object
+func (c *CriterionExpressionType) Hash() [32]byte {
+	var f []string
+	if !c.Type.IsEmpty() {
+		f = append(f, c.Type.Value)
+	}
+	if !c.Version.IsEmpty() {
+		f = append(f, c.Version.Value)
+	}
+	f = append(f, low.HashExtensions(c.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Parameter represents a low-level Arazzo Parameter object.
+//
+// Describes a single step parameter. A Parameter can also be a Reusable object, that references a parameter defined
+// in components (with Reference), and may override its value.
+//   - https://spec.openapis.org/arazzo/v1.0.0#parameter-object
+//   - https://spec.openapis.org/arazzo/v1.0.0#reusable-object
+type Parameter struct {
+	Name       low.NodeReference[string]
+	In         low.NodeReference[string]
+	Value      low.NodeReference[*yaml.Node]
+	Reference  low.NodeReference[string]
+	Extensions *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (p *Parameter) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, p.Extensions)
+}
+
+// GetExtensions returns all Parameter extensions and satisfies the low.HasExtensions interface.
+func (p *Parameter) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return p.Extensions
+}
+
+// Build will extract extensions for the Parameter.
+func (p *Parameter) Build(_ context.Context, _, root *yaml.Node, _ *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	p.Extensions = low.ExtractExtensions(root)
+	return nil
+}
+
+// Hash will return a consistent SHA256 Hash of the Parameter object
+func (p *Parameter) Hash() [32]byte {
+	var f []string
+	if !p.Name.IsEmpty() {
+		f = append(f, p.Name.Value)
+	}
+	if !p.In.IsEmpty() {
+		f = append(f, p.In.Value)
+	}
+	if !p.Value.IsEmpty() {
+		f = append(f, low.GenerateHashString(p.Value.Value))
+	}
+	if !p.Reference.IsEmpty() {
+		f = append(f, p.Reference.Value)
+	}
+	f = append(f, low.HashExtensions(p.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// RequestBody represents a low-level Arazzo Request Body object.
+//
+// A single request body describing the Content-Type and request body content to be passed by a step to an operation.
+//   - https://spec.openapis.org/arazzo/v1.0.0#request-body-object
+type RequestBody struct {
+	ContentType  low.NodeReference[string]
+	Payload      low.NodeReference[*yaml.Node]
+	Replacements low.NodeReference[[]low.ValueReference[*PayloadReplacement]]
+	Extensions   *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (r *RequestBody) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, r.Extensions)
+}
+
+// GetExtensions returns all RequestBody extensions and satisfies the low.HasExtensions interface.
+func (r *RequestBody) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return r.Extensions
+}
+
+// Build will extract the payload replacements of the RequestBody.
+func (r *RequestBody) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	r.Extensions = low.ExtractExtensions(root)
+	var err error
+	r.Replacements, err = extractArray[*PayloadReplacement](ctx, ReplacementsLabel, root, idx)
+	return err
+}
+
+// Hash will return a consistent SHA256 Hash of the RequestBody object
+func (r *RequestBody) Hash() [32]byte {
+	var f []string
+	if !r.ContentType.IsEmpty() {
+		f = append(f, r.ContentType.Value)
+	}
+	if !r.Payload.IsEmpty() {
+		f = append(f, low.GenerateHashString(r.Payload.Value))
+	}
+	f = append(f, hashArray(r.Replacements.Value)...)
+	f = append(f, low.HashExtensions(r.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
+
+// PayloadReplacement represents a low-level Arazzo Payload Replacement object.
+//
+// Describes a location within a payload (e.g., a request body) and a value to set within the location.
+//   - https://spec.openapis.org/arazzo/v1.0.0#payload-replacement-object
+type PayloadReplacement struct {
+	Target     low.NodeReference[string]
+	Value      low.NodeReference[*yaml.Node]
+	Extensions *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (p *PayloadReplacement) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, p.Extensions)
+}
+
+// GetExtensions returns all PayloadReplacement extensions and satisfies the low.HasExtensions interface.
+func (p *PayloadReplacement) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return p.Extensions
+}
+
+// Build will extract extensions for the PayloadReplacement.
+func (p *PayloadReplacement) Build(_ context.Context, _, root *yaml.Node, _ *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	p.Extensions = low.ExtractExtensions(root)
+	return nil
+}
+
+// Hash will return a consistent SHA256 Hash of the PayloadReplacement object
+func (p *PayloadReplacement) Hash() [32]byte {
+	var f []string
+	if !p.Target.IsEmpty() {
+		f = append(f, p.Target.Value)
+	}
+	if !p.Value.IsEmpty() {
+		f = append(f, low.GenerateHashString(p.Value.Value))
+	}
+	f = append(f, low.HashExtensions(p.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
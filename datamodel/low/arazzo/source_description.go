@@ -0,0 +1,62 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceDescription represents a low-level Arazzo Source Description object.
+//
+// Describes a source description (such as an OpenAPI description) that will be referenced by one or more workflows
+// described within an Arazzo description.
+//   - https://spec.openapis.org/arazzo/v1.0.0#source-description-object
+type SourceDescription struct {
+	Name       low.NodeReference[string]
+	URL        low.NodeReference[string]
+	Type       low.NodeReference[string]
+	Extensions *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (s *SourceDescription) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, s.Extensions)
+}
+
+// GetExtensions returns all SourceDescription extensions and satisfies the low.HasExtensions interface.
+func (s *SourceDescription) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return s.Extensions
+}
+
+// Build will extract extensions for the SourceDescription.
+func (s *SourceDescription) Build(_ context.Context, _, root *yaml.Node, _ *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	s.Extensions = low.ExtractExtensions(root)
+	return nil
+}
+
+// Hash will return a consistent SHA256 Hash of the SourceDescription object
+func (s *SourceDescription) Hash() [32]byte {
+	var f []string
+	if !s.Name.IsEmpty() {
+		f = append(f, s.Name.Value)
+	}
+	if !s.URL.IsEmpty() {
+		f = append(f, s.URL.Value)
+	}
+	if !s.Type.IsEmpty() {
+		f = append(f, s.Type.Value)
+	}
+	f = append(f, low.HashExtensions(s.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
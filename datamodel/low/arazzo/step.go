@@ -0,0 +1,91 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Step represents a low-level Arazzo Step object.
+//
+// Describes a single workflow step which may be a call to an API operation (operationId or operationPath) or
+// another workflow (workflowId).
+//   - https://spec.openapis.org/arazzo/v1.0.0#step-object
+type Step struct {
+	StepId          low.NodeReference[string]
+	Description     low.NodeReference[string]
+	OperationId     low.NodeReference[string]
+	OperationPath   low.NodeReference[string]
+	WorkflowId      low.NodeReference[string]
+	Parameters      low.NodeReference[[]low.ValueReference[*Parameter]]
+	RequestBody     low.NodeReference[*RequestBody]
+	SuccessCriteria low.NodeReference[[]low.ValueReference[*Criterion]]
+	OnSuccess       low.NodeReference[[]low.ValueReference[*SuccessAction]]
+	OnFailure       low.NodeReference[[]low.ValueReference[*FailureAction]]
+	Outputs         low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[string]]]
+	Extensions      *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (s *Step) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, s.Extensions)
+}
+
+// GetExtensions returns all Step extensions and satisfies the low.HasExtensions interface.
+func (s *Step) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return s.Extensions
+}
+
+// Build will extract the parameters, request body, success criteria and actions of the Step.
+func (s *Step) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	s.Extensions = low.ExtractExtensions(root)
+
+	var err error
+	if s.Parameters, err = extractArray[*Parameter](ctx, ParametersLabel, root, idx); err != nil {
+		return err
+	}
+	if s.RequestBody, err = low.ExtractObject[*RequestBody](ctx, RequestBodyLabel, root, idx); err != nil {
+		return err
+	}
+	if s.SuccessCriteria, err = extractArray[*Criterion](ctx, SuccessCriteriaLabel, root, idx); err != nil {
+		return err
+	}
+	if s.OnSuccess, err = extractArray[*SuccessAction](ctx, OnSuccessLabel, root, idx); err != nil {
+		return err
+	}
+	s.OnFailure, err = extractArray[*FailureAction](ctx, OnFailureLabel, root, idx)
+	return err
+}
+
+// Hash will return a consistent SHA256 Hash of the Step object
+func (s *Step) Hash() [32]byte {
+	var f []string
+	for _, v := range []low.NodeReference[string]{
+		s.StepId, s.Description, s.OperationId, s.OperationPath, s.WorkflowId,
+	} {
+		if !v.IsEmpty() {
+			f = append(f, v.Value)
+		}
+	}
+	f = append(f, hashArray(s.Parameters.Value)...)
+	if !s.RequestBody.IsEmpty() {
+		f = append(f, low.GenerateHashString(s.RequestBody.Value))
+	}
+	f = append(f, hashArray(s.SuccessCriteria.Value)...)
+	f = append(f, hashArray(s.OnSuccess.Value)...)
+	f = append(f, hashArray(s.OnFailure.Value)...)
+	f = append(f, hashStrings(s.Outputs.Value)...)
+	f = append(f, low.HashExtensions(s.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package arazzo
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow represents a low-level Arazzo Workflow object.
+//
+// Describes the steps to be taken across one or more APIs to achieve an objective. The workflow object may define
+// inputs needed in order to execute workflow steps, where the defined steps represent a call to an API operation
+// or another workflow, and a set of outputs.
+//   - https://spec.openapis.org/arazzo/v1.0.0#workflow-object
+type Workflow struct {
+	WorkflowId     low.NodeReference[string]
+	Summary        low.NodeReference[string]
+	Description    low.NodeReference[string]
+	Inputs         low.NodeReference[*base.SchemaProxy]
+	DependsOn      low.NodeReference[[]low.ValueReference[string]]
+	Steps          low.NodeReference[[]low.ValueReference[*Step]]
+	SuccessActions low.NodeReference[[]low.ValueReference[*SuccessAction]]
+	FailureActions low.NodeReference[[]low.ValueReference[*FailureAction]]
+	Outputs        low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[string]]]
+	Parameters     low.NodeReference[[]low.ValueReference[*Parameter]]
+	Extensions     *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+}
+
+// FindExtension returns a ValueReference containing the extension value, if found.
+func (w *Workflow) FindExtension(ext string) *low.ValueReference[*yaml.Node] {
+	return low.FindItemInOrderedMap(ext, w.Extensions)
+}
+
+// GetExtensions returns all Workflow extensions and satisfies the low.HasExtensions interface.
+func (w *Workflow) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]] {
+	return w.Extensions
+}
+
+// FindStep will attempt to locate a Step of the Workflow by its stepId.
+func (w *Workflow) FindStep(stepId string) *Step {
+	for _, s := range w.Steps.Value {
+		if s.Value.StepId.Value == stepId {
+			return s.Value
+		}
+	}
+	return nil
+}
+
+// Build will extract the inputs, steps, actions and parameters of the Workflow.
+func (w *Workflow) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
+	root = utils.NodeAlias(root)
+	utils.CheckForMergeNodes(root)
+	w.Extensions = low.ExtractExtensions(root)
+
+	inputs, err := low.ExtractObject[*base.SchemaProxy](ctx, InputsLabel, root, idx)
+	if err != nil {
+		return err
+	}
+	w.Inputs = inputs
+	if w.Steps, err = extractArray[*Step](ctx, StepsLabel, root, idx); err != nil {
+		return err
+	}
+	if w.SuccessActions, err = extractArray[*SuccessAction](ctx, SuccessActionsLabel, root, idx); err != nil {
+		return err
+	}
+	if w.FailureActions, err = extractArray[*FailureAction](ctx, FailureActionsLabel, root, idx); err != nil {
+		return err
+	}
+	w.Parameters, err = extractArray[*Parameter](ctx, ParametersLabel, root, idx)
+	return err
+}
+
+// Hash will return a consistent SHA256 Hash of the Workflow object
+func (w *Workflow) Hash() [32]byte {
+	var f []string
+	if !w.WorkflowId.IsEmpty() {
+		f = append(f, w.WorkflowId.Value)
+	}
+	if !w.Summary.IsEmpty() {
+		f = append(f, w.Summary.Value)
+	}
+	if !w.Description.IsEmpty() {
+		f = append(f, w.Description.Value)
+	}
+	if !w.Inputs.IsEmpty() {
+		f = append(f, low.GenerateHashString(w.Inputs.Value))
+	}
+	for _, d := range w.DependsOn.Value {
+		f = append(f, d.Value)
+	}
+	f = append(f, hashArray(w.Steps.Value)...)
+	f = append(f, hashArray(w.SuccessActions.Value)...)
+	f = append(f, hashArray(w.FailureActions.Value)...)
+	f = append(f, hashStrings(w.Outputs.Value)...)
+	f = append(f, hashArray(w.Parameters.Value)...)
+	f = append(f, low.HashExtensions(w.Extensions)...)
+	return sha256.Sum256([]byte(strings.Join(f, "|")))
+}
@@ -45,6 +45,10 @@ const (
 	SchemaLabel                = "schema"
 	SchemaTypeLabel            = "$schema"
 	AnchorLabel                = "$anchor"
+	DynamicAnchorLabel         = "$dynamicAnchor"
+	DynamicRefLabel            = "$dynamicRef"
+	IdLabel                    = "$id"
+	DefsLabel                  = "$defs"
 )
 
 /*
@@ -103,6 +103,11 @@ type Schema struct {
 	UnevaluatedItems      low.NodeReference[*SchemaProxy]
 	UnevaluatedProperties low.NodeReference[*SchemaDynamicValue[*SchemaProxy, bool]]
 	Anchor                low.NodeReference[string]
+	DynamicAnchor         low.NodeReference[string]
+	DynamicRef            low.NodeReference[string]
+	DynamicRefSchema      low.NodeReference[*SchemaProxy] // the schema DynamicRef resolves to, in the scope it was built in.
+	Id                    low.NodeReference[string]
+	Defs                  low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*SchemaProxy]]]
 
 	// Compatible with all versions
 	Title                low.NodeReference[string]
@@ -361,6 +366,18 @@ func (s *Schema) Hash() [32]byte {
 	if !s.Anchor.IsEmpty() {
 		d = append(d, fmt.Sprint(s.Anchor.Value))
 	}
+	if !s.DynamicAnchor.IsEmpty() {
+		d = append(d, fmt.Sprint(s.DynamicAnchor.Value))
+	}
+	if !s.DynamicRef.IsEmpty() {
+		d = append(d, fmt.Sprint(s.DynamicRef.Value))
+	}
+	if !s.Id.IsEmpty() {
+		d = append(d, fmt.Sprint(s.Id.Value))
+	}
+	for pair := orderedmap.First(orderedmap.SortAlpha(s.Defs.Value)); pair != nil; pair = pair.Next() {
+		d = append(d, fmt.Sprintf("%s-%s", pair.Key().Value, low.GenerateHashString(pair.Value().Value)))
+	}
 
 	for pair := orderedmap.First(orderedmap.SortAlpha(s.DependentSchemas.Value)); pair != nil; pair = pair.Next() {
 		d = append(d, fmt.Sprintf("%s-%s", pair.Key().Value, low.GenerateHashString(pair.Value().Value)))
@@ -422,6 +439,12 @@ func (s *Schema) FindDependentSchema(name string) *low.ValueReference[*SchemaPro
 	return low.FindItemInOrderedMap[*SchemaProxy](name, s.DependentSchemas.Value)
 }
 
+// FindDef will return a ValueReference pointer containing a SchemaProxy pointer
+// from a $defs key name. if found (3.1+ only)
+func (s *Schema) FindDef(name string) *low.ValueReference[*SchemaProxy] {
+	return low.FindItemInOrderedMap[*SchemaProxy](name, s.Defs.Value)
+}
+
 // FindPatternProperty will return a ValueReference pointer containing a SchemaProxy pointer
 // from a pattern property key name. if found (3.1+ only)
 func (s *Schema) FindPatternProperty(name string) *low.ValueReference[*SchemaProxy] {
@@ -457,11 +480,18 @@ func (s *Schema) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.V
 //   - UnevaluatedItems
 //   - UnevaluatedProperties
 //   - Anchor
+//   - DynamicAnchor
+//   - DynamicRef
+//   - Id
+//   - Defs
 func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecIndex) error {
 	root = utils.NodeAlias(root)
 	utils.CheckForMergeNodes(root)
 	s.Reference = new(low.Reference)
 	s.Index = idx
+
+	// schema resources ('$id') are added to the dynamic scope, before and after following a reference.
+	ctx = idx.EnterSchemaResource(ctx, root)
 	if h, _, _ := utils.IsNodeRefValue(root); h {
 		ref, _, err := low.LocateRefNode(root, idx)
 		if ref != nil {
@@ -477,6 +507,8 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		}
 	}
 
+	ctx = idx.EnterSchemaResource(ctx, root)
+
 	// Build model using possibly dereferenced root
 	if err := low.BuildModel(root, s); err != nil {
 		return err
@@ -613,6 +645,37 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		}
 	}
 
+	// handle dynamic anchor if set. (3.1)
+	_, dynamicAnchorLabel, dynamicAnchorNode := utils.FindKeyNodeFullTop(DynamicAnchorLabel, root.Content)
+	if dynamicAnchorNode != nil {
+		s.DynamicAnchor = low.NodeReference[string]{
+			Value: dynamicAnchorNode.Value, KeyNode: dynamicAnchorLabel, ValueNode: dynamicAnchorNode,
+		}
+	}
+
+	// handle dynamic reference if set, the schema it resolves to depends on the dynamic scope. (3.1)
+	_, dynamicRefLabel, dynamicRefNode := utils.FindKeyNodeFullTop(DynamicRefLabel, root.Content)
+	if dynamicRefNode != nil {
+		s.DynamicRef = low.NodeReference[string]{
+			Value: dynamicRefNode.Value, KeyNode: dynamicRefLabel, ValueNode: dynamicRefNode,
+		}
+		if found := idx.FindDynamicReference(root, index.GetDynamicScope(ctx)); found != nil {
+			sp := new(SchemaProxy)
+			_ = sp.Build(ctx, dynamicRefLabel, found.Node, found.Index)
+			s.DynamicRefSchema = low.NodeReference[*SchemaProxy]{
+				Value: sp, KeyNode: dynamicRefLabel, ValueNode: found.Node,
+			}
+		}
+	}
+
+	// handle id if set. (3.1)
+	_, idLabel, idNode := utils.FindKeyNodeFullTop(IdLabel, root.Content)
+	if idNode != nil {
+		s.Id = low.NodeReference[string]{
+			Value: idNode.Value, KeyNode: idLabel, ValueNode: idNode,
+		}
+	}
+
 	// handle example if set. (3.0)
 	_, expLabel, expNode := utils.FindKeyNodeFullTop(ExampleLabel, root.Content)
 	if expNode != nil {
@@ -701,6 +764,15 @@ func (s *Schema) Build(ctx context.Context, root *yaml.Node, idx *index.SpecInde
 		s.DependentSchemas = *props
 	}
 
+	// handle definitions
+	props, err = buildPropertyMap(ctx, root, idx, DefsLabel)
+	if err != nil {
+		return err
+	}
+	if props != nil {
+		s.Defs = *props
+	}
+
 	// handle pattern properties
 	props, err = buildPropertyMap(ctx, root, idx, PatternPropertiesLabel)
 	if err != nil {
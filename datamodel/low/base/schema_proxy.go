@@ -125,6 +125,11 @@ func (sp *SchemaProxy) GetValueNode() *yaml.Node {
 	return sp.vn
 }
 
+// GetContext returns the context the proxy was built with, it holds the location used to resolve references.
+func (sp *SchemaProxy) GetContext() context.Context {
+	return sp.ctx
+}
+
 // Hash will return a consistent SHA256 Hash of the SchemaProxy object (it will resolve it)
 func (sp *SchemaProxy) Hash() [32]byte {
 	if sp.rendered != nil {
@@ -1861,3 +1861,30 @@ components:
 	assert.Nil(t, res)
 	assert.Equal(t, "schema build failed: reference '[empty]' cannot be found at line 1, col 7", e.Error())
 }
+
+func TestSchema_Build_IdAndDefs(t *testing.T) {
+	left := `schema:
+  $id: https://example.com/pet.json
+  $defs:
+    Tag:
+      type: string`
+
+	right := `schema:
+  $id: https://example.com/pet.json
+  $defs:
+    Tag:
+      type: integer`
+
+	var lNode, rNode yaml.Node
+	_ = yaml.Unmarshal([]byte(left), &lNode)
+	_ = yaml.Unmarshal([]byte(right), &rNode)
+
+	lDoc, _ := ExtractSchema(context.Background(), lNode.Content[0], nil)
+	rDoc, _ := ExtractSchema(context.Background(), rNode.Content[0], nil)
+
+	sch := lDoc.Value.Schema()
+	assert.Equal(t, "https://example.com/pet.json", sch.Id.Value)
+	assert.Equal(t, "string", sch.FindDef("Tag").Value.Schema().Type.Value.A)
+	assert.Nil(t, sch.FindDef("Nope"))
+	assert.False(t, low.AreEqual(sch, rDoc.Value.Schema()))
+}
@@ -74,6 +74,17 @@ func LocateRefNodeWithContext(ctx context.Context, root *yaml.Node, idx *index.S
 				root.Line, root.Column), ctx
 		}
 
+		// a schema resource ('$id') holding a reference is part of the dynamic scope of what it references.
+		ctx = idx.EnterSchemaResource(ctx, root)
+
+		// references resolved against a schema '$id' or naming an '$anchor' are located by their node.
+		if found := idx.FindSchemaReference(root); found != nil && found.Node != root {
+			if jh, _, _ := utils.IsNodeRefValue(found.Node); jh && !IsCircular(found.Node, found.Index) {
+				return LocateRefNodeWithContext(ctx, found.Node, found.Index)
+			}
+			return utils.NodeAlias(found.Node), found.Index, nil, context.WithValue(ctx, index.CurrentPathKey, found.RemoteLocation)
+		}
+
 		// run through everything and return as soon as we find a match.
 		// this operates as fast as possible as ever
 		collections := generateIndexCollection(idx)
@@ -766,7 +777,7 @@ func ExtractMapExtensions[PT Buildable[N], N any](
 				v: v,
 			}, nil
 		}
-		err := datamodel.TranslatePipeline[buildInput, mappingResult[PT]](in, out, translateFunc)
+		err := datamodel.TranslatePipelineWithContext[buildInput, mappingResult[PT]](ctx, in, out, translateFunc)
 		wg.Wait()
 		if err != nil {
 			return nil, labelNode, valueNode, err
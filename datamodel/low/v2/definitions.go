@@ -145,7 +145,7 @@ func (d *Definitions) Build(ctx context.Context, _, root *yaml.Node, idx *index.
 		return definitionResult[*base.SchemaProxy]{k: value.label, v: v}, nil
 	}
 
-	err := datamodel.TranslatePipeline[buildInput, definitionResult[*base.SchemaProxy]](in, out, translateFunc)
+	err := datamodel.TranslatePipelineWithContext[buildInput, definitionResult[*base.SchemaProxy]](ctx, in, out, translateFunc)
 	wg.Wait()
 	if err != nil {
 		return err
@@ -64,7 +64,7 @@ func (h *Header) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecI
 	}
 	h.Items = items
 
-	_, ln, vn := utils.FindKeyNodeFull(DefaultLabel, root.Content)
+	_, ln, vn := utils.FindKeyNodeFullTop(DefaultLabel, root.Content)
 	if vn != nil {
 		h.Default = low.NodeReference[*yaml.Node]{
 			Value:     vn,
@@ -108,7 +108,7 @@ func (i *Items) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIn
 	}
 	i.Items = items
 
-	_, ln, vn := utils.FindKeyNodeFull(DefaultLabel, root.Content)
+	_, ln, vn := utils.FindKeyNodeFullTop(DefaultLabel, root.Content)
 	if vn != nil {
 		i.Default = low.NodeReference[*yaml.Node]{
 			Value:     vn,
@@ -84,6 +84,7 @@ type Parameter struct {
 	Enum             low.NodeReference[[]low.ValueReference[*yaml.Node]]
 	MultipleOf       low.NodeReference[int]
 	Extensions       *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+	*low.Reference
 }
 
 // FindExtension attempts to locate a extension value given a name.
@@ -100,6 +101,7 @@ func (p *Parameter) GetExtensions() *orderedmap.Map[low.KeyReference[string], lo
 func (p *Parameter) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
 	root = utils.NodeAlias(root)
 	utils.CheckForMergeNodes(root)
+	p.Reference = new(low.Reference)
 	p.Extensions = low.ExtractExtensions(root)
 	sch, sErr := base.ExtractSchema(ctx, root, idx)
 	if sErr != nil {
@@ -108,13 +110,16 @@ func (p *Parameter) Build(ctx context.Context, _, root *yaml.Node, idx *index.Sp
 	if sch != nil {
 		p.Schema = *sch
 	}
-	items, iErr := low.ExtractObject[*Items](ctx, ItemsLabel, root, idx)
-	if iErr != nil {
-		return iErr
+	// only extract items defined by the parameter itself, not those of a body parameter schema.
+	if _, _, itemsNode := utils.FindKeyNodeFullTop(ItemsLabel, root.Content); itemsNode != nil {
+		items, iErr := low.ExtractObject[*Items](ctx, ItemsLabel, root, idx)
+		if iErr != nil {
+			return iErr
+		}
+		p.Items = items
 	}
-	p.Items = items
 
-	_, ln, vn := utils.FindKeyNodeFull(DefaultLabel, root.Content)
+	_, ln, vn := utils.FindKeyNodeFullTop(DefaultLabel, root.Content)
 	if vn != nil {
 		p.Default = low.NodeReference[*yaml.Node]{
 			Value:     vn,
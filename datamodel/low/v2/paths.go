@@ -144,7 +144,7 @@ func (p *Paths) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIn
 			},
 		}, nil
 	}
-	err := datamodel.TranslatePipeline[buildInput, pathBuildResult](in, out, translateFunc)
+	err := datamodel.TranslatePipelineWithContext[buildInput, pathBuildResult](ctx, in, out, translateFunc)
 	wg.Wait()
 	if err != nil {
 		return err
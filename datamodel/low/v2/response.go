@@ -26,6 +26,7 @@ type Response struct {
 	Headers     low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*Header]]]
 	Examples    low.NodeReference[*Examples]
 	Extensions  *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
+	*low.Reference
 }
 
 // FindExtension will attempt to locate an extension value given a key to lookup.
@@ -47,6 +48,7 @@ func (r *Response) FindHeader(hType string) *low.ValueReference[*Header] {
 func (r *Response) Build(ctx context.Context, _, root *yaml.Node, idx *index.SpecIndex) error {
 	root = utils.NodeAlias(root)
 	utils.CheckForMergeNodes(root)
+	r.Reference = new(low.Reference)
 	r.Extensions = low.ExtractExtensions(root)
 	s, err := base.ExtractSchema(ctx, root, idx)
 	if err != nil {
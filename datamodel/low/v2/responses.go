@@ -60,6 +60,7 @@ func (r *Responses) getDefault() *low.NodeReference[*Response] {
 	for pair := orderedmap.First(r.Codes); pair != nil; pair = pair.Next() {
 		if strings.ToLower(pair.Key().Value) == DefaultLabel {
 			return &low.NodeReference[*Response]{
+				Reference: pair.Value().Reference,
 				ValueNode: pair.Value().ValueNode,
 				KeyNode:   pair.Key().KeyNode,
 				Value:     pair.Value().Value,
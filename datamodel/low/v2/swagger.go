@@ -131,10 +131,18 @@ func (s *Swagger) GetExtensions() *orderedmap.Map[low.KeyReference[string], low.
 // CreateDocumentFromConfig will create a new Swagger document from the provided SpecInfo and DocumentConfiguration.
 func CreateDocumentFromConfig(info *datamodel.SpecInfo,
 	configuration *datamodel.DocumentConfiguration) (*Swagger, error) {
-	return createDocument(info, configuration)
+	return createDocument(context.Background(), info, configuration)
 }
 
-func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfiguration) (*Swagger, error) {
+// CreateDocumentFromConfigWithContext is the same as CreateDocumentFromConfig, except building stops when the
+// context is cancelled. If the context is cancelled, the document is incomplete, and the error of the context
+// is returned.
+func CreateDocumentFromConfigWithContext(ctx context.Context, info *datamodel.SpecInfo,
+	configuration *datamodel.DocumentConfiguration) (*Swagger, error) {
+	return createDocument(ctx, info, configuration)
+}
+
+func createDocument(ctx context.Context, info *datamodel.SpecInfo, config *datamodel.DocumentConfiguration) (*Swagger, error) {
 	doc := Swagger{Swagger: low.ValueReference[string]{Value: info.Version, ValueNode: info.RootNode}}
 	doc.Extensions = low.ExtractExtensions(info.RootNode.Content[0])
 
@@ -147,6 +155,7 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 	idxConfig.BaseURL = config.BaseURL
 	idxConfig.BasePath = config.BasePath
 	idxConfig.Logger = config.Logger
+	idxConfig.Limits = config.Limits
 	rolodex := index.NewRolodex(idxConfig)
 	rolodex.SetRootNode(info.RootNode)
 	doc.Rolodex = rolodex
@@ -194,11 +203,11 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 	var errs []error
 
 	// index all the things!
-	_ = rolodex.IndexTheRolodex()
+	_ = rolodex.IndexTheRolodexWithContext(ctx)
 
 	// check for circular references
 	if !config.SkipCircularReferenceCheck {
-		rolodex.CheckForCircularReferences()
+		rolodex.CheckForCircularReferencesWithContext(ctx)
 	}
 
 	// extract errors
@@ -207,6 +216,14 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 		errs = append(errs, roloErrs...)
 	}
 
+	// if the context was cancelled, the index is incomplete, so there is nothing to extract.
+	if err := ctx.Err(); err != nil {
+		if !errors.Is(errors.Join(errs...), err) {
+			errs = append(errs, err)
+		}
+		return &doc, errors.Join(errs...)
+	}
+
 	// set the index on the document.
 	doc.Index = rolodex.GetRootIndex()
 	doc.SpecInfo = info
@@ -214,8 +231,6 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 	// build out swagger scalar variables.
 	_ = low.BuildModel(info.RootNode.Content[0], &doc)
 
-	ctx := context.Background()
-
 	// extract externalDocs
 	extDocs, err := low.ExtractObject[*base.ExternalDoc](ctx, base.ExternalDocsLabel, info.RootNode, rolodex.GetRootIndex())
 	if err != nil {
@@ -249,7 +264,9 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 			errs = append(errs, e)
 		}
 	}
-
+	if err := ctx.Err(); err != nil && !errors.Is(errors.Join(errs...), err) {
+		errs = append(errs, err)
+	}
 	return &doc, errors.Join(errs...)
 }
 
@@ -34,6 +34,7 @@ type Components struct {
 	SecuritySchemes low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*SecurityScheme]]]
 	Links           low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*Link]]]
 	Callbacks       low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*Callback]]]
+	PathItems       low.NodeReference[*orderedmap.Map[low.KeyReference[string], low.ValueReference[*PathItem]]]
 	Extensions      *orderedmap.Map[low.KeyReference[string], low.ValueReference[*yaml.Node]]
 	*low.Reference
 }
@@ -65,6 +66,7 @@ func (co *Components) Hash() [32]byte {
 	generateHashForObjectMap(co.SecuritySchemes.Value, &f)
 	generateHashForObjectMap(co.Links.Value, &f)
 	generateHashForObjectMap(co.Callbacks.Value, &f)
+	generateHashForObjectMap(co.PathItems.Value, &f)
 	f = append(f, low.HashExtensions(co.Extensions)...)
 	return sha256.Sum256([]byte(strings.Join(f, "|")))
 }
@@ -121,6 +123,11 @@ func (co *Components) FindCallback(callback string) *low.ValueReference[*Callbac
 	return low.FindItemInOrderedMap[*Callback](callback, co.Callbacks.Value)
 }
 
+// FindPathItem attempts to locate a PathItem from 'pathItems' (3.1+) with a specific name
+func (co *Components) FindPathItem(pathItem string) *low.ValueReference[*PathItem] {
+	return low.FindItemInOrderedMap[*PathItem](pathItem, co.PathItems.Value)
+}
+
 // Build converts root YAML node containing components to low level model.
 // Process each component in parallel.
 func (co *Components) Build(ctx context.Context, root *yaml.Node, idx *index.SpecIndex) error {
@@ -132,7 +139,7 @@ func (co *Components) Build(ctx context.Context, root *yaml.Node, idx *index.Spe
 	var reterr error
 	var ceMutex sync.Mutex
 	var wg sync.WaitGroup
-	wg.Add(9)
+	wg.Add(10)
 
 	captureError := func(err error) {
 		ceMutex.Lock()
@@ -196,6 +203,12 @@ func (co *Components) Build(ctx context.Context, root *yaml.Node, idx *index.Spe
 		co.Callbacks = callbacks
 		wg.Done()
 	}()
+	go func() {
+		pathItems, err := extractComponentValues[*PathItem](ctx, PathItemsLabel, root, idx)
+		captureError(err)
+		co.PathItems = pathItems
+		wg.Done()
+	}()
 
 	wg.Wait()
 	return reterr
@@ -269,8 +282,15 @@ func extractComponentValues[T low.Buildable[N], N any](ctx context.Context, labe
 		// If you're building components as references... pls... stop, this code should not need to be here.
 		// TODO: check circular crazy on this. It may explode
 		var err error
+		foundCtx, foundIdx := ctx, idx
 		if h, _, _ := utils.IsNodeRefValue(node); h && label != SchemasLabel {
-			node, _, err = low.LocateRefNode(node, idx)
+			var fIdx *index.SpecIndex
+			var fCtx context.Context
+			node, fIdx, err, fCtx = low.LocateRefNodeWithContext(ctx, node, idx)
+			if node != nil && fIdx != nil {
+				// relative references inside the located node resolve from where it was found.
+				foundCtx, foundIdx = fCtx, fIdx
+			}
 		}
 		if err != nil {
 			return componentBuildResult[T]{}, err
@@ -278,7 +298,7 @@ func extractComponentValues[T low.Buildable[N], N any](ctx context.Context, labe
 
 		// build.
 		_ = low.BuildModel(node, n)
-		err = n.Build(ctx, currentLabel, node, idx)
+		err = n.Build(foundCtx, currentLabel, node, foundIdx)
 		if err != nil {
 			return componentBuildResult[T]{}, err
 		}
@@ -293,7 +313,7 @@ func extractComponentValues[T low.Buildable[N], N any](ctx context.Context, labe
 			},
 		}, nil
 	}
-	err := datamodel.TranslatePipeline[componentInput, componentBuildResult[T]](in, out, translateFunc)
+	err := datamodel.TranslatePipelineWithContext[componentInput, componentBuildResult[T]](ctx, in, out, translateFunc)
 	wg.Wait()
 	if err != nil {
 		return emptyResult, err
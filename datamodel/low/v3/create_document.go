@@ -3,7 +3,6 @@ package v3
 import (
 	"context"
 	"errors"
-	"path/filepath"
 	"sync"
 
 	"github.com/pb33f/libopenapi/datamodel"
@@ -19,15 +18,25 @@ import (
 // Deprecated: Use CreateDocumentFromConfig instead. This function will be removed in a later version, it
 // defaults to allowing file and remote references, and does not support relative file references.
 func CreateDocument(info *datamodel.SpecInfo) (*Document, error) {
-	return createDocument(info, datamodel.NewDocumentConfiguration())
+	return createDocument(context.Background(), info, datamodel.NewDocumentConfiguration())
 }
 
 // CreateDocumentFromConfig Create a new document from the provided SpecInfo and DocumentConfiguration pointer.
 func CreateDocumentFromConfig(info *datamodel.SpecInfo, config *datamodel.DocumentConfiguration) (*Document, error) {
-	return createDocument(info, config)
+	return createDocument(context.Background(), info, config)
 }
 
-func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfiguration) (*Document, error) {
+// CreateDocumentFromConfigWithContext is the same as CreateDocumentFromConfig, except building stops when the
+// context is cancelled. The context is passed on to the rolodex (and the file systems it loads references from),
+// the resolver and every part of the model that is built in parallel. If the context is cancelled, the document
+// is incomplete, and the error of the context is returned.
+func CreateDocumentFromConfigWithContext(ctx context.Context, info *datamodel.SpecInfo,
+	config *datamodel.DocumentConfiguration,
+) (*Document, error) {
+	return createDocument(ctx, info, config)
+}
+
+func createDocument(ctx context.Context, info *datamodel.SpecInfo, config *datamodel.DocumentConfiguration) (*Document, error) {
 	_, labelNode, versionNode := utils.FindKeyNodeFull(OpenAPILabel, info.RootNode.Content)
 	var version low.NodeReference[string]
 	if versionNode == nil {
@@ -36,70 +45,19 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 	version = low.NodeReference[string]{Value: versionNode.Value, KeyNode: labelNode, ValueNode: versionNode}
 	doc := Document{Version: version}
 
-	// create an index config and shadow the document configuration.
-	idxConfig := index.CreateClosedAPIIndexConfig()
-	idxConfig.SpecInfo = info
-	idxConfig.IgnoreArrayCircularReferences = config.IgnoreArrayCircularReferences
-	idxConfig.IgnorePolymorphicCircularReferences = config.IgnorePolymorphicCircularReferences
-	idxConfig.AvoidCircularReferenceCheck = true
-	idxConfig.BaseURL = config.BaseURL
-	idxConfig.BasePath = config.BasePath
-	idxConfig.Logger = config.Logger
-	rolodex := index.NewRolodex(idxConfig)
-	<-info.GetJSONParsingChannel() // Need to wait for JSON parsing to complete before we can index.
-	rolodex.SetRootNode(info.RootNode)
+	// create the rolodex, with an index config that shadows the document configuration.
+	rolodex := index.NewDocumentRolodex(info, config).Rolodex
 	doc.Rolodex = rolodex
 
-	// If basePath is provided, add a local filesystem to the rolodex.
-	if idxConfig.BasePath != "" || config.AllowFileReferences {
-		var cwd string
-		cwd, _ = filepath.Abs(config.BasePath)
-		// if a supplied local filesystem is provided, add it to the rolodex.
-		if config.LocalFS != nil {
-			rolodex.AddLocalFS(cwd, config.LocalFS)
-		} else {
-
-			// create a local filesystem
-			localFSConf := index.LocalFSConfig{
-				BaseDirectory: cwd,
-				IndexConfig:   idxConfig,
-				FileFilters:   config.FileFilter,
-			}
-
-			fileFS, _ := index.NewLocalFSWithConfig(&localFSConf)
-			idxConfig.AllowFileLookup = true
-
-			// add the filesystem to the rolodex
-			rolodex.AddLocalFS(cwd, fileFS)
-		}
-	}
-	// if base url is provided, add a remote filesystem to the rolodex.
-	if idxConfig.BaseURL != nil || config.AllowRemoteReferences {
-
-		// create a remote filesystem
-		remoteFS, _ := index.NewRemoteFSWithConfig(idxConfig)
-		if config.RemoteURLHandler != nil {
-			remoteFS.RemoteHandlerFunc = config.RemoteURLHandler
-		}
-		idxConfig.AllowRemoteLookup = true
-
-		// add to the rolodex
-		u := "default"
-		if config.BaseURL != nil {
-			u = config.BaseURL.String()
-		}
-		rolodex.AddRemoteFS(u, remoteFS)
-	}
-
 	// index the rolodex
 	var errs []error
 
 	// index all the things.
-	_ = rolodex.IndexTheRolodex()
+	_ = rolodex.IndexTheRolodexWithContext(ctx)
 
 	// check for circular references
 	if !config.SkipCircularReferenceCheck {
-		rolodex.CheckForCircularReferences()
+		rolodex.CheckForCircularReferencesWithContext(ctx)
 	}
 
 	// extract errors
@@ -108,6 +66,14 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 		errs = append(errs, roloErrs...)
 	}
 
+	// if the context was cancelled, the index is incomplete, so there is nothing to extract.
+	if err := ctx.Err(); err != nil {
+		if !errors.Is(errors.Join(errs...), err) {
+			errs = append(errs, err)
+		}
+		return &doc, errors.Join(errs...)
+	}
+
 	// set root index.
 	doc.Index = rolodex.GetRootIndex()
 	var wg sync.WaitGroup
@@ -143,13 +109,14 @@ func createDocument(info *datamodel.SpecInfo, config *datamodel.DocumentConfigur
 		extractWebhooks,
 	}
 
-	ctx := context.Background()
-
 	wg.Add(len(extractionFuncs))
 	for _, f := range extractionFuncs {
 		go runExtraction(ctx, info, &doc, rolodex.GetRootIndex(), f, &errs, &wg)
 	}
 	wg.Wait()
+	if err := ctx.Err(); err != nil && !errors.Is(errors.Join(errs...), err) {
+		errs = append(errs, err)
+	}
 	return &doc, errors.Join(errs...)
 }
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/pb33f/libopenapi/index"
@@ -884,3 +885,33 @@ func ExampleCreateDocument() {
 	fmt.Print(document.Info.Value.Contact.Value.Email.Value)
 	// Output: apiteam@swagger.io
 }
+
+func TestCreateDocument_Components_FileReferences(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "responses"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "responses", "error.yaml"), []byte(`description: failed
+content:
+  application/json:
+    schema:
+      $ref: error-schema.yaml`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "responses", "error-schema.yaml"),
+		[]byte("type: object\ndescription: an error"), 0o644))
+
+	info, _ := datamodel.ExtractSpecInfo([]byte(`openapi: 3.1.0
+components:
+  responses:
+    Error:
+      $ref: responses/error.yaml`))
+	cf := datamodel.NewDocumentConfiguration()
+	cf.BasePath = dir
+	lDoc, err := CreateDocumentFromConfig(info, cf)
+	require.NoError(t, err)
+
+	// references inside the referenced response resolve from the file the response is in.
+	errResponse := lDoc.Components.Value.FindResponse("Error")
+	require.NotNil(t, errResponse)
+	assert.Equal(t, "failed", errResponse.Value.Description.Value)
+	schema := errResponse.Value.FindContent("application/json").Value.Schema.Value.Schema()
+	require.NotNil(t, schema)
+	assert.Equal(t, "an error", schema.Description.Value)
+}
@@ -290,7 +290,7 @@ func (p *PathItem) Build(ctx context.Context, _, root *yaml.Node, idx *index.Spe
 		}
 		return nil, nil
 	}
-	err := datamodel.TranslateSliceParallel[low.NodeReference[*Operation], any](ops, translateFunc, nil)
+	err := datamodel.TranslateSliceParallelWithContext[low.NodeReference[*Operation], any](ctx, ops, translateFunc, nil)
 	if err != nil {
 		return err
 	}
@@ -152,7 +152,7 @@ func extractPathItemsMap(ctx context.Context, root *yaml.Node, idx *index.SpecIn
 		wg.Done()
 	}()
 
-	err := datamodel.TranslatePipeline[buildInput, buildResult](in, out,
+	err := datamodel.TranslatePipelineWithContext[buildInput, buildResult](ctx, in, out,
 		func(value buildInput) (buildResult, error) {
 			pNode := value.pathNode
 			cNode := value.currentNode
@@ -47,8 +47,11 @@ func (si SpecInfo) GetJSONParsingChannel() chan bool {
 	return si.JsonParsingChannel
 }
 
+// ExtractSpecInfoWithConfig is the same as ExtractSpecInfoWithDocumentCheck, except the document check is bypassed
+// using the BypassDocumentCheck of the configuration, and the specification is checked against its Limits. If a
+// limit is exceeded, a *LimitError is returned.
 func ExtractSpecInfoWithConfig(spec []byte, config *DocumentConfiguration) (*SpecInfo, error) {
-	return ExtractSpecInfoWithDocumentCheck(spec, config.BypassDocumentCheck)
+	return extractSpecInfo(spec, config.BypassDocumentCheck, config.Limits)
 }
 
 func ExtractSpecInfoWithDocumentCheckSync(spec []byte, bypass bool) (*SpecInfo, error) {
@@ -61,6 +64,14 @@ func ExtractSpecInfoWithDocumentCheckSync(spec []byte, bypass bool) (*SpecInfo,
 }
 
 func ExtractSpecInfoWithDocumentCheck(spec []byte, bypass bool) (*SpecInfo, error) {
+	return extractSpecInfo(spec, bypass, nil)
+}
+
+func extractSpecInfo(spec []byte, bypass bool, limits *Limits) (*SpecInfo, error) {
+	if err := limits.CheckBytes(len(spec)); err != nil {
+		return nil, err
+	}
+
 	var parsedSpec yaml.Node
 
 	specInfo := &SpecInfo{}
@@ -85,6 +96,11 @@ func ExtractSpecInfoWithDocumentCheck(spec []byte, bypass bool) (*SpecInfo, erro
 		return nil, fmt.Errorf("unable to parse specification: %s", err.Error())
 	}
 
+	// check the limits before anything walks (or decodes) the tree.
+	if err = limits.CheckNode(&parsedSpec); err != nil {
+		return nil, err
+	}
+
 	specInfo.RootNode = &parsedSpec
 
 	_, openAPI3 := utils.FindKeyNode(utils.OpenApi3, parsedSpec.Content)
@@ -178,6 +194,29 @@ func ExtractSpecInfoWithDocumentCheck(spec []byte, bypass bool) (*SpecInfo, erro
 			}
 		}
 
+		// only check the top level for arazzo, it's a common enough word to be found as a property.
+		if specInfo.SpecType == "" && len(parsedSpec.Content) > 0 {
+			if _, arazzo := utils.FindKeyNodeTop(utils.Arazzo, parsedSpec.Content[0].Content); arazzo != nil {
+				version, majorVersion, versionErr := parseVersionTypeData(arazzo.Value)
+				if versionErr != nil {
+					return nil, versionErr
+				}
+
+				specInfo.SpecType = utils.Arazzo
+				specInfo.SpecFormat = Arazzo1
+				specInfo.Version = version
+				specInfo.VersionNumeric = float32(majorVersion)
+
+				// parse JSON
+				go parseJSON(spec, specInfo, &parsedSpec)
+
+				if majorVersion != 1 {
+					specInfo.Error = errors.New("spec is defined as arazzo, but has a major version that is invalid")
+					return specInfo, specInfo.Error
+				}
+			}
+		}
+
 		if specInfo.SpecType == "" {
 			// parse JSON
 			go parseJSON(spec, specInfo, &parsedSpec)
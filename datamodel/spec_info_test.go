@@ -321,3 +321,22 @@ func TestExtractSpecInfoSync_Error(t *testing.T) {
 	_, e := ExtractSpecInfoWithDocumentCheckSync([]byte(random), true)
 	assert.Error(t, e)
 }
+
+func TestExtractSpecInfo_Arazzo(t *testing.T) {
+	r, e := ExtractSpecInfo([]byte("arazzo: 1.0.1\ninfo:\n  title: workflows"))
+	assert.Nil(t, e)
+	assert.Equal(t, utils.Arazzo, r.SpecType)
+	assert.Equal(t, Arazzo1, r.SpecFormat)
+	assert.Equal(t, "1.0.1", r.Version)
+	<-r.JsonParsingChannel
+	assert.NotEmpty(t, r.SpecJSONBytes)
+}
+
+func TestExtractSpecInfo_BadVersion_Arazzo(t *testing.T) {
+	_, err := ExtractSpecInfo([]byte("arazzo: 2.0.0"))
+	assert.Error(t, err)
+
+	// arazzo is only detected at the top level.
+	_, err = ExtractSpecInfo([]byte("name:\n  arazzo: 1.0.0"))
+	assert.Error(t, err)
+}
@@ -43,11 +43,17 @@ type pipelineJobStatus[IN any, OUT any] struct {
 // translate() or result() may return `io.EOF` to break iteration.
 // Results are provided sequentially to result() in stable order from slice.
 func TranslateSliceParallel[IN any, OUT any](in []IN, translate TranslateSliceFunc[IN, OUT], result ActionFunc[OUT]) error {
+	return TranslateSliceParallelWithContext(context.Background(), in, translate, result)
+}
+
+// TranslateSliceParallelWithContext is the same as TranslateSliceParallel, except iteration stops when the
+// context is cancelled, and the error of the context is returned.
+func TranslateSliceParallelWithContext[IN any, OUT any](parent context.Context, in []IN, translate TranslateSliceFunc[IN, OUT], result ActionFunc[OUT]) error {
 	if in == nil {
 		return nil
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 	concurrency := runtime.NumCPU()
 	jobChan := make(chan *jobStatus[OUT], concurrency)
@@ -120,6 +126,9 @@ JOBLOOP:
 	if reterr == io.EOF {
 		return nil
 	}
+	if reterr == nil {
+		return parent.Err()
+	}
 	return reterr
 }
 
@@ -129,11 +138,17 @@ JOBLOOP:
 // Safely handles nil pointer.
 // Results are provided sequentially to result() in stable order from `*orderedmap.Map`.
 func TranslateMapParallel[K comparable, V any, RV any](m *orderedmap.Map[K, V], translate TranslateFunc[orderedmap.Pair[K, V], RV], result ResultFunc[RV]) error {
+	return TranslateMapParallelWithContext(context.Background(), m, translate, result)
+}
+
+// TranslateMapParallelWithContext is the same as TranslateMapParallel, except iteration stops when the
+// context is cancelled, and the error of the context is returned.
+func TranslateMapParallelWithContext[K comparable, V any, RV any](parent context.Context, m *orderedmap.Map[K, V], translate TranslateFunc[orderedmap.Pair[K, V], RV], result ResultFunc[RV]) error {
 	if m == nil {
 		return nil
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 	concurrency := runtime.NumCPU()
 	c := orderedmap.Iterate(ctx, m)
@@ -203,6 +218,9 @@ JOBLOOP:
 	if reterr == io.EOF {
 		return nil
 	}
+	if reterr == nil {
+		return parent.Err()
+	}
 	return reterr
 }
 
@@ -212,7 +230,13 @@ JOBLOOP:
 // Caller must close `in` channel to indicate EOF.
 // TranslatePipeline closes `out` channel to indicate EOF.
 func TranslatePipeline[IN any, OUT any](in <-chan IN, out chan<- OUT, translate TranslateFunc[IN, OUT]) error {
-	ctx, cancel := context.WithCancel(context.Background())
+	return TranslatePipelineWithContext(context.Background(), in, out, translate)
+}
+
+// TranslatePipelineWithContext is the same as TranslatePipeline, except processing stops when the context is
+// cancelled, and the error of the context is returned.
+func TranslatePipelineWithContext[IN any, OUT any](parent context.Context, in <-chan IN, out chan<- OUT, translate TranslateFunc[IN, OUT]) error {
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 	concurrency := runtime.NumCPU()
 	workChan := make(chan *pipelineJobStatus[IN, OUT])
@@ -301,9 +325,15 @@ func TranslatePipeline[IN any, OUT any](in <-chan IN, out chan<- OUT, translate
 			}
 			out <- j.result
 		case <-ctx.Done():
+			if reterr == nil {
+				return parent.Err()
+			}
 			return reterr
 		}
 	}
 
+	if reterr == nil {
+		return parent.Err()
+	}
 	return reterr
 }
@@ -1,6 +1,7 @@
 package datamodel_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -525,3 +526,99 @@ func TestTranslatePipeline(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateWithContext(t *testing.T) {
+	const itemCount = 1000
+
+	t.Run("Slice cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		in := make([]int, itemCount)
+		var resultCounter int
+		err := datamodel.TranslateSliceParallelWithContext[int, int](ctx, in,
+			func(i int, v int) (int, error) { return i, nil },
+			func(_ int) error {
+				resultCounter++
+				if resultCounter == 10 {
+					cancel()
+				}
+				return nil
+			})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, resultCounter, itemCount)
+	})
+
+	t.Run("Map cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		m := orderedmap.New[string, int]()
+		for i := 0; i < itemCount; i++ {
+			m.Set(fmt.Sprintf("key%d", i), i)
+		}
+		var resultCounter int
+		err := datamodel.TranslateMapParallelWithContext[string, int, int](ctx, m,
+			func(pair orderedmap.Pair[string, int]) (int, error) { return pair.Value(), nil },
+			func(_ int) error {
+				resultCounter++
+				if resultCounter == 10 {
+					cancel()
+				}
+				return nil
+			})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, resultCounter, itemCount)
+	})
+
+	t.Run("Pipeline cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		in := make(chan int)
+		out := make(chan int)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2) // input and output goroutines.
+
+		go func() {
+			defer func() {
+				close(in)
+				wg.Done()
+			}()
+			for i := 0; i < itemCount; i++ {
+				select {
+				case in <- i:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		var resultCounter int
+		go func() {
+			for range out {
+				resultCounter++
+				if resultCounter == 10 {
+					cancel()
+				}
+			}
+			close(done)
+			wg.Done()
+		}()
+
+		err := datamodel.TranslatePipelineWithContext[int, int](ctx, in, out, func(v int) (int, error) { return v, nil })
+		wg.Wait()
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, resultCounter, itemCount)
+	})
+
+	t.Run("Not cancelled", func(t *testing.T) {
+		var resultCounter int
+		err := datamodel.TranslateSliceParallelWithContext[int, int](context.Background(), make([]int, itemCount),
+			func(i int, v int) (int, error) { return i, nil },
+			func(_ int) error {
+				resultCounter++
+				return nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, itemCount, resultCounter)
+	})
+}
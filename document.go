@@ -14,10 +14,12 @@
 package libopenapi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/jsonschema"
 
 	"github.com/pb33f/libopenapi/datamodel"
 	v2high "github.com/pb33f/libopenapi/datamodel/high/v2"
@@ -73,9 +75,8 @@ type Document interface {
 	// references to the old model will be lost. The second return is the new Document that was created, and the third
 	// return is any errors hit trying to re-render.
 	//
-	// **IMPORTANT** This method only supports OpenAPI Documents. The Swagger model will not support mutations correctly
-	// and will not update when called. This choice has been made because we don't want to continue supporting Swagger,
-	// it's too old, so it should be motivation to upgrade to OpenAPI 3.
+	// Swagger documents are supported, the new document has its Swagger model built, which is returned by calling
+	// BuildV2Model on it. The third return is always nil for a Swagger document.
 	RenderAndReload() ([]byte, Document, *DocumentModel[v3high.Document], []error)
 
 	// Render will render the high level model as it currently exists (including any mutations, additions
@@ -90,7 +91,7 @@ type Document interface {
 	// 'reload' the model into memory, so that line numbers and column numbers are correct and the index is accurate.
 	// However, if you don't care about the low-level model, and you're not using the index, and you just want to
 	// print the state of the model as it currently exists, then Render() is the method to use.
-	// **IMPORTANT** This method only supports OpenAPI Documents.
+	// Both OpenAPI and Swagger documents are supported, a model must have been built before rendering.
 	Render() ([]byte, error)
 
 	// Serialize will re-render a Document back into a []byte slice. If any modifications have been made to the
@@ -104,6 +105,72 @@ type Document interface {
 	Serialize() ([]byte, error)
 }
 
+// DocumentValidator is implemented by every Document created by this package. It is kept apart from Document, so
+// types implementing Document outside of this package are not broken by it. Reach it with a type assertion:
+//
+//	errs, err := doc.(libopenapi.DocumentValidator).Validate()
+type DocumentValidator interface {
+	// Validate will validate the specification against the JSON Schema for its version (Swagger 2.0, OpenAPI 3.0 or
+	// OpenAPI 3.1) and return every structural problem found. Each *jsonschema.ValidationError carries the line
+	// and column of the rejected value in the specification.
+	//
+	// References to other files are followed using the Rolodex, and the referenced content is validated in place of
+	// the reference. Errors found in those files have their Location set to the file (or URL) they came from. If a
+	// model has not yet been built, one will be built so the Rolodex can load the referenced files.
+	//
+	// An error is only returned if the document cannot be validated at all. No validation errors means the
+	// specification is valid.
+	Validate() ([]*jsonschema.ValidationError, error)
+}
+
+// ContextModelBuilder is implemented by every Document created by this package, it builds models that can be
+// cancelled. It is kept apart from Document for the same reason as DocumentValidator:
+//
+//	model, errs := doc.(libopenapi.ContextModelBuilder).BuildV3ModelWithContext(ctx)
+type ContextModelBuilder interface {
+	// BuildV2ModelWithContext is the same as BuildV2Model, except building stops when the context is cancelled.
+	// The context is passed on to the Rolodex (and the file systems it loads references from), the resolver and
+	// every part of the model that is built in parallel. If the context is cancelled, no model is returned, and the
+	// errors will contain the error of the context.
+	BuildV2ModelWithContext(ctx context.Context) (*DocumentModel[v2high.Swagger], []error)
+
+	// BuildV3ModelWithContext is the same as BuildV3Model, except building stops when the context is cancelled.
+	// The context is passed on to the Rolodex (and the file systems it loads references from), the resolver and
+	// every part of the model that is built in parallel. If the context is cancelled, no model is returned, and the
+	// errors will contain the error of the context.
+	BuildV3ModelWithContext(ctx context.Context) (*DocumentModel[v3high.Document], []error)
+}
+
+// ObjectReloader is implemented by every Document created by this package, it reloads a single object of the
+// high level model. It is kept apart from Document for the same reason as DocumentValidator:
+//
+//	spec, err := doc.(libopenapi.ObjectReloader).RenderAndReloadObject(pathItem)
+type ObjectReloader interface {
+	// RenderAndReloadObject is a faster alternative to RenderAndReload, when a single object of the high level model
+	// has been changed. The object can be a *v3.PathItem (from paths or webhooks), or a schema
+	// (*base.SchemaProxy or *base.Schema), response, parameter, example, request body, header, security scheme,
+	// link or callback from components.
+	//
+	// Only the object is re-rendered. The rendered object replaces the original in the specification bytes, and in
+	// the yaml.Node tree, every line number that follows it is moved and the index is patched, without the
+	// specification being parsed or indexed again. The low and high level versions of the object are re-built in
+	// place, so the pointer that was passed in stays valid (anything held from inside the object must be fetched
+	// again). The new specification bytes are returned.
+	//
+	// The original formatting (YAML or JSON, and the indentation) of the specification is kept. Objects that are
+	// references cannot be reloaded. Circular references are not checked again, and the JSON version of the
+	// specification held by the SpecInfo is not updated.
+	//
+	// **IMPORTANT** This method only supports OpenAPI Documents.
+	RenderAndReloadObject(object any) ([]byte, error)
+}
+
+var (
+	_ DocumentValidator   = &document{}
+	_ ContextModelBuilder = &document{}
+	_ ObjectReloader      = &document{}
+)
+
 type document struct {
 	rolodex           *index.Rolodex
 	version           string
@@ -150,19 +217,51 @@ func NewDocumentWithTypeCheck(specByteArray []byte, bypassCheck bool) (Document,
 
 // NewDocumentWithConfiguration is the same as NewDocument, except it's a convenience function that calls NewDocument
 // under the hood and then calls SetConfiguration() on the returned Document.
+//
+// If the configuration sets Limits, the specification is checked against them, and a *datamodel.LimitError is
+// returned if any limit is exceeded.
 func NewDocumentWithConfiguration(specByteArray []byte, configuration *datamodel.DocumentConfiguration) (Document, error) {
-	var d Document
-	var err error
-	if configuration != nil && configuration.BypassDocumentCheck {
-		d, err = NewDocumentWithTypeCheck(specByteArray, true)
-	} else {
-		d, err = NewDocument(specByteArray)
+	if configuration == nil {
+		return NewDocument(specByteArray)
+	}
+	info, err := datamodel.ExtractSpecInfoWithConfig(specByteArray, configuration)
+	if err != nil {
+		return nil, err
 	}
+	d := new(document)
+	d.version = info.Version
+	d.info = info
+	d.SetConfiguration(configuration)
+	return d, nil
+}
 
-	if d != nil {
-		d.SetConfiguration(configuration)
+// NewDocumentWithContext is the same as NewDocumentWithConfiguration, except it gives up when the context is
+// cancelled, returning the error of the context. Parsing a specification cannot be interrupted, so a specification
+// that is still being parsed when the context is cancelled is abandoned, and left to finish in the background.
+//
+// The context is only used to create the document, pass it to BuildV3ModelWithContext (or BuildV2ModelWithContext)
+// of the ContextModelBuilder to be able to cancel building a model.
+func NewDocumentWithContext(ctx context.Context, specByteArray []byte,
+	configuration *datamodel.DocumentConfiguration,
+) (Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type created struct {
+		doc Document
+		err error
+	}
+	done := make(chan created, 1)
+	go func() {
+		d, err := NewDocumentWithConfiguration(specByteArray, configuration)
+		done <- created{doc: d, err: err}
+	}()
+	select {
+	case c := <-done:
+		return c.doc, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return d, err
 }
 
 func (d *document) GetRolodex() *index.Rolodex {
@@ -208,6 +307,17 @@ func (d *document) RenderAndReload() ([]byte, Document, *DocumentModel[v3high.Do
 
 	newDoc, err := NewDocumentWithConfiguration(newBytes, d.config)
 	errs = append(errs, err)
+	if err != nil || newDoc == nil {
+		return newBytes, newDoc, nil, errs
+	}
+
+	// a swagger document is rebuilt as a swagger model, which can be fetched from the new document.
+	if d.highOpenAPI3Model == nil {
+		if _, buildErrs := newDoc.BuildV2Model(); buildErrs != nil {
+			return newBytes, newDoc, nil, buildErrs
+		}
+		return newBytes, newDoc, nil, nil
+	}
 
 	// build the model.
 	m, buildErrs := newDoc.BuildV3Model()
@@ -219,8 +329,8 @@ func (d *document) RenderAndReload() ([]byte, Document, *DocumentModel[v3high.Do
 }
 
 func (d *document) Render() ([]byte, error) {
-	if d.highSwaggerModel != nil && d.highOpenAPI3Model == nil {
-		return nil, errors.New("this method only supports OpenAPI 3 documents, not Swagger")
+	if d.highOpenAPI3Model == nil && d.highSwaggerModel == nil {
+		return nil, errors.New("unable to render, no model has been built")
 	}
 
 	var newBytes []byte
@@ -233,19 +343,34 @@ func (d *document) Render() ([]byte, error) {
 				jsonIndent += " "
 			}
 		}
-		newBytes = d.highOpenAPI3Model.Model.RenderJSON(jsonIndent)
+		if d.highOpenAPI3Model != nil {
+			newBytes = d.highOpenAPI3Model.Model.RenderJSON(jsonIndent)
+		} else {
+			newBytes = d.highSwaggerModel.Model.RenderJSON(jsonIndent)
+		}
 	}
 	if d.info.SpecFileType == datamodel.YAMLFileType {
-		newBytes = d.highOpenAPI3Model.Model.RenderWithIndention(d.info.OriginalIndentation)
+		if d.highOpenAPI3Model != nil {
+			newBytes = d.highOpenAPI3Model.Model.RenderWithIndention(d.info.OriginalIndentation)
+		} else {
+			newBytes = d.highSwaggerModel.Model.RenderWithIndention(d.info.OriginalIndentation)
+		}
 	}
 
 	return newBytes, nil
 }
 
 func (d *document) BuildV2Model() (*DocumentModel[v2high.Swagger], []error) {
+	return d.BuildV2ModelWithContext(context.Background())
+}
+
+func (d *document) BuildV2ModelWithContext(ctx context.Context) (*DocumentModel[v2high.Swagger], []error) {
 	if d.highSwaggerModel != nil {
 		return d.highSwaggerModel, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, []error{err}
+	}
 	var errs []error
 	if d.info == nil {
 		errs = append(errs, fmt.Errorf("unable to build swagger document, no specification has been loaded"))
@@ -263,13 +388,18 @@ func (d *document) BuildV2Model() (*DocumentModel[v2high.Swagger], []error) {
 	}
 
 	var docErr error
-	lowDoc, docErr = v2low.CreateDocumentFromConfig(d.info, d.config)
+	lowDoc, docErr = v2low.CreateDocumentFromConfigWithContext(ctx, d.info, d.config)
 	d.rolodex = lowDoc.Rolodex
 
 	if docErr != nil {
 		errs = append(errs, utils.UnwrapErrors(docErr)...)
 	}
 
+	// a cancelled build is incomplete.
+	if ctx.Err() != nil {
+		return nil, errs
+	}
+
 	// Do not short-circuit on circular reference errors, so the client
 	// has the option of ignoring them.
 	for _, err := range errs {
@@ -282,6 +412,11 @@ func (d *document) BuildV2Model() (*DocumentModel[v2high.Swagger], []error) {
 	}
 	highDoc := v2high.NewSwaggerDocument(lowDoc)
 
+	// the high level model is built without the context, a build cancelled meanwhile is not kept.
+	if err := ctx.Err(); err != nil {
+		return nil, append(errs, err)
+	}
+
 	d.highSwaggerModel = &DocumentModel[v2high.Swagger]{
 		Model: *highDoc,
 		Index: lowDoc.Index,
@@ -290,9 +425,16 @@ func (d *document) BuildV2Model() (*DocumentModel[v2high.Swagger], []error) {
 }
 
 func (d *document) BuildV3Model() (*DocumentModel[v3high.Document], []error) {
+	return d.BuildV3ModelWithContext(context.Background())
+}
+
+func (d *document) BuildV3ModelWithContext(ctx context.Context) (*DocumentModel[v3high.Document], []error) {
 	if d.highOpenAPI3Model != nil {
 		return d.highOpenAPI3Model, nil
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, []error{err}
+	}
 	var errs []error
 	if d.info == nil {
 		errs = append(errs, fmt.Errorf("unable to build document, no specification has been loaded"))
@@ -313,13 +455,18 @@ func (d *document) BuildV3Model() (*DocumentModel[v3high.Document], []error) {
 	}
 
 	var docErr error
-	lowDoc, docErr = v3low.CreateDocumentFromConfig(d.info, d.config)
+	lowDoc, docErr = v3low.CreateDocumentFromConfigWithContext(ctx, d.info, d.config)
 	d.rolodex = lowDoc.Rolodex
 
 	if docErr != nil {
 		errs = append(errs, utils.UnwrapErrors(docErr)...)
 	}
 
+	// a cancelled build is incomplete.
+	if ctx.Err() != nil {
+		return nil, errs
+	}
+
 	// Do not short-circuit on circular reference errors, so the client
 	// has the option of ignoring them.
 	for _, err := range utils.UnwrapErrors(docErr) {
@@ -333,6 +480,11 @@ func (d *document) BuildV3Model() (*DocumentModel[v3high.Document], []error) {
 
 	highDoc := v3high.NewDocument(lowDoc)
 
+	// the high level model is built without the context, a build cancelled meanwhile is not kept.
+	if err := ctx.Err(); err != nil {
+		return nil, append(errs, err)
+	}
+
 	d.highOpenAPI3Model = &DocumentModel[v3high.Document]{
 		Model: *highDoc,
 		Index: lowDoc.Index,
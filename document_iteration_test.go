@@ -18,7 +18,7 @@ type loopFrame struct {
 	Restricted bool
 }
 
-type context struct {
+type iterationContext struct {
 	visited []string
 	stack   []loopFrame
 }
@@ -54,7 +54,7 @@ func Test_Speakeasy_Document_Iteration(t *testing.T) {
 	for pair := orderedmap.First(m.Model.Components.Schemas); pair != nil; pair = pair.Next() {
 		t.Log(pair.Key())
 
-		handleSchema(t, pair.Value(), context{})
+		handleSchema(t, pair.Value(), iterationContext{})
 	}
 }
 
@@ -69,7 +69,7 @@ func iterateOperations(t *testing.T, ops *orderedmap.Map[string, *v3.Operation])
 			t.Log("param", i, param.Name)
 
 			if param.Schema != nil {
-				handleSchema(t, param.Schema, context{})
+				handleSchema(t, param.Schema, iterationContext{})
 			}
 		}
 
@@ -82,7 +82,7 @@ func iterateOperations(t *testing.T, ops *orderedmap.Map[string, *v3.Operation])
 				mediaType := pair.Value()
 
 				if mediaType.Schema != nil {
-					handleSchema(t, mediaType.Schema, context{})
+					handleSchema(t, mediaType.Schema, iterationContext{})
 				}
 			}
 		}
@@ -100,7 +100,7 @@ func iterateOperations(t *testing.T, ops *orderedmap.Map[string, *v3.Operation])
 				mediaType := contentPair.Value()
 
 				if mediaType.Schema != nil {
-					handleSchema(t, mediaType.Schema, context{})
+					handleSchema(t, mediaType.Schema, iterationContext{})
 				}
 			}
 		}
@@ -121,7 +121,7 @@ func iterateOperations(t *testing.T, ops *orderedmap.Map[string, *v3.Operation])
 	}
 }
 
-func handleSchema(t *testing.T, schProxy *base.SchemaProxy, ctx context) {
+func handleSchema(t *testing.T, schProxy *base.SchemaProxy, ctx iterationContext) {
 	if checkCircularReference(t, &ctx, schProxy) {
 		return
 	}
@@ -214,7 +214,7 @@ func getResolvedType(sch *base.Schema) (string, []string) {
 	return "oneOf", subTypes
 }
 
-func handleAllOfAnyOfOneOf(t *testing.T, sch *base.Schema, ctx context) {
+func handleAllOfAnyOfOneOf(t *testing.T, sch *base.Schema, ctx iterationContext) {
 	var schemas []*base.SchemaProxy
 
 	switch {
@@ -233,7 +233,7 @@ func handleAllOfAnyOfOneOf(t *testing.T, sch *base.Schema, ctx context) {
 	}
 }
 
-func handleArray(t *testing.T, sch *base.Schema, ctx context) {
+func handleArray(t *testing.T, sch *base.Schema, ctx iterationContext) {
 	ctx.stack = append(ctx.stack, loopFrame{Type: "array", Restricted: sch.MinItems != nil && *sch.MinItems > 0})
 
 	if sch.Items != nil && sch.Items.IsA() {
@@ -251,7 +251,7 @@ func handleArray(t *testing.T, sch *base.Schema, ctx context) {
 	}
 }
 
-func handleObject(t *testing.T, sch *base.Schema, ctx context) {
+func handleObject(t *testing.T, sch *base.Schema, ctx iterationContext) {
 	for pair := orderedmap.First(sch.Properties); pair != nil; pair = pair.Next() {
 		ctx.stack = append(ctx.stack, loopFrame{Type: "object", Restricted: slices.Contains(sch.Required, pair.Key())})
 		handleSchema(t, pair.Value(), ctx)
@@ -262,7 +262,7 @@ func handleObject(t *testing.T, sch *base.Schema, ctx context) {
 	}
 }
 
-func checkCircularReference(t *testing.T, ctx *context, schProxy *base.SchemaProxy) bool {
+func checkCircularReference(t *testing.T, ctx *iterationContext, schProxy *base.SchemaProxy) bool {
 	loopRef := getSimplifiedRef(schProxy.GetReference())
 
 	if loopRef != "" {
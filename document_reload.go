@@ -0,0 +1,406 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package libopenapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	highbase "github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	v3low "github.com/pb33f/libopenapi/datamodel/low/v3"
+	"github.com/pb33f/libopenapi/index"
+	libjson "github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// reloadTarget is an object of the high-level model that can be re-rendered and reloaded on its own.
+type reloadTarget struct {
+	keyNode     *yaml.Node
+	valueNode   *yaml.Node
+	isReference bool
+	render      func() (*yaml.Node, error)
+	rebuild     func(ctx context.Context, idx *index.SpecIndex) error
+}
+
+func (d *document) RenderAndReloadObject(object any) ([]byte, error) {
+	if d.highOpenAPI3Model == nil {
+		if d.highSwaggerModel != nil {
+			return nil, errors.New("this method only supports OpenAPI 3 documents, not Swagger")
+		}
+		return nil, errors.New("unable to reload object, the OpenAPI 3 model has not been built")
+	}
+	target := d.locateReloadTarget(object)
+	if target == nil {
+		return nil, fmt.Errorf("unable to reload object, %T is not a path item, webhook or component of the document", object)
+	}
+	// a component that is a reference is built from the node it references.
+	if target.isReference || valueOfKey(d.info.RootNode, target.keyNode) != target.valueNode {
+		return nil, errors.New("unable to reload object, it is a reference to another object")
+	}
+	node := target.valueNode
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("unable to reload object at line %d, column %d, only objects can be reloaded",
+			node.Line, node.Column)
+	}
+
+	rendered, err := target.render()
+	if err != nil {
+		return nil, err
+	}
+	newLines, err := d.renderObjectLines(rendered, node)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(*d.info.SpecBytes), "\n")
+	last, suffix, err := objectExtent(lines, d.info.RootNode, node)
+	if err != nil {
+		return nil, err
+	}
+	first := []rune(lines[node.Line-1])
+	prefix := string(first[:node.Column-1])
+
+	// the replacement is parsed as it will sit in the specification, so every line and column is where it will be.
+	var patch strings.Builder
+	patch.WriteString(strings.Repeat("\n", node.Line-1))
+	patch.WriteString(strings.Repeat(" ", node.Column-1))
+	patch.WriteString(strings.Join(newLines, "\n"))
+	var replacement yaml.Node
+	if err = yaml.Unmarshal([]byte(patch.String()), &replacement); err != nil {
+		return nil, fmt.Errorf("unable to reload object, the rendered object cannot be parsed: %w", err)
+	}
+	if len(replacement.Content) == 0 {
+		return nil, errors.New("unable to reload object, the rendered object is empty")
+	}
+
+	newLines[0] = prefix + newLines[0]
+	newLines[len(newLines)-1] += suffix
+	spliced := append(append(append([]string{}, lines[:node.Line-1]...), newLines...), lines[last:]...)
+	lineDelta := len(newLines) - (last - node.Line + 1)
+
+	idx := d.highOpenAPI3Model.Index
+	if err = idx.PatchNode(node, replacement.Content[0], lineDelta); err != nil {
+		return nil, err
+	}
+	newBytes := []byte(strings.Join(spliced, "\n"))
+	*d.info.SpecBytes = newBytes
+
+	if err = target.rebuild(context.Background(), idx); err != nil {
+		return newBytes, err
+	}
+	return newBytes, nil
+}
+
+// renderObjectLines renders an object in the format of the specification, ready to replace an existing node.
+func (d *document) renderObjectLines(rendered *yaml.Node, node *yaml.Node) ([]string, error) {
+	var out string
+	switch {
+	case d.info.SpecFileType == datamodel.JSONFileType:
+		jsonIndent := "  "
+		for l := 0; l < d.info.OriginalIndentation-2; l++ {
+			jsonIndent += " "
+		}
+		b, err := libjson.YAMLNodeToJSON(rendered, jsonIndent)
+		if err != nil {
+			return nil, err
+		}
+		out = string(b)
+	case node.Style&yaml.FlowStyle != 0:
+		// a flow object in a YAML document stays on a single line.
+		b, err := libjson.YAMLNodeToJSON(rendered, "")
+		if err != nil {
+			return nil, err
+		}
+		var compact bytes.Buffer
+		if err = json.Compact(&compact, b); err != nil {
+			return nil, err
+		}
+		out = compact.String()
+	default:
+		indent := d.info.OriginalIndentation
+		if indent <= 0 {
+			indent = 2
+		}
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(indent)
+		if err := enc.Encode(rendered); err != nil {
+			return nil, err
+		}
+		out = strings.TrimSuffix(buf.String(), "\n")
+	}
+
+	lines := strings.Split(out, "\n")
+	var indent string
+	if d.info.SpecFileType == datamodel.JSONFileType {
+		line := d.specLine(node.Line)
+		indent = line[:len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+	} else {
+		indent = strings.Repeat(" ", node.Column-1)
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return lines, nil
+}
+
+func (d *document) specLine(line int) string {
+	lines := strings.Split(string(*d.info.SpecBytes), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return lines[line-1]
+}
+
+// valueOfKey finds the value of a key node in a tree.
+func valueOfKey(root, key *yaml.Node) *yaml.Node {
+	seen := make(map[*yaml.Node]bool)
+	var walk func(n *yaml.Node) *yaml.Node
+	walk = func(n *yaml.Node) *yaml.Node {
+		if seen[n] {
+			return nil
+		}
+		seen[n] = true
+		for i, c := range n.Content {
+			if c == key && n.Kind == yaml.MappingNode && i+1 < len(n.Content) {
+				return n.Content[i+1]
+			}
+			if found := walk(c); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+// objectExtent returns the last line of a node in the specification, and anything that follows the node on that line.
+func objectExtent(lines []string, root, node *yaml.Node) (int, string, error) {
+	if node.Line < 1 || node.Line > len(lines) {
+		return 0, "", fmt.Errorf("unable to reload object, line %d is not part of the specification", node.Line)
+	}
+	if node.Style&yaml.FlowStyle != 0 {
+		return flowExtent(lines, node)
+	}
+
+	// a block ends where the next node that is not part of it starts.
+	inside := make(map[*yaml.Node]bool)
+	var collect func(n *yaml.Node)
+	collect = func(n *yaml.Node) {
+		if inside[n] {
+			return
+		}
+		inside[n] = true
+		for _, c := range n.Content {
+			collect(c)
+		}
+	}
+	collect(node)
+	lastInside := 0
+	for n := range inside {
+		if n.Line > lastInside {
+			lastInside = n.Line
+		}
+	}
+	next := len(lines) + 1
+	seen := make(map[*yaml.Node]bool)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		if !inside[n] && n.Line > lastInside && n.Line < next {
+			next = n.Line
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	last := next - 1
+	for last > lastInside {
+		trimmed := strings.TrimSpace(lines[last-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		last--
+	}
+	return last, "", nil
+}
+
+// flowExtent finds the bracket that closes a flow (or JSON) node.
+func flowExtent(lines []string, node *yaml.Node) (int, string, error) {
+	depth := 0
+	var quote rune
+	escaped := false
+	for l := node.Line - 1; l < len(lines); l++ {
+		line := []rune(lines[l])
+		start := 0
+		if l == node.Line-1 {
+			start = node.Column - 1
+		}
+		for c := start; c < len(line); c++ {
+			r := line[c]
+			switch {
+			case escaped:
+				escaped = false
+			case quote != 0:
+				if r == '\\' && quote == '"' {
+					escaped = true
+				} else if r == quote {
+					quote = 0
+				}
+			case r == '"' || r == '\'':
+				quote = r
+			case r == '{' || r == '[':
+				depth++
+			case r == '}' || r == ']':
+				depth--
+				if depth == 0 {
+					return l + 1, string(line[c+1:]), nil
+				}
+			}
+		}
+	}
+	return 0, "", fmt.Errorf("unable to reload object, the object at line %d, column %d is not closed",
+		node.Line, node.Column)
+}
+
+// locateReloadTarget finds an object in the paths, webhooks or components of the high-level model.
+func (d *document) locateReloadTarget(object any) *reloadTarget {
+	model := &d.highOpenAPI3Model.Model
+	comp := model.Components
+	var lowComp *v3low.Components
+	if comp != nil {
+		lowComp = comp.GoLow()
+	}
+	switch obj := object.(type) {
+	case *v3high.PathItem:
+		if model.Paths != nil && model.Paths.GoLow() != nil {
+			if t := findReloadTarget(obj, model.Paths.PathItems, model.Paths.GoLow().PathItems, v3high.NewPathItem); t != nil {
+				return t
+			}
+		}
+		if model.GoLow() != nil {
+			return findReloadTarget(obj, model.Webhooks, model.GoLow().Webhooks.Value, v3high.NewPathItem)
+		}
+	case *highbase.Schema:
+		if obj != nil && obj.ParentProxy != nil {
+			return d.locateReloadTarget(obj.ParentProxy)
+		}
+	case *highbase.SchemaProxy:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Schemas, lowComp.Schemas.Value,
+				func(sp *lowbase.SchemaProxy) *highbase.SchemaProxy {
+					return highbase.NewSchemaProxy(&low.NodeReference[*lowbase.SchemaProxy]{
+						Value:     sp,
+						ValueNode: sp.GetValueNode(),
+					})
+				})
+		}
+	case *v3high.Response:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Responses, lowComp.Responses.Value, v3high.NewResponse)
+		}
+	case *v3high.Parameter:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Parameters, lowComp.Parameters.Value, v3high.NewParameter)
+		}
+	case *highbase.Example:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Examples, lowComp.Examples.Value, highbase.NewExample)
+		}
+	case *v3high.RequestBody:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.RequestBodies, lowComp.RequestBodies.Value, v3high.NewRequestBody)
+		}
+	case *v3high.Header:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Headers, lowComp.Headers.Value, v3high.NewHeader)
+		}
+	case *v3high.SecurityScheme:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.SecuritySchemes, lowComp.SecuritySchemes.Value, v3high.NewSecurityScheme)
+		}
+	case *v3high.Link:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Links, lowComp.Links.Value, v3high.NewLink)
+		}
+	case *v3high.Callback:
+		if lowComp != nil {
+			return findReloadTarget(obj, comp.Callbacks, lowComp.Callbacks.Value, v3high.NewCallback)
+		}
+	}
+	return nil
+}
+
+// findReloadTarget finds a high-level object in a map, and the low-level object it was built from.
+func findReloadTarget[T any, N low.Buildable[T], H any](obj *H, highMap *orderedmap.Map[string, *H],
+	lowMap *orderedmap.Map[low.KeyReference[string], low.ValueReference[N]], newHigh func(N) *H,
+) *reloadTarget {
+	if obj == nil {
+		return nil
+	}
+	for pair := orderedmap.First(highMap); pair != nil; pair = pair.Next() {
+		if pair.Value() != obj {
+			continue
+		}
+		for lowPair := orderedmap.First(lowMap); lowPair != nil; lowPair = lowPair.Next() {
+			if lowPair.Key().Value != pair.Key() {
+				continue
+			}
+			key, value := lowPair.Key(), lowPair.ValuePtr()
+			return &reloadTarget{
+				keyNode:     key.KeyNode,
+				valueNode:   value.ValueNode,
+				isReference: value.IsReference(),
+				render: func() (*yaml.Node, error) {
+					m, ok := any(obj).(yaml.Marshaler)
+					if !ok {
+						return nil, fmt.Errorf("unable to reload object, %T cannot be rendered", obj)
+					}
+					rendered, err := m.MarshalYAML()
+					if err != nil {
+						return nil, err
+					}
+					if n, ok := rendered.(*yaml.Node); ok {
+						return n, nil
+					}
+					var n yaml.Node
+					if err = n.Encode(rendered); err != nil {
+						return nil, err
+					}
+					return &n, nil
+				},
+				rebuild: func(ctx context.Context, idx *index.SpecIndex) error {
+					n := N(new(T))
+					if err := low.BuildModel(value.ValueNode, n); err != nil {
+						return err
+					}
+					if err := n.Build(ctx, key.KeyNode, value.ValueNode, idx); err != nil {
+						return err
+					}
+					value.Value = n
+					*obj = *newHigh(n)
+					return nil
+				},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package libopenapi
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertReloaded checks a reloaded document has the same lines as the same specification, loaded from scratch.
+func assertReloaded(t *testing.T, m *DocumentModel[v3high.Document], reloaded []byte) {
+	doc, err := NewDocument(reloaded)
+	require.NoError(t, err)
+	fresh, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+
+	assert.Equal(t, fresh.Index.GetLinesWithReferences(), m.Index.GetLinesWithReferences())
+	assert.Equal(t, fresh.Index.GetOperationCount(), m.Index.GetOperationCount())
+	freshSchemas := fresh.Index.GetAllComponentSchemas()
+	for k, s := range m.Index.GetAllComponentSchemas() {
+		require.NotNil(t, freshSchemas[k], k)
+		assert.Equal(t, freshSchemas[k].Node.Line, s.Node.Line, k)
+	}
+	for pair := fresh.Model.Paths.PathItems.First(); pair != nil; pair = pair.Next() {
+		pathItem := m.Model.Paths.PathItems.GetOrZero(pair.Key())
+		require.NotNil(t, pathItem)
+		assert.Equal(t, pair.Value().GoLow().Get.KeyNode.Line, pathItem.GoLow().Get.KeyNode.Line)
+		assert.Equal(t, orderedmap.Len(pair.Value().GetOperations()), orderedmap.Len(pathItem.GetOperations()))
+	}
+}
+
+func TestDocument_RenderAndReloadObject(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: burgers
+paths:
+  /burgers:
+    get:
+      description: list burgers
+      responses:
+        "200":
+          description: ok
+  /fries:
+    get:
+      responses:
+        "200":
+          $ref: '#/components/responses/Fries'
+components:
+  responses:
+    Fries:
+      description: fries
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+    Fries:
+      type: string
+`
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+
+	burgers := m.Model.Paths.PathItems.GetOrZero("/burgers")
+	burgers.Post = &v3high.Operation{Description: "make burgers"}
+	reloaded, err := doc.(ObjectReloader).RenderAndReloadObject(burgers)
+	require.NoError(t, err)
+	assert.Equal(t, `openapi: 3.1.0
+info:
+  title: burgers
+paths:
+  /burgers:
+    get:
+      description: list burgers
+      responses:
+        "200":
+          description: ok
+    post:
+      description: make burgers
+  /fries:
+    get:
+      responses:
+        "200":
+          $ref: '#/components/responses/Fries'
+components:
+  responses:
+    Fries:
+      description: fries
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+    Fries:
+      type: string
+`, string(reloaded))
+	assert.Equal(t, reloaded, *doc.GetSpecInfo().SpecBytes)
+	assert.Equal(t, 11, burgers.GoLow().Post.KeyNode.Line)
+	assert.Equal(t, 3, m.Index.GetOperationCount())
+	assertReloaded(t, m, reloaded)
+
+	burger := m.Model.Components.Schemas.GetOrZero("Burger")
+	burger.Schema().Description = "a tasty burger"
+	burger.Schema().Properties.Delete("name")
+	reloaded, err = doc.(ObjectReloader).RenderAndReloadObject(burger.Schema())
+	require.NoError(t, err)
+	assert.Contains(t, string(reloaded), `
+    Burger:
+      type: object
+      description: a tasty burger
+    Fries:
+      type: string
+`)
+	assert.Equal(t, "a tasty burger", burger.Schema().Description)
+	assert.Equal(t, 24, burger.GoLow().GetValueNode().Line)
+	assertReloaded(t, m, reloaded)
+
+	fries := m.Model.Components.Responses.GetOrZero("Fries")
+	fries.Description = "crispy fries"
+	reloaded, err = doc.(ObjectReloader).RenderAndReloadObject(fries)
+	require.NoError(t, err)
+	assert.Contains(t, string(reloaded), "      description: crispy fries\n")
+	assertReloaded(t, m, reloaded)
+}
+
+func TestDocument_RenderAndReloadObject_JSON(t *testing.T) {
+	spec := `{
+  "openapi": "3.1.0",
+  "info": {
+    "title": "burgers"
+  },
+  "paths": {
+    "/burgers": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "ok",
+            "content": {
+              "application/json": {
+                "schema": {"$ref": "#/components/schemas/Burger"}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Burger": {"type": "object"},
+      "Fries": {
+        "type": "string"
+      }
+    }
+  }
+}`
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+
+	burgers := m.Model.Paths.PathItems.GetOrZero("/burgers")
+	burgers.Get.Description = "list burgers"
+	reloaded, err := doc.(ObjectReloader).RenderAndReloadObject(burgers)
+	require.NoError(t, err)
+	assert.Contains(t, string(reloaded), `        },
+        "description": "list burgers"
+      }
+    }
+  },`)
+	assert.Equal(t, 21, burgers.Get.GoLow().Description.ValueNode.Line)
+	assertReloaded(t, m, reloaded)
+
+	burger := m.Model.Components.Schemas.GetOrZero("Burger")
+	burger.Schema().Title = "burger"
+	reloaded, err = doc.(ObjectReloader).RenderAndReloadObject(burger)
+	require.NoError(t, err)
+	assert.Contains(t, string(reloaded), `      "Burger": {
+        "type": "object",
+        "title": "burger"
+      },
+      "Fries": {`)
+	assert.Equal(t, "burger", burger.Schema().Title)
+	assert.Equal(t, 29, burger.Schema().GoLow().Title.ValueNode.Line)
+	assertReloaded(t, m, reloaded)
+}
+
+func TestDocument_RenderAndReloadObject_Errors(t *testing.T) {
+	doc, err := NewDocument([]byte(`openapi: 3.1.0
+components:
+  responses:
+    Burger:
+      $ref: '#/components/responses/Fries'
+    Fries:
+      description: fries`))
+	require.NoError(t, err)
+	_, err = doc.(ObjectReloader).RenderAndReloadObject(&base.SchemaProxy{})
+	assert.Equal(t, "unable to reload object, the OpenAPI 3 model has not been built", err.Error())
+
+	m, _ := doc.BuildV3Model()
+	_, err = doc.(ObjectReloader).RenderAndReloadObject(m.Model.Components.Responses.GetOrZero("Burger"))
+	assert.Equal(t, "unable to reload object, it is a reference to another object", err.Error())
+	_, err = doc.(ObjectReloader).RenderAndReloadObject(&base.SchemaProxy{})
+	assert.Equal(t, "unable to reload object, *base.SchemaProxy is not a path item, webhook or component of the document",
+		err.Error())
+
+	swagger, err := NewDocument([]byte(`swagger: 2.0`))
+	require.NoError(t, err)
+	_, _ = swagger.BuildV2Model()
+	_, err = swagger.(ObjectReloader).RenderAndReloadObject(&v3high.PathItem{})
+	assert.Equal(t, "this method only supports OpenAPI 3 documents, not Swagger", err.Error())
+}
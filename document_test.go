@@ -4,12 +4,18 @@ package libopenapi
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pb33f/libopenapi/datamodel"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
@@ -423,21 +429,113 @@ func TestDocument_Render_ChangeCheck_Burgershop(t *testing.T) {
 func TestDocument_RenderAndReload_Swagger(t *testing.T) {
 	petstore, _ := os.ReadFile("test_specs/petstorev2.json")
 	doc, _ := NewDocument(petstore)
-	doc.BuildV2Model()
-	doc.BuildV2Model()
-	_, _, _, e := doc.RenderAndReload()
-	assert.Len(t, e, 1)
-	assert.Equal(t, "this method only supports OpenAPI 3 documents, not Swagger", e[0].Error())
+	m, _ := doc.BuildV2Model()
+	m.Model.Info.Title = "Burger Shop"
+	m.Model.Paths.PathItems.GetOrZero("/pet").Post.Summary = "Add a new burger to the shop"
+
+	rend, newDoc, v3Model, e := doc.RenderAndReload()
+	assert.Nil(t, e)
+	assert.Nil(t, v3Model)
+	assert.NotNil(t, rend)
+
+	newModel, errs := newDoc.BuildV2Model()
+	assert.Empty(t, errs)
+	assert.Equal(t, "Burger Shop", newModel.Model.Info.Title)
+	assert.Equal(t, "Add a new burger to the shop", newModel.Model.Paths.PathItems.GetOrZero("/pet").Post.Summary)
+	assert.Equal(t, orderedmap.Len(m.Model.Definitions.Definitions), orderedmap.Len(newModel.Model.Definitions.Definitions))
+	assert.Equal(t, datamodel.JSONFileType, newDoc.GetSpecInfo().SpecFileType)
+}
+
+func TestDocument_RenderAndReload_Swagger_ReloadFails(t *testing.T) {
+	spec := `swagger: "2.0"
+info:
+  title: burgers
+  version: 1.0.0
+paths: {}`
+	config := datamodel.NewDocumentConfiguration()
+	config.Limits = &datamodel.Limits{MaxInputBytes: len(spec) + 10}
+	doc, err := NewDocumentWithConfiguration([]byte(spec), config)
+	require.NoError(t, err)
+	m, errs := doc.BuildV2Model()
+	require.Empty(t, errs)
+
+	// the rendered document is too big to be loaded again.
+	m.Model.Info.Description = strings.Repeat("tasty ", 10)
+	rend, newDoc, v3Model, errs := doc.RenderAndReload()
+	assert.NotNil(t, rend)
+	assert.Nil(t, newDoc)
+	assert.Nil(t, v3Model)
+	require.NotEmpty(t, errs)
+	assert.ErrorIs(t, errs[0], datamodel.ErrLimitExceeded)
+}
+
+func TestDocument_RenderAndReload_Swagger_BuildErrors(t *testing.T) {
+	spec := `swagger: "2.0"
+info:
+  title: burgers
+  version: 1.0.0
+paths: {}
+definitions:
+  Burger:
+    type: object
+    required: [bun]
+    properties:
+      bun:
+        $ref: '#/definitions/Burger'`
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	m, errs := doc.BuildV2Model()
+	require.NotNil(t, m)
+	require.Len(t, errs, 1)
+
+	// only the errors of building the new model are returned, loading it did not fail.
+	_, newDoc, _, errs := doc.RenderAndReload()
+	assert.NotNil(t, newDoc)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "infinite circular reference detected: Burger")
 }
 
 func TestDocument_Render_Swagger(t *testing.T) {
-	petstore, _ := os.ReadFile("test_specs/petstorev2.json")
-	doc, _ := NewDocument(petstore)
-	doc.BuildV2Model()
-	doc.BuildV2Model()
+	spec := `swagger: "2.0"
+info:
+  title: burgers
+  version: 1.0.0
+x-burger: tasty
+paths:
+  /burgers:
+    get:
+      parameters:
+        - $ref: '#/parameters/limit'
+      responses:
+        "200":
+          description: burgers
+          schema:
+            $ref: '#/definitions/Burger'
+parameters:
+  limit:
+    name: limit
+    in: query
+    type: integer
+definitions:
+  Burger:
+    type: object
+`
+	doc, _ := NewDocument([]byte(spec))
 	_, e := doc.Render()
-	assert.Error(t, e)
-	assert.Equal(t, "this method only supports OpenAPI 3 documents, not Swagger", e.Error())
+	assert.Equal(t, "unable to render, no model has been built", e.Error())
+
+	m, _ := doc.BuildV2Model()
+	rend, e := doc.Render()
+	assert.NoError(t, e)
+	assert.Equal(t, spec, string(rend))
+
+	m.Model.Paths.PathItems.GetOrZero("/burgers").Get.Description = "list burgers"
+	rend, e = doc.Render()
+	assert.NoError(t, e)
+	assert.Contains(t, string(rend), `          schema:
+            $ref: '#/definitions/Burger'
+      description: list burgers
+parameters:`)
 }
 
 func TestDocument_BuildModelPreBuild(t *testing.T) {
@@ -1249,3 +1347,186 @@ func TestDocument_LoadDocTwice(t *testing.T) {
 	_, errs = doc.BuildV3Model()
 	require.Empty(t, errs)
 }
+
+func TestDocument_BuildV3ModelWithContext(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewDocumentWithContext(ctx, []byte(spec), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	doc, err := NewDocumentWithContext(context.Background(), []byte(spec), nil)
+	require.NoError(t, err)
+
+	m, errs := doc.(ContextModelBuilder).BuildV3ModelWithContext(ctx)
+	assert.Nil(t, m)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+
+	// a cancelled build is not kept, so the model can still be built.
+	m, errs = doc.(ContextModelBuilder).BuildV3ModelWithContext(context.Background())
+	require.Empty(t, errs)
+	assert.Equal(t, 1, m.Model.Paths.PathItems.Len())
+
+	_, errs = doc.(ContextModelBuilder).BuildV2ModelWithContext(context.Background())
+	assert.Len(t, errs, 1)
+}
+
+func TestDocument_BuildV3ModelWithContext_RemoteTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// never respond, until the client gives up.
+		select {
+		case <-req.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Burger:
+      $ref: '` + srv.URL + `/burgers.yaml#/components/schemas/Burger'`
+
+	u, _ := url.Parse(srv.URL)
+	doc, err := NewDocumentWithConfiguration([]byte(spec), &datamodel.DocumentConfiguration{
+		BaseURL:               u,
+		AllowRemoteReferences: true,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	m, errs := doc.(ContextModelBuilder).BuildV3ModelWithContext(ctx)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	assert.Nil(t, m)
+	assert.ErrorIs(t, errors.Join(errs...), context.DeadlineExceeded)
+}
+
+func TestDocument_BuildV2ModelWithContext(t *testing.T) {
+	doc, err := NewDocument([]byte(`swagger: 2.0.1`))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m, errs := doc.(ContextModelBuilder).BuildV2ModelWithContext(ctx)
+	assert.Nil(t, m)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+
+	m, errs = doc.(ContextModelBuilder).BuildV2ModelWithContext(context.Background())
+	assert.Empty(t, errs)
+	assert.NotNil(t, m)
+}
+
+func TestNewDocumentWithConfiguration_Limits(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: burgers
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: burgers`
+
+	doc, err := NewDocumentWithConfiguration([]byte(spec), &datamodel.DocumentConfiguration{
+		Limits: &datamodel.Limits{MaxDepth: 4},
+	})
+	assert.Nil(t, doc)
+	assert.ErrorIs(t, err, datamodel.ErrLimitExceeded)
+	assert.Equal(t, "limit exceeded: specification exceeds MaxDepth (4)", err.Error())
+
+	doc, err = NewDocumentWithConfiguration([]byte(spec), &datamodel.DocumentConfiguration{
+		Limits: &datamodel.Limits{MaxDepth: 6, MaxNodeCount: 100, MaxInputBytes: 1024},
+	})
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	assert.Empty(t, errs)
+	assert.Equal(t, "burgers", m.Model.Info.Title)
+}
+
+func TestDocument_SchemaIdentifiersAndDynamicRefs(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        owner:
+          $ref: '#person'
+        tags:
+          $ref: https://example.com/schemas/string-list
+    Person:
+      $anchor: person
+      type: object
+      properties:
+        name:
+          type: string
+    List:
+      $id: https://example.com/schemas/list
+      $defs:
+        itemType:
+          $dynamicAnchor: itemType
+          description: any item
+      type: array
+      items:
+        $dynamicRef: '#itemType'
+    StringList:
+      $id: https://example.com/schemas/string-list
+      $ref: list
+      $defs:
+        itemType:
+          $dynamicAnchor: itemType
+          type: string`
+
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	schemas := model.Model.Components.Schemas
+
+	pet := schemas.GetOrZero("Pet").Schema()
+	owner := pet.Properties.GetOrZero("owner").Schema()
+	require.NotNil(t, owner)
+	assert.Equal(t, "person", owner.Anchor)
+	assert.Equal(t, "string", owner.Properties.GetOrZero("name").Schema().Type[0])
+
+	// the generic list resolves its items to the schema it defines.
+	items := schemas.GetOrZero("List").Schema().Items.A.Schema()
+	assert.Equal(t, "#itemType", items.DynamicRef)
+	require.NotNil(t, items.DynamicRefSchema)
+	assert.Equal(t, "any item", items.DynamicRefSchema.Schema().Description)
+
+	// entered through the string list, the items are strings.
+	for _, list := range []*base.Schema{schemas.GetOrZero("StringList").Schema(), pet.Properties.GetOrZero("tags").Schema()} {
+		require.NotNil(t, list)
+		items = list.Items.A.Schema()
+		require.NotNil(t, items.DynamicRefSchema)
+		assert.Equal(t, "string", items.DynamicRefSchema.Schema().Type[0])
+	}
+
+	// dynamic references are rendered as they are, not resolved.
+	out, err := schemas.GetOrZero("List").Schema().Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "$dynamicRef: '#itemType'")
+}
@@ -0,0 +1,340 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package exploder is the inverse of the bundler package. It splits a single OpenAPI 3+ document into a multi-file
+// layout, writing path items and components out into their own files, with relative references between them.
+//
+// Where each object is written is decided by a Layout. The DirectoryLayout is provided as a sensible default,
+// it writes every path item into a 'paths' directory and every component into a directory named after its type.
+package exploder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Layout decides which file every path item and component of an exploded document is written to. All returned
+// file paths are relative to the root document and must use forward slashes. Returning an empty string keeps the
+// object inside the root document.
+type Layout interface {
+	// PathItemFile returns the file a path item (keyed by its path, e.g. '/pets/{id}') is written to.
+	PathItemFile(path string) string
+
+	// ComponentFile returns the file a component is written to, the component type is the key used in
+	// the components object (e.g. 'schemas', 'parameters') and the name is the component name.
+	ComponentFile(componentType, name string) string
+}
+
+// DirectoryLayout is the default Layout. Path items are written to the PathsDirectory and components are
+// written to a directory named after the component type, inside the ComponentsDirectory.
+//
+// For example, the schema 'Pet' is written to 'components/schemas/Pet.yaml' and the path '/pets/{id}' is
+// written to 'paths/pets_id.yaml'.
+type DirectoryLayout struct {
+	// PathsDirectory is where path items are written, defaults to 'paths'.
+	PathsDirectory string
+
+	// ComponentsDirectory is where components are written, defaults to 'components'.
+	ComponentsDirectory string
+
+	// ComponentTypes limits which component types are exploded (e.g. 'schemas', 'parameters'). If empty,
+	// every component type is exploded.
+	ComponentTypes []string
+
+	// SkipPaths will keep all path items inside the root document.
+	SkipPaths bool
+
+	// FileExtension of every file written, defaults to '.yaml'. Use '.json' to write JSON files.
+	FileExtension string
+}
+
+const explodedMarker = "#exploded:"
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9.\-_]+`)
+
+// PathItemFile returns the file a path item is written to.
+func (l *DirectoryLayout) PathItemFile(p string) string {
+	if l.SkipPaths {
+		return ""
+	}
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(strings.Trim(p, "/"), "_"), "_")
+	if name == "" {
+		name = "root"
+	}
+	return path.Join(defaultString(l.PathsDirectory, "paths"), name+l.extension())
+}
+
+// ComponentFile returns the file a component is written to.
+func (l *DirectoryLayout) ComponentFile(componentType, name string) string {
+	if len(l.ComponentTypes) > 0 {
+		found := false
+		for _, t := range l.ComponentTypes {
+			if t == componentType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ""
+		}
+	}
+	return path.Join(defaultString(l.ComponentsDirectory, "components"), componentType,
+		unsafeFileChars.ReplaceAllString(name, "_")+l.extension())
+}
+
+func (l *DirectoryLayout) extension() string {
+	return defaultString(l.FileExtension, ".yaml")
+}
+
+// ExplodeConfig is used to configure how a document is exploded.
+type ExplodeConfig struct {
+	// Layout decides where every path item and component is written, defaults to a DirectoryLayout.
+	Layout Layout
+
+	// RootFileName is the name of the root document, defaults to 'openapi.yaml'. If the name ends with '.json'
+	// then the root document is rendered as JSON.
+	RootFileName string
+
+	// Indentation used when rendering every file, defaults to 2.
+	Indentation int
+}
+
+// ExplodeDocument will split a high-level Document into multiple files according to the configured Layout. The
+// returned map is keyed by the file path (relative to the root document), with the rendered bytes as the value.
+// The root document is always included under the RootFileName.
+//
+// Every local reference in the document is re-written to a relative reference that points to the file the
+// referenced object has been moved to. Components stay listed in the components object of the root document,
+// as references to their new files.
+func ExplodeDocument(doc *v3.Document, config *ExplodeConfig) (map[string][]byte, error) {
+	if doc == nil {
+		return nil, errors.New("unable to explode, document is nil")
+	}
+	config = defaultConfig(config)
+	rendered, err := doc.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := rendered.(*yaml.Node)
+	if !ok || !utils.IsNodeMap(root) {
+		return nil, errors.New("unable to explode, document did not render into an object")
+	}
+
+	// rendered nodes can be shared with the low-level model (and with each other), so they are copied before
+	// any of them are re-written.
+	root = utils.CopyNode(root)
+
+	e := &exploder{
+		config:   config,
+		files:    map[string]*yaml.Node{config.RootFileName: root},
+		pointers: make(map[string]string),
+	}
+	if err = e.split(root); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]byte, len(e.files))
+	for f, n := range e.files {
+		e.rewrite(n, f)
+		if out[f], err = json.RenderNode(n, strings.EqualFold(path.Ext(f), ".json"), config.Indentation); err != nil {
+			return nil, fmt.Errorf("unable to render '%s': %w", f, err)
+		}
+	}
+	return out, nil
+}
+
+// WriteExplodedDocument will explode a high-level Document using ExplodeDocument, and write every file out into
+// the supplied directory, creating any directories required by the Layout.
+func WriteExplodedDocument(doc *v3.Document, directory string, config *ExplodeConfig) error {
+	files, err := ExplodeDocument(doc, config)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for f := range files {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	for _, f := range names {
+		p := filepath.Join(directory, filepath.FromSlash(f))
+		if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err = os.WriteFile(p, files[f], 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type exploder struct {
+	config *ExplodeConfig
+	files  map[string]*yaml.Node
+
+	// pointers maps a JSON pointer into the root document, to the file the pointed at node has been moved to.
+	pointers map[string]string
+}
+
+// split will move every path item and component into its own file, leaving a reference behind.
+func (e *exploder) split(root *yaml.Node) error {
+	_, paths := utils.FindKeyNodeTop("paths", root.Content)
+	if paths != nil {
+		for i := 0; i < len(paths.Content)-1; i += 2 {
+			pointer := "/paths/" + utils.EscapePointerSegment(paths.Content[i].Value)
+			if err := e.move(paths.Content[i+1], pointer, e.config.Layout.PathItemFile(paths.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	}
+	_, comps := utils.FindKeyNodeTop("components", root.Content)
+	if comps != nil {
+		for i := 0; i < len(comps.Content)-1; i += 2 {
+			cType := comps.Content[i].Value
+			if !utils.IsNodeMap(comps.Content[i+1]) {
+				continue
+			}
+			entries := comps.Content[i+1].Content
+			for j := 0; j < len(entries)-1; j += 2 {
+				pointer := fmt.Sprintf("/components/%s/%s", cType, utils.EscapePointerSegment(entries[j].Value))
+				if err := e.move(entries[j+1], pointer, e.config.Layout.ComponentFile(cType, entries[j].Value)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// move will copy the node into a new file and turn the original node into a reference to that file.
+func (e *exploder) move(node *yaml.Node, pointer, file string) error {
+	if file == "" {
+		return nil
+	}
+	if strings.HasPrefix(file, "/") || strings.HasPrefix(path.Clean(file), "..") {
+		return fmt.Errorf("unable to explode '%s', file '%s' is outside of the root document directory", pointer, file)
+	}
+	file = path.Join(path.Dir(e.config.RootFileName), file)
+
+	// a node that is just a reference to another object does not need a file of its own.
+	if isReference(node) {
+		return nil
+	}
+	if _, exists := e.files[file]; exists {
+		ext := path.Ext(file)
+		base := strings.TrimSuffix(file, ext)
+		for i := 1; ; i++ {
+			candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+			if _, taken := e.files[candidate]; !taken {
+				file = candidate
+				break
+			}
+		}
+	}
+	moved := *node
+	e.files[file] = &moved
+	e.pointers[pointer] = file
+
+	// the reference is marked, so it can be made relative to the root document once all files are known.
+	*node = *utils.CreateRefNode(explodedMarker + file)
+	return nil
+}
+
+// rewrite will walk through every node in a file and re-write every local reference into a reference relative
+// to the file that contains it.
+func (e *exploder) rewrite(node *yaml.Node, file string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			if node.Content[i].Value == "$ref" && utils.IsNodeStringValue(node.Content[i+1]) {
+				node.Content[i+1].Value = e.resolve(node.Content[i+1].Value, file)
+				continue
+			}
+			e.rewrite(node.Content[i+1], file)
+		}
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			e.rewrite(n, file)
+		}
+	}
+}
+
+// resolve will convert a reference found in a file into a reference relative to that file.
+func (e *exploder) resolve(ref, file string) string {
+	if strings.HasPrefix(ref, explodedMarker) {
+		return relativeFile(file, strings.TrimPrefix(ref, explodedMarker))
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return ref
+	}
+	pointer := strings.TrimPrefix(ref, "#")
+
+	// find the longest pointer prefix that has been moved into a file.
+	target, matched := e.config.RootFileName, ""
+	for p, f := range e.pointers {
+		if (pointer == p || strings.HasPrefix(pointer, p+"/")) && len(p) > len(matched) {
+			target, matched = f, p
+		}
+	}
+	inner := strings.TrimPrefix(pointer, matched)
+	if target == file && inner != "" {
+		return "#" + inner
+	}
+	if inner == "" {
+		return relativeFile(file, target)
+	}
+	return relativeFile(file, target) + "#" + inner
+}
+
+func defaultConfig(config *ExplodeConfig) *ExplodeConfig {
+	c := ExplodeConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.Layout == nil {
+		c.Layout = &DirectoryLayout{}
+	}
+	c.RootFileName = path.Clean(filepath.ToSlash(defaultString(c.RootFileName, "openapi.yaml")))
+	if c.Indentation <= 0 {
+		c.Indentation = 2
+	}
+	return &c
+}
+
+// relativeFile returns the location of the target file, relative to the directory of the source file.
+func relativeFile(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
+
+func isReference(node *yaml.Node) bool {
+	if !utils.IsNodeMap(node) {
+		return false
+	}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == "$ref" {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package exploder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/bundler"
+	"github.com/pb33f/libopenapi/datamodel"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildBurgerShop(t *testing.T) *v3.Document {
+	spec, err := os.ReadFile("../test_specs/burgershop.openapi.yaml")
+	require.NoError(t, err)
+	doc, err := libopenapi.NewDocument(spec)
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	return &m.Model
+}
+
+func TestExplodeDocument(t *testing.T) {
+	files, err := ExplodeDocument(buildBurgerShop(t), nil)
+	require.NoError(t, err)
+
+	root := string(files["openapi.yaml"])
+	assert.Contains(t, root, "$ref: 'paths/burgers_burgerId_dressings.yaml'")
+	assert.Contains(t, root, "$ref: 'components/schemas/Burger.yaml'")
+
+	// references between exploded files are relative to each other.
+	burger := string(files["components/schemas/Burger.yaml"])
+	assert.Contains(t, burger, "$ref: 'Fries.yaml'")
+
+	burgers := string(files["paths/burgers.yaml"])
+	assert.Contains(t, burgers, "$ref: '../components/requestBodies/BurgerRequest.yaml'")
+	assert.NotContains(t, burgers, "#/components")
+
+	assert.Contains(t, files, "components/parameters/BurgerId.yaml")
+	assert.Contains(t, files, "components/responses/DressingResponse.yaml")
+}
+
+func TestWriteExplodedDocument_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, WriteExplodedDocument(buildBurgerShop(t), dir, nil))
+
+	spec, err := os.ReadFile(filepath.Join(dir, "openapi.yaml"))
+	require.NoError(t, err)
+
+	config := &datamodel.DocumentConfiguration{BasePath: dir}
+	doc, err := libopenapi.NewDocumentWithConfiguration(spec, config)
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+
+	op := m.Model.Paths.PathItems.GetOrZero("/burgers").Post
+	require.NotNil(t, op)
+	assert.Equal(t, "createBurger", op.OperationId)
+	schema := op.RequestBody.Content.GetOrZero("application/json").Schema.Schema()
+	require.NotNil(t, schema)
+	assert.Equal(t, "The name of your tasty burger - burger names are listed in our menus",
+		schema.Properties.GetOrZero("name").Schema().Description)
+
+	// bundling the exploded document must restore a single document.
+	bundled, err := bundler.BundleDocument(doc, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bundled), ".yaml")
+
+	bundledDoc, err := libopenapi.NewDocument(bundled)
+	require.NoError(t, err)
+	bm, errs := bundledDoc.BuildV3Model()
+	require.Empty(t, errs)
+	assert.Equal(t, 6, bm.Model.Components.Schemas.Len())
+	assert.NotNil(t, bm.Model.Components.Schemas.GetOrZero("Fries"))
+}
+
+func TestExplodeDocument_CustomLayout(t *testing.T) {
+	files, err := ExplodeDocument(buildBurgerShop(t), &ExplodeConfig{
+		RootFileName: "api/root.json",
+		Layout: &DirectoryLayout{
+			SkipPaths:      true,
+			ComponentTypes: []string{"schemas"},
+			FileExtension:  ".json",
+		},
+	})
+	require.NoError(t, err)
+
+	root := string(files["api/root.json"])
+	assert.True(t, strings.HasPrefix(root, "{"))
+	assert.Contains(t, root, `"$ref": "components/schemas/Burger.json"`)
+	assert.Contains(t, root, `"$ref": "#/components/parameters/BurgerId"`)
+	assert.NotContains(t, files, "api/components/parameters/BurgerId.json")
+
+	// references between exploded schemas stay relative to each other.
+	schema := string(files["api/components/schemas/Burger.json"])
+	assert.Contains(t, schema, `"$ref": "Fries.json"`)
+	assert.NotContains(t, schema, `"#/components`)
+	assert.Len(t, files, 7)
+}
+
+type flatLayout struct{}
+
+func (flatLayout) PathItemFile(string) string { return "" }
+
+func (flatLayout) ComponentFile(componentType, name string) string {
+	if componentType == "schemas" {
+		return "../" + name + ".yaml"
+	}
+	return ""
+}
+
+func TestExplodeDocument_InvalidLayout(t *testing.T) {
+	_, err := ExplodeDocument(buildBurgerShop(t), &ExplodeConfig{Layout: flatLayout{}})
+	assert.Error(t, err)
+
+	_, err = ExplodeDocument(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestDirectoryLayout_PathItemFile(t *testing.T) {
+	l := &DirectoryLayout{}
+	assert.Equal(t, "paths/root.yaml", l.PathItemFile("/"))
+	assert.Equal(t, "paths/pets_id_toys.yaml", l.PathItemFile("/pets/{id}/toys"))
+}
@@ -0,0 +1,543 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package filter creates a subset of an OpenAPI 3+ document, that contains only selected operations. Operations
+// are selected by tag, path (using globs), operationId or extension values.
+//
+// Once the operations have been filtered, the document is tree-shaken: every component that can no longer be
+// reached from what is left of the document is removed, using the reference graph of an index built from the
+// filtered document. The filtered document is then rendered, re-loaded and built, in the same way as
+// libopenapi.Document.RenderAndReload does. The source document is never modified.
+//
+// The same reachability analysis is used by FindUnusedComponents, to report components that are never used, and by
+// PruneUnusedComponents, to remove them from a model.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Selection selects operations. An operation is selected if it matches any of the criteria.
+type Selection struct {
+	// Tags selects operations that have any of the tags.
+	Tags []string
+
+	// Paths selects the operations of paths that match any of the globs (webhooks are matched by name). A '*'
+	// matches anything inside a single path segment, a '**' matches any number of segments. For example
+	// '/pets/*' matches '/pets/{id}', and '/admin/**' matches everything under '/admin'.
+	Paths []string
+
+	// OperationIds selects operations by operationId.
+	OperationIds []string
+
+	// Extensions selects operations with an extension set to a value, for example 'x-internal' set to 'false'.
+	// An empty value selects operations that have the extension, whatever its value.
+	Extensions map[string]string
+}
+
+// FilterConfig is used to configure which operations are kept by a filter.
+type FilterConfig struct {
+	// Include selects the operations to keep. If nil (or empty), every operation is kept.
+	Include *Selection
+
+	// Exclude selects operations to remove, from the operations selected by Include.
+	Exclude *Selection
+
+	// DocumentConfiguration is used to load the filtered document, a default configuration is used if it is nil.
+	DocumentConfiguration *datamodel.DocumentConfiguration
+}
+
+// Result contains the outcome of a filter.
+type Result struct {
+	// Bytes are the rendered bytes of the filtered document.
+	Bytes []byte
+
+	// Document is the new Document, created from the rendered Bytes.
+	Document libopenapi.Document
+
+	// Model is the OpenAPI 3+ model built from the new Document.
+	Model *libopenapi.DocumentModel[v3high.Document]
+
+	// RemovedOperations lists every operation that was removed, as the method and path, e.g. 'GET /pets'. Path items
+	// that are references are listed by path alone.
+	RemovedOperations []string
+
+	// RemovedComponents lists every component that was removed, as a reference, e.g. '#/components/schemas/Pet'.
+	RemovedComponents []string
+}
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// FilterDocument will build an OpenAPI 3+ model from the supplied Document and filter it, using Filter. The
+// configuration of the supplied Document is used when re-loading the filtered document, unless one is set.
+func FilterDocument(doc libopenapi.Document, config *FilterConfig) (*Result, error) {
+	if doc == nil || doc.GetSpecInfo() == nil {
+		return nil, errors.New("unable to filter, document has not been initialized")
+	}
+	if doc.GetSpecInfo().SpecFormat != datamodel.OAS3 && doc.GetSpecInfo().SpecFormat != datamodel.OAS31 {
+		return nil, errors.New("unable to filter, document is not an OpenAPI 3+ document")
+	}
+	m, errs := doc.BuildV3Model()
+	if m == nil {
+		return nil, fmt.Errorf("unable to build OpenAPI model: %w", errors.Join(errs...))
+	}
+	if config == nil {
+		config = &FilterConfig{}
+	}
+	if config.DocumentConfiguration == nil {
+		c := *config
+		c.DocumentConfiguration = doc.GetConfiguration()
+		config = &c
+	}
+	return Filter(&m.Model, config)
+}
+
+// Filter will create a new document that contains only the operations of the model selected by the
+// configuration, and every component they (and the rest of the document) still use.
+//
+//   - paths and webhooks left without any operations by the filter are removed, those that had no operations to
+//     begin with are kept. If every path is removed, 'paths' is kept as an empty object.
+//   - tags that were only used by removed operations are removed.
+//   - security schemes that are no longer used by any security requirement are removed.
+//
+// Path items that are references are not looked into, they are kept unless selected (or excluded) by a path glob.
+func Filter(doc *v3high.Document, config *FilterConfig) (*Result, error) {
+	if doc == nil {
+		return nil, errors.New("unable to filter, document is nil")
+	}
+	if config == nil {
+		config = &FilterConfig{}
+	}
+	rendered, err := doc.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := rendered.(*yaml.Node)
+	if !ok || !utils.IsNodeMap(root) {
+		return nil, errors.New("unable to filter, document did not render into an object")
+	}
+	root = utils.CopyNode(root)
+
+	f := &filter{config: config, usedTags: make(map[string]bool), removedTags: make(map[string]bool)}
+	f.filterPathItems(root, "paths")
+	f.filterPathItems(root, "webhooks")
+	f.removeTags(root)
+	f.treeShake(root)
+	return f.reload(root, doc)
+}
+
+type filter struct {
+	config      *FilterConfig
+	usedTags    map[string]bool
+	removedTags map[string]bool
+	result      Result
+}
+
+// filterPathItems removes every operation that is not selected from the paths (or webhooks).
+func (f *filter) filterPathItems(root *yaml.Node, section string) {
+	_, items := findKey(root, section)
+	if !utils.IsNodeMap(items) {
+		return
+	}
+	var kept []*yaml.Node
+	for i := 0; i < len(items.Content)-1; i += 2 {
+		key, item := items.Content[i], items.Content[i+1]
+		if strings.HasPrefix(key.Value, "x-") || !utils.IsNodeMap(item) {
+			kept = append(kept, key, item)
+			continue
+		}
+		if k, _ := findKey(item, "$ref"); k != nil {
+			if f.selectedReference(key.Value) {
+				kept = append(kept, key, item)
+			} else {
+				f.result.RemovedOperations = append(f.result.RemovedOperations, key.Value)
+			}
+			continue
+		}
+		operations, removed := 0, 0
+		var content []*yaml.Node
+		for j := 0; j < len(item.Content)-1; j += 2 {
+			method, op := item.Content[j].Value, item.Content[j+1]
+			if !isMethod(method) {
+				content = append(content, item.Content[j], op)
+				continue
+			}
+			selected := f.selected(key.Value, op)
+			for _, tag := range tags(op) {
+				if selected {
+					f.usedTags[tag] = true
+				} else {
+					f.removedTags[tag] = true
+				}
+			}
+			if !selected {
+				f.result.RemovedOperations = append(f.result.RemovedOperations,
+					strings.ToUpper(method)+" "+key.Value)
+				removed++
+				continue
+			}
+			operations++
+			content = append(content, item.Content[j], op)
+		}
+		// a path item is only removed if the filter took away every one of its operations.
+		if operations > 0 || removed == 0 {
+			item.Content = content
+			kept = append(kept, key, item)
+		}
+	}
+	if len(kept) == 0 && section != "paths" {
+		removeKey(root, section)
+		return
+	}
+	items.Content = kept
+}
+
+// selected checks if an operation of a path (or webhook) is selected by the configuration.
+func (f *filter) selected(p string, op *yaml.Node) bool {
+	if include := f.config.Include; include != nil && !include.empty() && !include.matches(p, op) {
+		return false
+	}
+	if exclude := f.config.Exclude; exclude != nil && exclude.matches(p, op) {
+		return false
+	}
+	return true
+}
+
+// selectedReference checks if a path item that is a reference is selected, only the path globs can be used.
+func (f *filter) selectedReference(p string) bool {
+	if include := f.config.Include; include != nil && !include.empty() && !matchAny(include.Paths, p) {
+		return false
+	}
+	if exclude := f.config.Exclude; exclude != nil && matchAny(exclude.Paths, p) {
+		return false
+	}
+	return true
+}
+
+func (s *Selection) empty() bool {
+	return len(s.Tags) == 0 && len(s.Paths) == 0 && len(s.OperationIds) == 0 && len(s.Extensions) == 0
+}
+
+func (s *Selection) matches(p string, op *yaml.Node) bool {
+	if matchAny(s.Paths, p) {
+		return true
+	}
+	for _, tag := range tags(op) {
+		for _, t := range s.Tags {
+			if tag == t {
+				return true
+			}
+		}
+	}
+	if _, id := findKey(op, "operationId"); id != nil {
+		for _, o := range s.OperationIds {
+			if id.Value == o {
+				return true
+			}
+		}
+	}
+	for ext, value := range s.Extensions {
+		if _, v := findKey(op, ext); v != nil && (value == "" || (v.Kind == yaml.ScalarNode && v.Value == value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(globs []string, p string) bool {
+	for _, g := range globs {
+		if matchGlob(strings.Split(strings.Trim(g, "/"), "/"), strings.Split(strings.Trim(p, "/"), "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against glob segments, where a '**' segment matches any number of segments.
+func matchGlob(glob, segments []string) bool {
+	if len(glob) == 0 {
+		return len(segments) == 0
+	}
+	if glob[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(glob[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, err := path.Match(glob[0], segments[0]); err != nil || !ok {
+		return false
+	}
+	return matchGlob(glob[1:], segments[1:])
+}
+
+// removeTags removes every tag that is only used by removed operations.
+func (f *filter) removeTags(root *yaml.Node) {
+	_, tagList := findKey(root, "tags")
+	if tagList == nil {
+		return
+	}
+	var kept []*yaml.Node
+	for _, tag := range tagList.Content {
+		if _, name := findKey(tag, "name"); name != nil && f.removedTags[name.Value] && !f.usedTags[name.Value] {
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	if len(kept) == 0 {
+		removeKey(root, "tags")
+		return
+	}
+	tagList.Content = kept
+}
+
+// treeShake removes every component that can no longer be reached from the rest of the document.
+func (f *filter) treeShake(root *yaml.Node) {
+	_, comps := findKey(root, "components")
+	if !utils.IsNodeMap(comps) {
+		return
+	}
+	reachable := reachableComponents(root)
+
+	var kept []*yaml.Node
+	for i := 0; i < len(comps.Content)-1; i += 2 {
+		cType, entries := comps.Content[i].Value, comps.Content[i+1]
+		if strings.HasPrefix(cType, "x-") || !utils.IsNodeMap(entries) {
+			kept = append(kept, comps.Content[i], entries)
+			continue
+		}
+		var keptEntries []*yaml.Node
+		for j := 0; j < len(entries.Content)-1; j += 2 {
+			component := "#/components/" + cType + "/" + utils.EscapePointerSegment(entries.Content[j].Value)
+			if reachable[component] {
+				keptEntries = append(keptEntries, entries.Content[j], entries.Content[j+1])
+			} else {
+				f.result.RemovedComponents = append(f.result.RemovedComponents, component)
+			}
+		}
+		if len(keptEntries) > 0 {
+			entries.Content = keptEntries
+			kept = append(kept, comps.Content[i], entries)
+		}
+	}
+	if len(kept) == 0 {
+		removeKey(root, "components")
+		return
+	}
+	comps.Content = kept
+}
+
+// reachableComponents returns the reference of every component that can be reached from the document outside of
+// its components: from paths, webhooks and security requirements, directly or through other reachable components.
+// Reachability is worked out from the references found by an index of the rendered document.
+func reachableComponents(root *yaml.Node) map[string]bool {
+	idx := index.NewSpecIndexWithConfig(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}},
+		index.CreateClosedAPIIndexConfig())
+	mapped := make(map[string]*index.Reference)
+	for _, ref := range idx.GetMappedReferences() {
+		mapped[ref.Definition] = ref
+	}
+
+	reachable := make(map[string]bool)
+	var queue []string
+	for i := 0; i < len(root.Content)-1; i += 2 {
+		if root.Content[i].Value != "components" {
+			queue = collectRefs(root.Content[i+1], queue)
+		}
+	}
+	for _, n := range securityRequirements(root) {
+		for j := 0; j < len(n.Content)-1; j += 2 {
+			queue = append(queue, "#/components/securitySchemes/"+n.Content[j].Value)
+		}
+	}
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		component := componentOf(ref)
+		if component == "" || reachable[component] {
+			continue
+		}
+		reachable[component] = true
+
+		// the whole component is kept, so everything it references is reachable, even if only part of it is used.
+		if ref, ok := mapped[component]; ok && ref.Node != nil {
+			queue = collectRefs(ref.Node, queue)
+		} else if ref = idx.FindComponent(component); ref != nil && ref.Node != nil {
+			queue = collectRefs(ref.Node, queue)
+		}
+	}
+	return reachable
+}
+
+// componentOf returns the reference of the component that contains the target of a local reference.
+func componentOf(ref string) string {
+	if !strings.HasPrefix(ref, "#/components/") {
+		return ""
+	}
+	segments := strings.SplitN(strings.TrimPrefix(ref, "#/components/"), "/", 3)
+	if len(segments) < 2 {
+		return ""
+	}
+	return "#/components/" + segments[0] + "/" + segments[1]
+}
+
+// collectRefs adds every local reference found inside a node to refs, including discriminator mappings.
+func collectRefs(node *yaml.Node, refs []string) []string {
+	if node == nil {
+		return refs
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if key == "$ref" && utils.IsNodeStringValue(value) {
+				refs = append(refs, value.Value)
+				continue
+			}
+			if key == "discriminator" {
+				if _, mapping := findKey(value, "mapping"); utils.IsNodeMap(mapping) {
+					for j := 1; j < len(mapping.Content); j += 2 {
+						target := mapping.Content[j].Value
+						if !strings.Contains(target, "/") {
+							target = "#/components/schemas/" + target
+						}
+						refs = append(refs, target)
+					}
+				}
+			}
+		}
+	}
+	for _, c := range node.Content {
+		refs = collectRefs(c, refs)
+	}
+	return refs
+}
+
+// securityRequirements returns every security requirement of the document, and of its operations.
+func securityRequirements(root *yaml.Node) []*yaml.Node {
+	var requirements []*yaml.Node
+	add := func(n *yaml.Node) {
+		if _, security := findKey(n, "security"); security != nil && security.Kind == yaml.SequenceNode {
+			requirements = append(requirements, security.Content...)
+		}
+	}
+	add(root)
+	var walk func(items *yaml.Node)
+	walk = func(items *yaml.Node) {
+		if !utils.IsNodeMap(items) {
+			return
+		}
+		for i := 1; i < len(items.Content); i += 2 {
+			for _, method := range httpMethods {
+				if _, op := findKey(items.Content[i], method); op != nil {
+					add(op)
+					if _, callbacks := findKey(op, "callbacks"); utils.IsNodeMap(callbacks) {
+						for j := 1; j < len(callbacks.Content); j += 2 {
+							walk(callbacks.Content[j])
+						}
+					}
+				}
+			}
+		}
+	}
+	_, paths := findKey(root, "paths")
+	walk(paths)
+	_, webhooks := findKey(root, "webhooks")
+	walk(webhooks)
+	if _, comps := findKey(root, "components"); comps != nil {
+		if _, callbacks := findKey(comps, "callbacks"); utils.IsNodeMap(callbacks) {
+			for j := 1; j < len(callbacks.Content); j += 2 {
+				walk(callbacks.Content[j])
+			}
+		}
+	}
+	return requirements
+}
+
+// reload will render the filtered document and create and build a new document from the rendered bytes, in the
+// same format as the source document.
+func (f *filter) reload(root *yaml.Node, doc *v3high.Document) (*Result, error) {
+	result := &f.result
+	sort.Strings(result.RemovedComponents)
+	fileType, indent := datamodel.YAMLFileType, 2
+	if doc.GoLow() != nil && doc.GoLow().Index != nil && doc.GoLow().Index.GetConfig().SpecInfo != nil {
+		info := doc.GoLow().Index.GetConfig().SpecInfo
+		fileType, indent = info.SpecFileType, info.OriginalIndentation
+	}
+	var err error
+	if result.Bytes, err = json.RenderNode(root, fileType == datamodel.JSONFileType, indent); err != nil {
+		return nil, fmt.Errorf("unable to render filtered document: %w", err)
+	}
+	config := f.config.DocumentConfiguration
+	if config == nil {
+		config = datamodel.NewDocumentConfiguration()
+	}
+	newDoc, err := libopenapi.NewDocumentWithConfiguration(result.Bytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reload filtered document: %w", err)
+	}
+	result.Document = newDoc
+	m, errs := newDoc.BuildV3Model()
+	result.Model = m
+	if len(errs) > 0 {
+		return result, fmt.Errorf("filtered document built with errors: %w", errors.Join(errs...))
+	}
+	return result, nil
+}
+
+func isMethod(key string) bool {
+	for _, m := range httpMethods {
+		if key == m {
+			return true
+		}
+	}
+	return false
+}
+
+func tags(op *yaml.Node) []string {
+	_, list := findKey(op, "tags")
+	if list == nil {
+		return nil
+	}
+	var t []string
+	for _, n := range list.Content {
+		t = append(t, n.Value)
+	}
+	return t
+}
+
+func findKey(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func removeKey(m *yaml.Node, key string) {
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var spec = `openapi: 3.1.0
+info:
+  title: burger shop
+  version: 1.0.0
+security:
+  - apiKey: []
+tags:
+  - name: burgers
+  - name: admin
+  - name: unused
+paths:
+  /burgers:
+    get:
+      operationId: listBurgers
+      tags:
+        - burgers
+      x-internal: false
+      responses:
+        "200":
+          $ref: '#/components/responses/Burgers'
+    post:
+      operationId: createBurger
+      tags:
+        - burgers
+        - admin
+      x-internal: true
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+      responses:
+        "201":
+          description: created
+  /burgers/{id}:
+    get:
+      operationId: getBurger
+      tags:
+        - burgers
+      security:
+        - oauth: []
+      responses:
+        "200":
+          description: a burger
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+  /admin/users/{id}:
+    delete:
+      operationId: deleteUser
+      tags:
+        - admin
+      parameters:
+        - $ref: '#/components/parameters/UserId'
+      responses:
+        "204":
+          description: deleted
+components:
+  responses:
+    Burgers:
+      description: burgers
+      content:
+        application/json:
+          schema:
+            type: array
+            items:
+              $ref: '#/components/schemas/Burger/properties/name'
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+        fries:
+          $ref: '#/components/schemas/Fries'
+        sauce:
+          oneOf:
+            - $ref: '#/components/schemas/Ketchup'
+          discriminator:
+            propertyName: type
+            mapping:
+              ketchup: Ketchup
+    Fries:
+      type: string
+    Ketchup:
+      type: object
+    Unused:
+      type: string
+  parameters:
+    UserId:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: string
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Api-Key
+    oauth:
+      type: http
+      scheme: bearer
+`
+
+func newDocument(t *testing.T) libopenapi.Document {
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	require.NoError(t, err)
+	return doc
+}
+
+func keys[V any](m *orderedmap.Map[string, V]) []string {
+	var k []string
+	for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
+		k = append(k, pair.Key())
+	}
+	return k
+}
+
+func TestFilterDocument_Extension(t *testing.T) {
+	result, err := FilterDocument(newDocument(t), &FilterConfig{
+		Include: &Selection{Extensions: map[string]string{"x-internal": "false"}},
+	})
+	require.NoError(t, err)
+	model := result.Model.Model
+
+	assert.Equal(t, []string{"/burgers"}, keys(model.Paths.PathItems))
+	assert.Nil(t, model.Paths.PathItems.GetOrZero("/burgers").Post)
+	assert.Equal(t, []string{"POST /burgers", "GET /burgers/{id}", "DELETE /admin/users/{id}"},
+		result.RemovedOperations)
+
+	// the response only uses part of Burger, but all of Burger (and everything it references) is kept.
+	assert.Equal(t, []string{"Burger", "Fries", "Ketchup"}, keys(model.Components.Schemas))
+	assert.Equal(t, []string{"Burgers"}, keys(model.Components.Responses))
+	assert.Zero(t, orderedmap.Len(model.Components.Parameters))
+	assert.Equal(t, []string{"apiKey"}, keys(model.Components.SecuritySchemes))
+	assert.Equal(t, []string{
+		"#/components/parameters/UserId", "#/components/schemas/Unused", "#/components/securitySchemes/oauth",
+	}, result.RemovedComponents)
+
+	// the admin tag was only used by removed operations, the unused tag was never used.
+	require.Len(t, model.Tags, 2)
+	assert.Equal(t, "burgers", model.Tags[0].Name)
+	assert.Equal(t, "unused", model.Tags[1].Name)
+}
+
+func TestFilterDocument_PathsAndExclude(t *testing.T) {
+	result, err := FilterDocument(newDocument(t), &FilterConfig{
+		Include: &Selection{Paths: []string{"/admin/**", "/burgers/*"}},
+		Exclude: &Selection{OperationIds: []string{"getBurger"}},
+	})
+	require.NoError(t, err)
+	model := result.Model.Model
+	assert.Equal(t, []string{"/admin/users/{id}"}, keys(model.Paths.PathItems))
+	assert.Equal(t, []string{"UserId"}, keys(model.Components.Parameters))
+	assert.Zero(t, orderedmap.Len(model.Components.Schemas))
+	assert.Zero(t, orderedmap.Len(model.Components.Responses))
+	require.Len(t, model.Tags, 2)
+	assert.Equal(t, "admin", model.Tags[0].Name)
+
+	result, err = FilterDocument(newDocument(t), &FilterConfig{
+		Include: &Selection{Tags: []string{"admin"}, OperationIds: []string{"getBurger"}},
+	})
+	require.NoError(t, err)
+	model = result.Model.Model
+	assert.Equal(t, []string{"/burgers", "/burgers/{id}", "/admin/users/{id}"}, keys(model.Paths.PathItems))
+	assert.Nil(t, model.Paths.PathItems.GetOrZero("/burgers").Get)
+	assert.Equal(t, []string{"apiKey", "oauth"}, keys(model.Components.SecuritySchemes))
+}
+
+func TestFilterDocument_NoSelection(t *testing.T) {
+	result, err := FilterDocument(newDocument(t), nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.RemovedOperations)
+	assert.Equal(t, []string{"#/components/schemas/Unused"}, result.RemovedComponents)
+}
+
+func TestFilterDocument_NothingSelected(t *testing.T) {
+	result, err := FilterDocument(newDocument(t), &FilterConfig{
+		Include: &Selection{OperationIds: []string{"nope"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Bytes), "paths: {}")
+	require.NotNil(t, result.Model.Model.Paths)
+	assert.Zero(t, orderedmap.Len(result.Model.Model.Paths.PathItems))
+}
+
+func TestFilterDocument_KeepPathsWithoutOperations(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.1.0
+info:
+  title: burger shop
+  version: 1.0.0
+paths:
+  /burgers:
+    get:
+      operationId: listBurgers
+      responses:
+        "200":
+          description: burgers
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/burgers~1list'
+  /fries:
+    get:
+      operationId: listFries
+      responses:
+        "200":
+          description: fries
+  /health:
+    summary: no operations, yet
+components:
+  schemas:
+    burgers/list:
+      type: array
+      items:
+        $ref: '#/components/schemas/burgers~1cheese'
+    burgers/cheese:
+      type: object
+    fries/list:
+      type: array`))
+	require.NoError(t, err)
+	result, err := FilterDocument(doc, &FilterConfig{
+		Include: &Selection{OperationIds: []string{"listBurgers"}},
+	})
+	require.NoError(t, err)
+	model := result.Model.Model
+
+	// only path items emptied by the filter are removed.
+	assert.Equal(t, []string{"/burgers", "/health"}, keys(model.Paths.PathItems))
+	assert.Equal(t, []string{"GET /fries"}, result.RemovedOperations)
+
+	// component names are escaped in references.
+	assert.Equal(t, []string{"burgers/list", "burgers/cheese"}, keys(model.Components.Schemas))
+	assert.Equal(t, []string{"#/components/schemas/fries~1list"}, result.RemovedComponents)
+}
+
+func TestMatchGlob(t *testing.T) {
+	assert.True(t, matchAny([]string{"/pets/*"}, "/pets/{id}"))
+	assert.False(t, matchAny([]string{"/pets/*"}, "/pets/{id}/toys"))
+	assert.True(t, matchAny([]string{"/pets/**"}, "/pets/{id}/toys"))
+	assert.True(t, matchAny([]string{"/pets/**"}, "/pets"))
+	assert.True(t, matchAny([]string{"/**/toys"}, "/pets/{id}/toys"))
+	assert.False(t, matchAny([]string{"/[pets"}, "/pets"))
+}
+
+func TestFilterDocument_Errors(t *testing.T) {
+	_, err := FilterDocument(nil, nil)
+	assert.Equal(t, "unable to filter, document has not been initialized", err.Error())
+
+	swagger, err := libopenapi.NewDocument([]byte(`swagger: "2.0"`))
+	require.NoError(t, err)
+	_, err = FilterDocument(swagger, nil)
+	assert.Equal(t, "unable to filter, document is not an OpenAPI 3+ document", err.Error())
+
+	_, err = Filter(nil, nil)
+	assert.Equal(t, "unable to filter, document is nil", err.Error())
+}
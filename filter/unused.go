@@ -0,0 +1,153 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// UnusedComponent is a component that is never referenced from paths, webhooks, security requirements or any other
+// component that is used.
+type UnusedComponent struct {
+	// Type is the type of the component, as named in components, e.g. 'schemas' or 'securitySchemes'.
+	Type string
+
+	// Name is the name of the component.
+	Name string
+
+	// Reference is the local reference to the component, e.g. '#/components/schemas/Pet'.
+	Reference string
+
+	// File is the absolute path of the specification (or file) the component was defined in, it's empty for
+	// components that were added to the model.
+	File string
+
+	// Line and Column are the position of the component key in File, they are zero for components that were added
+	// to the model, and do not exist in the specification.
+	Line   int
+	Column int
+}
+
+// Error returns the location and reference of the unused component, so it can be reported like any other error.
+func (u *UnusedComponent) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: component is never used", u.File, u.Line, u.Column, u.Reference)
+}
+
+// FindUnusedComponents returns every component of the model (schemas, parameters, responses, examples, headers,
+// request bodies, links, callbacks and security schemes) that cannot be reached from the paths, webhooks or security
+// requirements of the document, directly or through other components. Components are returned in the order they
+// are defined in. The model is rendered to find references, so changes made to the model are taken into account.
+func FindUnusedComponents(doc *v3high.Document) ([]*UnusedComponent, error) {
+	if doc == nil {
+		return nil, errors.New("unable to find unused components, document is nil")
+	}
+	rendered, err := doc.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := rendered.(*yaml.Node)
+	if !ok || !utils.IsNodeMap(root) {
+		return nil, errors.New("unable to find unused components, document did not render into an object")
+	}
+	_, comps := findKey(root, "components")
+	if !utils.IsNodeMap(comps) {
+		return nil, nil
+	}
+	reachable := reachableComponents(root)
+
+	var idx *index.SpecIndex
+	if doc.GoLow() != nil {
+		idx = doc.GoLow().Index
+	}
+
+	var unused []*UnusedComponent
+	for i := 0; i < len(comps.Content)-1; i += 2 {
+		cType, entries := comps.Content[i].Value, comps.Content[i+1]
+		if !utils.IsNodeMap(entries) {
+			continue
+		}
+		for j := 0; j < len(entries.Content)-1; j += 2 {
+			name := entries.Content[j].Value
+			ref := "#/components/" + cType + "/" + utils.EscapePointerSegment(name)
+			if !componentTypes[cType] || reachable[ref] {
+				continue
+			}
+			u := &UnusedComponent{Type: cType, Name: name, Reference: ref}
+			locateComponent(u, idx)
+			unused = append(unused, u)
+		}
+	}
+	return unused, nil
+}
+
+// PruneUnusedComponents finds every unused component of the model using FindUnusedComponents, and removes them from
+// the model, so that rendering the model emits a document without them. The removed components are returned.
+func PruneUnusedComponents(doc *v3high.Document) ([]*UnusedComponent, error) {
+	unused, err := FindUnusedComponents(doc)
+	if err != nil || doc.Components == nil {
+		return unused, err
+	}
+	c := doc.Components
+	for _, u := range unused {
+		switch u.Type {
+		case "schemas":
+			c.Schemas.Delete(u.Name)
+		case "responses":
+			c.Responses.Delete(u.Name)
+		case "parameters":
+			c.Parameters.Delete(u.Name)
+		case "examples":
+			c.Examples.Delete(u.Name)
+		case "requestBodies":
+			c.RequestBodies.Delete(u.Name)
+		case "headers":
+			c.Headers.Delete(u.Name)
+		case "securitySchemes":
+			c.SecuritySchemes.Delete(u.Name)
+		case "links":
+			c.Links.Delete(u.Name)
+		case "callbacks":
+			c.Callbacks.Delete(u.Name)
+		}
+	}
+	return unused, nil
+}
+
+// locateComponent sets the file, line and column of an unused component, using the index that defines it.
+// Components that were added to the model cannot be found, and are left without a location.
+func locateComponent(u *UnusedComponent, idx *index.SpecIndex) {
+	if idx == nil {
+		return
+	}
+	found := idx.FindComponent(u.Reference)
+	if found == nil {
+		return
+	}
+	u.File = found.RemoteLocation
+	defined := found.Index
+	if defined == nil {
+		defined = idx
+	}
+	if u.File == "" {
+		u.File = defined.GetSpecAbsolutePath()
+	}
+	if root := defined.GetRootNode(); root != nil && len(root.Content) > 0 {
+		_, comps := findKey(root.Content[0], "components")
+		_, entries := findKey(comps, u.Type)
+		if key, _ := findKey(entries, u.Name); key != nil {
+			u.Line, u.Column = key.Line, key.Column
+		}
+	}
+}
+
+var componentTypes = map[string]bool{
+	"schemas": true, "responses": true, "parameters": true, "examples": true, "requestBodies": true,
+	"headers": true, "securitySchemes": true, "links": true, "callbacks": true,
+}
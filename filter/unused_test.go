@@ -0,0 +1,194 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var unusedSpec = `openapi: 3.1.0
+info:
+  title: unused
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      security:
+        - oauth: []
+      responses:
+        "200":
+          $ref: '#/components/responses/Pets'
+components:
+  responses:
+    Pets:
+      description: pets
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+    Orphan:
+      description: nobody uses this
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Lonely'
+          examples:
+            lonely:
+              $ref: '#/components/examples/Lonely'
+  schemas:
+    Pet:
+      type: object
+    Lonely:
+      type: string
+  examples:
+    Lonely:
+      value: alone
+  securitySchemes:
+    oauth:
+      type: http
+      scheme: bearer
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Api-Key
+  x-stuff: kept
+`
+
+func buildUnused(t *testing.T) (libopenapi.Document, *v3high.Document) {
+	doc, err := libopenapi.NewDocument([]byte(unusedSpec))
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	return doc, &m.Model
+}
+
+func TestFindUnusedComponents(t *testing.T) {
+	_, model := buildUnused(t)
+	unused, err := FindUnusedComponents(model)
+	require.NoError(t, err)
+
+	// everything only used by an unused component is unused as well.
+	var refs []string
+	for _, u := range unused {
+		refs = append(refs, u.Reference)
+	}
+	assert.Equal(t, []string{
+		"#/components/responses/Orphan", "#/components/schemas/Lonely",
+		"#/components/examples/Lonely", "#/components/securitySchemes/apiKey",
+	}, refs)
+
+	orphan := unused[0]
+	assert.Equal(t, "responses", orphan.Type)
+	assert.Equal(t, "Orphan", orphan.Name)
+	assert.Equal(t, 21, orphan.Line)
+	assert.Equal(t, 5, orphan.Column)
+	assert.Equal(t, 42, unused[3].Line)
+	assert.Contains(t, orphan.Error(), ":21:5: #/components/responses/Orphan: component is never used")
+
+	// changes to the model are taken into account.
+	model.Paths.PathItems.GetOrZero("/pets").Get.Security = nil
+	unused, err = FindUnusedComponents(model)
+	require.NoError(t, err)
+	assert.Len(t, unused, 5)
+	assert.Equal(t, "#/components/securitySchemes/oauth", unused[3].Reference)
+}
+
+func TestFindUnusedComponents_Location(t *testing.T) {
+	config := datamodel.NewDocumentConfiguration()
+	config.BasePath = t.TempDir()
+	doc, err := libopenapi.NewDocumentWithConfiguration([]byte(unusedSpec), config)
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+
+	// components added to the model are not defined in any file.
+	m.Model.Components.Schemas.Set("Added", base.CreateSchemaProxy(&base.Schema{Type: []string{"string"}}))
+	unused, err := FindUnusedComponents(&m.Model)
+	require.NoError(t, err)
+	require.Len(t, unused, 5)
+
+	assert.Equal(t, m.Index.GetSpecAbsolutePath(), unused[0].File)
+	assert.Contains(t, unused[0].File, config.BasePath)
+	assert.Equal(t, 21, unused[0].Line)
+
+	assert.Equal(t, "#/components/schemas/Added", unused[2].Reference)
+	assert.Empty(t, unused[2].File)
+	assert.Zero(t, unused[2].Line)
+}
+
+func TestPruneUnusedComponents(t *testing.T) {
+	_, model := buildUnused(t)
+	pruned, err := PruneUnusedComponents(model)
+	require.NoError(t, err)
+	assert.Len(t, pruned, 4)
+
+	assert.Equal(t, []string{"Pets"}, keys(model.Components.Responses))
+	assert.Equal(t, []string{"Pet"}, keys(model.Components.Schemas))
+	assert.Zero(t, model.Components.Examples.Len())
+	assert.Equal(t, []string{"oauth"}, keys(model.Components.SecuritySchemes))
+
+	rendered, err := model.Render()
+	require.NoError(t, err)
+	assert.NotContains(t, string(rendered), "Orphan")
+	assert.NotContains(t, string(rendered), "Lonely")
+	assert.NotContains(t, string(rendered), "apiKey")
+	assert.Contains(t, string(rendered), "x-stuff: kept")
+
+	// once pruned, nothing is left to find.
+	unused, err := FindUnusedComponents(model)
+	require.NoError(t, err)
+	assert.Empty(t, unused)
+}
+
+func TestPruneUnusedComponents_EscapedNames(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.1.0
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/pets~1list'
+components:
+  schemas:
+    pets/list:
+      type: array
+    odd~name:
+      type: string`))
+	require.NoError(t, err)
+	m, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+
+	pruned, err := PruneUnusedComponents(&m.Model)
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, "odd~name", pruned[0].Name)
+	assert.Equal(t, "#/components/schemas/odd~0name", pruned[0].Reference)
+	assert.Equal(t, []string{"pets/list"}, keys(m.Model.Components.Schemas))
+}
+
+func TestFindUnusedComponents_NoComponents(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte("openapi: 3.1.0\ninfo:\n  title: empty\n  version: 1.0.0\n"))
+	require.NoError(t, err)
+	m, _ := doc.BuildV3Model()
+	unused, err := PruneUnusedComponents(&m.Model)
+	require.NoError(t, err)
+	assert.Empty(t, unused)
+
+	_, err = FindUnusedComponents(nil)
+	assert.Equal(t, "unable to find unused components, document is nil", err.Error())
+}
@@ -0,0 +1,482 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package formatter renders OpenAPI documents in a canonical form, so that diffs between two versions of a document
+// only show what has actually changed.
+//
+// A formatted document has:
+//
+//   - the keys of every object in the order of the specification, followed by any unknown keys and extensions, in
+//     their original order. A '$ref' always comes first.
+//   - components (and Swagger definitions) sorted by name, and optionally paths and webhooks.
+//   - consistent quoting, scalars are only quoted when they have to be, and consistent indentation.
+//
+// The order of the fields of each object is taken from the high-level model, in the same way NodeBuilder renders
+// them, except where the model does not follow the order of the specification. Values that are not part of the
+// model (examples, defaults, extensions) are never re-ordered. Comments are kept.
+//
+// Multi-file documents are formatted file by file: every file of the Rolodex is rewritten in place, references are
+// followed to work out what each (part of a) file contains.
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2high "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatConfig is used to configure how documents are formatted.
+type FormatConfig struct {
+	// Indent is the number of spaces used to indent YAML and JSON, defaults to 2.
+	Indent int
+
+	// SortPaths will sort paths and webhooks by name, they are kept in their original order otherwise.
+	SortPaths bool
+}
+
+// Result contains the formatted files of a document.
+type Result struct {
+	// Bytes are the formatted bytes of the root document.
+	Bytes []byte
+
+	// Files contains the formatted bytes of every file of the document, including the root document, by their
+	// absolute path.
+	Files map[string][]byte
+}
+
+// fieldOrder holds the order of the fields of the objects of the model that are not in the order of the
+// specification, or that have fields the model does not know about.
+var fieldOrder = map[reflect.Type][]string{
+	reflect.TypeOf(v3high.Document{}): {
+		"openapi", "info", "jsonSchemaDialect", "servers", "paths", "webhooks", "components", "security", "tags",
+		"externalDocs",
+	},
+	reflect.TypeOf(v3high.PathItem{}): {
+		"summary", "description", "get", "put", "post", "delete", "options", "head", "patch", "trace", "servers",
+		"parameters",
+	},
+	reflect.TypeOf(v3high.Components{}): {
+		"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links",
+		"callbacks", "pathItems",
+	},
+	reflect.TypeOf(base.Info{}): {
+		"title", "summary", "description", "termsOfService", "contact", "license", "version",
+	},
+	reflect.TypeOf(base.Schema{}): {
+		"$schema", "$id", "$anchor", "$dynamicAnchor", "$dynamicRef", "$vocabulary", "$comment", "title",
+		"description", "type", "format", "nullable", "const", "enum", "default", "example", "examples", "multipleOf",
+		"minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum", "minLength", "maxLength", "pattern",
+		"contentEncoding", "contentMediaType", "contentSchema", "items", "prefixItems", "contains", "minContains",
+		"maxContains", "minItems", "maxItems", "uniqueItems", "unevaluatedItems", "required", "properties",
+		"patternProperties", "additionalProperties", "unevaluatedProperties", "propertyNames", "dependentRequired",
+		"dependentSchemas", "minProperties", "maxProperties", "allOf", "oneOf", "anyOf", "not", "if", "then", "else",
+		"discriminator", "readOnly", "writeOnly", "deprecated", "xml", "externalDocs", "$defs",
+	},
+
+	// response codes and the default response are kept in their original order.
+	reflect.TypeOf(v3high.Responses{}): {},
+	reflect.TypeOf(v2high.Responses{}): {},
+}
+
+// sortedFields holds the objects with fields that are maps, sorted by name.
+var sortedFields = map[reflect.Type]bool{
+	reflect.TypeOf(v3high.Components{}): true,
+}
+
+// sortedEntries holds the objects that are maps, sorted by name.
+var sortedEntries = map[reflect.Type]bool{
+	reflect.TypeOf(v2high.Definitions{}):          true,
+	reflect.TypeOf(v2high.ParameterDefinitions{}): true,
+	reflect.TypeOf(v2high.ResponsesDefinitions{}): true,
+	reflect.TypeOf(v2high.SecurityDefinitions{}):  true,
+}
+
+var (
+	schemaType  = reflect.TypeOf(base.Schema{})
+	proxyType   = reflect.TypeOf(base.SchemaProxy{})
+	nodeType    = reflect.TypeOf(yaml.Node{})
+	v3PathsType = reflect.TypeOf(v3high.Paths{})
+	v2PathsType = reflect.TypeOf(v2high.Paths{})
+	v3DocType   = reflect.TypeOf(v3high.Document{})
+)
+
+// Format will format a single OpenAPI 3+ or Swagger specification. References to other files are not followed.
+func Format(spec []byte, config *FormatConfig) ([]byte, error) {
+	info, err := datamodel.ExtractSpecInfo(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to format: %w", err)
+	}
+	rootType, err := documentType(info)
+	if err != nil {
+		return nil, err
+	}
+	f := newFormatter(config)
+	f.add("", utils.CopyNode(info.RootNode), info.SpecFileType == datamodel.JSONFileType)
+	return f.format("", rootType)
+}
+
+// FormatDocument will format every file of a Document, the root document and every local file loaded by its
+// Rolodex. If no model has been built for the document yet, one is built so the Rolodex is loaded. The Document
+// itself is never modified.
+func FormatDocument(doc libopenapi.Document, config *FormatConfig) (*Result, error) {
+	if doc == nil || doc.GetSpecInfo() == nil {
+		return nil, errors.New("unable to format, document has not been initialized")
+	}
+	info := doc.GetSpecInfo()
+	rootType, err := documentType(info)
+	if err != nil {
+		return nil, err
+	}
+	if doc.GetRolodex() == nil {
+		if info.SpecFormat == datamodel.OAS2 {
+			_, _ = doc.BuildV2Model()
+		} else {
+			_, _ = doc.BuildV3Model()
+		}
+	}
+
+	f := newFormatter(config)
+	rootPath := ""
+	if rolodex := doc.GetRolodex(); rolodex != nil {
+		if rolodex.GetRootIndex() != nil {
+			rootPath = rolodex.GetRootIndex().GetSpecAbsolutePath()
+		}
+		for _, idx := range rolodex.GetIndexes() {
+			if p := idx.GetSpecAbsolutePath(); p != rootPath && idx.GetRootNode() != nil {
+				f.add(p, utils.CopyNode(idx.GetRootNode()), strings.EqualFold(filepath.Ext(p), ".json"))
+			}
+		}
+	}
+	f.add(rootPath, utils.CopyNode(info.RootNode), info.SpecFileType == datamodel.JSONFileType)
+	root, err := f.format(rootPath, rootType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Bytes: root, Files: map[string][]byte{rootPath: root}}
+	for p := range f.files {
+		if p != rootPath {
+			if result.Files[p], err = f.render(p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func documentType(info *datamodel.SpecInfo) (reflect.Type, error) {
+	switch info.SpecFormat {
+	case datamodel.OAS2:
+		return reflect.TypeOf(v2high.Swagger{}), nil
+	case datamodel.OAS3, datamodel.OAS31:
+		return v3DocType, nil
+	}
+	return nil, errors.New("unable to format, document is not an OpenAPI or Swagger document")
+}
+
+type file struct {
+	root *yaml.Node
+	json bool
+}
+
+type shape struct {
+	order   []string
+	fields  map[string]reflect.Type
+	entries reflect.Type
+}
+
+type formatter struct {
+	config  *FormatConfig
+	files   map[string]*file
+	shapes  map[reflect.Type]*shape
+	visited map[string]bool
+}
+
+func newFormatter(config *FormatConfig) *formatter {
+	c := FormatConfig{}
+	if config != nil {
+		c = *config
+	}
+	if c.Indent <= 0 {
+		c.Indent = 2
+	}
+	return &formatter{
+		config:  &c,
+		files:   make(map[string]*file),
+		shapes:  make(map[reflect.Type]*shape),
+		visited: make(map[string]bool),
+	}
+}
+
+// add adds a file to be formatted, the style of every node in the file is reset, so quoting is consistent.
+func (f *formatter) add(p string, root *yaml.Node, isJSON bool) {
+	if root == nil {
+		return
+	}
+	if root.Kind != yaml.DocumentNode {
+		root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	}
+	resetStyle(root)
+	f.files[p] = &file{root: root, json: isJSON}
+}
+
+// format formats the root file, and everything it references, and renders the root file.
+func (f *formatter) format(rootPath string, rootType reflect.Type) ([]byte, error) {
+	f.follow("#", rootType, rootPath)
+	return f.render(rootPath)
+}
+
+func (f *formatter) render(p string) ([]byte, error) {
+	fl := f.files[p]
+	if fl == nil || len(fl.root.Content) == 0 {
+		return nil, nil
+	}
+	b, err := json.RenderNode(fl.root, fl.json, f.config.Indent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render '%s': %w", p, err)
+	}
+	if fl.json {
+		b = append(b, '\n')
+	}
+	return b, nil
+}
+
+// follow formats the target of a reference, found in a file, as the type the reference was found in. Remote
+// references are not followed.
+func (f *formatter) follow(ref string, t reflect.Type, p string) {
+	target, pointer, _ := strings.Cut(ref, "#")
+	if strings.Contains(target, "://") {
+		return
+	}
+	if target == "" {
+		target = p
+	} else if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(p), target)
+	}
+	key := target + "#" + pointer
+	if f.visited[key] {
+		return
+	}
+	f.visited[key] = true
+	fl := f.files[target]
+	if fl == nil || len(fl.root.Content) == 0 {
+		return
+	}
+	if n := locate(fl.root.Content[0], pointer); n != nil {
+		f.walk(n, t, target, false)
+	}
+}
+
+// walk formats a node as the type of the model it represents.
+func (f *formatter) walk(n *yaml.Node, t reflect.Type, p string, sorted bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == proxyType {
+		t = schemaType
+	}
+	if isDynamicValue(t) {
+		a, _ := t.FieldByName("A")
+		f.walk(n, a.Type, p, false)
+		return
+	}
+	switch {
+	case isMap(t):
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		v := mapValue(t)
+		for i := 1; i < len(n.Content); i += 2 {
+			f.walk(n.Content[i], v, p, false)
+		}
+		if sorted {
+			n.Content = sortPairs(n.Content)
+		}
+	case t.Kind() == reflect.Slice:
+		if n.Kind == yaml.SequenceNode {
+			for _, c := range n.Content {
+				f.walk(c, t.Elem(), p, false)
+			}
+		}
+	case t.Kind() == reflect.Struct && t != nodeType:
+		if n.Kind == yaml.MappingNode {
+			f.object(n, t, p)
+		}
+	}
+}
+
+// object orders the keys of an object: a '$ref' first, then the fields in order, then unknown keys (or map
+// entries), then extensions.
+func (f *formatter) object(n *yaml.Node, t reflect.Type, p string) {
+	s := f.shapeOf(t)
+	known := make(map[string][]*yaml.Node)
+	var ref, rest, ext []*yaml.Node
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch {
+		case key.Value == "$ref":
+			ref = append(ref, key, value)
+			if value.Kind == yaml.ScalarNode {
+				f.follow(value.Value, t, p)
+			}
+			continue
+		case strings.HasPrefix(key.Value, "x-"):
+			ext = append(ext, key, value)
+			continue
+		case contains(s.order, key.Value):
+			known[key.Value] = []*yaml.Node{key, value}
+		default:
+			rest = append(rest, key, value)
+		}
+		if ft, ok := s.fields[key.Value]; ok {
+			f.walk(value, ft, p, sortedFields[t] || (f.config.SortPaths && t == v3DocType && key.Value == "webhooks"))
+		} else if s.entries != nil {
+			f.walk(value, s.entries, p, false)
+		}
+	}
+	if sortedEntries[t] || (f.config.SortPaths && (t == v3PathsType || t == v2PathsType)) {
+		rest = sortPairs(rest)
+	}
+	content := ref
+	for _, name := range s.order {
+		content = append(content, known[name]...)
+	}
+	content = append(content, rest...)
+	n.Content = append(content, ext...)
+}
+
+// shapeOf works out the fields of an object from the yaml tags of the model. Maps that are not rendered as a field
+// (such as the path items of Paths) hold the type of every key that is not a field.
+func (f *formatter) shapeOf(t reflect.Type) *shape {
+	if s, ok := f.shapes[t]; ok {
+		return s
+	}
+	s := &shape{fields: make(map[string]reflect.Type)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || name == "" {
+			if field.Name != "Extensions" && isMap(derefType(field.Type)) {
+				s.entries = mapValue(derefType(field.Type))
+			}
+			continue
+		}
+		s.order = append(s.order, name)
+		s.fields[name] = field.Type
+	}
+	if order, ok := fieldOrder[t]; ok {
+		s.order = order
+	}
+	f.shapes[t] = s
+	return s
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && strings.HasPrefix(t.Name(), "Map[") &&
+		t.PkgPath() == "github.com/pb33f/libopenapi/orderedmap"
+}
+
+func isDynamicValue(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && strings.HasPrefix(t.Name(), "DynamicValue[") && t.PkgPath() == schemaType.PkgPath()
+}
+
+// mapValue returns the type of the values of an ordered map.
+func mapValue(t reflect.Type) reflect.Type {
+	if m, ok := reflect.New(t).Interface().(interface{ GetValueType() reflect.Type }); ok {
+		return m.GetValueType().Elem()
+	}
+	return nodeType
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sortPairs sorts the key/value pairs of a map by key.
+func sortPairs(content []*yaml.Node) []*yaml.Node {
+	pairs := make([][2]*yaml.Node, 0, len(content)/2)
+	for i := 0; i < len(content)-1; i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{content[i], content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i][0].Value < pairs[j][0].Value
+	})
+	sorted := make([]*yaml.Node, 0, len(content))
+	for _, pair := range pairs {
+		sorted = append(sorted, pair[0], pair[1])
+	}
+	return sorted
+}
+
+// locate finds the node a JSON pointer points to.
+func locate(n *yaml.Node, pointer string) *yaml.Node {
+	if pointer == "" || pointer == "/" {
+		return n
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if s, err := url.PathUnescape(segment); err == nil {
+			segment = s
+		}
+		segment = utils.UnescapePointerSegment(segment)
+		var next *yaml.Node
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i < len(n.Content)-1; i += 2 {
+				if n.Content[i].Value == segment {
+					next = n.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(n.Content) {
+				next = n.Content[i]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// resetStyle resets the style of every node, so scalars are only quoted when they have to be, and every map and
+// sequence is rendered as a block.
+func resetStyle(n *yaml.Node) {
+	if n == nil || n.Kind == yaml.AliasNode {
+		return
+	}
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var scrambled = `components:
+  schemas:
+    Zebra:
+      properties:
+        stripes: {type: integer, x-order: 1}
+        name:
+          type: "string"
+      type: object
+      x-kind: animal
+    Ant:
+      description: "tiny"
+      $ref: '#/components/schemas/Zebra'
+paths:
+  /zoo:
+    x-zoo: true
+    post:
+      responses:
+        "201":
+          description: created
+        default:
+          description: error
+      operationId: "addAnimal" # add an animal
+      tags: [zoo]
+  /animals:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Zebra"
+          description: ok
+info:
+  version: '1.0.0'
+  title: zoo
+openapi: 3.1.0
+`
+
+var formatted = `openapi: 3.1.0
+info:
+  title: zoo
+  version: 1.0.0
+paths:
+  /zoo:
+    post:
+      tags:
+        - zoo
+      operationId: addAnimal # add an animal
+      responses:
+        "201":
+          description: created
+        default:
+          description: error
+    x-zoo: true
+  /animals:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Zebra'
+components:
+  schemas:
+    Ant:
+      $ref: '#/components/schemas/Zebra'
+      description: tiny
+    Zebra:
+      type: object
+      properties:
+        stripes:
+          type: integer
+          x-order: 1
+        name:
+          type: string
+      x-kind: animal
+`
+
+func TestFormat(t *testing.T) {
+	out, err := Format([]byte(scrambled), nil)
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(out))
+
+	// formatting a formatted document changes nothing.
+	again, err := Format(out, nil)
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(again))
+}
+
+func TestFormat_SortPathsAndIndent(t *testing.T) {
+	out, err := Format([]byte(scrambled), &FormatConfig{SortPaths: true, Indent: 4})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "paths:\n    /animals:\n        get:\n")
+	assert.Less(t, indexOf(string(out), "/animals:"), indexOf(string(out), "/zoo:"))
+}
+
+func TestFormat_JSON(t *testing.T) {
+	out, err := Format([]byte(`{"info": {"version": "1", "title": "t"}, "openapi": "3.0.3", "paths": {}}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"openapi\": \"3.0.3\",\n  \"info\": {\n    \"title\": \"t\",\n    \"version\": \"1\"\n  },\n"+
+		"  \"paths\": {}\n}\n", string(out))
+}
+
+func TestFormat_Swagger(t *testing.T) {
+	spec := `definitions:
+  Pet:
+    type: object
+  Error:
+    type: string
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          schema:
+            $ref: '#/definitions/Pet'
+          description: ok
+swagger: "2.0"
+info:
+  title: pets
+  version: "1"
+`
+	out, err := Format([]byte(spec), nil)
+	require.NoError(t, err)
+	assert.Equal(t, `swagger: "2.0"
+info:
+  title: pets
+  version: "1"
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          schema:
+            $ref: '#/definitions/Pet'
+definitions:
+  Error:
+    type: string
+  Pet:
+    type: object
+`, string(out))
+}
+
+func TestFormatDocument_MultiFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	write("openapi.yaml", `openapi: 3.1.0
+info:
+  version: 1.0.0
+  title: split
+paths:
+  /pets:
+    $ref: 'paths/pets.yaml'
+`)
+	write("paths/pets.yaml", `get:
+  responses:
+    "200":
+      content:
+        application/json:
+          schema:
+            $ref: '../schemas/pet.json#/Pet'
+      description: ok
+  operationId: listPets
+`)
+	write("schemas/pet.json", `{"Pet": {"properties": {"name": {"type": "string"}}, "type": "object"}}`)
+
+	spec, err := os.ReadFile(filepath.Join(dir, "openapi.yaml"))
+	require.NoError(t, err)
+	config := datamodel.NewDocumentConfiguration()
+	config.BasePath = dir
+	doc, err := libopenapi.NewDocumentWithConfiguration(spec, config)
+	require.NoError(t, err)
+
+	result, err := FormatDocument(doc, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Files, 3)
+	assert.Equal(t, "openapi: 3.1.0\ninfo:\n  title: split\n  version: 1.0.0\npaths:\n  /pets:\n    $ref: paths/pets.yaml\n",
+		string(result.Bytes))
+	assert.Equal(t, `get:
+  operationId: listPets
+  responses:
+    "200":
+      description: ok
+      content:
+        application/json:
+          schema:
+            $ref: ../schemas/pet.json#/Pet
+`, string(result.Files[filepath.Join(dir, "paths/pets.yaml")]))
+	assert.Equal(t, "{\n  \"Pet\": {\n    \"type\": \"object\",\n    \"properties\": {\n      \"name\": {\n"+
+		"        \"type\": \"string\"\n      }\n    }\n  }\n}\n", string(result.Files[filepath.Join(dir, "schemas/pet.json")]))
+
+	// the document is not modified.
+	assert.Equal(t, "version", doc.GetSpecInfo().RootNode.Content[0].Content[3].Content[0].Value)
+}
+
+func TestFormat_Errors(t *testing.T) {
+	_, err := FormatDocument(nil, nil)
+	assert.Equal(t, "unable to format, document has not been initialized", err.Error())
+
+	_, err = Format([]byte("hello: world"), nil)
+	assert.Error(t, err)
+
+	_, err = Format([]byte(""), nil)
+	assert.Error(t, err)
+}
+
+func indexOf(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if s[i:i+len(sub)] == sub {
+			return i
+		}
+	}
+	return -1
+}
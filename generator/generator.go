@@ -0,0 +1,225 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package generator generates Go code from the high-level model of OpenAPI 3+ documents.
+//
+// GenerateTypes generates a Go type for every schema of the components of a document. Schemas are mapped to Go as
+// follows:
+//
+//   - objects with properties become structs, optional and nullable properties become pointers (unless they are
+//     slices, maps or untyped).
+//   - enums of strings and numbers become a named type and a typed constant for each value.
+//   - a 'oneOf' becomes a struct holding a sealed interface, only the types of the 'oneOf' implement it. JSON is
+//     decoded using the discriminator when there is one, or into the first type that fits otherwise.
+//   - an 'allOf' is flattened into a single schema first, see base.Schema.Flatten.
+//   - schemas that cannot be typed (no type, several types, 'anyOf') become 'any'.
+//
+// The 'x-go-name' extension overrides the name of a type (on a component schema) or a field (on a property schema).
+// The 'x-go-type' extension replaces a schema with an existing Go type, such as 'time.Time' or
+// 'github.com/google/uuid.UUID', the package is imported automatically.
+//
+// GenerateServer generates the same types, together with a net/http server for the operations of the document: a
+// Server interface to implement, and a handler that routes and decodes requests for it.
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// GoNameExtension overrides the name of a generated type or field.
+	GoNameExtension = "x-go-name"
+
+	// GoTypeExtension replaces the generated type of a schema with an existing Go type.
+	GoTypeExtension = "x-go-type"
+)
+
+// Config is used to configure the generated code.
+type Config struct {
+	// PackageName is the name of the generated package, defaults to 'api'.
+	PackageName string
+}
+
+// goGenerator collects the declarations and imports of a generated file.
+type goGenerator struct {
+	config  *Config
+	imports map[string]bool
+	names   map[string]bool
+	decls   []string
+	helpers map[string]string
+	errs    []error
+}
+
+func newGoGenerator(config *Config) *goGenerator {
+	if config == nil {
+		config = &Config{}
+	}
+	return &goGenerator{
+		config:  config,
+		imports: make(map[string]bool),
+		names:   make(map[string]bool),
+		helpers: make(map[string]string),
+	}
+}
+
+// reserve returns a unique type name based on name, and reserves it.
+func (g *goGenerator) reserve(name string) string {
+	if name == "" {
+		name = "Type"
+	}
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// declare adds an empty declaration, filled in later, so declarations appear in the order they were started.
+func (g *goGenerator) declare() int {
+	g.decls = append(g.decls, "")
+	return len(g.decls) - 1
+}
+
+// helper adds a declaration that is shared by several types, once.
+func (g *goGenerator) helper(name, decl string) {
+	g.helpers[name] = decl
+}
+
+// externalType returns the Go expression of an 'x-go-type' value, importing its package.
+func (g *goGenerator) externalType(value string) string {
+	rest := strings.TrimLeft(value, "[]*")
+	prefix := value[:len(value)-len(rest)]
+	dot := strings.LastIndex(rest, ".")
+	if dot <= 0 || dot < strings.LastIndex(rest, "/") {
+		return value
+	}
+	pkg := rest[:dot]
+	g.imports[pkg] = true
+	return prefix + path.Base(pkg) + "." + rest[dot+1:]
+}
+
+// source returns the formatted source of the file.
+func (g *goGenerator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	name := g.config.PackageName
+	if name == "" {
+		name = "api"
+	}
+	buf.WriteString("// Code generated by libopenapi. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", name)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for i := range g.imports {
+			imports = append(imports, i)
+		}
+		// the standard library comes first, like goimports does.
+		sort.Slice(imports, func(i, j int) bool {
+			si, sj := standardLibrary(imports[i]), standardLibrary(imports[j])
+			if si != sj {
+				return si
+			}
+			return imports[i] < imports[j]
+		})
+		buf.WriteString("\nimport (\n")
+		for n, i := range imports {
+			if n > 0 && standardLibrary(imports[n-1]) != standardLibrary(i) {
+				buf.WriteString("\n")
+			}
+			fmt.Fprintf(&buf, "\t%q\n", i)
+		}
+		buf.WriteString(")\n")
+	}
+	for _, d := range g.decls {
+		if d != "" {
+			buf.WriteString("\n" + d)
+		}
+	}
+	helpers := make([]string, 0, len(g.helpers))
+	for h := range g.helpers {
+		helpers = append(helpers, h)
+	}
+	sort.Strings(helpers)
+	for _, h := range helpers {
+		buf.WriteString("\n" + g.helpers[h])
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return buf.Bytes(), fmt.Errorf("unable to format generated code: %w", err)
+	}
+	return out, errors.Join(g.errs...)
+}
+
+// standardLibrary reports if an import path is a package of the standard library.
+func standardLibrary(pkg string) bool {
+	return !strings.Contains(strings.Split(pkg, "/")[0], ".")
+}
+
+// initialisms are written in upper case, as Go does.
+var initialisms = map[string]bool{
+	"api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "eof": true, "guid": true, "html": true,
+	"http": true, "https": true, "id": true, "ip": true, "json": true, "sql": true, "ssh": true, "tcp": true,
+	"tls": true, "ttl": true, "udp": true, "ui": true, "uid": true, "uri": true, "url": true, "utf8": true,
+	"uuid": true, "xml": true,
+}
+
+// goName turns a name from a document (a component name, a property, an enum value) into an exported Go identifier.
+func goName(name string) string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		// a new word starts at an upper case letter that follows a lower case letter or a digit.
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) && len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	var b strings.Builder
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	out := b.String()
+	if out != "" && unicode.IsDigit([]rune(out)[0]) {
+		out = "N" + out
+	}
+	return out
+}
+
+// comment formats a description as a Go comment.
+func comment(description, indent string) string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(description, "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+	return b.String()
+}
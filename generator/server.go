@@ -0,0 +1,815 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// GenerateServer generates a net/http server for the operations of a document, in a single file with:
+//
+//   - the types of the component schemas, see GenerateTypes.
+//   - a Server interface, with a method for every operation, named after its operationId (or method and path when
+//     it has none). Each method receives a response writer with a method for every response status code, the
+//     request, the decoded parameters (if there are any) and the decoded JSON request body (if there is one).
+//   - a parameters struct for every operation, parameters are decoded according to their 'style' and 'explode'.
+//     Primitives, enums, arrays of them and objects with primitive properties are decoded, other parameters are
+//     passed as the raw (serialized) string.
+//   - NewHandler, which routes requests to a Server as an http.Handler.
+//
+// Operations are routed in the order of the document, paths are matched as they are written (use http.StripPrefix to
+// serve them below the path of a server). Paths with literal segments take precedence over templated ones.
+func GenerateServer(document *v3.Document, config *Config) ([]byte, error) {
+	g := newGoGenerator(config)
+	for _, name := range serverNames {
+		g.names[name] = true
+	}
+	s := &serverGenerator{typeGenerator: newTypeGenerator(g), methods: make(map[string]bool)}
+	if document.Components != nil {
+		s.components(document.Components.Schemas)
+	}
+	server := s.declare()
+	if document.Paths != nil {
+		for pair := orderedmap.First(document.Paths.PathItems); pair != nil; pair = pair.Next() {
+			s.pathItem(pair.Key(), pair.Value())
+		}
+	}
+	s.decls[server] = s.serverDecl()
+	s.use("router")
+	return g.source()
+}
+
+// serverNames are the names of the declarations every server has.
+var serverNames = []string{"Server", "NewHandler", "RequestError"}
+
+type serverGenerator struct {
+	*typeGenerator
+	methods    map[string]bool
+	operations []*operation
+}
+
+// operation is the generated code of an operation.
+type operation struct {
+	name    string
+	method  string
+	path    string
+	summary string
+	params  string
+	body    string
+	writer  string
+	handler string
+}
+
+// parameter is a parameter of an operation.
+type parameter struct {
+	*v3.Parameter
+	field    string
+	style    string
+	explode  bool
+	required bool
+}
+
+func (s *serverGenerator) pathItem(path string, pi *v3.PathItem) {
+	for pair := orderedmap.First(pi.GetOperations()); pair != nil; pair = pair.Next() {
+		// parameters of the operation override the parameters of the path.
+		var params []*v3.Parameter
+		overridden := make(map[string]bool)
+		for _, p := range pair.Value().Parameters {
+			overridden[p.In+":"+p.Name] = true
+		}
+		for _, p := range pi.Parameters {
+			if !overridden[p.In+":"+p.Name] {
+				params = append(params, p)
+			}
+		}
+		params = append(params, pair.Value().Parameters...)
+		s.operation(strings.ToUpper(pair.Key()), path, pair.Value(), params)
+	}
+}
+
+func (s *serverGenerator) operation(method, path string, op *v3.Operation, params []*v3.Parameter) {
+	name := goName(op.OperationId)
+	if name == "" {
+		name = goName(strings.ToLower(method) + " " + path)
+	}
+	unique := name
+	for i := 2; s.methods[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	s.methods[unique] = true
+
+	o := &operation{name: unique, method: method, path: path, summary: op.Summary}
+	if o.summary == "" {
+		o.summary = op.Description
+	}
+	o.handler = "handle" + unique
+	s.operations = append(s.operations, o)
+
+	// the handler is declared first, the types it uses follow it.
+	handler := s.declare()
+	var decode string
+	if len(params) > 0 {
+		o.params = s.reserve(unique + "Params")
+		decode = "decode" + o.params
+		s.paramsDecl(o.params, decode, params)
+	}
+	var body string
+	if op.RequestBody != nil {
+		body = s.requestBody(o, op.RequestBody)
+	}
+	o.writer = s.reserve(unique + "ResponseWriter")
+	s.writerDecl(o, op.Responses)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %s(s Server, w http.ResponseWriter, r *http.Request, path map[string]string) error {\n", o.handler)
+	args := fmt.Sprintf("%s{w}, r", o.writer)
+	if o.params != "" {
+		fmt.Fprintf(&b, "params, err := %s(r, path)\nif err != nil {\nreturn err\n}\n", decode)
+		args += ", params"
+	}
+	if o.body != "" {
+		b.WriteString(body)
+		args += ", body"
+	}
+	fmt.Fprintf(&b, "s.%s(%s)\nreturn nil\n}\n", unique, args)
+	s.decls[handler] = b.String()
+}
+
+// requestBody returns the code that decodes the JSON request body of an operation.
+func (s *serverGenerator) requestBody(o *operation, rb *v3.RequestBody) string {
+	var media *v3.MediaType
+	for pair := orderedmap.First(rb.Content); pair != nil; pair = pair.Next() {
+		if isJSON(pair.Key()) {
+			media = pair.Value()
+			break
+		}
+	}
+	if media == nil {
+		// other media types are read from the request by the handler.
+		return ""
+	}
+	goType := s.proxyType(media.Schema, o.name+"RequestBody")
+	s.imports["encoding/json"] = true
+	if rb.Required != nil && *rb.Required || untyped(goType) {
+		o.body = goType
+		return fmt.Sprintf("var body %s\nif err := json.NewDecoder(r.Body).Decode(&body); err != nil {\n"+
+			"return &RequestError{In: \"body\", Err: err}\n}\n", goType)
+	}
+	s.imports["errors"] = true
+	s.imports["io"] = true
+	o.body = "*" + goType
+	return fmt.Sprintf("var body *%s\n{\nvar value %s\nif err := json.NewDecoder(r.Body).Decode(&value); err == nil {\n"+
+		"body = &value\n} else if !errors.Is(err, io.EOF) {\nreturn &RequestError{In: \"body\", Err: err}\n}\n}\n",
+		goType, goType)
+}
+
+// isJSON reports if a media type is JSON.
+func isJSON(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func (s *serverGenerator) paramsDecl(name, decode string, params []*v3.Parameter) {
+	d := s.declare()
+	fields := make(map[string]bool)
+	var decl, code strings.Builder
+	fmt.Fprintf(&decl, "// %s are the parameters of %s.\ntype %s struct {\n", name, strings.TrimSuffix(name, "Params"), name)
+	fmt.Fprintf(&code, "func %s(r *http.Request, path map[string]string) (%s, error) {\nvar params %s\n", decode, name, name)
+	for _, param := range params {
+		p := parameter{Parameter: param, style: param.Style, required: param.In == "path"}
+		if param.Required != nil && *param.Required {
+			p.required = true
+		}
+		if p.style == "" {
+			p.style = "simple"
+			if param.In == "query" || param.In == "cookie" {
+				p.style = "form"
+			}
+		}
+		p.explode = p.style == "form"
+		if param.Explode != nil {
+			p.explode = *param.Explode
+		}
+		field := goName(param.Name)
+		if param.Extensions != nil {
+			if n, ok := param.Extensions.Get(GoNameExtension); ok && n.Value != "" {
+				field = n.Value
+			}
+		}
+		if field == "" {
+			field = "Param"
+		}
+		p.field = field
+		for i := 2; fields[p.field]; i++ {
+			p.field = field + strconv.Itoa(i)
+		}
+		fields[p.field] = true
+
+		goType, decoder, pointer := s.paramDecoder(p, name+p.field)
+		if pointer {
+			goType = "*" + goType
+		}
+		decl.WriteString(comment(param.Description, "\t"))
+		fmt.Fprintf(&decl, "\t%s %s\n", p.field, goType)
+		code.WriteString(decoder)
+		if p.required {
+			fmt.Fprintf(&code, " else {\nreturn params, %s\n}\n", requestError(p, "errMissing"))
+		} else {
+			code.WriteString("\n")
+		}
+	}
+	decl.WriteString("}\n\n")
+	code.WriteString("return params, nil\n}\n")
+	s.decls[d] = decl.String() + code.String()
+	s.use("params")
+}
+
+// requestError returns the expression of an error decoding a parameter.
+func requestError(p parameter, err string) string {
+	return fmt.Sprintf("&RequestError{In: %q, Name: %q, Err: %s}", p.In, p.Name, err)
+}
+
+// assign returns the statement setting a field to a value, taking the address if the field is a pointer.
+func assign(target, value string, pointer bool) string {
+	if pointer {
+		return fmt.Sprintf("%s = &%s\n", target, value)
+	}
+	return fmt.Sprintf("%s = %s\n", target, value)
+}
+
+// paramDecoder returns the Go type of a parameter, the code decoding it (an if statement without an else), and if the
+// field of the parameter is a pointer.
+func (s *serverGenerator) paramDecoder(p parameter, hint string) (string, string, bool) {
+	call := func(fn string, extra ...string) string {
+		args := append([]string{"r", "path", strconv.Quote(p.In), strconv.Quote(p.style)}, extra...)
+		return fmt.Sprintf("%s(%s)", fn, strings.Join(args, ", "))
+	}
+	target := "params." + p.field
+	var sch *base.Schema
+	if p.Schema != nil {
+		sch = p.Schema.Schema()
+	}
+	isPointer := func(goType string) bool {
+		return (!p.required || nullable(sch)) && !untyped(goType)
+	}
+	if goType, prim, ok := s.scalar(p.Schema, hint); ok {
+		pointer := isPointer(goType)
+		return goType, fmt.Sprintf("if v, ok := %s; ok {\n%s%s}", call("paramValue", strconv.Quote(p.Name)),
+			s.convert("v", "value", goType, prim, p), assign(target, "value", pointer)), pointer
+	}
+
+	if sch != nil && extension(sch, GoTypeExtension) == "" {
+		f := s.effective(sch)
+		switch k, _ := kind(f); k {
+		case kindArray:
+			if f.Items == nil || f.Items.IsB() {
+				break
+			}
+			goType := s.proxyType(p.Schema, hint)
+			itemType, prim, ok := s.scalar(f.Items.A, hint+"Item")
+			if !ok {
+				break
+			}
+			values := "values"
+			if goType != "[]"+itemType {
+				values = goType + "(values)"
+			}
+			return goType, fmt.Sprintf("if vs, ok := %s; ok {\nvalues := make([]%s, 0, len(vs))\n"+
+				"for _, v := range vs {\n%svalues = append(values, value)\n}\n%s = %s\n}",
+				call("paramList", strconv.FormatBool(p.explode), strconv.Quote(p.Name)), itemType,
+				s.convert("v", "value", itemType, prim, p), target, values), false
+
+		case kindStruct:
+			goType := s.proxyType(p.Schema, hint)
+			pointer := isPointer(goType)
+			var properties []string
+			var fields strings.Builder
+			for _, fl := range s.fields(f, goType) {
+				if fl.schema == nil || extension(fl.schema, GoTypeExtension) != "" {
+					continue
+				}
+				ff := s.effective(fl.schema)
+				fk, typ := kind(ff)
+				if fk != kindPrimitive && fk != kindEnum {
+					continue
+				}
+				properties = append(properties, strconv.Quote(fl.property))
+				fmt.Fprintf(&fields, "if v, ok := m[%q]; ok {\n%s%s}\n", fl.property,
+					s.convert("v", "value", strings.TrimPrefix(fl.goType, "*"), s.primitive(typ, ff.Format), p),
+					assign("object."+fl.name, "value", strings.HasPrefix(fl.goType, "*")))
+			}
+			return goType, fmt.Sprintf("if m, ok := %s; ok {\nvar object %s\n%s%s}",
+				call("paramObject", strconv.FormatBool(p.explode), strconv.Quote(p.Name),
+					"[]string{"+strings.Join(properties, ", ")+"}"),
+				goType, fields.String(), assign(target, "object", pointer)), pointer
+		}
+	}
+
+	// everything else is passed as it is.
+	pointer := isPointer("string")
+	return "string", fmt.Sprintf("if v, ok := %s; ok {\n%s}", call("paramValue", strconv.Quote(p.Name)),
+		assign(target, "v", pointer)), pointer
+}
+
+// scalar returns the Go type and the primitive Go type of a schema that is a primitive or an enum.
+func (s *serverGenerator) scalar(sp *base.SchemaProxy, hint string) (string, string, bool) {
+	if sp == nil {
+		return "", "", false
+	}
+	sch := sp.Schema()
+	if sch == nil || extension(sch, GoTypeExtension) != "" {
+		return "", "", false
+	}
+	f := s.effective(sch)
+	k, typ := kind(f)
+	if k != kindPrimitive && k != kindEnum {
+		return "", "", false
+	}
+	return s.proxyType(sp, hint), s.primitive(typ, f.Format), true
+}
+
+// parsers are the helpers parsing primitive values, by Go type.
+var parsers = map[string]string{
+	"int64":     "parseInt64",
+	"int32":     "parseInt32",
+	"float64":   "parseFloat64",
+	"float32":   "parseFloat32",
+	"bool":      "parseBool",
+	"time.Time": "parseTime",
+	"[]byte":    "parseBytes",
+}
+
+// convert returns the statements that parse the string variable in into the variable out of type goType.
+func (s *serverGenerator) convert(in, out, goType, prim string, p parameter) string {
+	parser, ok := parsers[prim]
+	if !ok {
+		if goType == "string" {
+			return fmt.Sprintf("%s := %s\n", out, in)
+		}
+		return fmt.Sprintf("%s := %s(%s)\n", out, goType, in)
+	}
+	s.use(parser)
+	parsed := out
+	if goType != prim {
+		parsed = "parsed"
+	}
+	code := fmt.Sprintf("%s, err := %s(%s)\nif err != nil {\nreturn params, %s\n}\n", parsed, parser, in,
+		requestError(p, "err"))
+	if goType != prim {
+		code += fmt.Sprintf("%s := %s(parsed)\n", out, goType)
+	}
+	return code
+}
+
+func (s *serverGenerator) writerDecl(o *operation, responses *v3.Responses) {
+	d := s.declare()
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s writes the responses of %s, headers can be set before a response is written.\n", o.writer, o.name)
+	fmt.Fprintf(&b, "type %s struct {\nhttp.ResponseWriter\n}\n", o.writer)
+	if responses != nil {
+		for pair := orderedmap.First(responses.Codes); pair != nil; pair = pair.Next() {
+			b.WriteString(s.writeMethod(o, strings.ToUpper(pair.Key()), pair.Value()))
+		}
+		if responses.Default != nil {
+			b.WriteString(s.writeMethod(o, "Default", responses.Default))
+		}
+	}
+	s.decls[d] = b.String()
+}
+
+// writeMethod returns the method writing a response. Ranges of status codes and the default response take the status
+// code to write.
+func (s *serverGenerator) writeMethod(o *operation, code string, r *v3.Response) string {
+	method := "Write" + code
+	var params []string
+	status := code
+	if _, err := strconv.Atoi(code); err != nil {
+		params = append(params, "status int")
+		status = "status"
+	}
+
+	var contentType, goType string
+	for pair := orderedmap.First(r.Content); pair != nil; pair = pair.Next() {
+		if isJSON(pair.Key()) {
+			contentType = pair.Key()
+			goType = s.proxyType(pair.Value().Schema, o.name+code+"Response")
+			break
+		}
+		if contentType == "" {
+			contentType, goType = pair.Key(), "[]byte"
+		}
+	}
+
+	var body string
+	switch {
+	case contentType == "":
+		body = fmt.Sprintf("w.WriteHeader(%s)\nreturn nil", status)
+	case goType == "[]byte":
+		params = append(params, "body []byte")
+		body = fmt.Sprintf("return writeBytes(w.ResponseWriter, %q, %s, body)", contentType, status)
+		s.use("writeBytes")
+	default:
+		params = append(params, "body "+goType)
+		body = fmt.Sprintf("return writeJSON(w.ResponseWriter, %q, %s, body)", contentType, status)
+		s.use("writeJSON")
+		s.use("writeBytes")
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	description := strings.TrimSpace(r.Description)
+	if code == "Default" {
+		fmt.Fprintf(&b, "// %s writes the default response", method)
+	} else {
+		fmt.Fprintf(&b, "// %s writes a %s response", method, code)
+	}
+	if description != "" {
+		b.WriteString(": " + strings.Split(description, "\n")[0])
+	}
+	fmt.Fprintf(&b, "\nfunc (w %s) %s(%s) error {\n%s\n}\n", o.writer, method, strings.Join(params, ", "), body)
+	return b.String()
+}
+
+func (s *serverGenerator) serverDecl() string {
+	var b strings.Builder
+	b.WriteString("// Server is implemented by the handlers of the operations of the API, use NewHandler to serve it.\n")
+	b.WriteString("type Server interface {\n")
+	for i, o := range s.operations {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(comment(o.summary, "\t"))
+		args := fmt.Sprintf("w %s, r *http.Request", o.writer)
+		if o.params != "" {
+			args += ", params " + o.params
+		}
+		if o.body != "" {
+			args += ", body " + o.body
+		}
+		fmt.Fprintf(&b, "\t%s(%s)\n", o.name, args)
+	}
+	b.WriteString("}\n\n// routes are the operations of the API.\nvar routes = []route{\n")
+	for _, o := range s.operations {
+		fmt.Fprintf(&b, "\t{method: %q, path: %q, handle: %s},\n", o.method, o.path, o.handler)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// use adds a helper (and the packages it imports) to the generated code.
+func (s *serverGenerator) use(name string) {
+	h := serverHelpers[name]
+	for _, i := range h.imports {
+		s.imports[i] = true
+	}
+	s.helper(name, h.code)
+}
+
+type serverHelper struct {
+	imports []string
+	code    string
+}
+
+var serverHelpers = map[string]serverHelper{
+	"router": {[]string{"errors", "fmt", "net/http", "net/url", "strings"}, `// RequestError is passed to the error handler of NewHandler when a request cannot be decoded.
+type RequestError struct {
+	// In is where the value is: path, query, header, cookie or body.
+	In string
+
+	// Name is the name of the parameter, it is empty for the body.
+	Name string
+
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("invalid request %s: %v", e.In, e.Err)
+	}
+	return fmt.Sprintf("invalid %s parameter '%s': %v", e.In, e.Name, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// errMissing is the error of a required parameter that is missing.
+var errMissing = errors.New("value is required")
+
+type route struct {
+	method string
+	path   string
+	handle func(s Server, w http.ResponseWriter, r *http.Request, path map[string]string) error
+}
+
+type handler struct {
+	server  Server
+	onError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// NewHandler returns an http.Handler that calls the operations of s. Requests that cannot be decoded are passed to
+// onError, which responds with 400 Bad Request when it is nil.
+func NewHandler(s Server, onError func(w http.ResponseWriter, r *http.Request, err error)) http.Handler {
+	return &handler{server: s, onError: onError}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var match *route
+	var params map[string]string
+	best, allowed := -1, false
+	for i := range routes {
+		p, literals, ok := matchPath(routes[i].path, r.URL.EscapedPath())
+		if !ok || literals <= best {
+			continue
+		}
+		if routes[i].method != r.Method {
+			allowed = true
+			continue
+		}
+		match, params, best = &routes[i], p, literals
+	}
+	if match == nil {
+		if allowed {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+	if err := match.handle(h.server, w, r, params); err != nil {
+		if h.onError != nil {
+			h.onError(w, r, err)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// matchPath matches a path with the path of a route, it returns the path parameters and the number of literal
+// segments of the route.
+func matchPath(route, path string) (map[string]string, int, bool) {
+	rs := strings.Split(strings.Trim(route, "/"), "/")
+	ps := strings.Split(strings.Trim(path, "/"), "/")
+	if len(rs) != len(ps) {
+		return nil, 0, false
+	}
+	params := make(map[string]string)
+	literals := 0
+	for i, segment := range rs {
+		if !strings.Contains(segment, "{") {
+			if segment != ps[i] {
+				return nil, 0, false
+			}
+			literals++
+			continue
+		}
+		if !matchSegment(segment, ps[i], params) {
+			return nil, 0, false
+		}
+	}
+	return params, literals, true
+}
+
+// matchSegment matches a segment of a path with a segment of a route that has parameters, such as '{name}.{ext}'.
+func matchSegment(segment, value string, params map[string]string) bool {
+	for segment != "" {
+		open := strings.Index(segment, "{")
+		if open < 0 {
+			return segment == value
+		}
+		if !strings.HasPrefix(value, segment[:open]) {
+			return false
+		}
+		value, segment = value[open:], segment[open+1:]
+		end := strings.Index(segment, "}")
+		if end < 0 {
+			return false
+		}
+		name := segment[:end]
+		segment = segment[end+1:]
+
+		// the value of a parameter ends where the text that follows it starts.
+		n := len(value)
+		if next := strings.Index(segment, "{"); next > 0 {
+			if n = strings.Index(value, segment[:next]); n < 0 {
+				return false
+			}
+		} else if next < 0 && segment != "" {
+			if !strings.HasSuffix(value, segment) {
+				return false
+			}
+			n = len(value) - len(segment)
+		}
+		v, err := url.PathUnescape(value[:n])
+		if err != nil {
+			return false
+		}
+		params[name] = v
+		value = value[n:]
+	}
+	return value == ""
+}
+`},
+	"params": {[]string{"net/http", "strings"}, `// rawParam returns the serialized value of a path, header or cookie parameter.
+func rawParam(r *http.Request, path map[string]string, in, name string) (string, bool) {
+	switch in {
+	case "path":
+		v, ok := path[name]
+		return v, ok
+	case "header":
+		vs := r.Header.Values(name)
+		return strings.Join(vs, ","), len(vs) > 0
+	case "cookie":
+		c, err := r.Cookie(name)
+		if err != nil {
+			return "", false
+		}
+		return c.Value, true
+	}
+	return "", false
+}
+
+// paramValue returns the value of a parameter that is a primitive.
+func paramValue(r *http.Request, path map[string]string, in, style, name string) (string, bool) {
+	if in == "query" {
+		q := r.URL.Query()
+		if !q.Has(name) {
+			return "", false
+		}
+		return q.Get(name), true
+	}
+	v, ok := rawParam(r, path, in, name)
+	switch style {
+	case "label":
+		v = strings.TrimPrefix(v, ".")
+	case "matrix":
+		v = strings.TrimPrefix(v, ";"+name+"=")
+	}
+	return v, ok
+}
+
+// paramList returns the values of a parameter that is an array.
+func paramList(r *http.Request, path map[string]string, in, style string, explode bool, name string) ([]string, bool) {
+	if in == "query" {
+		vs, ok := r.URL.Query()[name]
+		if !ok {
+			return nil, false
+		}
+		if style == "form" && explode {
+			return vs, true
+		}
+		switch style {
+		case "spaceDelimited":
+			return strings.Split(vs[0], " "), true
+		case "pipeDelimited":
+			return strings.Split(vs[0], "|"), true
+		}
+		return strings.Split(vs[0], ","), true
+	}
+	v, ok := rawParam(r, path, in, name)
+	if !ok {
+		return nil, false
+	}
+	sep := ","
+	switch style {
+	case "label":
+		v = strings.TrimPrefix(v, ".")
+		if explode {
+			sep = "."
+		}
+	case "matrix":
+		if explode {
+			var values []string
+			for _, part := range strings.Split(strings.TrimPrefix(v, ";"), ";") {
+				values = append(values, strings.TrimPrefix(part, name+"="))
+			}
+			return values, true
+		}
+		v = strings.TrimPrefix(v, ";"+name+"=")
+	}
+	if v == "" {
+		return []string{}, true
+	}
+	return strings.Split(v, sep), true
+}
+
+// paramObject returns the properties of a parameter that is an object.
+func paramObject(r *http.Request, path map[string]string, in, style string, explode bool, name string, properties []string) (map[string]string, bool) {
+	values := make(map[string]string)
+	if in == "query" {
+		q := r.URL.Query()
+		switch {
+		case style == "deepObject":
+			for _, p := range properties {
+				if key := name + "[" + p + "]"; q.Has(key) {
+					values[p] = q.Get(key)
+				}
+			}
+			return values, len(values) > 0
+		case explode:
+			for _, p := range properties {
+				if q.Has(p) {
+					values[p] = q.Get(p)
+				}
+			}
+			return values, len(values) > 0
+		}
+		if !q.Has(name) {
+			return nil, false
+		}
+		return pairs(strings.Split(q.Get(name), ",")), true
+	}
+	v, ok := rawParam(r, path, in, name)
+	if !ok {
+		return nil, false
+	}
+	sep := ","
+	switch style {
+	case "label":
+		v = strings.TrimPrefix(v, ".")
+		if explode {
+			sep = "."
+		}
+	case "matrix":
+		if explode {
+			v, sep = strings.TrimPrefix(v, ";"), ";"
+		} else {
+			v = strings.TrimPrefix(v, ";"+name+"=")
+		}
+	}
+	parts := strings.Split(v, sep)
+	if !explode {
+		return pairs(parts), true
+	}
+	for _, part := range parts {
+		k, value, _ := strings.Cut(part, "=")
+		values[k] = value
+	}
+	return values, true
+}
+
+// pairs turns a list of alternating keys and values into a map.
+func pairs(parts []string) map[string]string {
+	values := make(map[string]string)
+	for i := 0; i+1 < len(parts); i += 2 {
+		values[parts[i]] = parts[i+1]
+	}
+	return values
+}
+`},
+	"writeJSON": {[]string{"encoding/json", "net/http"}, `// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, contentType string, status int, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return writeBytes(w, contentType, status, data)
+}
+`},
+	"writeBytes": {[]string{"net/http"}, `// writeBytes writes a response.
+func writeBytes(w http.ResponseWriter, contentType string, status int, body []byte) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err := w.Write(body)
+	return err
+}
+`},
+	"parseInt64": {[]string{"strconv"}, `func parseInt64(v string) (int64, error) {
+	return strconv.ParseInt(v, 10, 64)
+}
+`},
+	"parseInt32": {[]string{"strconv"}, `func parseInt32(v string) (int32, error) {
+	i, err := strconv.ParseInt(v, 10, 32)
+	return int32(i), err
+}
+`},
+	"parseFloat64": {[]string{"strconv"}, `func parseFloat64(v string) (float64, error) {
+	return strconv.ParseFloat(v, 64)
+}
+`},
+	"parseFloat32": {[]string{"strconv"}, `func parseFloat32(v string) (float32, error) {
+	f, err := strconv.ParseFloat(v, 32)
+	return float32(f), err
+}
+`},
+	"parseBool": {[]string{"strconv"}, `func parseBool(v string) (bool, error) {
+	return strconv.ParseBool(v)
+}
+`},
+	"parseTime": {[]string{"time"}, `func parseTime(v string) (time.Time, error) {
+	return time.Parse(time.RFC3339, v)
+}
+`},
+	"parseBytes": {[]string{"encoding/base64"}, `func parseBytes(v string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(v)
+}
+`},
+}
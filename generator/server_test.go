@@ -0,0 +1,273 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var serverSpec = `openapi: 3.1.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List all pets.
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            format: int32
+        - name: tags
+          in: query
+          explode: false
+          schema:
+            type: array
+            items:
+              type: string
+        - name: filter
+          in: query
+          style: deepObject
+          schema:
+            type: object
+            properties:
+              kind:
+                type: string
+                enum: [cat, dog]
+              minAge:
+                type: integer
+        - name: X-Request-ID
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          description: A list of pets.
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        default:
+          description: Unexpected error.
+          content:
+            application/problem+json:
+              schema:
+                $ref: '#/components/schemas/Error'
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: Created.
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema:
+          type: integer
+    get:
+      operationId: showPetById
+      responses:
+        '200':
+          description: A pet.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        4XX:
+          description: Not found.
+          content:
+            text/plain:
+              schema:
+                type: string
+  /pets/mine:
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '204':
+          description: Updated.
+  /pets/{petId}/photos/{photo}.{ext}:
+    get:
+      operationId: getPhoto
+      parameters:
+        - name: petId
+          in: path
+          style: label
+          schema:
+            type: integer
+        - name: photo
+          in: path
+          schema:
+            type: string
+        - name: ext
+          in: path
+          schema:
+            type: string
+      responses:
+        '200':
+          description: A photo.
+          content:
+            image/png: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+    Error:
+      type: object
+      properties:
+        message:
+          type: string`
+
+func generateServer(t *testing.T, spec string) string {
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	out, err := GenerateServer(&model.Model, &Config{PackageName: "main"})
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+func TestGenerateServer(t *testing.T) {
+	code := generateServer(t, serverSpec)
+
+	assert.Contains(t, code, "type Pet struct {")
+	assert.Contains(t, code, "type Server interface {\n"+
+		"\t// List all pets.\n"+
+		"\tListPets(w ListPetsResponseWriter, r *http.Request, params ListPetsParams)\n\n"+
+		"\tCreatePet(w CreatePetResponseWriter, r *http.Request, body Pet)\n\n"+
+		"\tShowPetByID(w ShowPetByIDResponseWriter, r *http.Request, params ShowPetByIDParams)\n\n"+
+		"\tPutPetsMine(w PutPetsMineResponseWriter, r *http.Request, body *Pet)\n\n"+
+		"\tGetPhoto(w GetPhotoResponseWriter, r *http.Request, params GetPhotoParams)\n}")
+
+	assert.Contains(t, code, "type ListPetsParams struct {\n"+
+		"\tLimit      *int32\n"+
+		"\tTags       []string\n"+
+		"\tFilter     *ListPetsParamsFilter\n"+
+		"\tXRequestID string\n}")
+	assert.Contains(t, code, "type ListPetsParamsFilterKind string")
+	assert.Contains(t, code, "func (w ListPetsResponseWriter) Write200(body []Pet) error {\n"+
+		"\treturn writeJSON(w.ResponseWriter, \"application/json\", 200, body)\n}")
+	assert.Contains(t, code, "func (w ListPetsResponseWriter) WriteDefault(status int, body Error) error {")
+	assert.Contains(t, code, "func (w ShowPetByIDResponseWriter) Write4XX(status int, body []byte) error {")
+	assert.Contains(t, code, "func (w CreatePetResponseWriter) Write201() error {\n\tw.WriteHeader(201)\n")
+	assert.Contains(t, code, "\t{method: \"GET\", path: \"/pets/{petId}/photos/{photo}.{ext}\", handle: handleGetPhoto},\n")
+}
+
+func TestGenerateServer_Serves(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+	code := generateServer(t, serverSpec)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gentest\n\ngo 1.21\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.go"), []byte(code), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type pets struct{}
+
+func (pets) ListPets(w ListPetsResponseWriter, r *http.Request, params ListPetsParams) {
+	if params.Limit != nil && *params.Limit == 0 {
+		_ = w.WriteDefault(500, Error{})
+		return
+	}
+	_ = w.Write200([]Pet{{ID: int64(*params.Limit), Name: fmt.Sprintf("%v %v %d %s", params.Tags, *params.Filter.Kind, *params.Filter.MinAge, params.XRequestID)}})
+}
+
+func (pets) CreatePet(w CreatePetResponseWriter, r *http.Request, body Pet) {
+	w.Header().Set("Location", fmt.Sprintf("/pets/%d", body.ID))
+	_ = w.Write201()
+}
+
+func (pets) ShowPetByID(w ShowPetByIDResponseWriter, r *http.Request, params ShowPetByIDParams) {
+	_ = w.Write4XX(404, []byte(fmt.Sprint("no pet ", params.PetID)))
+}
+
+func (pets) PutPetsMine(w PutPetsMineResponseWriter, r *http.Request, body *Pet) {
+	_ = w.Write204()
+}
+
+func (pets) GetPhoto(w GetPhotoResponseWriter, r *http.Request, params GetPhotoParams) {
+	_ = w.Write200([]byte(fmt.Sprint(params.PetID, params.Photo, params.Ext)))
+}
+
+func main() {
+	h := NewHandler(pets{}, nil)
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/pets?limit=5&tags=a,b&filter[kind]=cat&filter[minAge]=2", nil),
+		httptest.NewRequest("GET", "/pets?limit=x", nil),
+		httptest.NewRequest("POST", "/pets", strings.NewReader(`+"`"+`{"id":7,"name":"Tom"}`+"`"+`)),
+		httptest.NewRequest("GET", "/pets/42", nil),
+		httptest.NewRequest("GET", "/pets/nope", nil),
+		httptest.NewRequest("PUT", "/pets/mine", nil),
+		httptest.NewRequest("DELETE", "/pets", nil),
+		httptest.NewRequest("GET", "/pets/.3/photos/front.png", nil),
+		httptest.NewRequest("GET", "/owners", nil),
+	} {
+		req.Header.Set("X-Request-ID", "abc")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		body, _ := io.ReadAll(w.Body)
+		fmt.Println(w.Code, w.Header().Get("Content-Type"), w.Header().Get("Location"), strings.TrimSpace(string(body)))
+	}
+}
+`), 0o644))
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, `200 application/json  [{"id":5,"name":"[a b] cat 2 abc"}]
+400 text/plain; charset=utf-8  invalid query parameter 'limit': strconv.ParseInt: parsing "x": invalid syntax
+201  /pets/7 
+404 text/plain  no pet 42
+400 text/plain; charset=utf-8  invalid path parameter 'petId': strconv.ParseInt: parsing "nope": invalid syntax
+204   
+405 text/plain; charset=utf-8  Method Not Allowed
+200 image/png  3frontpng
+404 text/plain; charset=utf-8  404 page not found
+`, string(out))
+}
+
+func TestGenerateServer_NoPaths(t *testing.T) {
+	out, err := GenerateServer(&v3.Document{}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "type Server interface {\n}")
+}
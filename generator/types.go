@@ -0,0 +1,536 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// componentPrefix is the prefix of references to component schemas.
+const componentPrefix = "#/components/schemas/"
+
+// GenerateTypes generates a Go type for every schema of the components, in the order they are defined. Inline schemas
+// that need a type of their own (objects, enums, 'oneOf') are named after the schema and property they are defined
+// in.
+//
+// The generated code is formatted. Schemas with conflicting 'allOf' members are typed as 'any', and the conflicts are
+// returned as an error, together with the generated code.
+func GenerateTypes(components *v3.Components, config *Config) ([]byte, error) {
+	g := newGoGenerator(config)
+	t := newTypeGenerator(g)
+	if components != nil {
+		t.components(components.Schemas)
+	}
+	return g.source()
+}
+
+// typeGenerator generates Go types for schemas.
+type typeGenerator struct {
+	*goGenerator
+	refs  map[string]string
+	named map[*base.Schema]string
+	flat  map[*base.Schema]*base.Schema
+}
+
+func newTypeGenerator(g *goGenerator) *typeGenerator {
+	return &typeGenerator{
+		goGenerator: g,
+		refs:        make(map[string]string),
+		named:       make(map[*base.Schema]string),
+		flat:        make(map[*base.Schema]*base.Schema),
+	}
+}
+
+// components declares a type for each component schema. Names are reserved first, so references can be resolved
+// while the types are generated.
+func (t *typeGenerator) components(schemas *orderedmap.Map[string, *base.SchemaProxy]) {
+	type component struct {
+		name  string
+		proxy *base.SchemaProxy
+	}
+	var declared []component
+	for pair := orderedmap.First(schemas); pair != nil; pair = pair.Next() {
+		sp := pair.Value()
+		ref := componentPrefix + pair.Key()
+		var s *base.Schema
+		if !sp.IsReference() {
+			s = sp.Schema()
+		}
+		if v := extension(s, GoTypeExtension); v != "" {
+			t.refs[ref] = t.externalType(v)
+			continue
+		}
+		name := goName(pair.Key())
+		if v := extension(s, GoNameExtension); v != "" {
+			name = v
+		}
+		name = t.reserve(name)
+		t.refs[ref] = name
+		declared = append(declared, component{name: name, proxy: sp})
+	}
+	for _, c := range declared {
+		if c.proxy.IsReference() {
+			d := t.declare()
+			t.decls[d] = fmt.Sprintf("type %s = %s\n", c.name, t.proxyType(c.proxy, c.name))
+			continue
+		}
+		if s := c.proxy.Schema(); s != nil {
+			t.declareSchema(s, c.name)
+		}
+	}
+}
+
+// extension returns the string value of an extension of a schema.
+func extension(s *base.Schema, name string) string {
+	if s == nil || s.Extensions == nil {
+		return ""
+	}
+	if n, ok := s.Extensions.Get(name); ok && n != nil && n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	return ""
+}
+
+// proxyType returns the Go type of a schema proxy, hint is used to name the type if one has to be declared.
+func (t *typeGenerator) proxyType(sp *base.SchemaProxy, hint string) string {
+	if sp == nil {
+		return "any"
+	}
+	if sp.IsReference() {
+		ref := sp.GetReference()
+		if name, ok := t.refs[ref]; ok {
+			return name
+		}
+		// references to schemas that are not components (other files) get a type of their own.
+		s := sp.Schema()
+		if s == nil {
+			return "any"
+		}
+		name := ref[strings.LastIndexAny(ref, "/#")+1:]
+		name = strings.TrimSuffix(name, fileExtension(name))
+		if name = goName(name); name == "" {
+			name = hint
+		}
+		t.refs[ref] = t.schemaType(s, name)
+		return t.refs[ref]
+	}
+	return t.schemaType(sp.Schema(), hint)
+}
+
+// fileExtension returns the extension of a file name.
+func fileExtension(name string) string {
+	if i := strings.LastIndex(name, "."); i > 0 {
+		return name[i:]
+	}
+	return ""
+}
+
+// effective returns the schema with its 'allOf' flattened.
+func (t *typeGenerator) effective(s *base.Schema) *base.Schema {
+	if len(s.AllOf) == 0 {
+		return s
+	}
+	if flat, ok := t.flat[s]; ok {
+		return flat
+	}
+	flat, err := s.Flatten()
+	if err != nil {
+		t.errs = append(t.errs, err)
+		flat = nil
+	}
+	t.flat[s] = flat
+	return flat
+}
+
+// schemaKind is how a schema is represented in Go.
+type schemaKind int
+
+const (
+	kindAny schemaKind = iota
+	kindPrimitive
+	kindEnum
+	kindArray
+	kindMap
+	kindStruct
+	kindOneOf
+)
+
+// kind returns how a (flattened) schema is represented, and the non-null type it has.
+func kind(s *base.Schema) (schemaKind, string) {
+	if s == nil {
+		return kindAny, ""
+	}
+	if len(s.OneOf) > 0 {
+		return kindOneOf, ""
+	}
+	if len(s.AnyOf) > 0 {
+		return kindAny, ""
+	}
+	var types []string
+	for _, st := range s.Type {
+		if st != "null" {
+			types = append(types, st)
+		}
+	}
+	if len(types) > 1 {
+		return kindAny, ""
+	}
+	typ := ""
+	if len(types) == 1 {
+		typ = types[0]
+	}
+	switch typ {
+	case "string", "integer", "number":
+		if len(s.Enum) > 0 {
+			return kindEnum, typ
+		}
+		return kindPrimitive, typ
+	case "boolean":
+		return kindPrimitive, typ
+	case "array":
+		return kindArray, typ
+	case "object", "":
+		if s.Properties != nil && s.Properties.Len() > 0 {
+			return kindStruct, "object"
+		}
+		if typ == "object" || s.AdditionalProperties != nil {
+			return kindMap, "object"
+		}
+	}
+	return kindAny, ""
+}
+
+// nullable reports if a schema allows null.
+func nullable(s *base.Schema) bool {
+	if s == nil {
+		return false
+	}
+	if s.Nullable != nil && *s.Nullable {
+		return true
+	}
+	for _, st := range s.Type {
+		if st == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// primitive returns the Go type of a primitive schema, taking its format into account.
+func (t *typeGenerator) primitive(typ, format string) string {
+	switch typ {
+	case "string":
+		switch format {
+		case "date-time":
+			t.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	return "any"
+}
+
+// schemaType returns the Go type of a schema, declaring a type named after hint if the schema needs one.
+func (t *typeGenerator) schemaType(s *base.Schema, hint string) string {
+	if s == nil {
+		return "any"
+	}
+	if v := extension(s, GoTypeExtension); v != "" {
+		return t.externalType(v)
+	}
+	if name, ok := t.named[s]; ok {
+		return name
+	}
+	f := t.effective(s)
+	switch k, typ := kind(f); k {
+	case kindPrimitive:
+		return t.primitive(typ, f.Format)
+	case kindArray:
+		return "[]" + t.itemType(f, hint)
+	case kindMap:
+		return "map[string]" + t.valueType(f, hint)
+	case kindEnum, kindStruct, kindOneOf:
+		name := t.reserve(hint)
+		t.declareSchema(s, name)
+		return name
+	}
+	return "any"
+}
+
+// itemType returns the Go type of the items of an array.
+func (t *typeGenerator) itemType(s *base.Schema, hint string) string {
+	if s.Items == nil || s.Items.IsB() {
+		return "any"
+	}
+	item := t.proxyType(s.Items.A, hint+"Item")
+	if !s.Items.A.IsReference() && nullable(s.Items.A.Schema()) && !untyped(item) {
+		return "*" + item
+	}
+	return item
+}
+
+// valueType returns the Go type of the values of a map.
+func (t *typeGenerator) valueType(s *base.Schema, hint string) string {
+	if s.AdditionalProperties == nil || s.AdditionalProperties.IsB() {
+		return "any"
+	}
+	return t.proxyType(s.AdditionalProperties.A, hint+"Value")
+}
+
+// untyped reports if a Go type already has a zero value that means 'not set', so it does not need a pointer.
+func untyped(goType string) bool {
+	return goType == "any" || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
+
+// declareSchema declares a named type for a schema.
+func (t *typeGenerator) declareSchema(s *base.Schema, name string) {
+	t.named[s] = name
+	d := t.declare()
+	f := t.effective(s)
+	var decl string
+	switch k, typ := kind(f); k {
+	case kindStruct:
+		decl = t.structDecl(f, name)
+	case kindEnum:
+		decl = t.enumDecl(f, name, t.primitive(typ, f.Format))
+	case kindOneOf:
+		decl = t.oneOfDecl(f, name)
+	case kindPrimitive:
+		decl = fmt.Sprintf("type %s %s\n", name, t.primitive(typ, f.Format))
+	case kindArray:
+		decl = fmt.Sprintf("type %s []%s\n", name, t.itemType(f, name))
+	case kindMap:
+		decl = fmt.Sprintf("type %s map[string]%s\n", name, t.valueType(f, name))
+	default:
+		decl = fmt.Sprintf("type %s any\n", name)
+	}
+	t.decls[d] = comment(s.Description, "") + decl
+}
+
+// field is a field of a generated struct.
+type field struct {
+	name        string
+	property    string
+	goType      string
+	description string
+	required    bool
+	schema      *base.Schema
+}
+
+// fields returns the fields of the struct generated for an object schema.
+func (t *typeGenerator) fields(s *base.Schema, name string) []field {
+	var fields []field
+	names := make(map[string]bool)
+	for pair := s.Properties.First(); pair != nil; pair = pair.Next() {
+		sp := pair.Value()
+		var ps *base.Schema
+		if sp != nil && !sp.IsReference() {
+			ps = sp.Schema()
+		}
+		fieldName := goName(pair.Key())
+		if v := extension(ps, GoNameExtension); v != "" {
+			fieldName = v
+		}
+		if fieldName == "" {
+			fieldName = "Field"
+		}
+		unique := fieldName
+		for i := 2; names[unique]; i++ {
+			unique = fieldName + strconv.Itoa(i)
+		}
+		names[unique] = true
+
+		f := field{name: unique, property: pair.Key(), goType: t.proxyType(sp, name+unique), schema: ps}
+		f.required = containsString(s.Required, pair.Key())
+		if ps != nil {
+			f.description = ps.Description
+		}
+		if sp != nil && sp.IsReference() {
+			f.schema = sp.Schema()
+		}
+		if (!f.required || nullable(f.schema)) && !untyped(f.goType) {
+			f.goType = "*" + f.goType
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func (t *typeGenerator) structDecl(s *base.Schema, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, f := range t.fields(s, name) {
+		tag := f.property
+		if !f.required {
+			tag += ",omitempty"
+		}
+		b.WriteString(comment(f.description, "\t"))
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", f.name, f.goType, tag)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (t *typeGenerator) enumDecl(s *base.Schema, name, goType string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s %s\n\nconst (\n", name, goType)
+	consts := make(map[string]bool)
+	for i, v := range s.Enum {
+		if v == nil || v.Kind != yaml.ScalarNode || v.Tag == "!!null" {
+			continue
+		}
+		suffix := goName(v.Value)
+		if suffix == "" {
+			suffix = strconv.Itoa(i)
+		}
+		c := name + suffix
+		for j := 2; consts[c] || t.names[c]; j++ {
+			c = name + suffix + strconv.Itoa(j)
+		}
+		consts[c] = true
+		t.names[c] = true
+		value := v.Value
+		if goType == "string" {
+			value = strconv.Quote(v.Value)
+		}
+		fmt.Fprintf(&b, "\t%s %s = %s\n", c, name, value)
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// variant is a type of a 'oneOf'.
+type variant struct {
+	goType string
+	values []string
+}
+
+func (t *typeGenerator) oneOfDecl(s *base.Schema, name string) string {
+	var variants []variant
+	seen := make(map[string]bool)
+	for i, sp := range s.OneOf {
+		goType := t.proxyType(sp, fmt.Sprintf("%s%d", name, i+1))
+		if !t.local(goType) {
+			// only types of this package can implement the interface.
+			alias := t.reserve(fmt.Sprintf("%s%d", name, i+1))
+			d := t.declare()
+			t.decls[d] = fmt.Sprintf("type %s %s\n", alias, goType)
+			goType = alias
+		}
+		if seen[goType] {
+			continue
+		}
+		seen[goType] = true
+		v := variant{goType: goType}
+		if s.Discriminator != nil && sp != nil && sp.IsReference() {
+			ref := sp.GetReference()
+			for m := orderedmap.First(s.Discriminator.Mapping); m != nil; m = m.Next() {
+				if m.Value() == ref || componentPrefix+m.Value() == ref {
+					v.values = append(v.values, m.Key())
+				}
+			}
+			if len(v.values) == 0 {
+				v.values = []string{ref[strings.LastIndex(ref, "/")+1:]}
+			}
+		}
+		variants = append(variants, v)
+	}
+
+	iface := t.reserve(name + "Value")
+	marker := "is" + name
+	t.imports["encoding/json"] = true
+
+	var b strings.Builder
+	names := make([]string, len(variants))
+	for i, v := range variants {
+		names[i] = v.goType
+	}
+	fmt.Fprintf(&b, "type %s struct {\n\tValue %s\n}\n\n", name, iface)
+	fmt.Fprintf(&b, "// %s is implemented by the types a %s can hold: %s.\n", iface, name, strings.Join(names, ", "))
+	fmt.Fprintf(&b, "type %s interface {\n\t%s()\n}\n\n", iface, marker)
+	for _, v := range variants {
+		fmt.Fprintf(&b, "func (%s) %s() {}\n\n", v.goType, marker)
+	}
+
+	if s.Discriminator != nil && s.Discriminator.PropertyName != "" {
+		prop := s.Discriminator.PropertyName
+		t.imports["fmt"] = true
+		fmt.Fprintf(&b, "// UnmarshalJSON decodes the %s using the '%s' property.\n", name, prop)
+		fmt.Fprintf(&b, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+		fmt.Fprintf(&b, "\tvar d struct {\n\t\tValue string `json:%q`\n\t}\n", prop)
+		b.WriteString("\tif err := json.Unmarshal(data, &d); err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\tswitch d.Value {\n")
+		for _, v := range variants {
+			if len(v.values) == 0 {
+				continue
+			}
+			quoted := make([]string, len(v.values))
+			for i, value := range v.values {
+				quoted[i] = strconv.Quote(value)
+			}
+			fmt.Fprintf(&b, "\tcase %s:\n\t\tvar value %s\n", strings.Join(quoted, ", "), v.goType)
+			b.WriteString("\t\tif err := json.Unmarshal(data, &value); err != nil {\n\t\t\treturn err\n\t\t}\n")
+			b.WriteString("\t\tv.Value = value\n")
+		}
+		fmt.Fprintf(&b, "\tdefault:\n\t\treturn fmt.Errorf(\"unknown %s %%q\", d.Value)\n\t}\n\treturn nil\n}\n\n", prop)
+	} else {
+		t.imports["bytes"] = true
+		t.imports["errors"] = true
+		t.helper("decodeStrict", decodeStrict)
+		fmt.Fprintf(&b, "// UnmarshalJSON decodes the %s into the first type that fits.\n", name)
+		fmt.Fprintf(&b, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+		for _, v := range variants {
+			fmt.Fprintf(&b, "\t{\n\t\tvar value %s\n", v.goType)
+			b.WriteString("\t\tif decodeStrict(data, &value) == nil {\n\t\t\tv.Value = value\n\t\t\treturn nil\n\t\t}\n\t}\n")
+		}
+		fmt.Fprintf(&b, "\treturn errors.New(%q)\n}\n\n", "value does not match any of "+strings.Join(names, ", "))
+	}
+	fmt.Fprintf(&b, "// MarshalJSON encodes the value the %s holds.\n", name)
+	fmt.Fprintf(&b, "func (v %s) MarshalJSON() ([]byte, error) {\n\treturn json.Marshal(v.Value)\n}\n", name)
+	return b.String()
+}
+
+// decodeStrict is shared by the 'oneOf' types without a discriminator.
+const decodeStrict = `// decodeStrict decodes JSON, failing on properties the value does not have.
+func decodeStrict(data []byte, value any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	return d.Decode(value)
+}
+`
+
+// local reports if a Go type is a named type declared in the generated package.
+func (t *typeGenerator) local(goType string) bool {
+	return t.names[goType]
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var typesSpec = `openapi: 3.1.0
+components:
+  schemas:
+    Pet:
+      description: A pet of the store.
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      discriminator:
+        propertyName: petType
+        mapping:
+          kitty: '#/components/schemas/Cat'
+    Animal:
+      type: object
+      required: [petType, name]
+      properties:
+        petType:
+          type: string
+        name:
+          description: The name of the animal.
+          type: string
+        birthday:
+          type: string
+          format: date-time
+        nickname:
+          type: [string, 'null']
+        tags:
+          type: array
+          items:
+            type: string
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Animal'
+        - properties:
+            lives:
+              type: integer
+              format: int32
+    Dog:
+      allOf:
+        - $ref: '#/components/schemas/Animal'
+        - required: [size]
+          properties:
+            size:
+              type: string
+              enum: [small, large, x-large]
+            owner_id:
+              x-go-name: Owner
+              type: string
+              x-go-type: github.com/example/ids.OwnerID
+    Status:
+      x-go-name: PetStatus
+      type: integer
+      enum: [1, 2]
+    Shape:
+      oneOf:
+        - type: object
+          required: [radius]
+          properties:
+            radius:
+              type: number
+        - type: string
+    Labels:
+      type: object
+      additionalProperties:
+        type: string`
+
+func generateTypes(t *testing.T, spec, pkg string) string {
+	doc, err := libopenapi.NewDocument([]byte(spec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	out, err := GenerateTypes(model.Model.Components, &Config{PackageName: pkg})
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestGenerateTypes(t *testing.T) {
+	code := generateTypes(t, typesSpec, "")
+
+	assert.Contains(t, code, "// Code generated by libopenapi. DO NOT EDIT.\n\npackage api\n")
+	assert.Contains(t, code, "\t\"time\"\n\n\t\"github.com/example/ids\"\n)")
+
+	// structs, with pointers for optional properties.
+	assert.Contains(t, code, "type Animal struct {\n"+
+		"\tPetType string `json:\"petType\"`\n"+
+		"\t// The name of the animal.\n"+
+		"\tName     string     `json:\"name\"`\n"+
+		"\tBirthday *time.Time `json:\"birthday,omitempty\"`\n"+
+		"\tNickname *string    `json:\"nickname,omitempty\"`\n"+
+		"\tTags     []string   `json:\"tags,omitempty\"`\n}")
+
+	// allOf is flattened, extensions override names and types.
+	assert.Contains(t, code, "\tLives    *int32     `json:\"lives,omitempty\"`\n")
+	assert.Contains(t, code, "\tSize     DogSize      `json:\"size\"`\n")
+	assert.Contains(t, code, "\tOwner    *ids.OwnerID `json:\"owner_id,omitempty\"`\n")
+
+	// enums.
+	assert.Contains(t, code, "type DogSize string\n\nconst (\n\tDogSizeSmall  DogSize = \"small\"\n")
+	assert.Contains(t, code, "\tDogSizeXLarge DogSize = \"x-large\"\n")
+	assert.Contains(t, code, "type PetStatus int64\n\nconst (\n\tPetStatusN1 PetStatus = 1\n")
+
+	// oneOf, with and without a discriminator.
+	assert.Contains(t, code, "// A pet of the store.\ntype Pet struct {\n\tValue PetValue\n}")
+	assert.Contains(t, code, "func (Cat) isPet() {}")
+	assert.Contains(t, code, "\tcase \"kitty\":\n\t\tvar value Cat\n")
+	assert.Contains(t, code, "\tcase \"Dog\":\n\t\tvar value Dog\n")
+	assert.Contains(t, code, "type Shape1 struct {\n\tRadius float64 `json:\"radius\"`\n}")
+	assert.Contains(t, code, "type Shape2 string")
+	assert.Contains(t, code, "func decodeStrict(data []byte, value any) error {")
+
+	assert.Contains(t, code, "type Labels map[string]string")
+}
+
+func TestGenerateTypes_Compiles(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not available")
+	}
+	spec := strings.Replace(typesSpec, "github.com/example/ids.OwnerID", "string", 1)
+	code := generateTypes(t, spec, "main")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gentest\n\ngo 1.21\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), []byte(code), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() {
+	var pets []Pet
+	if err := json.Unmarshal([]byte(`+"`"+`[{"petType":"kitty","name":"Tom","lives":9},{"petType":"Dog","name":"Rex","size":"small"}]`+"`"+`), &pets); err != nil {
+		panic(err)
+	}
+	var shapes []Shape
+	if err := json.Unmarshal([]byte(`+"`"+`[{"radius":2},"square"]`+"`"+`), &shapes); err != nil {
+		panic(err)
+	}
+	out, _ := json.Marshal(shapes)
+	fmt.Printf("%T %d %T %s %s\n", pets[0].Value, *pets[0].Value.(Cat).Lives, pets[1].Value, pets[1].Value.(Dog).Size, out)
+}
+`), 0o644))
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.Equal(t, "main.Cat 9 main.Dog small [{\"radius\":2},\"square\"]\n", string(out))
+}
+
+func TestGenerateTypes_NoComponents(t *testing.T) {
+	out, err := GenerateTypes(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "// Code generated by libopenapi. DO NOT EDIT.\n\npackage api\n", string(out))
+
+	_, err = GenerateTypes(&v3.Components{}, &Config{PackageName: "types"})
+	assert.NoError(t, err)
+}
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "UserID", goName("userId"))
+	assert.Equal(t, "PetStoreAPI", goName("pet-store_api"))
+	assert.Equal(t, "HTTPServer", goName("HTTPServer"))
+	assert.Equal(t, "N200Response", goName("200 response"))
+	assert.Equal(t, "", goName("--"))
+}
@@ -4,6 +4,7 @@
 package index
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -295,6 +296,12 @@ func (index *SpecIndex) ExtractRefs(node, parent *yaml.Node, seenPath []string,
 					}
 				}
 
+				// references resolved against a schema '$id' or naming an '$anchor' use the URI they resolve to.
+				if located := index.FindSchemaReference(node); located != nil {
+					fullDefinitionPath = located.FullDefinition
+					componentName = value
+				}
+
 				_, p := utils.ConvertComponentIdIntoFriendlyPathSearch(componentName)
 
 				ref := &Reference{
@@ -548,13 +555,19 @@ func (index *SpecIndex) ExtractRefs(node, parent *yaml.Node, seenPath []string,
 // ExtractComponentsFromRefs returns located components from references. The returned nodes from here
 // can be used for resolving as they contain the actual object properties.
 func (index *SpecIndex) ExtractComponentsFromRefs(refs []*Reference) []*Reference {
+	return index.extractComponentsFromRefs(context.Background(), refs)
+}
+
+// extractComponentsFromRefs is the same as ExtractComponentsFromRefs, except other files are no longer opened once
+// the context is cancelled.
+func (index *SpecIndex) extractComponentsFromRefs(ctx context.Context, refs []*Reference) []*Reference {
 	var found []*Reference
 
 	// run this async because when things get recursive, it can take a while
 	c := make(chan bool)
 
 	locate := func(ref *Reference, refIndex int, sequence []*ReferenceMapped) {
-		located := index.FindComponent(ref.FullDefinition)
+		located := index.findComponent(ctx, ref.FullDefinition)
 		if located != nil {
 
 			// have we already mapped this?
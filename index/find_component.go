@@ -4,6 +4,7 @@
 package index
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"path/filepath"
@@ -18,17 +19,29 @@ import (
 // This method will recurse through remote, local and file references. For each new external reference
 // a new index will be created. These indexes can then be traversed recursively.
 func (index *SpecIndex) FindComponent(componentId string) *Reference {
+	return index.findComponent(context.Background(), componentId)
+}
+
+// findComponent is the same as FindComponent, except other files are no longer opened once the context is cancelled.
+func (index *SpecIndex) findComponent(ctx context.Context, componentId string) *Reference {
 	if index.root == nil {
 		return nil
 	}
 
+	// schema resources ('$id') and anchors ('$anchor') are located by their absolute URI.
+	if !strings.HasPrefix(componentId, "#/") {
+		if r := index.FindSchemaIdentifier(componentId); r != nil {
+			return r
+		}
+	}
+
 	uri := strings.Split(componentId, "#/")
 	if len(uri) == 2 {
 		if uri[0] != "" {
 			if index.specAbsolutePath == uri[0] {
 				return index.FindComponentInRoot(fmt.Sprintf("#/%s", uri[1]))
 			} else {
-				return index.lookupRolodex(uri)
+				return index.lookupRolodex(ctx, uri)
 			}
 		} else {
 			return index.FindComponentInRoot(fmt.Sprintf("#/%s", uri[1]))
@@ -38,7 +51,7 @@ func (index *SpecIndex) FindComponent(componentId string) *Reference {
 		// does it contain a file extension?
 		fileExt := filepath.Ext(componentId)
 		if fileExt != "" {
-			return index.lookupRolodex(uri)
+			return index.lookupRolodex(ctx, uri)
 		}
 
 		// root search
@@ -89,7 +102,7 @@ func (index *SpecIndex) FindComponentInRoot(componentId string) *Reference {
 	return nil
 }
 
-func (index *SpecIndex) lookupRolodex(uri []string) *Reference {
+func (index *SpecIndex) lookupRolodex(ctx context.Context, uri []string) *Reference {
 	if index.rolodex == nil {
 		return nil
 	}
@@ -124,7 +137,7 @@ func (index *SpecIndex) lookupRolodex(uri []string) *Reference {
 		idx := index
 		if ext != "" {
 			// extract the document from the rolodex.
-			rFile, rError := index.rolodex.Open(absoluteFileLocation)
+			rFile, rError := index.rolodex.OpenWithContext(ctx, absoluteFileLocation)
 
 			if rError != nil {
 				index.logger.Error("unable to open the rolodex file, check specification references and base path",
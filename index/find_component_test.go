@@ -4,6 +4,7 @@
 package index
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 	"os"
@@ -318,7 +319,7 @@ components:
 	r := NewRolodex(c)
 	index.rolodex = r
 
-	n := index.lookupRolodex([]string{"bingobango"})
+	n := index.lookupRolodex(context.Background(), []string{"bingobango"})
 
 	// if the reference is not found, it should return the root.
 	assert.NotNil(t, n)
@@ -374,7 +375,7 @@ components:
 	r := NewRolodex(c)
 	index.rolodex = r
 
-	n := index.lookupRolodex(nil)
+	n := index.lookupRolodex(context.Background(), nil)
 
 	// no url, no ref.
 	assert.Nil(t, n)
@@ -122,6 +122,10 @@ type SpecIndexConfig struct {
 	// struct that was used to create this index.
 	SpecInfo *datamodel.SpecInfo
 
+	// Limits are used by the rolodex to limit the files it loads (and how big they are), and by the resolver to
+	// limit the length of reference journeys. If not set, nothing is limited.
+	Limits *datamodel.Limits
+
 	// Rolodex is what provides all file and remote based lookups. Without the rolodex, no remote or file lookups
 	// can be used. Normally you won't need to worry about setting this as each root document gets a rolodex
 	// of its own automatically.
@@ -278,6 +282,15 @@ type SpecIndex struct {
 	nodeMap                             map[int]map[int]*yaml.Node
 	nodeMapCompleted                    chan bool
 	pendingResolve                      []refMap
+	schemaBaseURI                       string                // base URI of the document, the '$id' of the root schema or the spec path.
+	schemaResources                     map[string]*yaml.Node // schema resources, by the absolute URI of their '$id'.
+	schemaResourceIds                   map[*yaml.Node]string // absolute URI of the '$id' of schema resources, by node.
+	schemaAnchors                       map[string]*yaml.Node // schemas by the absolute URI of their '$anchor' or '$dynamicAnchor'.
+	dynamicAnchors                      map[string]*yaml.Node // schemas by the absolute URI of their '$dynamicAnchor'.
+	schemaRefs                          map[*yaml.Node]string // absolute URI of '$ref' values resolved against a schema '$id', by the node holding the '$ref'.
+	dynamicRefs                         map[*yaml.Node]string // absolute URI of '$dynamicRef' values, by the node holding the '$dynamicRef'.
+	schemaIdentifierRefs                map[string]*Reference // references located using schema identifiers, by absolute URI.
+	schemaIdLock                        sync.RWMutex
 }
 
 // GetResolver returns the resolver for this index.
@@ -6,6 +6,8 @@ package index
 import (
 	"strings"
 	"sync"
+
+	"gopkg.in/yaml.v3"
 )
 
 func isHttpMethod(val string) bool {
@@ -31,6 +33,13 @@ func isHttpMethod(val string) bool {
 func boostrapIndexCollections(index *SpecIndex) {
 	index.allRefs = make(map[string]*Reference)
 	index.allMappedRefs = make(map[string]*Reference)
+	index.schemaResources = make(map[string]*yaml.Node)
+	index.schemaResourceIds = make(map[*yaml.Node]string)
+	index.schemaAnchors = make(map[string]*yaml.Node)
+	index.dynamicAnchors = make(map[string]*yaml.Node)
+	index.schemaRefs = make(map[*yaml.Node]string)
+	index.dynamicRefs = make(map[*yaml.Node]string)
+	index.schemaIdentifierRefs = make(map[string]*Reference)
 	index.refsByLine = make(map[string]map[int]bool)
 	index.linesWithRefs = make(map[int]bool)
 	index.pathRefs = make(map[string]map[string]*Reference)
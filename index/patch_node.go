@@ -0,0 +1,352 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// PatchNode replaces the content of a node in the tree of the index with the content of a replacement, and brings
+// the index up to date with the change, without the specification being parsed (or indexed) again. It's used when a
+// single object (like a path item or a schema) has changed, and re-building everything would take too long.
+//
+// The replacement must already be positioned where it will live in the specification, so the first line of the
+// replacement is the line of the target. lineDelta is the number of lines the patch added to the specification
+// (negative if lines were removed), every node that follows the target is moved by lineDelta lines.
+//
+// Everything the index found inside the target (references, inline schemas, descriptions, summaries, enums, security
+// requirements and external documents) is replaced with what is found inside the replacement. The maps built from
+// paths and components (operations, parameters, tags, servers, links, callbacks and components) are re-built from the
+// patched tree. Circular references are not checked again.
+//
+// Patching is not free. Only the replacement is searched for references (and only its references are looked up), but
+// the node map and every count and map listed above are re-built from the whole tree, which costs as much as the
+// counting done by BuildIndex. What's saved is parsing the specification, and extracting and looking up every
+// reference in it again.
+//
+// The target node itself is kept (only its content is replaced), so anything that points to it stays valid.
+// PatchNode is not safe to use while the index is being used by something else.
+func (index *SpecIndex) PatchNode(target, replacement *yaml.Node, lineDelta int) error {
+	if target == nil || replacement == nil {
+		return errors.New("unable to patch index, a target and a replacement are required")
+	}
+	location, ok := index.locateNode(target)
+	if !ok {
+		return fmt.Errorf("unable to patch index, the node at line %d, column %d is not part of the index",
+			target.Line, target.Column)
+	}
+
+	// everything inside the target is about to be replaced.
+	stale := make(map[*yaml.Node]bool)
+	collectNodes(target, stale)
+	delete(stale, target)
+
+	*target = *replacement
+
+	index.moveNodes(target, lineDelta)
+	index.dropStaleReferences(stale)
+	index.extractPatchedReferences(target, location)
+	index.rebuildMaps()
+	return nil
+}
+
+// nodeLocation is where a node lives in the tree of an index, as seen by ExtractRefs.
+type nodeLocation struct {
+	parent   *yaml.Node
+	seenPath []string
+	poly     bool
+	polyName string
+}
+
+// locateNode walks the tree of the index to find a node, and returns the location ExtractRefs would have seen it at.
+func (index *SpecIndex) locateNode(target *yaml.Node) (*nodeLocation, bool) {
+	if index.root == nil {
+		return nil, false
+	}
+	seen := make(map[*yaml.Node]bool)
+	var walk func(node *yaml.Node, location nodeLocation) (*nodeLocation, bool)
+	walk = func(node *yaml.Node, location nodeLocation) (*nodeLocation, bool) {
+		if seen[node] {
+			return nil, false
+		}
+		seen[node] = true
+		for i, child := range node.Content {
+			childLocation := nodeLocation{
+				parent:   node,
+				seenPath: location.seenPath,
+				poly:     location.poly,
+				polyName: location.polyName,
+			}
+			if utils.IsNodeMap(node) {
+				if i%2 == 0 {
+					continue
+				}
+				key := node.Content[i-1].Value
+				if key != "$ref" && key != "" {
+					childLocation.seenPath = append(append([]string{}, location.seenPath...),
+						strings.ReplaceAll(key, "/", "~1"))
+				}
+				if isPoly, _ := index.checkPolymorphicNode(key); isPoly {
+					childLocation.poly = true
+					childLocation.polyName = key
+				}
+			}
+			if child == target {
+				return &childLocation, true
+			}
+			if found, ok := walk(child, childLocation); ok {
+				return found, true
+			}
+		}
+		return nil, false
+	}
+	if index.root == target {
+		return &nodeLocation{}, true
+	}
+	return walk(index.root, nodeLocation{})
+}
+
+// collectNodes adds a node, and every node inside it, to a set.
+func collectNodes(node *yaml.Node, nodes map[*yaml.Node]bool) {
+	if node == nil || nodes[node] {
+		return
+	}
+	nodes[node] = true
+	for _, child := range node.Content {
+		collectNodes(child, nodes)
+	}
+}
+
+// moveNodes moves every node that follows a patched node by a number of lines, and maps the nodes of the tree again.
+func (index *SpecIndex) moveNodes(patched *yaml.Node, lineDelta int) {
+	inside := make(map[*yaml.Node]bool)
+	collectNodes(patched, inside)
+	nodeMap := make(map[int]map[int]*yaml.Node)
+	seen := make(map[*yaml.Node]bool)
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if seen[node] {
+			return
+		}
+		seen[node] = true
+		if !inside[node] && node.Line > patched.Line {
+			node.Line += lineDelta
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+		// like MapNodes, a node is mapped after its children.
+		if nodeMap[node.Line] == nil {
+			nodeMap[node.Line] = make(map[int]*yaml.Node)
+		}
+		nodeMap[node.Line][node.Column] = node
+	}
+	walk(index.root)
+	index.nodeMap = nodeMap
+}
+
+// dropStaleReferences removes everything the index found inside nodes that no longer exist.
+func (index *SpecIndex) dropStaleReferences(stale map[*yaml.Node]bool) {
+	isStale := func(r *Reference) bool { return r == nil || stale[r.Node] }
+
+	index.rawSequencedRefs = filterStale(index.rawSequencedRefs, isStale)
+	index.allRefSchemaDefinitions = filterStale(index.allRefSchemaDefinitions, isStale)
+	index.allInlineSchemaDefinitions = filterStale(index.allInlineSchemaDefinitions, isStale)
+	index.allInlineSchemaObjectDefinitions = filterStale(index.allInlineSchemaObjectDefinitions, isStale)
+	index.polymorphicAllOfRefs = filterStale(index.polymorphicAllOfRefs, isStale)
+	index.polymorphicAnyOfRefs = filterStale(index.polymorphicAnyOfRefs, isStale)
+	index.polymorphicOneOfRefs = filterStale(index.polymorphicOneOfRefs, isStale)
+	index.externalDocumentsRef = filterStale(index.externalDocumentsRef, isStale)
+	index.externalDocumentsCount = len(index.externalDocumentsRef)
+	index.allDescriptions = filterStale(index.allDescriptions, func(d *DescriptionReference) bool { return stale[d.Node] })
+	index.descriptionCount = len(index.allDescriptions)
+	index.allSummaries = filterStale(index.allSummaries, func(d *DescriptionReference) bool { return stale[d.Node] })
+	index.summaryCount = len(index.allSummaries)
+	index.allEnums = filterStale(index.allEnums, func(e *EnumReference) bool { return stale[e.Node] })
+	index.enumCount = len(index.allEnums)
+	index.allObjectsWithProperties = filterStale(index.allObjectsWithProperties,
+		func(o *ObjectReference) bool { return stale[o.Node] })
+	index.allMappedRefsSequenced = filterStale(index.allMappedRefsSequenced,
+		func(m *ReferenceMapped) bool { return isStale(m.OriginalReference) })
+	index.refErrors = filterStale(index.refErrors, func(err error) bool {
+		var idxErr *IndexingError
+		return errors.As(err, &idxErr) && stale[idxErr.Node]
+	})
+
+	for k, r := range index.polymorphicRefs {
+		if isStale(r) {
+			delete(index.polymorphicRefs, k)
+		}
+	}
+	for k, r := range index.refsWithSiblings {
+		// siblings are copied, so look at the key of the original node.
+		if len(r.Node.Content) > 0 && stale[r.Node.Content[0]] {
+			delete(index.refsWithSiblings, k)
+		}
+	}
+	for secKey, requirements := range index.securityRequirementRefs {
+		for name, refs := range requirements {
+			if requirements[name] = filterStale(refs, isStale); len(requirements[name]) == 0 {
+				delete(requirements, name)
+			}
+		}
+		if len(requirements) == 0 {
+			delete(index.securityRequirementRefs, secKey)
+		}
+	}
+	index.cache.Range(func(key, value any) bool {
+		if r, ok := value.(*Reference); ok && isStale(r) {
+			index.cache.Delete(key)
+		}
+		return true
+	})
+
+	// a reference that was found inside the target may still be used somewhere else.
+	for k, r := range index.allRefs {
+		if isStale(r) {
+			delete(index.allRefs, k)
+		}
+	}
+	poly := make(map[*Reference]bool)
+	for _, refs := range [][]*Reference{index.polymorphicAllOfRefs, index.polymorphicAnyOfRefs, index.polymorphicOneOfRefs} {
+		for _, r := range refs {
+			poly[r] = true
+		}
+	}
+	for _, r := range index.rawSequencedRefs {
+		if !poly[r] && r.Definition != "" && index.allRefs[r.FullDefinition] == nil {
+			index.allRefs[r.FullDefinition] = r
+		}
+	}
+
+	// components found inside the target have moved, so they are located again.
+	relocated := make(map[*Reference]*Reference)
+	for k, r := range index.allMappedRefs {
+		if !isStale(r) {
+			continue
+		}
+		delete(index.allMappedRefs, k)
+		if located := index.FindComponent(r.FullDefinition); located != nil {
+			index.allMappedRefs[located.FullDefinition] = located
+			relocated[r] = located
+		}
+	}
+	for _, m := range index.allMappedRefsSequenced {
+		if located, ok := relocated[m.Reference]; ok {
+			m.Reference = located
+		}
+	}
+}
+
+// filterStale returns the items of a slice that are not stale.
+func filterStale[T any](items []T, stale func(T) bool) []T {
+	var kept []T
+	for _, item := range items {
+		if !stale(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// extractPatchedReferences extracts everything the index finds in a patched node, as if it was found when the
+// specification was indexed.
+func (index *SpecIndex) extractPatchedReferences(patched *yaml.Node, location *nodeLocation) {
+	seenPoly := make(map[*Reference]bool)
+	for _, r := range index.polymorphicRefs {
+		seenPoly[r] = true
+	}
+
+	found := index.ExtractRefs(patched, location.parent, location.seenPath, 0, location.poly, location.polyName)
+
+	var poly []*Reference
+	for _, r := range index.polymorphicRefs {
+		if !seenPoly[r] {
+			poly = append(poly, r)
+		}
+	}
+	index.ExtractComponentsFromRefs(found)
+	index.ExtractComponentsFromRefs(poly)
+	index.ExtractExternalDocuments(patched)
+
+	// components that are no longer referenced are no longer mapped.
+	used := make(map[string]bool)
+	for _, m := range index.allMappedRefsSequenced {
+		used[m.FullDefinition] = true
+	}
+	for k := range index.allMappedRefs {
+		if !used[k] {
+			delete(index.allMappedRefs, k)
+		}
+	}
+
+	// lines with references are looked up by the line of each reference, which may have moved.
+	index.linesWithRefs = make(map[int]bool)
+	index.refsByLine = make(map[string]map[int]bool)
+	for _, r := range index.rawSequencedRefs {
+		refKey, refValue := utils.FindKeyNodeTop("$ref", r.Node.Content)
+		if refKey == nil || refValue == nil {
+			continue
+		}
+		index.linesWithRefs[refKey.Line] = true
+		refName := refValue.Value[strings.LastIndex(refValue.Value, "/")+1:]
+		if index.refsByLine[refName] == nil {
+			index.refsByLine[refName] = make(map[int]bool)
+		}
+		index.refsByLine[refName][refKey.Line] = true
+	}
+	index.refCount = len(index.allRefs)
+}
+
+// rebuildMaps re-builds the maps of paths, operations, parameters, tags, servers and components from the tree.
+// Nothing is updated in place, every map is reset and BuildIndex counts the whole tree again (a full re-count).
+func (index *SpecIndex) rebuildMaps() {
+	index.pathRefs = make(map[string]map[string]*Reference)
+	index.paramOpRefs = make(map[string]map[string]map[string][]*Reference)
+	index.operationTagsRefs = make(map[string]map[string][]*Reference)
+	index.operationDescriptionRefs = make(map[string]map[string]*Reference)
+	index.operationSummaryRefs = make(map[string]map[string]*Reference)
+	index.paramCompRefs = make(map[string]*Reference)
+	index.paramAllRefs = make(map[string]*Reference)
+	index.paramInlineDuplicateNames = make(map[string][]*Reference)
+	index.globalTagRefs = make(map[string]*Reference)
+	index.callbacksRefs = make(map[string]map[string][]*Reference)
+	index.linksRefs = make(map[string]map[string][]*Reference)
+	index.opServersRefs = make(map[string]map[string][]*Reference)
+	index.allComponentSchemaDefinitions = &sync.Map{}
+	index.allParameters = make(map[string]*Reference)
+	index.allSecuritySchemes = make(map[string]*Reference)
+	index.allRequestBodies = make(map[string]*Reference)
+	index.allResponses = make(map[string]*Reference)
+	index.allHeaders = make(map[string]*Reference)
+	index.allExamples = make(map[string]*Reference)
+	index.allLinks = make(map[string]*Reference)
+	index.allCallbacks = make(map[string]*Reference)
+	index.serversRefs = nil
+	index.rootSecurity = nil
+	index.operationParamErrors = nil
+
+	index.pathCount = 0
+	index.operationCount = 0
+	index.schemaCount = 0
+	index.globalTagsCount = 0
+	index.componentParamCount = 0
+	index.operationParamCount = 0
+	index.componentsInlineParamUniqueCount = 0
+	index.componentsInlineParamDuplicateCount = 0
+	index.operationTagsCount = 0
+	index.globalLinksCount = 0
+	index.globalCallbacksCount = 0
+	index.totalTagsCount = 0
+
+	index.GetPathCount()
+	index.built = false
+	index.BuildIndex()
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSpecIndex_PatchNode(t *testing.T) {
+	spec := `openapi: 3.1.0
+paths:
+  /burgers:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+components:
+  schemas:
+    Burger:
+      type: object
+      properties:
+        name:
+          type: string
+    Fries:
+      type: string`
+
+	patch := `    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Fries'
+    post:
+      description: make burgers`
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(spec), &root))
+	idx := NewSpecIndexWithConfig(&root, CreateOpenAPIIndexConfig())
+	assert.Equal(t, 1, idx.GetOperationCount())
+	assert.Equal(t, 15, idx.GetAllComponentSchemas()["#/components/schemas/Burger"].Node.Line)
+
+	var replacement yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(strings.Repeat("\n", 3)+patch), &replacement))
+
+	pathItem := root.Content[0].Content[3].Content[1]
+	require.NoError(t, idx.PatchNode(pathItem, replacement.Content[0], 2))
+
+	// the path item node is kept, with the new content.
+	assert.Equal(t, "post", pathItem.Content[2].Value)
+	assert.Equal(t, 2, idx.GetOperationCount())
+	assert.Len(t, idx.GetPathsNode().Content, 2)
+
+	// the reference to Burger is gone, and Fries is referenced from line 11.
+	assert.Len(t, idx.GetAllReferences(), 1)
+	assert.NotNil(t, idx.GetMappedReferences()["#/components/schemas/Fries"])
+	assert.Nil(t, idx.GetMappedReferences()["#/components/schemas/Burger"])
+	assert.Equal(t, map[int]bool{11: true}, idx.GetRefsByLine()["Fries"])
+	assert.Nil(t, idx.GetRefsByLine()["Burger"])
+	assert.Len(t, idx.GetAllSequencedReferences(), 1)
+	assert.Equal(t, 2, idx.GetAllDescriptionsCount())
+
+	// everything after the path item has moved down two lines.
+	assert.Equal(t, 17, idx.GetAllComponentSchemas()["#/components/schemas/Burger"].Node.Line)
+	assert.Equal(t, 22, idx.GetAllComponentSchemas()["#/components/schemas/Fries"].Node.Line)
+	n, ok := idx.GetNode(13, 20)
+	require.True(t, ok)
+	assert.Equal(t, "make burgers", n.Value)
+	n, ok = idx.GetNode(17, 13)
+	require.True(t, ok)
+	assert.Equal(t, "object", n.Value)
+
+	// the tree is the same as the patched specification, parsed from scratch.
+	lines := strings.Split(spec, "\n")
+	patched := strings.Join(append(append(lines[:3:3], strings.Split(patch, "\n")...), lines[11:]...), "\n")
+	var fresh yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(patched), &fresh))
+	freshIdx := NewSpecIndexWithConfig(&fresh, CreateOpenAPIIndexConfig())
+	assert.Equal(t, freshIdx.GetOperationCount(), idx.GetOperationCount())
+	assert.Equal(t, freshIdx.GetLinesWithReferences(), idx.GetLinesWithReferences())
+	for line, columns := range freshIdx.nodeMap {
+		for column, node := range columns {
+			patchedNode, found := idx.GetNode(line, column)
+			require.True(t, found, "line %d, column %d", line, column)
+			assert.Equal(t, node.Value, patchedNode.Value)
+		}
+	}
+}
+
+func TestSpecIndex_PatchNode_NotFound(t *testing.T) {
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("openapi: 3.1.0"), &root))
+	idx := NewSpecIndexWithConfig(&root, CreateOpenAPIIndexConfig())
+
+	err := idx.PatchNode(&yaml.Node{Line: 4, Column: 2}, &yaml.Node{}, 0)
+	assert.Equal(t, "unable to patch index, the node at line 4, column 2 is not part of the index", err.Error())
+	assert.Error(t, idx.PatchNode(nil, nil, 0))
+}
@@ -4,12 +4,14 @@
 package index
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
 	"path/filepath"
 	"strings"
 
+	"github.com/pb33f/libopenapi/datamodel"
 	"github.com/pb33f/libopenapi/utils"
 	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
@@ -51,6 +53,11 @@ func (r *ResolvingError) Error() string {
 	return strings.Join(msgs, "\n")
 }
 
+// Unwrap returns the error thrown by the resolver.
+func (r *ResolvingError) Unwrap() error {
+	return r.ErrorRef
+}
+
 // Resolver will use a *index.SpecIndex to stitch together a resolved root tree using all the discovered
 // references in the doc.
 type Resolver struct {
@@ -66,6 +73,8 @@ type Resolver struct {
 	relativesSeen          int
 	IgnorePoly             bool
 	IgnoreArray            bool
+	ctx                    context.Context
+	journeyLimitHit        bool
 }
 
 // NewResolver will create a new resolver from a *index.SpecIndex
@@ -189,7 +198,18 @@ func (resolver *Resolver) GetRelativesSeen() int {
 // re-organize the node tree. Make sure you have copied your original tree before running this (if you want to preserve
 // original data)
 func (resolver *Resolver) Resolve() []*ResolvingError {
+	return resolver.ResolveWithContext(context.Background())
+}
+
+// ResolveWithContext is the same as Resolve, except the resolver stops when the context is cancelled, and the
+// error of the context is returned as a *ResolvingError. A cancelled resolve leaves the tree partially resolved.
+func (resolver *Resolver) ResolveWithContext(ctx context.Context) []*ResolvingError {
+	resolver.ctx = ctx
 	visitIndex(resolver, resolver.specIndex)
+	if err := ctx.Err(); err != nil {
+		resolver.resolvingErrors = append(resolver.resolvingErrors, &ResolvingError{ErrorRef: err})
+		return resolver.resolvingErrors
+	}
 
 	for _, circRef := range resolver.circularReferences {
 		// If the circular reference is not required, we can ignore it, as it's a terminable loop rather than an infinite one
@@ -209,7 +229,18 @@ func (resolver *Resolver) Resolve() []*ResolvingError {
 
 // CheckForCircularReferences Check for circular references, without resolving, a non-destructive run.
 func (resolver *Resolver) CheckForCircularReferences() []*ResolvingError {
+	return resolver.CheckForCircularReferencesWithContext(context.Background())
+}
+
+// CheckForCircularReferencesWithContext is the same as CheckForCircularReferences, except the check stops when the
+// context is cancelled, and the error of the context is returned as a *ResolvingError.
+func (resolver *Resolver) CheckForCircularReferencesWithContext(ctx context.Context) []*ResolvingError {
+	resolver.ctx = ctx
 	visitIndexWithoutDamagingIt(resolver, resolver.specIndex)
+	if err := ctx.Err(); err != nil {
+		resolver.resolvingErrors = append(resolver.resolvingErrors, &ResolvingError{ErrorRef: err})
+		return resolver.resolvingErrors
+	}
 	for _, circRef := range resolver.circularReferences {
 		// If the circular reference is not required, we can ignore it, as it's a terminable loop rather than an infinite one
 		if !circRef.IsInfiniteLoop {
@@ -233,6 +264,9 @@ func visitIndexWithoutDamagingIt(res *Resolver, idx *SpecIndex) {
 	mappedIndex := idx.GetMappedReferences()
 	res.indexesVisited++
 	for _, ref := range mapped {
+		if res.cancelled() {
+			return
+		}
 		seenReferences := make(map[string]bool)
 		var journey []*Reference
 		res.journeysTaken++
@@ -240,6 +274,9 @@ func visitIndexWithoutDamagingIt(res *Resolver, idx *SpecIndex) {
 	}
 	schemas := idx.GetAllComponentSchemas()
 	for s, schemaRef := range schemas {
+		if res.cancelled() {
+			return
+		}
 		if mappedIndex[s] == nil {
 			seenReferences := make(map[string]bool)
 			var journey []*Reference
@@ -261,6 +298,9 @@ func visitIndex(res *Resolver, idx *SpecIndex) {
 
 	var refs []refMap
 	for _, ref := range mapped {
+		if res.cancelled() {
+			return
+		}
 		seenReferences := make(map[string]bool)
 		var journey []*Reference
 		res.journeysTaken++
@@ -310,9 +350,42 @@ func visitIndex(res *Resolver, idx *SpecIndex) {
 	}
 }
 
+// cancelled returns true if the context of a resolve (or circular reference check) has been cancelled.
+func (resolver *Resolver) cancelled() bool {
+	return resolver.ctx != nil && resolver.ctx.Err() != nil
+}
+
+// journeyTooLong returns true if a journey is longer than the MaxReferenceJourney limit of the index configuration.
+// The limit is only reported once, the first journey to exceed it is recorded as a resolving error.
+func (resolver *Resolver) journeyTooLong(ref *Reference, journey []*Reference) bool {
+	if resolver.specIndex == nil || resolver.specIndex.config == nil {
+		return false
+	}
+	limits := resolver.specIndex.config.Limits
+	if limits == nil || limits.MaxReferenceJourney <= 0 || len(journey) <= limits.MaxReferenceJourney {
+		return false
+	}
+	if !resolver.journeyLimitHit {
+		resolver.journeyLimitHit = true
+		resolver.resolvingErrors = append(resolver.resolvingErrors, &ResolvingError{
+			ErrorRef: &datamodel.LimitError{
+				Limit:    datamodel.LimitMaxReferenceJourney,
+				Max:      limits.MaxReferenceJourney,
+				Location: ref.FullDefinition,
+			},
+			Node: ref.Node,
+			Path: ref.Definition,
+		})
+	}
+	return true
+}
+
 // VisitReference will visit a reference as part of a journey and will return resolved nodes.
 func (resolver *Resolver) VisitReference(ref *Reference, seen map[string]bool, journey []*Reference, resolve bool) []*yaml.Node {
 	resolver.referencesVisited++
+	if resolver.cancelled() {
+		return ref.Node.Content
+	}
 	if resolve && ref.Seen {
 		if ref.Resolved {
 			return ref.Node.Content
@@ -323,6 +396,9 @@ func (resolver *Resolver) VisitReference(ref *Reference, seen map[string]bool, j
 	}
 
 	journey = append(journey, ref)
+	if resolver.journeyTooLong(ref, journey) {
+		return ref.Node.Content
+	}
 	seenRelatives := make(map[int]bool)
 	relatives := resolver.extractRelatives(ref, ref.Node, nil, seen, journey, seenRelatives, resolve, 0)
 
@@ -439,7 +515,7 @@ func (resolver *Resolver) extractRelatives(ref *Reference, node, parent *yaml.No
 	foundRelatives map[string]bool,
 	journey []*Reference, seen map[int]bool, resolve bool, depth int,
 ) []*Reference {
-	if len(journey) > 100 {
+	if len(journey) > 100 || resolver.cancelled() {
 		return nil
 	}
 
@@ -585,7 +661,10 @@ func (resolver *Resolver) extractRelatives(ref *Reference, node, parent *yaml.No
 					IsRemote:       true,
 				}
 
-				locatedRef, _ = resolver.specIndex.SearchIndexForReferenceByReference(searchRef)
+				// references resolved against a schema '$id' or naming an '$anchor' are located by their node.
+				if locatedRef = resolver.specIndex.FindSchemaReference(node); locatedRef == nil {
+					locatedRef, _ = resolver.specIndex.SearchIndexForReferenceByReference(searchRef)
+				}
 
 				if locatedRef == nil {
 					_, path := utils.ConvertComponentIdIntoFriendlyPathSearch(value)
@@ -623,6 +702,20 @@ func (resolver *Resolver) extractRelatives(ref *Reference, node, parent *yaml.No
 				foundRelatives[value] = true
 			}
 
+			// dynamic references depend on the scope they are evaluated in, so they are not followed, but they
+			// must resolve.
+			if i%2 == 0 && n.Value == "$dynamicRef" && utils.IsNodeStringValue(node.Content[i+1]) {
+				if resolver.specIndex.FindDynamicReference(node, nil) == nil {
+					value := node.Content[i+1].Value
+					err := &ResolvingError{
+						ErrorRef: fmt.Errorf("cannot resolve dynamic reference `%s`, it's missing", value),
+						Node:     n,
+						Path:     value,
+					}
+					resolver.resolvingErrors = append(resolver.resolvingErrors, err)
+				}
+			}
+
 			if i%2 == 0 && n.Value != "$ref" && n.Value != "" {
 				if n.Value == "allOf" ||
 					n.Value == "oneOf" ||
@@ -2,6 +2,7 @@ package index
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/pb33f/libopenapi/datamodel"
@@ -1133,3 +1134,74 @@ paths:
 	assert.Equal(t, nodes[0].Content[1].Value, "message")
 
 }
+
+func TestResolver_ResolveWithContext_Cancelled(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    One:
+      $ref: '#/components/schemas/Two'
+    Two:
+      type: string`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+
+	idx := NewSpecIndexWithConfig(&rootNode, CreateOpenAPIIndexConfig())
+	resolver := NewResolver(idx)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errs := resolver.CheckForCircularReferencesWithContext(ctx)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+
+	errs = resolver.ResolveWithContext(ctx)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[1], context.Canceled)
+
+	// nothing was resolved.
+	one := idx.GetAllComponentSchemas()["#/components/schemas/One"]
+	assert.Equal(t, "$ref", one.Node.Content[0].Value)
+
+	resolver = NewResolver(idx)
+	assert.Empty(t, resolver.ResolveWithContext(context.Background()))
+	assert.Equal(t, "type", one.Node.Content[0].Value)
+}
+
+func TestResolver_MaxReferenceJourney(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    One:
+      $ref: '#/components/schemas/Two'
+    Two:
+      $ref: '#/components/schemas/Three'
+    Three:
+      $ref: '#/components/schemas/Four'
+    Four:
+      type: string`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+
+	cf := CreateOpenAPIIndexConfig()
+	cf.Limits = &datamodel.Limits{MaxReferenceJourney: 2}
+	idx := NewSpecIndexWithConfig(&rootNode, cf)
+	resolver := NewResolver(idx)
+
+	errs := resolver.Resolve()
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], datamodel.ErrLimitExceeded)
+
+	var limitErr *datamodel.LimitError
+	assert.ErrorAs(t, errs[0], &limitErr)
+	assert.Equal(t, datamodel.LimitMaxReferenceJourney, limitErr.Limit)
+
+	// a longer journey is fine.
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+	cf.Limits.MaxReferenceJourney = 3
+	resolver = NewResolver(NewSpecIndexWithConfig(&rootNode, cf))
+	assert.Empty(t, resolver.Resolve())
+}
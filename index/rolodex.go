@@ -4,8 +4,10 @@
 package index
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/pb33f/libopenapi/datamodel"
 	"gopkg.in/yaml.v3"
 	"io"
 	"io/fs"
@@ -25,6 +27,12 @@ type CanBeIndexed interface {
 	Index(config *SpecIndexConfig) (*SpecIndex, error)
 }
 
+// canBeIndexedWithContext is implemented by the files of the rolodex, so they stop looking up references in other
+// files once the context is cancelled.
+type canBeIndexedWithContext interface {
+	indexWithContext(ctx context.Context, config *SpecIndexConfig) (*SpecIndex, error)
+}
+
 // RolodexFile is an interface that represents a file in the rolodex. It combines multiple `fs` interfaces
 // like `fs.FileInfo` and `fs.File` into one interface, so the same struct can be used for everything.
 type RolodexFile interface {
@@ -49,6 +57,12 @@ type RolodexFS interface {
 	GetFiles() map[string]RolodexFile
 }
 
+// RolodexContextFS is a file system that stops opening files once a context is cancelled. The LocalFS and the
+// RemoteFS implement it, any other file system is opened without a context.
+type RolodexContextFS interface {
+	OpenWithContext(ctx context.Context, name string) (fs.File, error)
+}
+
 // Rolodex is a file system abstraction that allows for the indexing of multiple file systems
 // and the ability to resolve references across those file systems. It is used to hold references to external
 // files, and the indexes they hold. The rolodex is the master lookup for all references.
@@ -73,6 +87,8 @@ type Rolodex struct {
 	ignoredCircularReferences  []*CircularReferenceResult
 	logger                     *slog.Logger
 	rolodex                    *Rolodex
+	errorLock                  sync.Mutex
+	files                      fileCounter
 }
 
 // NewRolodex creates a new rolodex with the provided index configuration.
@@ -179,10 +195,18 @@ func (r *Rolodex) AddRemoteFS(baseURL string, fileSystem fs.FS) {
 
 // IndexTheRolodex indexes the rolodex, building out the indexes for each file, and then building the root index.
 func (r *Rolodex) IndexTheRolodex() error {
+	return r.IndexTheRolodexWithContext(context.Background())
+}
+
+// IndexTheRolodexWithContext is the same as IndexTheRolodex, except indexing stops when the context is cancelled.
+// The context is passed on to the local and remote file systems of the rolodex, so no more files are opened or
+// fetched once it has been cancelled. The error of the context is returned (and caught) when indexing is cut short.
+//
+// A rolodex that has been cancelled will not be indexed again, a new one is required.
+func (r *Rolodex) IndexTheRolodexWithContext(ctx context.Context) error {
 	if r.indexed {
 		return nil
 	}
-
 	var caughtErrors []error
 
 	var indexBuildQueue []*SpecIndex
@@ -202,7 +226,13 @@ func (r *Rolodex) IndexTheRolodex() error {
 			copiedConfig := *r.indexConfig
 			copiedConfig.SpecAbsolutePath = fullPath
 			copiedConfig.AvoidBuildIndex = true // we will build out everything in two steps.
-			idx, err := idxFile.Index(&copiedConfig)
+			var idx *SpecIndex
+			var err error
+			if cf, ok := idxFile.(canBeIndexedWithContext); ok {
+				idx, err = cf.indexWithContext(ctx, &copiedConfig)
+			} else {
+				idx, err = idxFile.Index(&copiedConfig)
+			}
 
 			if err != nil {
 				errChan <- err
@@ -244,6 +274,19 @@ func (r *Rolodex) IndexTheRolodex() error {
 		}
 	}
 
+	// count the files the file systems have already loaded, there may be too many to index.
+	for _, fileSystem := range r.allFileSystems() {
+		if rfs, ok := fileSystem.(RolodexFS); ok {
+			for path := range rfs.GetFiles() {
+				if err := r.files.count(path, path, r.indexConfig.Limits); err != nil {
+					r.indexed = true
+					r.catchLimitErrors([]error{err})
+					return err
+				}
+			}
+		}
+	}
+
 	indexingCompleted := 0
 	totalToIndex := len(r.localFS) + len(r.remoteFS)
 	doneChan := make(chan bool)
@@ -279,11 +322,14 @@ func (r *Rolodex) IndexTheRolodex() error {
 	})
 
 	for _, idx := range indexBuildQueue {
+		if ctx.Err() != nil {
+			break
+		}
 		idx.BuildIndex()
 		if r.indexConfig.AvoidCircularReferenceCheck {
 			continue
 		}
-		errs := idx.resolver.CheckForCircularReferences()
+		errs := idx.resolver.CheckForCircularReferencesWithContext(ctx)
 		for e := range errs {
 			caughtErrors = append(caughtErrors, errs[e])
 		}
@@ -296,7 +342,7 @@ func (r *Rolodex) IndexTheRolodex() error {
 	}
 
 	// indexed and built every supporting file, we can build the root index (our entry point)
-	if r.rootNode != nil {
+	if r.rootNode != nil && ctx.Err() == nil {
 
 		// if there is a base path, then we need to set the root spec config to point to a theoretical root.yaml
 		// which does not exist, but is used to formulate the absolute path to root references correctly.
@@ -312,7 +358,7 @@ func (r *Rolodex) IndexTheRolodex() error {
 			}
 		}
 
-		index := NewSpecIndexWithConfig(r.rootNode, r.indexConfig)
+		index := newSpecIndexWithContext(ctx, r.rootNode, r.indexConfig)
 		resolver := NewResolver(index)
 
 		if r.indexConfig.IgnoreArrayCircularReferences {
@@ -327,7 +373,7 @@ func (r *Rolodex) IndexTheRolodex() error {
 		r.logger.Debug("[rolodex] root index build completed")
 
 		if !r.indexConfig.AvoidCircularReferenceCheck {
-			resolvingErrors := resolver.CheckForCircularReferences()
+			resolvingErrors := resolver.CheckForCircularReferencesWithContext(ctx)
 			r.circChecked = true
 			for e := range resolvingErrors {
 				caughtErrors = append(caughtErrors, resolvingErrors[e])
@@ -344,9 +390,18 @@ func (r *Rolodex) IndexTheRolodex() error {
 			caughtErrors = append(caughtErrors, index.refErrors...)
 		}
 	}
+	if err := ctx.Err(); err != nil && !errors.Is(errors.Join(caughtErrors...), err) {
+		caughtErrors = append(caughtErrors, err)
+	}
 	r.indexingDuration = time.Since(started)
 	r.indexed = true
+	r.errorLock.Lock()
+	previous := r.caughtErrors
 	r.caughtErrors = caughtErrors
+	r.errorLock.Unlock()
+
+	// limits exceeded looking up references while indexing are kept, re-indexing does not repeat anything else.
+	r.catchLimitErrors(previous)
 	r.built = true
 	return errors.Join(caughtErrors...)
 
@@ -354,9 +409,15 @@ func (r *Rolodex) IndexTheRolodex() error {
 
 // CheckForCircularReferences checks for circular references in the rolodex.
 func (r *Rolodex) CheckForCircularReferences() {
+	r.CheckForCircularReferencesWithContext(context.Background())
+}
+
+// CheckForCircularReferencesWithContext is the same as CheckForCircularReferences, except the check stops when the
+// context is cancelled, and the error of the context is caught.
+func (r *Rolodex) CheckForCircularReferencesWithContext(ctx context.Context) {
 	if !r.circChecked {
 		if r.rootIndex != nil && r.rootIndex.resolver != nil {
-			resolvingErrors := r.rootIndex.resolver.CheckForCircularReferences()
+			resolvingErrors := r.rootIndex.resolver.CheckForCircularReferencesWithContext(ctx)
 			for e := range resolvingErrors {
 				r.caughtErrors = append(r.caughtErrors, resolvingErrors[e])
 			}
@@ -375,6 +436,12 @@ func (r *Rolodex) CheckForCircularReferences() {
 
 // Resolve resolves references in the rolodex.
 func (r *Rolodex) Resolve() {
+	r.ResolveWithContext(context.Background())
+}
+
+// ResolveWithContext is the same as Resolve, except resolving stops when the context is cancelled, and the error
+// of the context is caught. A cancelled resolve leaves the tree partially resolved.
+func (r *Rolodex) ResolveWithContext(ctx context.Context) {
 
 	var resolvers []*Resolver
 	if r.rootIndex != nil && r.rootIndex.resolver != nil {
@@ -386,7 +453,10 @@ func (r *Rolodex) Resolve() {
 		}
 	}
 	for _, res := range resolvers {
-		resolvingErrors := res.Resolve()
+		if ctx.Err() != nil {
+			break
+		}
+		resolvingErrors := res.ResolveWithContext(ctx)
 		for e := range resolvingErrors {
 			r.caughtErrors = append(r.caughtErrors, resolvingErrors[e])
 		}
@@ -400,6 +470,13 @@ func (r *Rolodex) Resolve() {
 		r.infiniteCircularReferences = append(r.infiniteCircularReferences, res.GetInfiniteCircularReferences()...)
 	}
 
+	if err := ctx.Err(); err != nil {
+		if !errors.Is(errors.Join(r.caughtErrors...), err) {
+			r.caughtErrors = append(r.caughtErrors, err)
+		}
+		return
+	}
+
 	// resolve pending nodes
 	for _, res := range resolvers {
 		res.ResolvePendingNodes()
@@ -421,12 +498,42 @@ func (r *Rolodex) BuildIndexes() {
 	r.manualBuilt = true
 }
 
+// allFileSystems returns every local and remote file system of the rolodex, by location.
+func (r *Rolodex) allFileSystems() map[string]fs.FS {
+	all := make(map[string]fs.FS, len(r.localFS)+len(r.remoteFS))
+	for k, v := range r.localFS {
+		all[k] = v
+	}
+	for k, v := range r.remoteFS {
+		all[k] = v
+	}
+	return all
+}
+
+// openWithContext opens a file from a file system, passing on the context if the file system accepts one.
+func openWithContext(ctx context.Context, fileSystem fs.FS, name string) (fs.File, error) {
+	if cfs, ok := fileSystem.(RolodexContextFS); ok {
+		return cfs.OpenWithContext(ctx, name)
+	}
+	return fileSystem.Open(name)
+}
+
 // Open opens a file in the rolodex, and returns a RolodexFile.
 func (r *Rolodex) Open(location string) (RolodexFile, error) {
+	return r.OpenWithContext(context.Background(), location)
+}
+
+// OpenWithContext is the same as Open, except the file is not opened (or fetched) once the context is cancelled.
+// The context is passed on to the file systems of the rolodex, and to the indexing of the file that is opened.
+func (r *Rolodex) OpenWithContext(ctx context.Context, location string) (RolodexFile, error) {
 	if r == nil {
 		return nil, fmt.Errorf("rolodex has not been initialized, cannot open file '%s'", location)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("unable to open '%s': %w", location, err)
+	}
+
 	if len(r.localFS) <= 0 && len(r.remoteFS) <= 0 {
 		return nil, fmt.Errorf("rolodex has no file systems configured, cannot open '%s'. Add a BaseURL or BasePath to your configuration so the rolodex knows how to resolve references", location)
 	}
@@ -454,10 +561,10 @@ func (r *Rolodex) Open(location string) (RolodexFile, error) {
 				fileLookup, _ = filepath.Abs(filepath.Join(k, location))
 			}
 
-			f, err := v.Open(fileLookup)
+			f, err := openWithContext(ctx, v, fileLookup)
 			if err != nil {
 				// try a lookup that is not absolute, but relative
-				f, err = v.Open(location)
+				f, err = openWithContext(ctx, v, location)
 				if err != nil {
 					errorStack = append(errorStack, err)
 					continue
@@ -498,7 +605,7 @@ func (r *Rolodex) Open(location string) (RolodexFile, error) {
 
 			// if there was no file found locally, then search the remote FS.
 			for _, v := range r.remoteFS {
-				f, err := v.Open(location)
+				f, err := openWithContext(ctx, v, location)
 				if err != nil {
 					errorStack = append(errorStack, err)
 					continue
@@ -517,7 +624,10 @@ func (r *Rolodex) Open(location string) (RolodexFile, error) {
 		}
 
 		for _, v := range r.remoteFS {
-			f, err := v.Open(fileLookup)
+			f, err := openWithContext(ctx, v, fileLookup)
+			if errors.Is(err, datamodel.ErrLimitExceeded) {
+				errorStack = append(errorStack, err)
+			}
 			if err == nil {
 
 				if rf, ok := interface{}(f).(*RemoteFile); ok {
@@ -552,6 +662,8 @@ func (r *Rolodex) Open(location string) (RolodexFile, error) {
 		}
 	}
 
+	r.catchLimitErrors(errorStack)
+
 	if localFile != nil {
 		return &rolodexFile{
 			rolodex:   r,
@@ -0,0 +1,80 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/pb33f/libopenapi/datamodel"
+)
+
+// DocumentRolodex is a rolodex created for a document by NewDocumentRolodex, with the index configuration it
+// shares with its file systems.
+type DocumentRolodex struct {
+	// Rolodex holds the root node of the document, and the file systems references are looked up in.
+	Rolodex *Rolodex
+
+	// IndexConfig is the configuration of every index the rolodex builds.
+	IndexConfig *SpecIndexConfig
+
+	// LocalFS is the file system local references are looked up in, nil if file references are not allowed.
+	LocalFS fs.FS
+
+	// BaseDirectory is the absolute path LocalFS was added to the rolodex with.
+	BaseDirectory string
+}
+
+// NewDocumentRolodex creates a rolodex for a document, shadowing the document configuration with an index
+// configuration. A local file system is added when there is a BasePath (or file references are allowed), and a
+// remote file system is added when there is a BaseURL (or remote references are allowed). The rolodex is not
+// indexed.
+func NewDocumentRolodex(info *datamodel.SpecInfo, config *datamodel.DocumentConfiguration) *DocumentRolodex {
+	idxConfig := CreateClosedAPIIndexConfig()
+	idxConfig.SpecInfo = info
+	idxConfig.IgnoreArrayCircularReferences = config.IgnoreArrayCircularReferences
+	idxConfig.IgnorePolymorphicCircularReferences = config.IgnorePolymorphicCircularReferences
+	idxConfig.AvoidCircularReferenceCheck = true
+	idxConfig.BaseURL = config.BaseURL
+	idxConfig.BasePath = config.BasePath
+	idxConfig.Logger = config.Logger
+	idxConfig.Limits = config.Limits
+	rolodex := NewRolodex(idxConfig)
+	<-info.GetJSONParsingChannel() // Need to wait for JSON parsing to complete before we can index.
+	rolodex.SetRootNode(info.RootNode)
+	d := &DocumentRolodex{Rolodex: rolodex, IndexConfig: idxConfig}
+
+	// If basePath is provided, add a local filesystem to the rolodex.
+	if idxConfig.BasePath != "" || config.AllowFileReferences {
+		d.BaseDirectory, _ = filepath.Abs(config.BasePath)
+		// if a supplied local filesystem is provided, add it to the rolodex.
+		d.LocalFS = config.LocalFS
+		if d.LocalFS == nil {
+
+			// create a local filesystem
+			d.LocalFS, _ = NewLocalFSWithConfig(&LocalFSConfig{
+				BaseDirectory: d.BaseDirectory,
+				IndexConfig:   idxConfig,
+				FileFilters:   config.FileFilter,
+			})
+			idxConfig.AllowFileLookup = true
+		}
+		rolodex.AddLocalFS(d.BaseDirectory, d.LocalFS)
+	}
+
+	// if base url is provided, add a remote filesystem to the rolodex.
+	if idxConfig.BaseURL != nil || config.AllowRemoteReferences {
+		remoteFS, _ := NewRemoteFSWithConfig(idxConfig)
+		if config.RemoteURLHandler != nil {
+			remoteFS.RemoteHandlerFunc = config.RemoteURLHandler
+		}
+		idxConfig.AllowRemoteLookup = true
+		u := "default"
+		if idxConfig.BaseURL != nil {
+			u = idxConfig.BaseURL.String()
+		}
+		rolodex.AddRemoteFS(u, remoteFS)
+	}
+	return d
+}
@@ -4,6 +4,8 @@
 package index
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -34,6 +36,7 @@ type LocalFS struct {
 	rolodex             *Rolodex
 	processingFiles     syncmap.Map
 	fileListeners       int
+	files               fileCounter
 }
 
 // GetFiles returns the files that have been indexed. A map of RolodexFile objects keyed by the full path of the file.
@@ -61,6 +64,12 @@ type waiterLocal struct {
 
 // Open opens a file, returning it or an error. If the file is not found, the error is of type *PathError.
 func (l *LocalFS) Open(name string) (fs.File, error) {
+	return l.OpenWithContext(context.Background(), name)
+}
+
+// OpenWithContext is the same as Open, except the file is not read from the OS once the context is cancelled, and
+// the references of the file are looked up with the context.
+func (l *LocalFS) OpenWithContext(ctx context.Context, name string) (fs.File, error) {
 	if l.indexConfig != nil && !l.indexConfig.AllowFileLookup {
 		return nil, &fs.PathError{
 			Op: "open", Path: name,
@@ -76,6 +85,9 @@ func (l *LocalFS) Open(name string) (fs.File, error) {
 	if f, ok := l.Files.Load(name); ok {
 		return f.(*LocalFile), nil
 	} else {
+		if err := ctx.Err(); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
 		if l.fsConfig != nil && l.fsConfig.DirFS == nil {
 
 			// if we're processing, we need to block and wait for the file to be processed
@@ -87,11 +99,17 @@ func (l *LocalFS) Open(name string) (fs.File, error) {
 
 				l.logger.Debug("[rolodex file loader]: waiting for existing OS load to complete", "file", name, "listeners", wait.listeners)
 
-				for !wait.done {
+				for !wait.done && ctx.Err() == nil {
 					l.logger.Debug("[rolodex file loader]: sleeping for 200ns", "file", name, "listeners", wait.listeners)
 					time.Sleep(200 * time.Nanosecond) // breathe for a few nanoseconds.
 				}
 				wait.listeners--
+				if !wait.done {
+					return nil, &fs.PathError{Op: "open", Path: name, Err: ctx.Err()}
+				}
+				if wait.file == nil {
+					return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+				}
 				l.logger.Debug("[rolodex file loader]: waiting done, OS load completed, returning file", "file", name, "listeners", wait.listeners)
 				return wait.file, nil
 			}
@@ -107,6 +125,7 @@ func (l *LocalFS) Open(name string) (fs.File, error) {
 			l.logger.Debug("[rolodex file loader]: extracting file from OS", "file", name)
 			extractedFile, extErr = l.extractFile(name)
 			if extErr != nil {
+				processingWaiter.done = true
 				l.processingFiles.Delete(name)
 				return nil, extErr
 			}
@@ -117,12 +136,20 @@ func (l *LocalFS) Open(name string) (fs.File, error) {
 					copiedCfg.SpecAbsolutePath = name
 					copiedCfg.AvoidBuildIndex = true
 
-					idx, idxError := extractedFile.Index(&copiedCfg)
+					idx, idxError := extractedFile.indexWithContext(ctx, &copiedCfg)
 
 					if idx != nil && l.rolodex != nil {
 						idx.rolodex = l.rolodex
 					}
 
+					if errors.Is(idxError, datamodel.ErrLimitExceeded) {
+						// the file is too big to index, so it can't be used at all.
+						l.Files.Delete(name)
+						processingWaiter.done = true
+						l.processingFiles.Delete(name)
+						return nil, idxError
+					}
+
 					if idxError != nil && idx == nil {
 						extractedFile.readingErrors = append(l.readingErrors, idxError)
 					} else {
@@ -177,18 +204,22 @@ func (l *LocalFile) GetIndex() *SpecIndex {
 
 // Index returns the *SpecIndex for the file. If the index has not been created, it will be created (indexed)
 func (l *LocalFile) Index(config *SpecIndexConfig) (*SpecIndex, error) {
+	return l.indexWithContext(context.Background(), config)
+}
+
+func (l *LocalFile) indexWithContext(ctx context.Context, config *SpecIndexConfig) (*SpecIndex, error) {
 	if l.index != nil {
 		return l.index, nil
 	}
 	content := l.data
 
 	// first, we must parse the content of the file
-	info, err := datamodel.ExtractSpecInfoWithDocumentCheckSync(content, true)
+	info, err := extractFileSpecInfo(content, l.fullPath, config)
 	if err != nil {
 		return nil, err
 	}
 
-	index := NewSpecIndexWithConfig(info.RootNode, config)
+	index := newSpecIndexWithContext(ctx, info.RootNode, config)
 	index.specAbsolutePath = l.fullPath
 
 	l.index = index
@@ -413,12 +444,28 @@ func (l *LocalFS) extractFile(p string) (*LocalFile, error) {
 			return nil, fileError
 		}
 
+		counter := &l.files
+		if l.rolodex != nil {
+			counter = &l.rolodex.files
+		}
+		if err := counter.count(abs, abs, limitsOf(l.indexConfig)); err != nil {
+			return nil, err
+		}
+
 		modTime := time.Now()
 		stat, _ := file.Stat()
 		if stat != nil {
 			modTime = stat.ModTime()
 		}
-		fileData, _ = io.ReadAll(file)
+		maxBytes := 0
+		if limits := limitsOf(l.indexConfig); limits != nil {
+			maxBytes = limits.MaxInputBytes
+		}
+		var readErr error
+		fileData, readErr = readLimited(file, maxBytes, datamodel.LimitMaxInputBytes, abs)
+		if errors.Is(readErr, datamodel.ErrLimitExceeded) {
+			return nil, readErr
+		}
 
 		lf := &LocalFile{
 			filename:      p,
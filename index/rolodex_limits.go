@@ -0,0 +1,101 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel"
+)
+
+// limitsOf returns the limits of an index configuration, which may be nil.
+func limitsOf(config *SpecIndexConfig) *datamodel.Limits {
+	if config == nil {
+		return nil
+	}
+	return config.Limits
+}
+
+// fileCounter counts the distinct files loaded by the rolodex, so a file that is opened more than once (or that
+// fails to load, and is looked up again) is only counted once.
+type fileCounter struct {
+	lock  sync.Mutex
+	files map[string]int
+}
+
+// count adds a file to the counter, and returns a *datamodel.LimitError if it's more than MaxRolodexFiles.
+// The key is the full path of the file, the location is used to report the error.
+func (c *fileCounter) count(key, location string, limits *datamodel.Limits) error {
+	if limits == nil || limits.MaxRolodexFiles <= 0 {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.files == nil {
+		c.files = make(map[string]int)
+	}
+	n, ok := c.files[key]
+	if !ok {
+		n = len(c.files) + 1
+		c.files[key] = n
+	}
+	if n > limits.MaxRolodexFiles {
+		return &datamodel.LimitError{Limit: datamodel.LimitMaxRolodexFiles, Max: limits.MaxRolodexFiles, Location: location}
+	}
+	return nil
+}
+
+// readLimited reads a file, and returns a *datamodel.LimitError if it's bigger than the limit (in bytes).
+// A limit of zero reads everything.
+func readLimited(r io.Reader, limit int, name, location string) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, &datamodel.LimitError{Limit: name, Max: limit, Location: location}
+	}
+	return data, nil
+}
+
+// extractFileSpecInfo parses a file loaded by the rolodex, checking it against the limits of the configuration.
+func extractFileSpecInfo(content []byte, location string, config *SpecIndexConfig) (*datamodel.SpecInfo, error) {
+	info, err := datamodel.ExtractSpecInfoWithConfig(content, &datamodel.DocumentConfiguration{
+		BypassDocumentCheck: true,
+		Limits:              limitsOf(config),
+	})
+	if err != nil {
+		var limitErr *datamodel.LimitError
+		if errors.As(err, &limitErr) {
+			limitErr.Location = location
+		}
+		return nil, err
+	}
+	<-info.GetJSONParsingChannel()
+	return info, nil
+}
+
+// catchLimitErrors keeps every *datamodel.LimitError, so exceeded limits are reported by GetCaughtErrors, even when
+// they were hit looking up a reference.
+func (r *Rolodex) catchLimitErrors(errs []error) {
+	r.errorLock.Lock()
+	defer r.errorLock.Unlock()
+ERRORS:
+	for _, err := range errs {
+		if !errors.Is(err, datamodel.ErrLimitExceeded) {
+			continue
+		}
+		for _, caught := range r.caughtErrors {
+			if caught.Error() == err.Error() {
+				continue ERRORS // the same limit, exceeded by the same file.
+			}
+		}
+		r.caughtErrors = append(r.caughtErrors, err)
+	}
+}
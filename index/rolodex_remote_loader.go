@@ -4,6 +4,7 @@
 package index
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"time"
 
 	"github.com/pb33f/libopenapi/datamodel"
@@ -45,6 +47,8 @@ type RemoteFS struct {
 	logger            *slog.Logger
 	extractedFiles    map[string]RolodexFile
 	rolodex           *Rolodex
+	files             fileCounter
+	client            *http.Client
 }
 
 // RemoteFile is a file that has been indexed by the RemoteFS. It implements the RolodexFile interface.
@@ -174,18 +178,22 @@ func (f *RemoteFile) Read(b []byte) (int, error) {
 
 // Index indexes the file and returns a *SpecIndex, any errors are returned as well.
 func (f *RemoteFile) Index(config *SpecIndexConfig) (*SpecIndex, error) {
+	return f.indexWithContext(context.Background(), config)
+}
+
+func (f *RemoteFile) indexWithContext(ctx context.Context, config *SpecIndexConfig) (*SpecIndex, error) {
 	if f.index != nil {
 		return f.index, nil
 	}
 	content := f.data
 
 	// first, we must parse the content of the file
-	info, err := datamodel.ExtractSpecInfoWithDocumentCheckSync(content, true)
+	info, err := extractFileSpecInfo(content, config.SpecAbsolutePath, config)
 	if err != nil {
 		return nil, err
 	}
 
-	index := NewSpecIndexWithConfig(info.RootNode, config)
+	index := newSpecIndexWithContext(ctx, info.RootNode, config)
 	index.specAbsolutePath = config.SpecAbsolutePath
 	f.index = index
 	return index, nil
@@ -222,12 +230,10 @@ func NewRemoteFSWithConfig(specIndexConfig *SpecIndexConfig) (*RemoteFS, error)
 		rfs.RemoteHandlerFunc = specIndexConfig.RemoteURLHandler
 	} else {
 		// default http client
-		client := &http.Client{
+		rfs.client = &http.Client{
 			Timeout: time.Second * 120,
 		}
-		rfs.RemoteHandlerFunc = func(url string) (*http.Response, error) {
-			return client.Get(url)
-		}
+		rfs.RemoteHandlerFunc = rfs.get
 	}
 	return rfs, nil
 }
@@ -243,6 +249,29 @@ func NewRemoteFSWithRootURL(rootURL string) (*RemoteFS, error) {
 	return NewRemoteFSWithConfig(config)
 }
 
+// get is the default remote handler, it fetches a URL with an HTTP client.
+func (i *RemoteFS) get(url string) (*http.Response, error) {
+	return i.getWithContext(context.Background(), url)
+}
+
+// getWithContext fetches a URL with an HTTP client, the request is cancelled with the context.
+func (i *RemoteFS) getWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return i.client.Do(req)
+}
+
+// fetch requests a remote file with the remote handler. A custom handler does not accept a context, only the
+// default handler can cancel a request that has already been made.
+func (i *RemoteFS) fetch(ctx context.Context, url string) (*http.Response, error) {
+	if i.client != nil && reflect.ValueOf(i.RemoteHandlerFunc).Pointer() == reflect.ValueOf(i.get).Pointer() {
+		return i.getWithContext(ctx, url)
+	}
+	return i.RemoteHandlerFunc(url)
+}
+
 // SetRemoteHandlerFunc sets the remote handler function.
 func (i *RemoteFS) SetRemoteHandlerFunc(handlerFunc utils.RemoteURLHandler) {
 	i.RemoteHandlerFunc = handlerFunc
@@ -278,6 +307,12 @@ type waiterRemote struct {
 
 // Open opens a file, returning it or an error. If the file is not found, the error is of type *PathError.
 func (i *RemoteFS) Open(remoteURL string) (fs.File, error) {
+	return i.OpenWithContext(context.Background(), remoteURL)
+}
+
+// OpenWithContext is the same as Open, except the file is not fetched once the context is cancelled, and the
+// references of the file are looked up with the context. The default remote handler also cancels its request.
+func (i *RemoteFS) OpenWithContext(ctx context.Context, remoteURL string) (fs.File, error) {
 	if i.indexConfig != nil && !i.indexConfig.AllowRemoteLookup {
 		return nil, fmt.Errorf("remote lookup for '%s' is not allowed, please set "+
 			"AllowRemoteLookup to true as part of the index configuration", remoteURL)
@@ -304,11 +339,17 @@ func (i *RemoteFS) Open(remoteURL string) (fs.File, error) {
 		i.logger.Debug("[rolodex remote loader] waiting for existing fetch to complete", "file", remoteURL,
 			"remoteURL", remoteParsedURL.String())
 
-		for !wait.done {
+		for !wait.done && ctx.Err() == nil {
 			time.Sleep(500 * time.Nanosecond) // breathe for a few nanoseconds.
 		}
 
 		wait.listeners--
+		if !wait.done {
+			return nil, &fs.PathError{Op: "open", Path: remoteURL, Err: ctx.Err()}
+		}
+		if wait.file == nil {
+			return nil, &fs.PathError{Op: "open", Path: remoteURL, Err: fs.ErrNotExist}
+		}
 		i.logger.Debug("[rolodex remote loader]: waiting done, remote completed, returning file", "file",
 			remoteParsedURL.String(), "listeners", wait.listeners)
 		return wait.file, nil
@@ -344,9 +385,26 @@ func (i *RemoteFS) Open(remoteURL string) (fs.File, error) {
 		return nil, nil // not a remote file, nothing wrong with that - just we can't keep looking here partner.
 	}
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		i.ProcessingFiles.Delete(remoteParsedURL.Path)
+		return nil, &fs.PathError{Op: "open", Path: remoteURL, Err: ctxErr}
+	}
+
+	// files are counted before they are fetched, so a file over the limit is never downloaded.
+	counter := &i.files
+	if i.rolodex != nil {
+		counter = &i.rolodex.files
+	}
+	if limitErr := counter.count(remoteParsedURL.String(), remoteParsedURL.String(), limitsOf(i.indexConfig)); limitErr != nil {
+		processingWaiter.done = true
+		i.ProcessingFiles.Delete(remoteParsedURL.Path)
+		i.remoteErrors = append(i.remoteErrors, limitErr)
+		return nil, limitErr
+	}
+
 	i.logger.Debug("loading remote file", "file", remoteURL, "remoteURL", remoteParsedURL.String())
 
-	response, clientErr := i.RemoteHandlerFunc(remoteParsedURL.String())
+	response, clientErr := i.fetch(ctx, remoteParsedURL.String())
 	if clientErr != nil {
 
 		i.remoteErrors = append(i.remoteErrors, clientErr)
@@ -365,7 +423,20 @@ func (i *RemoteFS) Open(remoteURL string) (fs.File, error) {
 
 		return nil, fmt.Errorf("empty response from remote URL: %s", remoteParsedURL.String())
 	}
-	responseBytes, readError := io.ReadAll(response.Body)
+	maxBytes := 0
+	if limits := limitsOf(i.indexConfig); limits != nil {
+		maxBytes = limits.MaxRemoteFetchBytes
+	}
+	responseBytes, readError := readLimited(response.Body, maxBytes, datamodel.LimitMaxRemoteFetchBytes,
+		remoteParsedURL.String())
+	if errors.Is(readError, datamodel.ErrLimitExceeded) {
+
+		// remove from processing
+		processingWaiter.done = true
+		i.ProcessingFiles.Delete(remoteParsedURL.Path)
+		i.remoteErrors = append(i.remoteErrors, readError)
+		return nil, readError
+	}
 	if readError != nil {
 
 		// remove from processing
@@ -432,7 +503,7 @@ func (i *RemoteFS) Open(remoteURL string) (fs.File, error) {
 	i.ProcessingFiles.Delete(remoteParsedURL.Path)
 	i.Files.Store(absolutePath, remoteFile)
 
-	idx, idxError := remoteFile.Index(&copiedCfg)
+	idx, idxError := remoteFile.indexWithContext(ctx, &copiedCfg)
 
 	if idxError != nil && idx == nil {
 		i.remoteErrors = append(i.remoteErrors, idxError)
@@ -4,6 +4,9 @@
 package index
 
 import (
+	"context"
+	"errors"
+	"github.com/pb33f/libopenapi/datamodel"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 	"io"
@@ -13,7 +16,9 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
 	"time"
@@ -1652,3 +1657,269 @@ func TestHumanFileSize(t *testing.T) {
 	assert.Equal(t, "1 MB", HumanFileSize(1024*1024))
 
 }
+
+func TestRolodex_IndexTheRolodexWithContext_RemoteTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// never respond, until the client gives up.
+		select {
+		case <-req.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Thing:
+      $ref: '` + srv.URL + `/slow.yaml#/components/schemas/Thing'`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+
+	u, _ := url.Parse(srv.URL)
+	cf := CreateOpenAPIIndexConfig()
+	cf.BaseURL = u
+	cf.AllowRemoteLookup = true
+	rolodex := NewRolodex(cf)
+	remoteFS, _ := NewRemoteFSWithConfig(cf)
+	rolodex.AddRemoteFS(srv.URL, remoteFS)
+	rolodex.SetRootNode(&rootNode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	err := rolodex.IndexTheRolodexWithContext(ctx)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// files opened once indexing is done use the context they are opened with.
+	_, err = rolodex.OpenWithContext(ctx, srv.URL+"/slow.yaml")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRolodex_IndexTheRolodexWithContext_Cancelled(t *testing.T) {
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(`openapi: 3.1.0`), &rootNode)
+
+	rolodex := NewRolodex(CreateOpenAPIIndexConfig())
+	rolodex.SetRootNode(&rootNode)
+	rolodex.AddLocalFS(".", fstest.MapFS{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := rolodex.IndexTheRolodexWithContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, rolodex.GetRootIndex())
+	assert.Len(t, rolodex.GetCaughtErrors(), 2) // the file system cannot be indexed, and the context.
+
+	_, err = rolodex.Open("nope.yaml")
+	assert.Error(t, err)
+
+	rolodex.ResolveWithContext(ctx)
+	assert.ErrorIs(t, rolodex.GetCaughtErrors()[1], context.Canceled)
+	assert.Len(t, rolodex.GetCaughtErrors(), 2)
+}
+
+func TestRolodex_OpenWithContext(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "thing.yaml"), []byte("type: string"), 0o644))
+
+	cf := CreateOpenAPIIndexConfig()
+	cf.BasePath = dir
+	cf.AllowRemoteLookup = true
+	localFS, err := NewLocalFSWithConfig(&LocalFSConfig{BaseDirectory: dir, IndexConfig: cf})
+	assert.NoError(t, err)
+	remoteFS, _ := NewRemoteFSWithConfig(cf)
+	rolodex := NewRolodex(cf)
+	rolodex.AddLocalFS(dir, localFS)
+	rolodex.AddRemoteFS("https://pb33f.io", remoteFS)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the file systems are given the context they are opened with, not the one the rolodex was indexed with.
+	_, err = localFS.OpenWithContext(ctx, "thing.yaml")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = remoteFS.OpenWithContext(ctx, "https://pb33f.io/thing.yaml")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = rolodex.OpenWithContext(ctx, "thing.yaml")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	f, err := rolodex.OpenWithContext(context.Background(), "thing.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "type: string", f.GetContent())
+}
+
+// limitErrors returns the limit errors caught by a rolodex, references to the files that exceeded
+// the limits cannot be resolved either.
+func limitErrors(rolodex *Rolodex) []string {
+	var errs []string
+	for _, err := range rolodex.GetCaughtErrors() {
+		if errors.Is(err, datamodel.ErrLimitExceeded) {
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs
+}
+
+func TestRolodex_MaxRolodexFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"one.yaml", "two.yaml", "three.yaml"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("type: string"), 0o644))
+	}
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    One:
+      $ref: 'one.yaml'
+    Two:
+      $ref: 'two.yaml'
+    Three:
+      $ref: 'three.yaml'`
+
+	index := func(limits *datamodel.Limits) *Rolodex {
+		var rootNode yaml.Node
+		_ = yaml.Unmarshal([]byte(spec), &rootNode)
+
+		cf := CreateOpenAPIIndexConfig()
+		cf.BasePath = dir
+		cf.Limits = limits
+		rolodex := NewRolodex(cf)
+		fileFS, err := NewLocalFSWithConfig(&LocalFSConfig{BaseDirectory: dir, IndexConfig: cf})
+		assert.NoError(t, err)
+		rolodex.AddLocalFS(dir, fileFS)
+		rolodex.SetRootNode(&rootNode)
+		_ = rolodex.IndexTheRolodex()
+		return rolodex
+	}
+
+	errs := limitErrors(index(&datamodel.Limits{MaxRolodexFiles: 2}))
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "exceeds MaxRolodexFiles (2)")
+
+	errs = limitErrors(index(&datamodel.Limits{MaxRolodexFiles: 3, MaxInputBytes: 5}))
+	assert.Len(t, errs, 3)
+	for _, err := range errs {
+		assert.Contains(t, err, "exceeds MaxInputBytes (5)")
+	}
+
+	errs = limitErrors(index(&datamodel.Limits{MaxRolodexFiles: 3, MaxNodeCount: 2}))
+	assert.Len(t, errs, 3)
+	for _, err := range errs {
+		assert.Contains(t, err, "exceeds MaxNodeCount (2)")
+	}
+
+	rolodex := index(&datamodel.Limits{MaxRolodexFiles: 3})
+	assert.Empty(t, rolodex.GetCaughtErrors())
+	assert.Len(t, rolodex.GetIndexes(), 3)
+}
+
+func TestNewDocumentRolodex(t *testing.T) {
+	info, err := datamodel.ExtractSpecInfo([]byte("openapi: 3.1.0"))
+	assert.NoError(t, err)
+
+	// without a base path or URL, nothing can be looked up.
+	d := NewDocumentRolodex(info, &datamodel.DocumentConfiguration{})
+	assert.Nil(t, d.LocalFS)
+	assert.False(t, d.IndexConfig.AllowFileLookup)
+	assert.False(t, d.IndexConfig.AllowRemoteLookup)
+	assert.Equal(t, info.RootNode, d.Rolodex.GetRootNode())
+
+	dir := t.TempDir()
+	limits := &datamodel.Limits{MaxRolodexFiles: 5}
+	d = NewDocumentRolodex(info, &datamodel.DocumentConfiguration{
+		BasePath: dir, AllowRemoteReferences: true, Limits: limits,
+	})
+	assert.NotNil(t, d.LocalFS)
+	assert.Equal(t, dir, d.BaseDirectory)
+	assert.True(t, d.IndexConfig.AllowFileLookup)
+	assert.True(t, d.IndexConfig.AllowRemoteLookup)
+	assert.Equal(t, limits, d.IndexConfig.Limits)
+	assert.NoError(t, d.Rolodex.IndexTheRolodex())
+}
+
+func TestRolodex_MaxRolodexFiles_Remote(t *testing.T) {
+	var fetched sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetched.Store(req.URL.Path, true)
+		_, _ = rw.Write([]byte("type: string"))
+	}))
+	defer srv.Close()
+
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    One:
+      $ref: '` + srv.URL + `/one.yaml'
+    Two:
+      $ref: '` + srv.URL + `/two.yaml'
+    Three:
+      $ref: '` + srv.URL + `/three.yaml'`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+
+	cf := CreateOpenAPIIndexConfig()
+	cf.AllowRemoteLookup = true
+	cf.Limits = &datamodel.Limits{MaxRolodexFiles: 2}
+	rolodex := NewRolodex(cf)
+	remoteFS, _ := NewRemoteFSWithConfig(cf)
+	rolodex.AddRemoteFS(srv.URL, remoteFS)
+	rolodex.SetRootNode(&rootNode)
+
+	_ = rolodex.IndexTheRolodex()
+	errs := limitErrors(rolodex)
+	assert.Len(t, errs, 1)
+	for _, err := range errs {
+		assert.Contains(t, err, "exceeds MaxRolodexFiles (2)")
+	}
+
+	// the file over the limit is never fetched.
+	count := 0
+	fetched.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 2, count)
+}
+
+func TestRolodex_MaxRemoteFetchBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("type: object\ndescription: " + strings.Repeat("lol", 1000)))
+	}))
+	defer srv.Close()
+
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Thing:
+      $ref: '` + srv.URL + `/big.yaml'`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+
+	u, _ := url.Parse(srv.URL)
+	cf := CreateOpenAPIIndexConfig()
+	cf.BaseURL = u
+	cf.AllowRemoteLookup = true
+	cf.Limits = &datamodel.Limits{MaxRemoteFetchBytes: 1024}
+	rolodex := NewRolodex(cf)
+	remoteFS, _ := NewRemoteFSWithConfig(cf)
+	rolodex.AddRemoteFS(srv.URL, remoteFS)
+	rolodex.SetRootNode(&rootNode)
+
+	_ = rolodex.IndexTheRolodex()
+	errs := limitErrors(rolodex)
+	assert.Equal(t, []string{"limit exceeded: '" + srv.URL + "/big.yaml' exceeds MaxRemoteFetchBytes (1024)"}, errs)
+
+	// the same file, within the limit.
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+	cf.Limits.MaxRemoteFetchBytes = 4096
+	rolodex = NewRolodex(cf)
+	remoteFS, _ = NewRemoteFSWithConfig(cf)
+	rolodex.AddRemoteFS(srv.URL, remoteFS)
+	rolodex.SetRootNode(&rootNode)
+	assert.NoError(t, rolodex.IndexTheRolodex())
+}
@@ -0,0 +1,330 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// DynamicScopeKey is the context key holding the dynamic scope of a schema, the absolute URIs of the schema
+// resources that have been entered to reach it, outermost first. It is used to resolve '$dynamicRef' values.
+const DynamicScopeKey ContextKey = "dynamicScope"
+
+// extractSchemaIdentifiers walks the document and collects the schema resources ('$id'), the anchors ('$anchor' and
+// '$dynamicAnchor') and the absolute URI of every '$ref' and '$dynamicRef' that is resolved against a schema resource.
+func (index *SpecIndex) extractSchemaIdentifiers(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	base, err := url.Parse(index.specAbsolutePath)
+	if err != nil {
+		base = &url.URL{}
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.MappingNode {
+		base = schemaResourceURI(base, node)
+	}
+	index.schemaBaseURI = base.String()
+
+	index.schemaIdLock.Lock()
+	defer index.schemaIdLock.Unlock()
+	index.walkSchemaIdentifiers(node, base, base.String(), false)
+}
+
+// walkSchemaIdentifiers walks a node, collecting schema identifiers. When names is true, the node is one of the
+// namedMaps (like 'properties' or '$defs'), so its keys are names and not keywords.
+func (index *SpecIndex) walkSchemaIdentifiers(node *yaml.Node, base *url.URL, docBase string, names bool) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			index.walkSchemaIdentifiers(n, base, docBase, false)
+		}
+	case yaml.MappingNode:
+		if names {
+			for i := 1; i < len(node.Content); i += 2 {
+				index.walkSchemaIdentifiers(node.Content[i], base, docBase, false)
+			}
+			return
+		}
+		if resource := schemaResourceURI(base, node); resource != base {
+			base = resource
+			index.schemaResources[base.String()] = node
+			index.schemaResourceIds[node] = base.String()
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch key {
+			case "$anchor", "$dynamicAnchor":
+				if value.Kind != yaml.ScalarNode {
+					break
+				}
+				anchor := *base
+				anchor.Fragment = value.Value
+				index.schemaAnchors[anchor.String()] = node
+				if key == "$dynamicAnchor" {
+					index.dynamicAnchors[anchor.String()] = node
+				}
+			case "$ref":
+				// JSON pointers into the document are looked up as they always have been.
+				if value.Kind != yaml.ScalarNode || (base.String() == docBase && strings.HasPrefix(value.Value, "#/")) {
+					break
+				}
+				if u, e := url.Parse(value.Value); e == nil {
+					index.schemaRefs[node] = base.ResolveReference(u).String()
+				}
+			case "$dynamicRef":
+				if value.Kind != yaml.ScalarNode {
+					break
+				}
+				if u, e := url.Parse(value.Value); e == nil {
+					index.dynamicRefs[node] = base.ResolveReference(u).String()
+				}
+			case "example", "examples", "enum", "const", "default":
+				// values, not schemas.
+			default:
+				index.walkSchemaIdentifiers(value, base, docBase, namedMaps[key])
+			}
+		}
+	}
+}
+
+// schemaResourceURI returns the base URI of a schema with an '$id', resolved against the base URI it is defined
+// in. base is returned if the schema has no '$id'.
+func schemaResourceURI(base *url.URL, node *yaml.Node) *url.URL {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "$id" || node.Content[i+1].Kind != yaml.ScalarNode {
+			continue
+		}
+		u, err := url.Parse(node.Content[i+1].Value)
+		if err != nil {
+			return base
+		}
+		resolved := base.ResolveReference(u)
+		resolved.Fragment = ""
+		resolved.RawFragment = ""
+		return resolved
+	}
+	return base
+}
+
+// FindSchemaIdentifier will locate a schema by an absolute URI made of the '$id' of a schema resource and a
+// fragment, which is either a plain name '$anchor' (or '$dynamicAnchor'), or a JSON pointer into the resource.
+// The indexes of every document in the rolodex are searched, nil is returned if nothing is found.
+func (index *SpecIndex) FindSchemaIdentifier(uri string) *Reference {
+	if index == nil {
+		return nil
+	}
+	if r := index.findSchemaIdentifier(uri); r != nil {
+		return r
+	}
+	for _, idx := range index.rolodexIndexes()[1:] {
+		if r := idx.findSchemaIdentifier(uri); r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// rolodexIndexes returns this index, followed by the other indexes in the rolodex.
+func (index *SpecIndex) rolodexIndexes() []*SpecIndex {
+	indexes := []*SpecIndex{index}
+	if index.rolodex == nil {
+		return indexes
+	}
+	for _, idx := range append([]*SpecIndex{index.rolodex.GetRootIndex()}, index.rolodex.GetIndexes()...) {
+		if idx != nil && idx != index {
+			indexes = append(indexes, idx)
+		}
+	}
+	return indexes
+}
+
+func (index *SpecIndex) findSchemaIdentifier(uri string) *Reference {
+	index.schemaIdLock.Lock()
+	defer index.schemaIdLock.Unlock()
+	if r, ok := index.schemaIdentifierRefs[uri]; ok {
+		return r
+	}
+
+	resource, fragment, _ := strings.Cut(uri, "#")
+	var node *yaml.Node
+	name := fragment
+	if fragment != "" && !strings.HasPrefix(fragment, "/") {
+		node = index.schemaAnchors[uri]
+	} else if node = index.schemaResources[resource]; node != nil && fragment != "" {
+		node, name = locateSchemaPointer(node, fragment)
+	}
+	if node == nil {
+		return nil
+	}
+	if name == "" {
+		name = resource[strings.LastIndex(resource, "/")+1:]
+	}
+	r := &Reference{
+		FullDefinition: uri,
+		Definition:     uri,
+		Name:           name,
+		Node:           node,
+		Index:          index,
+		RemoteLocation: index.specAbsolutePath,
+		IsRemote:       index.rolodex != nil && index.rolodex.GetRootIndex() != index,
+	}
+	index.schemaIdentifierRefs[uri] = r
+	return r
+}
+
+// locateSchemaPointer follows a JSON pointer from a schema node, returning the node and the last segment of the
+// pointer, or nil if the pointer does not resolve.
+func locateSchemaPointer(node *yaml.Node, pointer string) (*yaml.Node, string) {
+	var segment string
+	for _, seg := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if s, err := url.PathUnescape(seg); err == nil {
+			seg = s
+		}
+		segment = utils.UnescapePointerSegment(seg)
+		switch node.Kind {
+		case yaml.MappingNode:
+			var found *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					found = node.Content[i+1]
+					break
+				}
+			}
+			node = found
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil, ""
+			}
+			node = node.Content[i]
+		default:
+			return nil, ""
+		}
+		if node == nil {
+			return nil, ""
+		}
+	}
+	return node, segment
+}
+
+// FindSchemaReference will locate the schema a '$ref' resolves to, when it is resolved against the '$id' of a schema
+// resource or names an '$anchor'. node is the mapping node holding the '$ref'. nil is returned for references that
+// are JSON pointers into a document, those are located with FindComponent.
+func (index *SpecIndex) FindSchemaReference(node *yaml.Node) *Reference {
+	if index == nil {
+		return nil
+	}
+	uri, ok := index.lookupSchemaRef(node, false)
+	if !ok {
+		return nil
+	}
+	return index.FindSchemaIdentifier(uri)
+}
+
+// FindDynamicReference will locate the schema a '$dynamicRef' resolves to. node is the mapping node holding the
+// '$dynamicRef', and scope is the dynamic scope it is evaluated in (see GetDynamicScope).
+//
+// The reference is first resolved like a '$ref'. If the schema it resolves to has a '$dynamicAnchor' of the same
+// name, the outermost schema resource in the dynamic scope with a '$dynamicAnchor' of that name is used instead.
+// nil is returned if the reference cannot be resolved.
+func (index *SpecIndex) FindDynamicReference(node *yaml.Node, scope []string) *Reference {
+	if index == nil {
+		return nil
+	}
+	uri, ok := index.lookupSchemaRef(node, true)
+	if !ok {
+		return nil
+	}
+	static := index.FindSchemaIdentifier(uri)
+	_, name, _ := strings.Cut(uri, "#")
+	if static == nil || name == "" || strings.HasPrefix(name, "/") || !static.Index.isDynamicAnchor(uri) {
+		return static
+	}
+
+	// the document a schema is evaluated from is the outermost resource of every dynamic scope.
+	root := index
+	if index.rolodex != nil && index.rolodex.GetRootIndex() != nil {
+		root = index.rolodex.GetRootIndex()
+	}
+	for _, resource := range append([]string{root.schemaBaseURI}, scope...) {
+		anchor, _, _ := strings.Cut(resource, "#")
+		anchor += "#" + name
+		if r := index.FindSchemaIdentifier(anchor); r != nil && r.Index.isDynamicAnchor(anchor) {
+			return r
+		}
+	}
+	return static
+}
+
+// lookupSchemaRef returns the absolute URI recorded for a '$ref' (or a '$dynamicRef') by this index, or by the
+// index of the document in the rolodex that holds the node.
+func (index *SpecIndex) lookupSchemaRef(node *yaml.Node, dynamic bool) (string, bool) {
+	for _, idx := range index.rolodexIndexes() {
+		idx.schemaIdLock.RLock()
+		refs := idx.schemaRefs
+		if dynamic {
+			refs = idx.dynamicRefs
+		}
+		uri, ok := refs[node]
+		idx.schemaIdLock.RUnlock()
+		if ok {
+			return uri, true
+		}
+	}
+	return "", false
+}
+
+func (index *SpecIndex) isDynamicAnchor(uri string) bool {
+	index.schemaIdLock.RLock()
+	defer index.schemaIdLock.RUnlock()
+	return index.dynamicAnchors[uri] != nil
+}
+
+// EnterSchemaResource returns a context with the schema added to the end of the dynamic scope of the context, if
+// the schema is a schema resource (it has an '$id'). Otherwise, the context is returned as is.
+func (index *SpecIndex) EnterSchemaResource(ctx context.Context, node *yaml.Node) context.Context {
+	if index == nil || ctx == nil || node == nil {
+		return ctx
+	}
+	var id string
+	for _, idx := range index.rolodexIndexes() {
+		idx.schemaIdLock.RLock()
+		id = idx.schemaResourceIds[node]
+		idx.schemaIdLock.RUnlock()
+		if id != "" {
+			break
+		}
+	}
+	if id == "" {
+		return ctx
+	}
+	scope := GetDynamicScope(ctx)
+	if len(scope) > 0 && scope[len(scope)-1] == id {
+		return ctx
+	}
+	entered := make([]string, len(scope), len(scope)+1)
+	copy(entered, scope)
+	return context.WithValue(ctx, DynamicScopeKey, append(entered, id))
+}
+
+// GetDynamicScope returns the absolute URIs of the schema resources in the dynamic scope of a context, outermost
+// first.
+func GetDynamicScope(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	if scope, ok := ctx.Value(DynamicScopeKey).([]string); ok {
+		return scope
+	}
+	return nil
+}
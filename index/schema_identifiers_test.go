@@ -0,0 +1,182 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var schemaIdentifierSpec = `openapi: 3.1.0
+components:
+  schemas:
+    Pet:
+      $anchor: pet
+      type: object
+      properties:
+        owner:
+          $ref: '#person'
+        address:
+          $ref: https://example.com/schemas/address
+    Person:
+      $anchor: person
+      type: object
+      properties:
+        name:
+          type: string
+    Address:
+      $id: https://example.com/schemas/address
+      type: object
+      properties:
+        country:
+          $ref: country
+        street:
+          $ref: '#/$defs/street'
+      $defs:
+        street:
+          type: string
+    Country:
+      $id: https://example.com/schemas/country
+      type: string
+      examples:
+        - $anchor: notAnAnchor`
+
+func TestSpecIndex_SchemaIdentifiers(t *testing.T) {
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(schemaIdentifierSpec), &rootNode)
+
+	idx := NewSpecIndexWithConfig(&rootNode, CreateClosedAPIIndexConfig())
+	assert.Empty(t, idx.GetReferenceIndexErrors())
+
+	// anchors are located by name.
+	person := idx.FindComponent("#person")
+	require.NotNil(t, person)
+	assert.Equal(t, "person", person.Name)
+	assert.Equal(t, "#person", person.FullDefinition)
+	assert.Same(t, person, idx.FindSchemaIdentifier("#person"))
+
+	// schema resources are located by '$id', with or without a JSON pointer.
+	address := idx.FindComponent("https://example.com/schemas/address")
+	require.NotNil(t, address)
+	assert.Equal(t, "address", address.Name)
+	street := idx.FindSchemaIdentifier("https://example.com/schemas/address#/$defs/street")
+	require.NotNil(t, street)
+	assert.Equal(t, "street", street.Name)
+	assert.Equal(t, "string", street.Node.Content[1].Value)
+	assert.Nil(t, idx.FindSchemaIdentifier("https://example.com/schemas/address#/$defs/nope"))
+	assert.Nil(t, idx.FindSchemaIdentifier("https://example.com/schemas/address#/properties/street/$ref/0"))
+	assert.Nil(t, idx.FindSchemaIdentifier("#notAnAnchor"))
+	assert.Nil(t, idx.FindSchemaIdentifier("https://example.com/nope"))
+
+	// references inside a schema resource resolve against its '$id'.
+	mapped := idx.GetMappedReferences()
+	assert.Contains(t, mapped, "#person")
+	assert.Contains(t, mapped, "https://example.com/schemas/address")
+	assert.Contains(t, mapped, "https://example.com/schemas/country")
+	assert.Contains(t, mapped, "https://example.com/schemas/address#/$defs/street")
+
+	resolver := NewResolver(idx)
+	assert.Empty(t, resolver.CheckForCircularReferences())
+	assert.Empty(t, resolver.Resolve())
+}
+
+func TestSpecIndex_SchemaIdentifiers_PropertyNames(t *testing.T) {
+	spec := `openapi: 3.1.0
+components:
+  schemas:
+    Settings:
+      type: object
+      default: {$anchor: notAnAnchor}
+      properties:
+        default:
+          $anchor: defaultValue
+        example:
+          $anchor: exampleValue
+        enum:
+          $anchor: enumValue
+        const:
+          $anchor: constValue
+        examples:
+          $anchor: examplesValue
+        $anchor:
+          $anchor: anchorProperty
+      $defs:
+        default:
+          $id: https://example.com/schemas/default`
+
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(spec), &rootNode)
+	idx := NewSpecIndexWithConfig(&rootNode, CreateClosedAPIIndexConfig())
+
+	// properties and definitions are names, not keywords, whatever they are called.
+	for _, anchor := range []string{
+		"defaultValue", "exampleValue", "enumValue", "constValue", "examplesValue", "anchorProperty",
+	} {
+		assert.NotNil(t, idx.FindSchemaIdentifier("#"+anchor), anchor)
+	}
+	assert.NotNil(t, idx.FindSchemaIdentifier("https://example.com/schemas/default"))
+	assert.Nil(t, idx.FindSchemaIdentifier("#notAnAnchor"))
+}
+
+var dynamicRefSpec = `openapi: 3.1.0
+components:
+  schemas:
+    List:
+      $id: https://example.com/schemas/list
+      $defs:
+        itemType:
+          $dynamicAnchor: itemType
+          not: true
+      type: array
+      items:
+        $dynamicRef: '#itemType'
+    StringList:
+      $id: https://example.com/schemas/string-list
+      $ref: list
+      $defs:
+        itemType:
+          $dynamicAnchor: itemType
+          type: string
+    Missing:
+      $dynamicRef: '#nope'`
+
+func TestSpecIndex_FindDynamicReference(t *testing.T) {
+	var rootNode yaml.Node
+	_ = yaml.Unmarshal([]byte(dynamicRefSpec), &rootNode)
+
+	idx := NewSpecIndexWithConfig(&rootNode, CreateClosedAPIIndexConfig())
+	schemas := rootNode.Content[0].Content[3].Content[1]
+	list := schemas.Content[1]
+	items := list.Content[7]
+	stringList := schemas.Content[3]
+
+	// without a dynamic scope, the reference resolves to the schema the list defines.
+	found := idx.FindDynamicReference(items, nil)
+	require.NotNil(t, found)
+	assert.Equal(t, "https://example.com/schemas/list#itemType", found.FullDefinition)
+
+	// entered through the string list, the outermost dynamic anchor wins.
+	ctx := idx.EnterSchemaResource(context.Background(), stringList)
+	ctx = idx.EnterSchemaResource(ctx, list)
+	ctx = idx.EnterSchemaResource(ctx, list)
+	assert.Equal(t, []string{"https://example.com/schemas/string-list", "https://example.com/schemas/list"},
+		GetDynamicScope(ctx))
+	found = idx.FindDynamicReference(items, GetDynamicScope(ctx))
+	require.NotNil(t, found)
+	assert.Equal(t, "https://example.com/schemas/string-list#itemType", found.FullDefinition)
+
+	// schemas that are not resources do not change the scope.
+	assert.Equal(t, ctx, idx.EnterSchemaResource(ctx, items))
+	assert.Nil(t, GetDynamicScope(context.Background()))
+	assert.Nil(t, idx.FindDynamicReference(list, nil))
+
+	resolver := NewResolver(idx)
+	errs := resolver.CheckForCircularReferences()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "cannot resolve dynamic reference `#nope`, it's missing", errs[0].ErrorRef.Error())
+}
@@ -125,7 +125,7 @@ func (index *SpecIndex) SearchIndexForReferenceByReferenceWithContext(ctx contex
 		if filepath.Base(roloLookup) == "root.yaml" {
 			return nil, index, ctx
 		}
-		rFile, err := index.rolodex.Open(roloLookup)
+		rFile, err := index.rolodex.OpenWithContext(ctx, roloLookup)
 		if err != nil {
 			return nil, index, ctx
 		}
@@ -171,7 +171,7 @@ func (index *SpecIndex) SearchIndexForReferenceByReferenceWithContext(ctx contex
 						found = FindComponent(node, compId, exp[0], idx)
 					}
 					if found == nil {
-						found = idx.FindComponent(ref)
+						found = idx.findComponent(ctx, ref)
 					}
 
 					if found != nil {
@@ -0,0 +1,417 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// DiagnosticCode identifies the kind of problem found by the semantic validation of a specification.
+type DiagnosticCode string
+
+const (
+	// PathParameterMissing is a path template expression (like '{id}') with no matching 'in: path' parameter.
+	PathParameterMissing DiagnosticCode = "path-parameter-missing"
+
+	// PathParameterNotInTemplate is an 'in: path' parameter that does not appear in the path template.
+	PathParameterNotInTemplate DiagnosticCode = "path-parameter-not-in-template"
+
+	// DuplicateOperationId is an operationId used by more than one operation.
+	DuplicateOperationId DiagnosticCode = "operation-id-duplicate"
+
+	// UndefinedSecurityScheme is a security requirement that names a scheme that has not been defined.
+	UndefinedSecurityScheme DiagnosticCode = "security-scheme-undefined"
+
+	// UnknownDiscriminatorMapping is a discriminator mapping that points at a schema that does not exist.
+	UnknownDiscriminatorMapping DiagnosticCode = "discriminator-mapping-unknown"
+
+	// UnknownLinkOperation is a link with an operationId that does not belong to any operation.
+	UnknownLinkOperation DiagnosticCode = "link-operation-unknown"
+)
+
+// Diagnostic is a problem found by the semantic validation of a specification. These are problems that cannot be
+// expressed by the OpenAPI JSON Schema, so they are not found by structural validation.
+type Diagnostic struct {
+	// Code identifies the kind of problem.
+	Code DiagnosticCode
+
+	// Message explains the problem.
+	Message string
+
+	// Path is the path to the problem, in the same style as every other path in the index.
+	Path string
+
+	// Node is the node that caused the problem.
+	Node *yaml.Node
+
+	// Line and Column of the node that caused the problem.
+	Line   int
+	Column int
+
+	// Location is the absolute path (or URL) of the file that contains the node.
+	Location string
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("%s (line %d, column %d): %s [%s]", d.Path, d.Line, d.Column, d.Message, d.Code)
+}
+
+var pathTemplateRegex = regexp.MustCompile(`\{([^{}/]+)}`)
+
+// operations are checked in this order, so diagnostics are always returned in the same order.
+var semanticMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// ValidateSemantics checks the specification for problems that the OpenAPI JSON Schema cannot catch. Path
+// templates and 'in: path' parameters must match, operationIds must be unique, security requirements must use
+// schemes that have been defined, discriminator mappings must point at schemas that exist, and links must use
+// an operationId that exists.
+//
+// Every problem is returned as a Diagnostic, with a Code and the position of the node that caused it. No
+// diagnostics means no problems were found.
+func (index *SpecIndex) ValidateSemantics() []*Diagnostic {
+	if index == nil || index.root == nil {
+		return nil
+	}
+	var diagnostics []*Diagnostic
+	operationIds := make(map[string]*yaml.Node)
+	allOperations := true // false when an operation cannot be seen, so its operationId is unknown.
+
+	for _, path := range index.orderedPaths() {
+		operations := index.pathRefs[path]
+		params := index.paramOpRefs[path]
+		templateNames := make(map[string]bool)
+		for _, match := range pathTemplateRegex.FindAllStringSubmatch(path, -1) {
+			templateNames[match[1]] = true
+		}
+		diagnostics = append(diagnostics, index.checkPathParameters(path, "top", params["top"], templateNames)...)
+		_, pathItem := utils.FindKeyNodeTop(path, index.pathsNode.Content)
+
+		for _, method := range semanticMethods {
+			op := operations[method]
+			if op == nil {
+				continue
+			}
+			diagnostics = append(diagnostics, index.checkPathParameters(path, method, params[method], templateNames)...)
+
+			// an operation (or a parameter) that cannot be resolved could define any parameter.
+			if index.unresolved(op.Node) {
+				allOperations = false
+				continue
+			}
+			if !index.unresolvedParameters(pathItem) && !index.unresolvedParameters(op.Node) {
+				diagnostics = append(diagnostics, index.checkMissingParameters(op, method, params, templateNames)...)
+			}
+
+			if _, idNode := utils.FindKeyNodeTop("operationId", op.Node.Content); idNode != nil {
+				if first, ok := operationIds[idNode.Value]; ok {
+					diagnostics = append(diagnostics, index.newDiagnostic(DuplicateOperationId, idNode, op.Path+".operationId",
+						"operationId '%s' is already used by the operation on line %d", idNode.Value, first.Line))
+				} else {
+					operationIds[idNode.Value] = idNode
+				}
+			}
+
+			if _, security := utils.FindKeyNodeTop("security", op.Node.Content); security != nil {
+				diagnostics = append(diagnostics, index.checkSecurity(security, op.Path+".security")...)
+			}
+		}
+	}
+	diagnostics = append(diagnostics, index.checkSecurity(index.rootSecurityNode, "$.security")...)
+
+	index.walkSemantics(index.root, "$", "", func(node *yaml.Node, path, key string) {
+		switch key {
+		case "discriminator":
+			diagnostics = append(diagnostics, index.checkDiscriminator(node, path)...)
+		case "links":
+			if allOperations {
+				diagnostics = append(diagnostics, index.checkLinks(node, path, operationIds)...)
+			}
+		}
+	})
+	return diagnostics
+}
+
+// orderedPaths returns every path in the order it appears in the specification.
+func (index *SpecIndex) orderedPaths() []string {
+	var paths []string
+	if index.pathsNode == nil {
+		return paths
+	}
+	for i := 0; i+1 < len(index.pathsNode.Content); i += 2 {
+		if _, ok := index.pathRefs[index.pathsNode.Content[i].Value]; ok {
+			paths = append(paths, index.pathsNode.Content[i].Value)
+		}
+	}
+	return paths
+}
+
+// checkPathParameters makes sure every 'in: path' parameter appears in the path template.
+func (index *SpecIndex) checkPathParameters(path, method string, params map[string][]*Reference,
+	templateNames map[string]bool,
+) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	for _, ref := range sortedParameters(params) {
+		name, in := parameterNameAndLocation(ref.Node)
+		if in != "path" || templateNames[name] {
+			continue
+		}
+		where := fmt.Sprintf("the '%s' operation", method)
+		if method == "top" {
+			where = "the path item"
+		}
+		diagnostics = append(diagnostics, index.newDiagnostic(PathParameterNotInTemplate, ref.Node, ref.Path,
+			"path parameter '%s' is defined by %s, but is not in the path template '%s'", name, where, path))
+	}
+	return diagnostics
+}
+
+// checkMissingParameters makes sure every path template expression has an 'in: path' parameter, defined by either
+// the path item or the operation.
+func (index *SpecIndex) checkMissingParameters(op *Reference, method string, params map[string]map[string][]*Reference,
+	templateNames map[string]bool,
+) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	declared := pathParameterNames(params["top"])
+	for name := range pathParameterNames(params[method]) {
+		declared[name] = true
+	}
+	var missing []string
+	for name := range templateNames {
+		if !declared[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		diagnostics = append(diagnostics, index.newDiagnostic(PathParameterMissing, op.ParentNode, op.Path,
+			"path parameter '%s' is in the path template, but is not defined by the '%s' operation", name, method))
+	}
+	return diagnostics
+}
+
+// unresolved returns true if the node is a reference that cannot be found.
+func (index *SpecIndex) unresolved(node *yaml.Node) bool {
+	if !utils.IsNodeMap(node) {
+		return false
+	}
+	_, ref := utils.FindKeyNodeTop("$ref", node.Content)
+	if ref == nil {
+		return false
+	}
+	found, _ := index.SearchIndexForReference(ref.Value)
+	return found == nil
+}
+
+// unresolvedParameters returns true if any parameter of a path item or operation cannot be found.
+func (index *SpecIndex) unresolvedParameters(node *yaml.Node) bool {
+	if !utils.IsNodeMap(node) {
+		return false
+	}
+	_, params := utils.FindKeyNodeTop("parameters", node.Content)
+	if !utils.IsNodeArray(params) {
+		return false
+	}
+	for _, param := range params.Content {
+		if index.unresolved(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSecurity makes sure every security requirement names a scheme that has been defined.
+func (index *SpecIndex) checkSecurity(security *yaml.Node, path string) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	if !utils.IsNodeArray(security) {
+		return diagnostics
+	}
+	for i, requirement := range security.Content {
+		if !utils.IsNodeMap(requirement) {
+			continue
+		}
+		for j := 0; j+1 < len(requirement.Content); j += 2 {
+			name := requirement.Content[j]
+			if index.allSecuritySchemes["#/components/securitySchemes/"+name.Value] != nil ||
+				index.allSecuritySchemes["#/securityDefinitions/"+name.Value] != nil {
+				continue
+			}
+			diagnostics = append(diagnostics, index.newDiagnostic(UndefinedSecurityScheme, name,
+				fmt.Sprintf("%s[%d].%s", path, i, name.Value),
+				"security requirement uses '%s', but no security scheme with that name has been defined", name.Value))
+		}
+	}
+	return diagnostics
+}
+
+// checkDiscriminator makes sure every discriminator mapping points at a schema that exists.
+func (index *SpecIndex) checkDiscriminator(discriminator *yaml.Node, path string) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	if !utils.IsNodeMap(discriminator) {
+		return diagnostics // swagger discriminators are just a property name.
+	}
+	_, mapping := utils.FindKeyNodeTop("mapping", discriminator.Content)
+	if !utils.IsNodeMap(mapping) {
+		return diagnostics
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		value := mapping.Content[i+1]
+		target := value.Value
+		if !strings.Contains(target, "#") && !strings.Contains(target, "/") {
+			// a plain name is the name of a component schema.
+			target = "#/components/schemas/" + target
+		}
+		if index.locateComponent(target) {
+			continue
+		}
+		diagnostics = append(diagnostics, index.newDiagnostic(UnknownDiscriminatorMapping, value,
+			fmt.Sprintf("%s.mapping.%s", path, mapping.Content[i].Value),
+			"discriminator mapping '%s' points at '%s', which does not exist", mapping.Content[i].Value, value.Value))
+	}
+	return diagnostics
+}
+
+// checkLinks makes sure every link with an operationId points at an operation that exists.
+func (index *SpecIndex) checkLinks(links *yaml.Node, path string, operationIds map[string]*yaml.Node) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	if !utils.IsNodeMap(links) {
+		return diagnostics
+	}
+	for i := 0; i+1 < len(links.Content); i += 2 {
+		link := links.Content[i+1]
+		if !utils.IsNodeMap(link) {
+			continue
+		}
+		_, id := utils.FindKeyNodeTop("operationId", link.Content)
+		if id == nil || operationIds[id.Value] != nil {
+			continue
+		}
+		diagnostics = append(diagnostics, index.newDiagnostic(UnknownLinkOperation, id,
+			fmt.Sprintf("%s.%s.operationId", path, links.Content[i].Value),
+			"link '%s' uses operationId '%s', but no operation has that operationId", links.Content[i].Value, id.Value))
+	}
+	return diagnostics
+}
+
+// namedMaps are keywords whose values are maps keyed by a name (or path, code or media type) and not a keyword.
+var namedMaps = map[string]bool{
+	"properties": true, "patternProperties": true, "dependentSchemas": true, "definitions": true, "$defs": true,
+	"schemas": true, "parameters": true, "responses": true, "requestBodies": true, "headers": true,
+	"securitySchemes": true, "securityDefinitions": true, "links": true, "callbacks": true, "pathItems": true,
+	"paths": true, "webhooks": true, "content": true, "encoding": true, "mapping": true, "variables": true,
+	"scopes": true,
+}
+
+// walkSemantics visits every keyword in the specification. Values that are not part of the specification
+// structure (like examples, enums and extensions) are skipped, and names (like schema property names) are
+// never visited as keywords.
+func (index *SpecIndex) walkSemantics(node *yaml.Node, path, parentKey string, visit func(*yaml.Node, string, string)) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			index.walkSemantics(n, path, parentKey, visit)
+		}
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			index.walkSemantics(n, fmt.Sprintf("%s[%d]", path, i), parentKey, visit)
+		}
+	case yaml.MappingNode:
+		isNamedMap := namedMaps[parentKey]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch key {
+			case "example", "examples", "default", "enum", "const":
+				if !isNamedMap {
+					continue
+				}
+			}
+			if strings.HasPrefix(key, "x-") && !isNamedMap {
+				continue
+			}
+			childPath := fmt.Sprintf("%s.%s", path, key)
+			if !isNamedMap {
+				visit(value, childPath, key)
+			}
+			index.walkSemantics(value, childPath, key, visit)
+		}
+	}
+}
+
+// locateComponent checks if a reference (local or in the rolodex) can be found.
+func (index *SpecIndex) locateComponent(ref string) bool {
+	if strings.HasPrefix(ref, "#/") {
+		return index.FindComponentInRoot(ref) != nil
+	}
+	found, _ := index.SearchIndexForReference(ref)
+	return found != nil
+}
+
+func (index *SpecIndex) newDiagnostic(code DiagnosticCode, node *yaml.Node, path, format string, args ...any) *Diagnostic {
+	d := &Diagnostic{
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Path:     path,
+		Node:     node,
+		Location: index.specAbsolutePath,
+	}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+		if index.rolodex != nil {
+			if origin := index.rolodex.FindNodeOrigin(node); origin != nil {
+				d.Location = origin.AbsoluteLocation
+			}
+		}
+	}
+	return d
+}
+
+// sortedParameters returns every parameter reference, in the order they appear in the specification.
+func sortedParameters(params map[string][]*Reference) []*Reference {
+	var refs []*Reference
+	for _, r := range params {
+		refs = append(refs, r...)
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].Node.Line != refs[j].Node.Line {
+			return refs[i].Node.Line < refs[j].Node.Line
+		}
+		return refs[i].Node.Column < refs[j].Node.Column
+	})
+	return refs
+}
+
+// pathParameterNames returns the name of every 'in: path' parameter.
+func pathParameterNames(params map[string][]*Reference) map[string]bool {
+	names := make(map[string]bool)
+	for _, refs := range params {
+		for _, ref := range refs {
+			if name, in := parameterNameAndLocation(ref.Node); in == "path" {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+func parameterNameAndLocation(node *yaml.Node) (string, string) {
+	if node == nil {
+		return "", ""
+	}
+	var name, in string
+	if _, n := utils.FindKeyNodeTop("name", node.Content); n != nil {
+		name = n.Value
+	}
+	if _, n := utils.FindKeyNodeTop("in", node.Content); n != nil {
+		in = n.Value
+	}
+	return name, in
+}
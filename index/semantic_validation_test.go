@@ -0,0 +1,151 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package index
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var semanticSpec = `openapi: 3.0.3
+info:
+  title: burgers
+  version: "1"
+security:
+  - nobody: []
+paths:
+  /burgers/{burgerId}:
+    parameters:
+      - $ref: '#/components/parameters/Dressing'
+    get:
+      operationId: getBurger
+      security:
+        - api_key: []
+          oauth: [read]
+      responses:
+        "200":
+          description: ok
+          links:
+            Fries:
+              operationId: getFries
+            Self:
+              operationId: getBurger
+  /fries:
+    post:
+      operationId: getBurger
+      parameters:
+        - name: fryId
+          in: path
+          required: true
+      responses:
+        "200":
+          description: ok
+components:
+  parameters:
+    Dressing:
+      name: dressingId
+      in: path
+      required: true
+  securitySchemes:
+    api_key:
+      type: apiKey
+      name: key
+      in: header
+  links:
+    Dressing:
+      operationId: getDressing
+  schemas:
+    Meal:
+      discriminator:
+        propertyName: kind
+        mapping:
+          burger: '#/components/schemas/Burger'
+          fries: Fries
+          drink: '#/components/schemas/Drink'
+          salad: Salad
+      properties:
+        discriminator:
+          type: string
+        links:
+          type: string
+    Burger:
+      type: object
+    Fries:
+      type: object`
+
+func TestSpecIndex_ValidateSemantics(t *testing.T) {
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(semanticSpec), &root))
+	idx := NewSpecIndexWithConfig(&root, CreateOpenAPIIndexConfig())
+
+	diagnostics := idx.ValidateSemantics()
+	var codes []DiagnosticCode
+	for _, d := range diagnostics {
+		codes = append(codes, d.Code)
+	}
+	assert.Equal(t, []DiagnosticCode{
+		PathParameterNotInTemplate,  // dressingId
+		PathParameterMissing,        // burgerId
+		UndefinedSecurityScheme,     // oauth
+		PathParameterNotInTemplate,  // fryId
+		DuplicateOperationId,        // getBurger
+		UndefinedSecurityScheme,     // nobody
+		UnknownLinkOperation,        // getFries
+		UnknownLinkOperation,        // getDressing
+		UnknownDiscriminatorMapping, // drink
+		UnknownDiscriminatorMapping, // salad
+	}, codes)
+
+	assert.Equal(t, "$.paths./burgers/{burgerId}.get (line 11, column 5): path parameter 'burgerId' is in the "+
+		"path template, but is not defined by the 'get' operation [path-parameter-missing]", diagnostics[1].Error())
+	assert.Equal(t, 37, diagnostics[0].Line)
+	assert.Equal(t, "$.paths./burgers/{burgerId}.get.security[0].oauth", diagnostics[2].Path)
+	assert.Equal(t, 15, diagnostics[2].Line)
+	assert.Equal(t, 11, diagnostics[2].Column)
+	assert.Equal(t, 26, diagnostics[4].Line)
+	assert.Contains(t, diagnostics[4].Message, "line 12")
+	assert.Equal(t, "$.paths./burgers/{burgerId}.get.responses.200.links.Fries.operationId", diagnostics[6].Path)
+	assert.Equal(t, "$.components.schemas.Meal.discriminator.mapping.drink", diagnostics[8].Path)
+	assert.Equal(t, 55, diagnostics[8].Line)
+}
+
+func TestSpecIndex_ValidateSemantics_Clean(t *testing.T) {
+	for _, spec := range []string{"../test_specs/petstorev3.json", "../test_specs/petstorev2.json"} {
+		b, err := os.ReadFile(spec)
+		require.NoError(t, err)
+		var root yaml.Node
+		require.NoError(t, yaml.Unmarshal(b, &root))
+		idx := NewSpecIndexWithConfig(&root, CreateOpenAPIIndexConfig())
+		assert.Empty(t, idx.ValidateSemantics(), spec)
+	}
+
+	var idx *SpecIndex
+	assert.Nil(t, idx.ValidateSemantics())
+}
+
+func TestSpecIndex_ValidateSemantics_Unresolved(t *testing.T) {
+	spec := `openapi: 3.0.3
+paths:
+  /burgers/{burgerId}:
+    get:
+      $ref: 'operations.yaml#/getBurger'
+  /fries/{fryId}:
+    get:
+      parameters:
+        - $ref: 'parameters.yaml#/fryId'
+components:
+  links:
+    Burger:
+      operationId: getBurger`
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(spec), &root))
+	idx := NewSpecIndexWithConfig(&root, CreateOpenAPIIndexConfig())
+
+	// nothing is known about operations and parameters that cannot be found, so nothing is reported.
+	assert.Empty(t, idx.ValidateSemantics())
+}
@@ -13,6 +13,7 @@
 package index
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -30,6 +31,12 @@ import (
 // except it sets a base URL for resolving relative references, except it also allows for granular control over
 // how the index is set up.
 func NewSpecIndexWithConfig(rootNode *yaml.Node, config *SpecIndexConfig) *SpecIndex {
+	return newSpecIndexWithContext(context.Background(), rootNode, config)
+}
+
+// newSpecIndexWithContext is the same as NewSpecIndexWithConfig, except references to other files are no longer
+// looked up once the context is cancelled.
+func newSpecIndexWithContext(ctx context.Context, rootNode *yaml.Node, config *SpecIndexConfig) *SpecIndex {
 	index := new(SpecIndex)
 	boostrapIndexCollections(index)
 	index.config = config
@@ -48,7 +55,7 @@ func NewSpecIndexWithConfig(rootNode *yaml.Node, config *SpecIndexConfig) *SpecI
 	}
 
 	index.root = rootNode
-	return createNewIndex(rootNode, index, config.AvoidBuildIndex)
+	return createNewIndex(ctx, rootNode, index, config.AvoidBuildIndex)
 }
 
 // NewSpecIndex will create a new index of an OpenAPI or Swagger spec. It's not resolved or converted into anything
@@ -62,10 +69,10 @@ func NewSpecIndex(rootNode *yaml.Node) *SpecIndex {
 	index.config = CreateOpenAPIIndexConfig()
 	index.root = rootNode
 	boostrapIndexCollections(index)
-	return createNewIndex(rootNode, index, false)
+	return createNewIndex(context.Background(), rootNode, index, false)
 }
 
-func createNewIndex(rootNode *yaml.Node, index *SpecIndex, avoidBuildOut bool) *SpecIndex {
+func createNewIndex(ctx context.Context, rootNode *yaml.Node, index *SpecIndex, avoidBuildOut bool) *SpecIndex {
 	// there is no node! return an empty index.
 	if rootNode == nil {
 		return index
@@ -76,6 +83,9 @@ func createNewIndex(rootNode *yaml.Node, index *SpecIndex, avoidBuildOut bool) *
 
 	index.cache = new(syncmap.Map)
 
+	// collect schema resources and anchors, so references can be resolved against them.
+	index.extractSchemaIdentifiers(index.root)
+
 	// boot index.
 	results := index.ExtractRefs(index.root.Content[0], index.root, []string{}, 0, false, "")
 
@@ -88,8 +98,8 @@ func createNewIndex(rootNode *yaml.Node, index *SpecIndex, avoidBuildOut bool) *
 	}
 
 	// pull out references
-	index.ExtractComponentsFromRefs(results)
-	index.ExtractComponentsFromRefs(poly)
+	index.extractComponentsFromRefs(ctx, results)
+	index.extractComponentsFromRefs(ctx, poly)
 
 	index.ExtractExternalDocuments(index.root)
 	index.GetPathCount()
@@ -718,7 +728,8 @@ func (index *SpecIndex) GetRawReferenceCount() int {
 	return len(index.rawSequencedRefs)
 }
 
-// GetComponentSchemaCount will return the number of schemas located in the 'components' or 'definitions' node.
+// GetComponentSchemaCount will return the number of schemas located in the 'components', 'definitions' or
+// '$defs' node.
 func (index *SpecIndex) GetComponentSchemaCount() int {
 	if index.root == nil || len(index.root.Content) == 0 {
 		return -1
@@ -853,6 +864,16 @@ func (index *SpecIndex) GetComponentSchemaCount() int {
 				}
 			}
 
+			// JSON Schema documents
+			if n.Value == "$defs" {
+				schemasNode := index.root.Content[0].Content[i+1]
+				if schemasNode != nil {
+					index.extractDefinitionsAndSchemas(schemasNode, "#/$defs/")
+					index.schemasNode = schemasNode
+					index.schemaCount = len(schemasNode.Content) / 2
+				}
+			}
+
 			// swagger
 			if n.Value == "parameters" {
 				parametersNode := index.root.Content[0].Content[i+1]
@@ -5,6 +5,7 @@ package index
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"log/slog"
@@ -964,7 +965,7 @@ func TestSpecIndex_TestPathsNodeAsArray(t *testing.T) {
 	_ = yaml.Unmarshal([]byte(yml), &rootNode)
 
 	index := NewSpecIndexWithConfig(&rootNode, CreateOpenAPIIndexConfig())
-	assert.Nil(t, index.lookupRolodex(nil))
+	assert.Nil(t, index.lookupRolodex(context.Background(), nil))
 }
 
 func TestSpecIndex_CheckBadURLRefNoRemoteAllowed(t *testing.T) {
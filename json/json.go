@@ -1,8 +1,10 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/pb33f/libopenapi/orderedmap"
 	"gopkg.in/yaml.v3"
@@ -20,6 +22,24 @@ func YAMLNodeToJSON(node *yaml.Node, indentation string) ([]byte, error) {
 	return json.MarshalIndent(v, "", indentation)
 }
 
+// RenderNode renders a node as JSON (see YAMLNodeToJSON) or as YAML, indented by the given number of spaces, or two
+// spaces when the indentation is not set.
+func RenderNode(node *yaml.Node, asJSON bool, indentation int) ([]byte, error) {
+	if indentation <= 0 {
+		indentation = 2
+	}
+	if asJSON {
+		return YAMLNodeToJSON(node, strings.Repeat(" ", indentation))
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indentation)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func handleYAMLNode(node *yaml.Node) (any, error) {
 	switch node.Kind {
 	case yaml.DocumentNode:
@@ -85,3 +85,16 @@ func TestYAMLNodeToJSON_FromJSON(t *testing.T) {
 
 	assert.Equal(t, j, string(o))
 }
+
+func TestRenderNode(t *testing.T) {
+	var v yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("pizza:\n    slices: 8\n"), &v))
+
+	o, err := json.RenderNode(&v, false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "pizza:\n  slices: 8\n", string(o))
+
+	o, err = json.RenderNode(&v, true, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n    \"pizza\": {\n        \"slices\": 8\n    }\n}", string(o))
+}
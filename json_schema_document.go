@@ -0,0 +1,180 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package libopenapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	lowbase "github.com/pb33f/libopenapi/datamodel/low/base"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchemaDocument is a standalone JSON Schema document (for example a JSON Schema 2020-12 file of shared data
+// models) that has been built into a high-level base.Schema tree.
+type JSONSchemaDocument struct {
+	// Schema is the root schema of the document.
+	Schema *base.Schema
+
+	// BaseURI is the base URI of the document, taken from the '$id' of the root schema. Relative references are
+	// resolved against it, it is empty if the root schema has no '$id'.
+	BaseURI string
+
+	// Index is the index of the document. Schemas in '$defs' are indexed as component schemas, in the same way
+	// 'components/schemas' are indexed for OpenAPI documents.
+	Index *index.SpecIndex
+
+	// Rolodex holds the index of the document, and the indexes of every document it references.
+	Rolodex *index.Rolodex
+
+	// SpecInfo is the parsed JSON Schema document.
+	SpecInfo *datamodel.SpecInfo
+}
+
+// NewJSONSchemaDocument will create a new JSONSchemaDocument from a JSON Schema document, in JSON or YAML. File
+// and remote references are not allowed, use NewJSONSchemaDocumentWithConfiguration to allow them.
+func NewJSONSchemaDocument(schema []byte) (*JSONSchemaDocument, error) {
+	return NewJSONSchemaDocumentWithConfiguration(schema, nil)
+}
+
+// NewJSONSchemaDocumentWithConfiguration is the same as NewJSONSchemaDocument, except the configuration controls
+// how references are looked up, in the same way it does for an OpenAPI Document.
+//
+// If the root schema has an absolute '$id', it is the base URI of the document. References to URIs under the base
+// URI are looked up in the BasePath of the configuration, so schemas published under their '$id' can be resolved
+// from local files. Without a BasePath or BaseURL, the base URI is used as the BaseURL if remote references are
+// allowed.
+func NewJSONSchemaDocumentWithConfiguration(schema []byte,
+	configuration *datamodel.DocumentConfiguration,
+) (*JSONSchemaDocument, error) {
+	return NewJSONSchemaDocumentWithContext(context.Background(), schema, configuration)
+}
+
+// NewJSONSchemaDocumentWithContext is the same as NewJSONSchemaDocumentWithConfiguration, except building stops
+// when the context is cancelled, returning the error of the context.
+func NewJSONSchemaDocumentWithContext(ctx context.Context, schema []byte,
+	configuration *datamodel.DocumentConfiguration,
+) (*JSONSchemaDocument, error) {
+	if configuration == nil {
+		configuration = &datamodel.DocumentConfiguration{}
+	}
+	info, err := datamodel.ExtractSpecInfoWithConfig(schema, &datamodel.DocumentConfiguration{
+		BypassDocumentCheck: true,
+		Limits:              configuration.Limits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if info.RootNode == nil || len(info.RootNode.Content) == 0 || !utils.IsNodeMap(info.RootNode.Content[0]) {
+		return nil, errors.New("unable to create JSON Schema document, the root of the document is not a schema")
+	}
+	root := info.RootNode.Content[0]
+
+	doc := &JSONSchemaDocument{SpecInfo: info}
+	if _, id := utils.FindKeyNodeTop(lowbase.IdLabel, root.Content); id != nil {
+		doc.BaseURI = id.Value
+	}
+
+	// without a BasePath or BaseURL, remote references are looked up from the base URI.
+	baseURI := schemaBaseURI(doc.BaseURI)
+	rolodexConfig := *configuration
+	if baseURI != nil && rolodexConfig.BaseURL == nil && rolodexConfig.BasePath == "" &&
+		rolodexConfig.AllowRemoteReferences {
+		rolodexConfig.BaseURL = baseURI
+	}
+	created := index.NewDocumentRolodex(info, &rolodexConfig)
+	rolodex := created.Rolodex
+	doc.Rolodex = rolodex
+
+	// references to the base URI are served from the local file system.
+	if baseURI != nil && created.LocalFS != nil {
+		created.IndexConfig.AllowRemoteLookup = true
+		rolodex.AddRemoteFS(baseURI.String(), &schemaIdFS{
+			base: baseURI.String(), dir: created.BaseDirectory, local: created.LocalFS,
+		})
+	}
+
+	_ = rolodex.IndexTheRolodexWithContext(ctx)
+	if !configuration.SkipCircularReferenceCheck {
+		rolodex.CheckForCircularReferencesWithContext(ctx)
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	doc.Index = rolodex.GetRootIndex()
+
+	lowSchema := new(lowbase.Schema)
+	_ = low.BuildModel(root, lowSchema)
+	errs := rolodex.GetCaughtErrors()
+	if err = lowSchema.Build(ctx, root, doc.Index); err != nil {
+		errs = append(errs, err)
+	}
+	doc.Schema = base.NewSchema(lowSchema)
+	return doc, errors.Join(errs...)
+}
+
+// FindDef will return the schema defined in the '$defs' of the root schema by name, or nil if it does not exist.
+func (d *JSONSchemaDocument) FindDef(name string) *base.Schema {
+	if d.Schema == nil || d.Schema.Defs == nil {
+		return nil
+	}
+	if p := d.Schema.Defs.GetOrZero(name); p != nil {
+		return p.Schema()
+	}
+	return nil
+}
+
+// Render will return a YAML representation of the root schema of the document.
+func (d *JSONSchemaDocument) Render() ([]byte, error) {
+	if d.Schema == nil {
+		return nil, fmt.Errorf("unable to render JSON Schema document, it has no schema")
+	}
+	return yaml.Marshal(d.Schema)
+}
+
+// schemaBaseURI returns the URI that references relative to a schema '$id' are resolved against, or nil if the
+// '$id' is not an absolute URI.
+func schemaBaseURI(id string) *url.URL {
+	u, err := url.Parse(id)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil
+	}
+	u.Fragment = ""
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path = path.Dir(u.Path) + "/"
+	}
+	return u
+}
+
+// schemaIdFS serves files referenced by a URI under the base URI of a JSON Schema document from a local
+// file system, the path of the URI relative to the base URI is the path of the file relative to the directory.
+type schemaIdFS struct {
+	base  string
+	dir   string
+	local fs.FS
+}
+
+func (s *schemaIdFS) Open(name string) (fs.File, error) {
+	name, _, _ = strings.Cut(name, "#")
+	if !strings.HasPrefix(name, s.base) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return s.local.Open(filepath.Join(s.dir, filepath.FromSlash(strings.TrimPrefix(name, s.base))))
+}
+
+// GetFiles returns no files, the files are indexed by the local file system they are served from.
+func (s *schemaIdFS) GetFiles() map[string]index.RolodexFile {
+	return map[string]index.RolodexFile{}
+}
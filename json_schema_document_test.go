@@ -0,0 +1,138 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package libopenapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var petSchema = `$schema: https://json-schema.org/draft/2020-12/schema
+$id: https://example.com/schemas/pet.json
+title: Pet
+type: object
+required:
+  - name
+properties:
+  name:
+    type: string
+  tags:
+    type: array
+    items:
+      $ref: '#/$defs/Tag'
+$defs:
+  Tag:
+    type: object
+    properties:
+      label:
+        type: string
+  Category:
+    type: string
+    enum: [cat, dog]
+`
+
+func TestNewJSONSchemaDocument(t *testing.T) {
+	doc, err := NewJSONSchemaDocument([]byte(petSchema))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/schemas/pet.json", doc.BaseURI)
+	assert.Equal(t, "https://example.com/schemas/pet.json", doc.Schema.Id)
+	assert.Equal(t, "Pet", doc.Schema.Title)
+	assert.Equal(t, 2, doc.Schema.Defs.Len())
+
+	// references into $defs are resolved.
+	tags := doc.Schema.Properties.GetOrZero("tags").Schema()
+	assert.True(t, tags.Items.A.IsReference())
+	assert.Equal(t, "#/$defs/Tag", tags.Items.A.GetReference())
+	assert.Equal(t, "string", tags.Items.A.Schema().Properties.GetOrZero("label").Schema().Type[0])
+
+	// $defs are looked up like component schemas.
+	assert.Equal(t, []string{"cat", "dog"}, []string{
+		doc.FindDef("Category").Enum[0].Value, doc.FindDef("Category").Enum[1].Value,
+	})
+	assert.Nil(t, doc.FindDef("Nope"))
+	assert.Equal(t, 2, doc.Index.GetComponentSchemaCount())
+	assert.Contains(t, doc.Index.GetAllComponentSchemas(), "#/$defs/Tag")
+	assert.NotNil(t, doc.Index.FindComponent("#/$defs/Category"))
+	assert.NotNil(t, doc.Rolodex)
+
+	out, err := doc.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "$defs:\n    Tag:\n")
+	assert.Contains(t, string(out), "$id: https://example.com/schemas/pet.json\n")
+}
+
+func TestNewJSONSchemaDocument_JSON(t *testing.T) {
+	doc, err := NewJSONSchemaDocument([]byte(`{"type": "object", "properties": {"id": {"$ref": "#/$defs/Id"}},
+"$defs": {"Id": {"type": "integer"}}}`))
+	require.NoError(t, err)
+	assert.Empty(t, doc.BaseURI)
+	assert.Equal(t, "integer", doc.Schema.Properties.GetOrZero("id").Schema().Type[0])
+}
+
+func TestNewJSONSchemaDocumentWithConfiguration_BaseURI(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	write("common/address.json", `{"$id": "https://example.com/schemas/common/address.json", "type": "object",
+"properties": {"street": {"type": "string"}}}`)
+	write("owner.yaml", `$defs:
+  Owner:
+    type: object
+    properties:
+      name:
+        type: string
+`)
+
+	config := datamodel.NewDocumentConfiguration()
+	config.BasePath = dir
+	doc, err := NewJSONSchemaDocumentWithConfiguration([]byte(`$id: https://example.com/schemas/pet.json
+type: object
+properties:
+  address:
+    $ref: https://example.com/schemas/common/address.json
+  owner:
+    $ref: owner.yaml#/$defs/Owner
+`), config)
+	require.NoError(t, err)
+
+	// the absolute reference is served from the base path, using the base URI of the document.
+	address := doc.Schema.Properties.GetOrZero("address").Schema()
+	require.NotNil(t, address)
+	assert.Equal(t, "string", address.Properties.GetOrZero("street").Schema().Type[0])
+
+	owner := doc.Schema.Properties.GetOrZero("owner").Schema()
+	require.NotNil(t, owner)
+	assert.Equal(t, "string", owner.Properties.GetOrZero("name").Schema().Type[0])
+	assert.Len(t, doc.Rolodex.GetIndexes(), 2)
+}
+
+func TestNewJSONSchemaDocument_Errors(t *testing.T) {
+	_, err := NewJSONSchemaDocument([]byte(""))
+	assert.Error(t, err)
+
+	_, err = NewJSONSchemaDocument([]byte("- not\n- a schema"))
+	assert.Equal(t, "unable to create JSON Schema document, the root of the document is not a schema", err.Error())
+
+	// references to other files are not allowed without a configuration.
+	doc, err := NewJSONSchemaDocument([]byte("properties:\n  a:\n    $ref: other.json"))
+	assert.Error(t, err)
+	assert.NotNil(t, doc)
+
+	_, err = (&JSONSchemaDocument{}).Render()
+	assert.Error(t, err)
+
+	// limits apply to the schema itself.
+	_, err = NewJSONSchemaDocumentWithConfiguration([]byte(petSchema), &datamodel.DocumentConfiguration{
+		Limits: &datamodel.Limits{MaxInputBytes: 20},
+	})
+	assert.ErrorIs(t, err, datamodel.ErrLimitExceeded)
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package jsonschema
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError describes a value in an instance that was rejected by a schema keyword.
+type ValidationError struct {
+	// Message explains why the value was rejected.
+	Message string
+
+	// Keyword is the schema keyword that rejected the value, for example 'required'.
+	Keyword string
+
+	// InstancePath is a JSON pointer to the rejected value in the instance, for example '/info/title'. When an
+	// instance reference has been resolved, the path continues through the reference.
+	InstancePath string
+
+	// SchemaPath is a JSON pointer to the keyword that rejected the value, following every '$ref' from the
+	// root of the schema, for example '/properties/info/$ref/required'.
+	SchemaPath string
+
+	// Node is the rejected value in the instance, or the key node when a property name was rejected.
+	Node *yaml.Node
+
+	// SchemaNode is the value of the keyword that rejected the instance value.
+	SchemaNode *yaml.Node
+
+	// SchemaLine and SchemaColumn of the keyword that rejected the instance value.
+	SchemaLine   int
+	SchemaColumn int
+
+	// SchemaLocation is the file (or URL) that contains the keyword, when it is known.
+	SchemaLocation string
+
+	// Line and Column of the rejected value in the instance.
+	Line   int
+	Column int
+
+	// Location is the file (or URL) that contains the rejected value. The evaluator does not know where an
+	// instance came from, so this is only set by callers that do, like libopenapi.Document.Validate.
+	Location string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.InstancePath
+	if path == "" {
+		path = "/"
+	}
+	if e.Location != "" {
+		path = e.Location + ": " + path
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s (line %d, column %d): %s", path, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package jsonschema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+// FormatChecker checks if a string value is valid for a format.
+type FormatChecker func(value string) bool
+
+var (
+	hostnameRegex = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*\.?$`)
+	uuidRegex     = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	pointerRegex  = regexp.MustCompile(`^(/([^~/]|~[01])*)*$`)
+	templateRegex = regexp.MustCompile(`\{[^{}]*}`)
+	portRegex     = regexp.MustCompile(`:\{[^{}]*}`)
+)
+
+// expandTemplate replaces every URI template expression (like '{scheme}') with a plain value, so URI templates
+// (like server URLs) can be parsed as URIs.
+func expandTemplate(v string) string {
+	return templateRegex.ReplaceAllString(portRegex.ReplaceAllString(v, ":0"), "x")
+}
+
+// DefaultFormats are the formats asserted by a schema when no formats are set in the Options. Only string values
+// are checked, formats for any other type always pass.
+var DefaultFormats = map[string]FormatChecker{
+	"date-time": func(v string) bool {
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(v))
+		return err == nil
+	},
+	"date": func(v string) bool {
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	},
+	"time": func(v string) bool {
+		_, err := time.Parse(time.RFC3339Nano, "2000-01-01T"+strings.ToUpper(v))
+		return err == nil
+	},
+	"email": func(v string) bool {
+		a, err := mail.ParseAddress(v)
+		return err == nil && a.Address == v
+	},
+	"hostname": func(v string) bool {
+		return len(v) <= 253 && hostnameRegex.MatchString(v)
+	},
+	"ipv4": func(v string) bool {
+		ip := net.ParseIP(v)
+		return ip != nil && strings.Contains(v, ".") && ip.To4() != nil
+	},
+	"ipv6": func(v string) bool {
+		return net.ParseIP(v) != nil && strings.Contains(v, ":")
+	},
+	"uri": func(v string) bool {
+		u, err := url.Parse(expandTemplate(v))
+		return err == nil && u.IsAbs()
+	},
+	"uri-reference": func(v string) bool {
+		_, err := url.Parse(expandTemplate(v))
+		return err == nil
+	},
+	"uuid": func(v string) bool {
+		return uuidRegex.MatchString(v)
+	},
+	"regex": func(v string) bool {
+		// patterns are ECMA 262 regular expressions, so only reject syntax that is broken in any dialect.
+		_, err := syntax.Parse(v, syntax.Perl)
+		if e, ok := err.(*syntax.Error); ok {
+			switch e.Code {
+			case syntax.ErrMissingParen, syntax.ErrUnexpectedParen, syntax.ErrMissingBracket,
+				syntax.ErrMissingRepeatArgument, syntax.ErrTrailingBackslash:
+				return false
+			}
+		}
+		return true
+	},
+	"json-pointer": func(v string) bool {
+		return pointerRegex.MatchString(v)
+	},
+}
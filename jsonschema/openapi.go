@@ -0,0 +1,111 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOptions are used to evaluate the schemas of OpenAPI documents, which are not standalone JSON Schema
+// resources. When set:
+//
+//   - a 'discriminator' picks the 'oneOf' or 'anyOf' branch an object is validated against, when the value of the
+//     discriminator property names one of the referenced branches.
+//   - patterns that Go cannot compile (like lookarounds) are ignored, instead of failing compilation.
+//   - references are resolved while validating, by ResolveReference. References that cannot be resolved are
+//     reported as errors of the values validated against them.
+type OpenAPIOptions struct {
+	// Nullable makes 'nullable: true' allow null values, as OpenAPI 3.0 (and Swagger) schemas do.
+	Nullable bool
+
+	// ResolveReference resolves the '$ref' of a schema. It is called with the schema that holds the reference, and
+	// the origin returned when that schema was itself reached through ResolveReference (nil for the compiled
+	// schema). It returns the referenced schema and its origin, or nil if the reference cannot be resolved. When
+	// nil (or when it returns nil for a reference inside the compiled schema), references are resolved by the
+	// compiled schema.
+	ResolveReference func(schema *yaml.Node, origin any) (*yaml.Node, any)
+}
+
+// reference resolves the '$ref' of a schema, returning the target and the origin to resolve its references with.
+func (e *evaluator) reference(sc scope, sch *yaml.Node, ref string) (*yaml.Node, any, error) {
+	if o := e.schema.openapi; o != nil && o.ResolveReference != nil {
+		if target, origin := o.ResolveReference(sch, e.origin); target != nil {
+			return unwrap(target), origin, nil
+		}
+		if e.origin != nil {
+			return nil, nil, fmt.Errorf("reference '%s' cannot be resolved", ref)
+		}
+	}
+	target, err := e.schema.resolve(sc.base, ref)
+	return target, e.origin, err
+}
+
+// nullable reports if a schema allows a null value using 'nullable', in which case no other keyword applies.
+func (e *evaluator) nullable(sch, inst *yaml.Node) bool {
+	if e.schema.openapi == nil || !e.schema.openapi.Nullable || typeOf(inst) != "null" {
+		return false
+	}
+	b, ok := boolean(lookup(sch, "nullable"))
+	return ok && b
+}
+
+// discriminate returns the branch named by the discriminator of a schema, or -1 if no branch is named.
+func (e *evaluator) discriminate(sch, branches, inst *yaml.Node) int {
+	if e.schema.openapi == nil || inst.Kind != yaml.MappingNode {
+		return -1
+	}
+	d := lookup(sch, "discriminator")
+	name, ok := stringValue(d, "propertyName")
+	if !ok || name == "" {
+		return -1
+	}
+	value := lookup(inst, name)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return -1
+	}
+	target := value.Value
+	if mapped, ok := stringValue(lookup(d, "mapping"), value.Value); ok {
+		target = mapped
+	}
+	for i, branch := range branches.Content {
+		if ref, ok := stringValue(unwrap(branch), "$ref"); ok && (ref == target || strings.HasSuffix(ref, "/"+target)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hidden returns the keyword ('readOnly' or 'writeOnly') that stops a property from being sent in the direction
+// being validated, and the node of the keyword. A property schema that is a reference is resolved first.
+func (e *evaluator) hidden(sc scope, sch *yaml.Node) (string, *yaml.Node) {
+	if !e.request && !e.response {
+		return "", nil
+	}
+	sch = unwrap(sch)
+	if ref, ok := stringValue(sch, "$ref"); ok {
+		if target, _, err := e.reference(sc, sch, ref); err == nil {
+			sch = target
+		}
+	}
+	for _, keyword := range []string{"readOnly", "writeOnly"} {
+		if keyword == "readOnly" && !e.request || keyword == "writeOnly" && !e.response {
+			continue
+		}
+		if b, ok := boolean(lookup(sch, keyword)); ok && b {
+			return keyword, lookup(sch, keyword)
+		}
+	}
+	return "", nil
+}
+
+// direction describes the direction being validated, for error messages.
+func (e *evaluator) direction() string {
+	if e.request {
+		return "request"
+	}
+	return "response"
+}
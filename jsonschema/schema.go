@@ -0,0 +1,346 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package jsonschema is a JSON Schema evaluator that works directly with *yaml.Node trees. Both the schema and the
+// instance being validated are nodes, so every ValidationError is able to point at the exact line and column of the
+// value that failed, as well as the schema keyword that rejected it.
+//
+// Draft-04 (used by the Swagger and OpenAPI 3.0 schemas) and 2020-12 (used by OpenAPI 3.1) are supported. The draft
+// is determined by the '$schema' keyword of the root schema, or by the Draft set in Options.
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Draft represents the version of JSON Schema used to interpret a schema.
+type Draft int
+
+const (
+	// Draft2020 is JSON Schema 2020-12, the default.
+	Draft2020 Draft = iota
+
+	// Draft4 is JSON Schema draft-04.
+	Draft4
+)
+
+// Options are used to configure how a schema is compiled.
+type Options struct {
+	// Draft is used when the root schema does not declare a '$schema'.
+	Draft Draft
+
+	// BaseURI is the URI of the root schema, used to resolve relative references. If the root schema declares an
+	// id, that takes precedence.
+	BaseURI string
+
+	// Resources are other schema documents that can be referenced by the schema, keyed by their URI. Any ids
+	// declared inside these documents can also be referenced.
+	Resources map[string]*yaml.Node
+
+	// Formats are the format checkers used to assert the 'format' keyword, if nil then DefaultFormats is used.
+	// Formats that are not known are ignored.
+	Formats map[string]FormatChecker
+
+	// OpenAPI is set to evaluate a schema of an OpenAPI document, see OpenAPIOptions.
+	OpenAPI *OpenAPIOptions
+}
+
+// Schema is a compiled schema, ready to validate instances. A Schema is safe for concurrent use.
+type Schema struct {
+	root      *yaml.Node
+	base      string
+	draft     Draft
+	formats   map[string]FormatChecker
+	openapi   *OpenAPIOptions
+	resources map[string]*yaml.Node // resource URI (without a fragment) -> schema node.
+	anchors   map[string]*yaml.Node // resource URI + '#' + anchor -> schema node.
+	dynamic   map[string]*yaml.Node // resource URI + '#' + dynamic anchor -> schema node.
+	scopes    map[*yaml.Node]scope  // schema node -> the resource it lives in.
+	ids       map[*yaml.Node]string // schema nodes that declare an id -> resource URI.
+	regexps   sync.Map              // pattern -> *regexp.Regexp
+}
+
+// scope is the base URI and draft of a resource.
+type scope struct {
+	base  string
+	draft Draft
+}
+
+// keys that contain values (and not schemas), these are never walked when a schema is compiled.
+var valueKeywords = map[string]bool{"enum": true, "const": true, "default": true, "examples": true}
+
+// CompileBytes will parse a JSON or YAML schema and compile it.
+func CompileBytes(schema []byte, opts *Options) (*Schema, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(schema, &node); err != nil {
+		return nil, fmt.Errorf("unable to parse schema: %w", err)
+	}
+	return Compile(&node, opts)
+}
+
+// Compile will compile a schema node (and any resources in the options). Every id and anchor is indexed, every
+// pattern is compiled, and every reference is checked. If anything cannot be compiled, an error is returned.
+func Compile(node *yaml.Node, opts *Options) (*Schema, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	node = unwrap(node)
+	if node == nil || node.Kind == 0 {
+		return nil, errors.New("unable to compile schema: schema is empty")
+	}
+	s := &Schema{
+		root:      node,
+		draft:     opts.Draft,
+		formats:   opts.Formats,
+		openapi:   opts.OpenAPI,
+		resources: make(map[string]*yaml.Node),
+		anchors:   make(map[string]*yaml.Node),
+		dynamic:   make(map[string]*yaml.Node),
+		scopes:    make(map[*yaml.Node]scope),
+		ids:       make(map[*yaml.Node]string),
+	}
+	if s.formats == nil {
+		s.formats = DefaultFormats
+	}
+	if d, ok := draftOf(node); ok {
+		s.draft = d
+	}
+	s.base = normalize(opts.BaseURI)
+
+	var errs []error
+	s.index(node, s.base, s.draft, &errs)
+	for uri, r := range opts.Resources {
+		r = unwrap(r)
+		if r == nil {
+			continue
+		}
+		d := s.draft
+		if rd, ok := draftOf(r); ok {
+			d = rd
+		}
+		s.index(r, normalize(uri), d, &errs)
+	}
+	if len(errs) == 0 && (s.openapi == nil || s.openapi.ResolveReference == nil) {
+		s.checkRefs(&errs)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("unable to compile schema: %w", errors.Join(errs...))
+	}
+	return s, nil
+}
+
+// Root returns the root node of the schema.
+func (s *Schema) Root() *yaml.Node {
+	return s.root
+}
+
+// Draft returns the draft of the root schema.
+func (s *Schema) Draft() Draft {
+	return s.draft
+}
+
+// index walks a resource, recording the base URI of every schema node, and every id and anchor.
+func (s *Schema) index(node *yaml.Node, base string, draft Draft, errs *[]error) {
+	if _, ok := s.resources[base]; !ok {
+		s.resources[base] = node
+	}
+	s.ids[node] = base
+	s.walk(node, base, draft, errs)
+}
+
+func (s *Schema) walk(node *yaml.Node, base string, draft Draft, errs *[]error) {
+	node = unwrap(node)
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			s.walk(n, base, draft, errs)
+		}
+		return
+	case yaml.MappingNode:
+	default:
+		return
+	}
+	if _, seen := s.scopes[node]; seen {
+		return
+	}
+	if d, ok := draftOf(node); ok {
+		draft = d
+	}
+	idKey := "$id"
+	if draft == Draft4 {
+		idKey = "id"
+	}
+	if id, ok := stringValue(node, idKey); ok && id != "" {
+		uri := resolveURI(base, id)
+		doc, frag := splitFragment(uri)
+		if frag != "" && draft == Draft4 {
+			s.anchors[doc+"#"+frag] = node
+		} else {
+			base = doc
+			if _, exists := s.resources[base]; !exists {
+				s.resources[base] = node
+			}
+			s.ids[node] = base
+		}
+	}
+	s.scopes[node] = scope{base: base, draft: draft}
+	if a, ok := stringValue(node, "$anchor"); ok {
+		s.anchors[base+"#"+a] = node
+	}
+	if a, ok := stringValue(node, "$dynamicAnchor"); ok {
+		s.anchors[base+"#"+a] = node
+		s.dynamic[base+"#"+a] = node
+	}
+	if p, ok := stringValue(node, "pattern"); ok {
+		if _, err := s.regexp(p); err != nil && s.openapi == nil {
+			*errs = append(*errs, err)
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, unwrap(node.Content[i+1])
+		if valueKeywords[key] {
+			continue
+		}
+		if key == "patternProperties" && value != nil && value.Kind == yaml.MappingNode {
+			for j := 0; j < len(value.Content); j += 2 {
+				if _, err := s.regexp(value.Content[j].Value); err != nil && s.openapi == nil {
+					*errs = append(*errs, err)
+				}
+			}
+		}
+		s.walk(value, base, draft, errs)
+	}
+}
+
+// checkRefs makes sure every reference in every resource can be resolved.
+func (s *Schema) checkRefs(errs *[]error) {
+	for node, sc := range s.scopes {
+		for _, key := range []string{"$ref", "$dynamicRef"} {
+			if ref, ok := stringValue(node, key); ok {
+				if _, err := s.resolve(sc.base, ref); err != nil {
+					*errs = append(*errs, err)
+				}
+			}
+		}
+	}
+}
+
+// resolve locates the schema node a reference points to.
+func (s *Schema) resolve(base, ref string) (*yaml.Node, error) {
+	doc, frag := splitFragment(resolveURI(base, ref))
+	root := s.resources[doc]
+	if root == nil {
+		return nil, fmt.Errorf("reference '%s' cannot be resolved, resource '%s' is unknown", ref, doc)
+	}
+	if frag == "" {
+		return root, nil
+	}
+	if !strings.HasPrefix(frag, "/") {
+		if n := s.anchors[doc+"#"+frag]; n != nil {
+			return n, nil
+		}
+		return nil, fmt.Errorf("reference '%s' cannot be resolved, anchor '%s' is unknown", ref, frag)
+	}
+	n := root
+	for _, seg := range strings.Split(frag[1:], "/") {
+		seg = utils.UnescapePointerSegment(seg)
+		n = unwrap(n)
+		switch n.Kind {
+		case yaml.MappingNode:
+			var found *yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == seg {
+					found = n.Content[i+1]
+					break
+				}
+			}
+			n = found
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(n.Content) {
+				n = nil
+			} else {
+				n = n.Content[idx]
+			}
+		default:
+			n = nil
+		}
+		if n == nil {
+			return nil, fmt.Errorf("reference '%s' cannot be resolved, '%s' cannot be found", ref, frag)
+		}
+	}
+	return unwrap(n), nil
+}
+
+// regexp compiles (and caches) a pattern.
+func (s *Schema) regexp(pattern string) (*regexp.Regexp, error) {
+	if r, ok := s.regexps.Load(pattern); ok {
+		return r.(*regexp.Regexp), nil
+	}
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern '%s' is not supported: %w", pattern, err)
+	}
+	s.regexps.Store(pattern, r)
+	return r, nil
+}
+
+// draftOf reads the draft of a schema from the '$schema' keyword.
+func draftOf(node *yaml.Node) (Draft, bool) {
+	v, ok := stringValue(node, "$schema")
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case strings.Contains(v, "draft-04"), strings.Contains(v, "draft-03"):
+		return Draft4, true
+	default:
+		return Draft2020, true
+	}
+}
+
+func resolveURI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+func normalize(uri string) string {
+	doc, frag := splitFragment(uri)
+	if frag != "" {
+		return doc + "#" + frag
+	}
+	return doc
+}
+
+func splitFragment(uri string) (string, string) {
+	if i := strings.Index(uri, "#"); i >= 0 {
+		frag, err := url.PathUnescape(uri[i+1:])
+		if err != nil {
+			frag = uri[i+1:]
+		}
+		return uri[:i], frag
+	}
+	return uri, ""
+}
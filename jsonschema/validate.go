@@ -0,0 +1,696 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// maxDepth limits how deep the evaluator will go, this catches references that loop without ever moving
+// through the instance.
+const maxDepth = 1024
+
+// ValidateOptions are used to configure how an instance is validated.
+type ValidateOptions struct {
+	// ResolveInstance is called for every object in the instance that contains a '$ref' string. If it returns a
+	// node (other than the one it was given), that node is validated in place of the reference. This allows
+	// instances that are spread across multiple files to be validated as one. A node that is already being
+	// validated is never substituted again, so circular references are safe.
+	ResolveInstance func(node *yaml.Node) *yaml.Node
+
+	// Request means the instance is sent in a request, so properties marked 'readOnly' are not required, and are
+	// not allowed.
+	Request bool
+
+	// Response means the instance is sent in a response, so properties marked 'writeOnly' are not required, and
+	// are not allowed.
+	Response bool
+}
+
+// Validate will validate an instance against the schema, and return every error found. No errors means the
+// instance is valid.
+func (s *Schema) Validate(instance *yaml.Node) []*ValidationError {
+	return s.ValidateWithOptions(instance, nil)
+}
+
+// ValidateWithOptions will validate an instance against the schema, using the supplied options.
+func (s *Schema) ValidateWithOptions(instance *yaml.Node, opts *ValidateOptions) []*ValidationError {
+	e := &evaluator{schema: s, active: make(map[*yaml.Node]int), following: make(map[visit]bool)}
+	if opts != nil {
+		e.resolveInstance = opts.ResolveInstance
+		e.request, e.response = opts.Request, opts.Response
+	}
+	inst := unwrap(instance)
+	if inst == nil {
+		inst = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+	return e.eval(s.root, "", inst, "").errs
+}
+
+type evaluator struct {
+	schema          *Schema
+	resolveInstance func(node *yaml.Node) *yaml.Node
+	active          map[*yaml.Node]int // instance nodes that have been substituted, and are being validated.
+	following       map[visit]bool     // references being followed, by instance.
+	dynamic         []string           // the dynamic scope, every resource entered, outermost first.
+	origin          any                // where the schema being evaluated was resolved from, see OpenAPIOptions.
+	request         bool               // true when 'readOnly' properties are not allowed.
+	response        bool               // true when 'writeOnly' properties are not allowed.
+	depth           int
+}
+
+// visit is a reference being followed for an instance. Following the same reference for the same instance again
+// means the reference loops, and there is nothing new to learn.
+type visit struct {
+	ref  *yaml.Node
+	inst *yaml.Node
+}
+
+// result is the outcome of evaluating an instance against a schema.
+type result struct {
+	errs     []*ValidationError
+	props    map[string]bool // properties evaluated by the schema, used by 'unevaluatedProperties'.
+	items    map[int]bool    // items evaluated by the schema, used by 'unevaluatedItems'.
+	allItems bool
+	known    int // properties named by 'properties' or 'patternProperties', used to pick the best failed branch.
+}
+
+func (r *result) valid() bool {
+	return len(r.errs) == 0
+}
+
+func (r *result) fail(err *ValidationError) {
+	r.errs = append(r.errs, err)
+}
+
+func (r *result) prop(name string) {
+	if r.props == nil {
+		r.props = make(map[string]bool)
+	}
+	r.props[name] = true
+}
+
+func (r *result) item(i int) {
+	if r.items == nil {
+		r.items = make(map[int]bool)
+	}
+	r.items[i] = true
+}
+
+// annotate takes every evaluated property and item from another result.
+func (r *result) annotate(o *result) {
+	for p := range o.props {
+		r.prop(p)
+	}
+	for i := range o.items {
+		r.item(i)
+	}
+	r.allItems = r.allItems || o.allItems
+	r.known += o.known
+}
+
+// add takes the errors and annotations of a subschema result.
+func (r *result) add(o *result) {
+	r.errs = append(r.errs, o.errs...)
+	r.annotate(o)
+}
+
+func (e *evaluator) newError(keyword, spath string, sch, inst *yaml.Node, ipath, format string,
+	args ...any,
+) *ValidationError {
+	err := &ValidationError{
+		Message:      fmt.Sprintf(format, args...),
+		Keyword:      keyword,
+		InstancePath: ipath,
+		SchemaPath:   spath + "/" + keyword,
+		Node:         inst,
+		SchemaNode:   sch,
+		Line:         inst.Line,
+		Column:       inst.Column,
+	}
+	if sch != nil {
+		err.SchemaLine, err.SchemaColumn = sch.Line, sch.Column
+	}
+	return err
+}
+
+// substitute swaps a reference in the instance for the node it references, if there is a resolver.
+func (e *evaluator) substitute(inst *yaml.Node) (*yaml.Node, func()) {
+	if e.resolveInstance == nil || inst.Kind != yaml.MappingNode {
+		return inst, nil
+	}
+	if _, ok := stringValue(inst, "$ref"); !ok {
+		return inst, nil
+	}
+	target := unwrap(e.resolveInstance(inst))
+	if target == nil || target == inst || e.active[target] > 0 {
+		return inst, nil
+	}
+	e.active[target]++
+	return target, func() { e.active[target]-- }
+}
+
+func (e *evaluator) eval(sch *yaml.Node, spath string, inst *yaml.Node, ipath string) *result {
+	r := &result{}
+	sch = unwrap(sch)
+	if sch == nil {
+		return r
+	}
+	if b, ok := boolean(sch); ok {
+		if !b {
+			r.fail(e.newError("false", spath, sch, inst, ipath, "no value is allowed"))
+		}
+		return r
+	}
+	if sch.Kind != yaml.MappingNode || e.nullable(sch, inst) {
+		return r
+	}
+	inst, release := e.substitute(inst)
+	if release != nil {
+		defer release()
+	}
+	if e.depth >= maxDepth {
+		r.fail(e.newError("$ref", spath, sch, inst, ipath, "schema is nested too deeply, references may be circular"))
+		return r
+	}
+	e.depth++
+	defer func() { e.depth-- }()
+	if base, ok := e.schema.ids[sch]; ok {
+		e.dynamic = append(e.dynamic, base)
+		defer func() { e.dynamic = e.dynamic[:len(e.dynamic)-1] }()
+	}
+	sc, ok := e.schema.scopes[sch]
+	if !ok {
+		// schemas resolved from outside of the compiled schema (see OpenAPIOptions) use the draft of the root.
+		sc.draft = e.schema.draft
+	}
+
+	if ref, ok := stringValue(sch, "$ref"); ok {
+		if e.following[visit{sch, inst}] {
+			// the reference loops back to itself without moving through the instance.
+		} else if target, origin, err := e.reference(sc, sch, ref); err != nil {
+			r.fail(e.newError("$ref", spath, sch, inst, ipath, "%s", err.Error()))
+		} else {
+			outer := e.origin
+			e.origin = origin
+			e.following[visit{sch, inst}] = true
+			r.add(e.eval(target, spath+"/$ref", inst, ipath))
+			delete(e.following, visit{sch, inst})
+			e.origin = outer
+		}
+		if sc.draft == Draft4 {
+			// in draft-04, every other keyword next to a '$ref' is ignored.
+			return r
+		}
+	}
+	if ref, ok := stringValue(sch, "$dynamicRef"); ok {
+		if target, err := e.dynamicTarget(sc.base, ref); err != nil {
+			r.fail(e.newError("$dynamicRef", spath, sch, inst, ipath, "%s", err.Error()))
+		} else {
+			r.add(e.eval(target, spath+"/$dynamicRef", inst, ipath))
+		}
+	}
+
+	e.evalGeneric(r, sch, spath, inst, ipath)
+	e.evalApplicators(r, sch, sc, spath, inst, ipath)
+	switch inst.Kind {
+	case yaml.MappingNode:
+		e.evalObject(r, sch, sc, spath, inst, ipath)
+	case yaml.SequenceNode:
+		e.evalArray(r, sch, sc, spath, inst, ipath)
+	default:
+		e.evalScalar(r, sch, spath, inst, ipath)
+	}
+	return r
+}
+
+// dynamicTarget resolves a '$dynamicRef'. If the reference points at a '$dynamicAnchor', the outermost resource in
+// the dynamic scope that declares the same dynamic anchor is used instead.
+func (e *evaluator) dynamicTarget(base, ref string) (*yaml.Node, error) {
+	target, err := e.schema.resolve(base, ref)
+	if err != nil {
+		return nil, err
+	}
+	_, anchor := splitFragment(resolveURI(base, ref))
+	if a, ok := stringValue(target, "$dynamicAnchor"); !ok || a != anchor {
+		return target, nil
+	}
+	for _, scopeBase := range e.dynamic {
+		if n := e.schema.dynamic[scopeBase+"#"+anchor]; n != nil {
+			return n, nil
+		}
+	}
+	return target, nil
+}
+
+// evalGeneric evaluates the keywords that apply to every type of value.
+func (e *evaluator) evalGeneric(r *result, sch *yaml.Node, spath string, inst *yaml.Node, ipath string) {
+	if t := lookup(sch, "type"); t != nil {
+		var types []string
+		if t.Kind == yaml.SequenceNode {
+			for _, n := range t.Content {
+				types = append(types, n.Value)
+			}
+		} else {
+			types = []string{t.Value}
+		}
+		matched := false
+		for _, ty := range types {
+			if isType(inst, ty) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			r.fail(e.newError("type", spath, t, inst, ipath, "expected %s, but got %s",
+				strings.Join(types, " or "), typeOf(inst)))
+		}
+	}
+	if en := lookup(sch, "enum"); en != nil && en.Kind == yaml.SequenceNode {
+		matched := false
+		for _, n := range en.Content {
+			if equal(n, inst) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			var values []string
+			for _, n := range en.Content {
+				values = append(values, display(n))
+			}
+			r.fail(e.newError("enum", spath, en, inst, ipath, "value must be one of %s", strings.Join(values, ", ")))
+		}
+	}
+	if c := lookup(sch, "const"); c != nil && !equal(c, inst) {
+		r.fail(e.newError("const", spath, c, inst, ipath, "value must be %s", display(c)))
+	}
+}
+
+// evalApplicators evaluates the keywords that combine subschemas.
+func (e *evaluator) evalApplicators(r *result, sch *yaml.Node, sc scope, spath string, inst *yaml.Node, ipath string) {
+	if all := lookup(sch, "allOf"); all != nil && all.Kind == yaml.SequenceNode {
+		for i, sub := range all.Content {
+			r.add(e.eval(sub, fmt.Sprintf("%s/allOf/%d", spath, i), inst, ipath))
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches := lookup(sch, keyword)
+		if branches == nil || branches.Kind != yaml.SequenceNode || len(branches.Content) == 0 {
+			continue
+		}
+		if i := e.discriminate(sch, branches, inst); i >= 0 {
+			r.add(e.eval(branches.Content[i], fmt.Sprintf("%s/%s/%d", spath, keyword, i), inst, ipath))
+			continue
+		}
+		var results []*result
+		var matched []*result
+		for i, sub := range branches.Content {
+			sr := e.eval(sub, fmt.Sprintf("%s/%s/%d", spath, keyword, i), inst, ipath)
+			results = append(results, sr)
+			if sr.valid() {
+				matched = append(matched, sr)
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			r.add(best(results))
+		case keyword == "oneOf" && len(matched) > 1:
+			r.fail(e.newError(keyword, spath, branches, inst, ipath,
+				"value must match exactly one schema, but matches %d", len(matched)))
+		default:
+			for _, m := range matched {
+				r.annotate(m)
+			}
+		}
+	}
+	if not := lookup(sch, "not"); not != nil {
+		if e.eval(not, spath+"/not", inst, ipath).valid() {
+			r.fail(e.newError("not", spath, not, inst, ipath, "value must not match the schema"))
+		}
+	}
+	if cond := lookup(sch, "if"); cond != nil && sc.draft != Draft4 {
+		ir := e.eval(cond, spath+"/if", inst, ipath)
+		if ir.valid() {
+			r.annotate(ir)
+			if then := lookup(sch, "then"); then != nil {
+				r.add(e.eval(then, spath+"/then", inst, ipath))
+			}
+		} else if els := lookup(sch, "else"); els != nil {
+			r.add(e.eval(els, spath+"/else", inst, ipath))
+		}
+	}
+}
+
+// best picks the failed branch most likely intended by the author of the instance. The branch that failed
+// deepest in the instance wins, then the branch that knew about the most properties, then the branch with the
+// fewest errors.
+func best(results []*result) *result {
+	var b *result
+	bDepth := -1
+	for _, r := range results {
+		depth := 0
+		for _, err := range r.errs {
+			d := strings.Count(err.InstancePath, "/")
+			if err.Keyword == "additionalProperties" || err.Keyword == "unevaluatedProperties" {
+				d-- // the property is rejected by the object that contains it.
+			}
+			if d > depth {
+				depth = d
+			}
+		}
+		switch {
+		case b == nil, depth > bDepth,
+			depth == bDepth && r.known > b.known,
+			depth == bDepth && r.known == b.known && len(r.errs) < len(b.errs):
+			b, bDepth = r, depth
+		}
+	}
+	return b
+}
+
+func (e *evaluator) evalObject(r *result, sch *yaml.Node, sc scope, spath string, inst *yaml.Node, ipath string) {
+	props := lookup(sch, "properties")
+	patterns := lookup(sch, "patternProperties")
+	additional := lookup(sch, "additionalProperties")
+	for i := 0; i+1 < len(inst.Content); i += 2 {
+		key, val := inst.Content[i], unwrap(inst.Content[i+1])
+		name := key.Value
+		path := ipath + "/" + utils.EscapePointerSegment(name)
+		known := false
+		if sub := lookup(props, name); sub != nil {
+			known = true
+			r.known++
+			r.prop(name)
+			ppath := spath + "/properties/" + utils.EscapePointerSegment(name)
+			if keyword, node := e.hidden(sc, sub); keyword != "" {
+				r.fail(e.newError(keyword, ppath, node, key, path, "property '%s' is %s, it cannot be sent in a %s",
+					name, keyword, e.direction()))
+			}
+			r.errs = append(r.errs, e.eval(sub, ppath, val, path).errs...)
+		}
+		if patterns != nil && patterns.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(patterns.Content); j += 2 {
+				re, err := e.schema.regexp(patterns.Content[j].Value)
+				if err != nil || !re.MatchString(name) {
+					continue
+				}
+				known = true
+				r.known++
+				r.prop(name)
+				r.errs = append(r.errs, e.eval(patterns.Content[j+1],
+					spath+"/patternProperties/"+utils.EscapePointerSegment(patterns.Content[j].Value), val, path).errs...)
+			}
+		}
+		if !known && additional != nil {
+			r.prop(name)
+			if b, ok := boolean(additional); ok && !b {
+				r.fail(e.newError("additionalProperties", spath, additional, key, path,
+					"property '%s' is not allowed", name))
+			} else {
+				r.errs = append(r.errs, e.eval(additional, spath+"/additionalProperties", val, path).errs...)
+			}
+		}
+	}
+
+	if req := lookup(sch, "required"); req != nil && req.Kind == yaml.SequenceNode {
+		var missing []string
+		for _, n := range req.Content {
+			if keyword, _ := e.hidden(sc, lookup(props, n.Value)); keyword != "" {
+				continue
+			}
+			if lookup(inst, n.Value) == nil {
+				missing = append(missing, "'"+n.Value+"'")
+			}
+		}
+		if len(missing) == 1 {
+			r.fail(e.newError("required", spath, req, inst, ipath, "missing property %s", missing[0]))
+		} else if len(missing) > 1 {
+			r.fail(e.newError("required", spath, req, inst, ipath, "missing properties %s", strings.Join(missing, ", ")))
+		}
+	}
+	count := len(inst.Content) / 2
+	if n, ok := number(lookup(sch, "maxProperties")); ok && float64(count) > n {
+		r.fail(e.newError("maxProperties", spath, lookup(sch, "maxProperties"), inst, ipath,
+			"object must have at most %v properties, but has %d", n, count))
+	}
+	if n, ok := number(lookup(sch, "minProperties")); ok && float64(count) < n {
+		r.fail(e.newError("minProperties", spath, lookup(sch, "minProperties"), inst, ipath,
+			"object must have at least %v properties, but has %d", n, count))
+	}
+
+	// draft-04 'dependencies' is both 'dependentRequired' and 'dependentSchemas'.
+	for _, keyword := range []string{"dependencies", "dependentRequired", "dependentSchemas"} {
+		deps := lookup(sch, keyword)
+		if deps == nil || deps.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(deps.Content); i += 2 {
+			name, dep := deps.Content[i].Value, unwrap(deps.Content[i+1])
+			if lookup(inst, name) == nil {
+				continue
+			}
+			if dep.Kind == yaml.SequenceNode {
+				for _, n := range dep.Content {
+					if lookup(inst, n.Value) == nil {
+						r.fail(e.newError(keyword, spath, dep, inst, ipath,
+							"property '%s' is required when '%s' is present", n.Value, name))
+					}
+				}
+			} else {
+				r.add(e.eval(dep, spath+"/"+keyword+"/"+utils.EscapePointerSegment(name), inst, ipath))
+			}
+		}
+	}
+
+	if names := lookup(sch, "propertyNames"); names != nil {
+		for i := 0; i+1 < len(inst.Content); i += 2 {
+			key := inst.Content[i]
+			name := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.Value, Line: key.Line, Column: key.Column}
+			for _, err := range e.eval(names, spath+"/propertyNames", name, ipath+"/"+utils.EscapePointerSegment(key.Value)).errs {
+				err.Node = key
+				r.fail(err)
+			}
+		}
+	}
+
+	if unevaluated := lookup(sch, "unevaluatedProperties"); unevaluated != nil && sc.draft != Draft4 {
+		for i := 0; i+1 < len(inst.Content); i += 2 {
+			key, val := inst.Content[i], unwrap(inst.Content[i+1])
+			if r.props[key.Value] {
+				continue
+			}
+			path := ipath + "/" + utils.EscapePointerSegment(key.Value)
+			if b, ok := boolean(unevaluated); ok && !b {
+				r.fail(e.newError("unevaluatedProperties", spath, unevaluated, key, path,
+					"property '%s' is not allowed", key.Value))
+			} else {
+				r.errs = append(r.errs, e.eval(unevaluated, spath+"/unevaluatedProperties", val, path).errs...)
+			}
+			r.prop(key.Value)
+		}
+	}
+}
+
+func (e *evaluator) evalArray(r *result, sch *yaml.Node, sc scope, spath string, inst *yaml.Node, ipath string) {
+	items := lookup(sch, "items")
+	prefix := lookup(sch, "prefixItems")
+	if items != nil && items.Kind == yaml.SequenceNode {
+		// draft-04 (and 2019-09) tuples, where 'additionalItems' covers everything after the tuple.
+		prefix = items
+		items = lookup(sch, "additionalItems")
+	}
+	start := 0
+	if prefix != nil && prefix.Kind == yaml.SequenceNode {
+		keyword := "prefixItems"
+		if lookup(sch, "prefixItems") == nil {
+			keyword = "items"
+		}
+		for i := 0; i < len(prefix.Content) && i < len(inst.Content); i++ {
+			r.item(i)
+			r.errs = append(r.errs, e.eval(prefix.Content[i], fmt.Sprintf("%s/%s/%d", spath, keyword, i),
+				unwrap(inst.Content[i]), ipath+"/"+strconv.Itoa(i)).errs...)
+		}
+		start = len(prefix.Content)
+	}
+	if items != nil {
+		keyword := "items"
+		if lookup(sch, "additionalItems") == items {
+			keyword = "additionalItems"
+		}
+		for i := start; i < len(inst.Content); i++ {
+			path := ipath + "/" + strconv.Itoa(i)
+			if b, ok := boolean(items); ok && !b {
+				r.fail(e.newError(keyword, spath, items, unwrap(inst.Content[i]), path,
+					"array must have at most %d items", start))
+				break
+			}
+			r.errs = append(r.errs, e.eval(items, spath+"/"+keyword, unwrap(inst.Content[i]), path).errs...)
+		}
+		r.allItems = true
+	}
+
+	if contains := lookup(sch, "contains"); contains != nil && sc.draft != Draft4 {
+		matches := 0
+		for i, n := range inst.Content {
+			if e.eval(contains, spath+"/contains", unwrap(n), ipath+"/"+strconv.Itoa(i)).valid() {
+				matches++
+				r.item(i)
+			}
+		}
+		minimum := 1.0
+		if n, ok := number(lookup(sch, "minContains")); ok {
+			minimum = n
+		}
+		if float64(matches) < minimum {
+			r.fail(e.newError("contains", spath, contains, inst, ipath,
+				"array must contain at least %v matching items, but contains %d", minimum, matches))
+		}
+		if n, ok := number(lookup(sch, "maxContains")); ok && float64(matches) > n {
+			r.fail(e.newError("maxContains", spath, lookup(sch, "maxContains"), inst, ipath,
+				"array must contain at most %v matching items, but contains %d", n, matches))
+		}
+	}
+
+	count := len(inst.Content)
+	if n, ok := number(lookup(sch, "maxItems")); ok && float64(count) > n {
+		r.fail(e.newError("maxItems", spath, lookup(sch, "maxItems"), inst, ipath,
+			"array must have at most %v items, but has %d", n, count))
+	}
+	if n, ok := number(lookup(sch, "minItems")); ok && float64(count) < n {
+		r.fail(e.newError("minItems", spath, lookup(sch, "minItems"), inst, ipath,
+			"array must have at least %v items, but has %d", n, count))
+	}
+	if unique, ok := boolean(lookup(sch, "uniqueItems")); ok && unique {
+	outer:
+		for i := 1; i < count; i++ {
+			for j := 0; j < i; j++ {
+				if equal(inst.Content[i], inst.Content[j]) {
+					r.fail(e.newError("uniqueItems", spath, lookup(sch, "uniqueItems"), unwrap(inst.Content[i]),
+						ipath+"/"+strconv.Itoa(i), "items %d and %d are equal, but items must be unique", j, i))
+					break outer
+				}
+			}
+		}
+	}
+
+	if unevaluated := lookup(sch, "unevaluatedItems"); unevaluated != nil && sc.draft != Draft4 && !r.allItems {
+		for i, n := range inst.Content {
+			if r.items[i] {
+				continue
+			}
+			path := ipath + "/" + strconv.Itoa(i)
+			if b, ok := boolean(unevaluated); ok && !b {
+				r.fail(e.newError("unevaluatedItems", spath, unevaluated, unwrap(n), path, "item %d is not allowed", i))
+			} else {
+				r.errs = append(r.errs, e.eval(unevaluated, spath+"/unevaluatedItems", unwrap(n), path).errs...)
+			}
+			r.item(i)
+		}
+	}
+}
+
+func (e *evaluator) evalScalar(r *result, sch *yaml.Node, spath string, inst *yaml.Node, ipath string) {
+	switch typeOf(inst) {
+	case "string":
+		e.evalString(r, sch, spath, inst, ipath)
+	case "number", "integer":
+		e.evalNumber(r, sch, spath, inst, ipath)
+	}
+}
+
+func (e *evaluator) evalString(r *result, sch *yaml.Node, spath string, inst *yaml.Node, ipath string) {
+	length := utf8.RuneCountInString(inst.Value)
+	if n, ok := number(lookup(sch, "maxLength")); ok && float64(length) > n {
+		r.fail(e.newError("maxLength", spath, lookup(sch, "maxLength"), inst, ipath,
+			"string must be at most %v characters long, but is %d", n, length))
+	}
+	if n, ok := number(lookup(sch, "minLength")); ok && float64(length) < n {
+		r.fail(e.newError("minLength", spath, lookup(sch, "minLength"), inst, ipath,
+			"string must be at least %v characters long, but is %d", n, length))
+	}
+	if p, ok := stringValue(sch, "pattern"); ok {
+		if re, err := e.schema.regexp(p); err == nil && !re.MatchString(inst.Value) {
+			r.fail(e.newError("pattern", spath, lookup(sch, "pattern"), inst, ipath,
+				"string must match the pattern '%s'", p))
+		}
+	}
+	if f, ok := stringValue(sch, "format"); ok {
+		if check := e.schema.formats[f]; check != nil && !check(inst.Value) {
+			r.fail(e.newError("format", spath, lookup(sch, "format"), inst, ipath,
+				"'%s' is not a valid '%s'", inst.Value, f))
+		}
+	}
+}
+
+func (e *evaluator) evalNumber(r *result, sch *yaml.Node, spath string, inst *yaml.Node, ipath string) {
+	v, ok := number(inst)
+	if !ok {
+		return
+	}
+	if m, ok := number(lookup(sch, "multipleOf")); ok && m > 0 {
+		q := v / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			r.fail(e.newError("multipleOf", spath, lookup(sch, "multipleOf"), inst, ipath,
+				"%v is not a multiple of %v", v, m))
+		}
+	}
+	// draft-04 exclusive bounds are booleans that modify 'maximum' and 'minimum', later drafts use numbers.
+	exclusive := func(keyword string) (float64, bool, bool) {
+		n := lookup(sch, keyword)
+		if b, ok := boolean(n); ok {
+			return 0, b, false
+		}
+		x, ok := number(n)
+		return x, false, ok
+	}
+	_, exMaxFlag, _ := exclusive("exclusiveMaximum")
+	_, exMinFlag, _ := exclusive("exclusiveMinimum")
+	if max, ok := number(lookup(sch, "maximum")); ok {
+		if exMaxFlag && v >= max {
+			r.fail(e.newError("maximum", spath, lookup(sch, "maximum"), inst, ipath, "%v must be less than %v", v, max))
+		} else if !exMaxFlag && v > max {
+			r.fail(e.newError("maximum", spath, lookup(sch, "maximum"), inst, ipath,
+				"%v must be less than or equal to %v", v, max))
+		}
+	}
+	if min, ok := number(lookup(sch, "minimum")); ok {
+		if exMinFlag && v <= min {
+			r.fail(e.newError("minimum", spath, lookup(sch, "minimum"), inst, ipath, "%v must be greater than %v", v, min))
+		} else if !exMinFlag && v < min {
+			r.fail(e.newError("minimum", spath, lookup(sch, "minimum"), inst, ipath,
+				"%v must be greater than or equal to %v", v, min))
+		}
+	}
+	if x, _, ok := exclusive("exclusiveMaximum"); ok && v >= x {
+		r.fail(e.newError("exclusiveMaximum", spath, lookup(sch, "exclusiveMaximum"), inst, ipath,
+			"%v must be less than %v", v, x))
+	}
+	if x, _, ok := exclusive("exclusiveMinimum"); ok && v <= x {
+		r.fail(e.newError("exclusiveMinimum", spath, lookup(sch, "exclusiveMinimum"), inst, ipath,
+			"%v must be greater than %v", v, x))
+	}
+}
+
+// display renders a value for an error message.
+func display(node *yaml.Node) string {
+	node = unwrap(node)
+	switch {
+	case node == nil:
+		return "null"
+	case node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str":
+		return "'" + node.Value + "'"
+	case node.Kind == yaml.ScalarNode:
+		return node.Value
+	default:
+		return typeOf(node)
+	}
+}
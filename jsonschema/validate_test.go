@@ -0,0 +1,281 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func parse(t *testing.T, s string) *yaml.Node {
+	var n yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(s), &n))
+	return &n
+}
+
+func validate(t *testing.T, schema, instance string) []*ValidationError {
+	s, err := CompileBytes([]byte(schema), nil)
+	require.NoError(t, err)
+	return s.Validate(parse(t, instance))
+}
+
+func TestSchema_Validate_Keywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		keywords []string
+	}{
+		{"type", `type: string`, `1`, []string{"type"}},
+		{"type list", `type: [string, "null"]`, `null`, nil},
+		{"integer is a number", `type: number`, `12`, nil},
+		{"integral float is an integer", `type: integer`, `12.0`, nil},
+		{"enum", `enum: [a, 1]`, `"1"`, []string{"enum"}},
+		{"const", `const: {a: [1, 2]}`, `{a: [1, 2.0]}`, nil},
+		{"numbers", `{minimum: 1, exclusiveMaximum: 10, multipleOf: 0.1}`, `10`, []string{"exclusiveMaximum"}},
+		{"multipleOf", `multipleOf: 0.1`, `0.35`, []string{"multipleOf"}},
+		{"strings", `{minLength: 2, maxLength: 3, pattern: "^a"}`, `bcde`, []string{"maxLength", "pattern"}},
+		{"format", `format: date-time`, `not a date`, []string{"format"}},
+		{"unknown format", `format: burger`, `anything`, nil},
+		{"required", `{required: [a, b], properties: {a: {type: string}}}`, `{a: 1}`, []string{"type", "required"}},
+		{"additionalProperties", `{properties: {a: true}, patternProperties: {"^x-": true}, additionalProperties: false}`,
+			`{a: 1, x-b: 2, c: 3}`, []string{"additionalProperties"}},
+		{"propertyNames", `propertyNames: {maxLength: 2}`, `{abc: 1}`, []string{"maxLength"}},
+		{"dependentRequired", `dependentRequired: {a: [b]}`, `{a: 1}`, []string{"dependentRequired"}},
+		{"arrays", `{minItems: 3, uniqueItems: true, items: {type: integer}}`, `[1, 1]`, []string{"minItems", "uniqueItems"}},
+		{"prefixItems", `{prefixItems: [{type: string}], items: false}`, `[a, b]`, []string{"items"}},
+		{"contains", `{contains: {const: 1}, maxContains: 1}`, `[1, 1]`, []string{"maxContains"}},
+		{"allOf", `allOf: [{type: object}, {required: [a]}]`, `{}`, []string{"required"}},
+		{"anyOf", `anyOf: [{type: string}, {type: integer}]`, `1`, nil},
+		{"oneOf many", `oneOf: [{type: number}, {type: integer}]`, `1`, []string{"oneOf"}},
+		{"not", `not: {type: string}`, `a`, []string{"not"}},
+		{"if then else", `{if: {type: string}, then: {minLength: 2}, else: {minimum: 5}}`, `1`, []string{"minimum"}},
+		{"unevaluatedProperties", `{allOf: [{properties: {a: true}}], unevaluatedProperties: false}`, `{a: 1, b: 2}`,
+			[]string{"unevaluatedProperties"}},
+		{"unevaluatedItems", `{prefixItems: [true], unevaluatedItems: false}`, `[1, 2]`, []string{"unevaluatedItems"}},
+		{"false", `properties: {a: false}`, `{a: 1}`, []string{"false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keywords []string
+			for _, e := range validate(t, tt.schema, tt.instance) {
+				keywords = append(keywords, e.Keyword)
+			}
+			assert.Equal(t, tt.keywords, keywords)
+		})
+	}
+}
+
+func TestSchema_Validate_Draft4(t *testing.T) {
+	schema := `$schema: "http://json-schema.org/draft-04/schema#"
+definitions:
+  positive:
+    minimum: 0
+    exclusiveMinimum: true
+properties:
+  count:
+    $ref: '#/definitions/positive'
+    maximum: 1
+  tuple:
+    items: [{type: string}]
+    additionalItems: false
+dependencies:
+  count: [tuple]`
+	errs := validate(t, schema, `{count: 0, tuple: [a, b]}`)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "minimum", errs[0].Keyword)
+	assert.Equal(t, "/properties/count/$ref/minimum", errs[0].SchemaPath)
+	assert.Equal(t, "/count", errs[0].InstancePath)
+	assert.Equal(t, "additionalItems", errs[1].Keyword)
+	assert.Equal(t, "/tuple/1", errs[1].InstancePath)
+
+	// siblings of a '$ref' are ignored.
+	errs = validate(t, schema, `{count: 5}`)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "property 'tuple' is required when 'count' is present", errs[0].Message)
+}
+
+func TestSchema_Validate_Positions(t *testing.T) {
+	schema := `{properties: {info: {required: [title], properties: {version: true}, additionalProperties: false}}}`
+	errs := validate(t, schema, `openapi: 3.0.0
+info:
+  version: 1
+  nope: true
+`)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "/info/nope (line 4, column 3): property 'nope' is not allowed", errs[0].Error())
+	assert.Equal(t, "missing property 'title'", errs[1].Message)
+	assert.Equal(t, 3, errs[1].Line)
+	assert.Equal(t, 3, errs[1].Column)
+}
+
+func TestSchema_Validate_BestBranch(t *testing.T) {
+	schema := `$defs:
+  reference: {type: object, required: [$ref], properties: {$ref: {type: string}}, additionalProperties: false}
+  parameter: {type: object, required: [name, in], properties: {name: {type: string}, in: {enum: [query]}}}
+items:
+  oneOf: [{$ref: '#/$defs/reference'}, {$ref: '#/$defs/parameter'}]`
+	errs := validate(t, schema, `[{name: a}, {name: b, in: body}]`)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "missing property 'in'", errs[0].Message)
+	assert.Equal(t, "/1/in", errs[1].InstancePath)
+	assert.Equal(t, "enum", errs[1].Keyword)
+}
+
+func TestSchema_Validate_Anchors(t *testing.T) {
+	schema := `$id: https://example.com/root
+$dynamicAnchor: node
+properties:
+  name: {$ref: 'other#name'}
+  children:
+    items: {$dynamicRef: '#node'}
+`
+	other := parse(t, `{$anchor: name, type: string}`)
+	s, err := Compile(parse(t, schema), &Options{Resources: map[string]*yaml.Node{"https://example.com/other": other}})
+	require.NoError(t, err)
+	errs := s.Validate(parse(t, `{name: a, children: [{name: 1}]}`))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/children/0/name", errs[0].InstancePath)
+}
+
+func TestSchema_Validate_ResolveInstance(t *testing.T) {
+	s, err := CompileBytes([]byte(`{properties: {a: {$ref: '#'}, b: {type: integer}}}`), nil)
+	require.NoError(t, err)
+	root := parse(t, `{a: {$ref: loop}, b: x}`)
+	errs := s.ValidateWithOptions(root, &ValidateOptions{ResolveInstance: func(node *yaml.Node) *yaml.Node {
+		return root.Content[0] // a reference back to the root.
+	}})
+
+	// the root is validated twice (once in place of the reference), but never a third time.
+	require.Len(t, errs, 2)
+	assert.Equal(t, "/a/b", errs[0].InstancePath)
+	assert.Equal(t, "/b", errs[1].InstancePath)
+}
+
+func TestSchema_Validate_OpenAPI(t *testing.T) {
+	doc := parse(t, `components:
+  schemas:
+    Pet:
+      type: object
+      nullable: true
+      required: [id, name]
+      discriminator:
+        propertyName: kind
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+      properties:
+        id:
+          $ref: '#/components/schemas/Id'
+        name:
+          type: string
+        secret:
+          type: string
+          writeOnly: true
+        pattern:
+          type: string
+          pattern: '^(?!x-)'
+    Id:
+      type: integer
+      readOnly: true
+    Cat:
+      properties:
+        lives: {type: integer}
+    Dog:
+      properties:
+        lives: {type: string}`).Content[0]
+	schemas := lookup(lookup(doc, "components"), "schemas")
+
+	// references are resolved from the document, the origin is the path followed to each schema.
+	var origins []any
+	resolve := func(schema *yaml.Node, origin any) (*yaml.Node, any) {
+		origins = append(origins, origin)
+		ref, _ := stringValue(schema, "$ref")
+		return lookup(schemas, ref[strings.LastIndex(ref, "/")+1:]), ref
+	}
+	s, err := Compile(lookup(schemas, "Pet"), &Options{Draft: Draft4, OpenAPI: &OpenAPIOptions{
+		Nullable: true, ResolveReference: resolve,
+	}})
+	require.NoError(t, err)
+
+	assert.Empty(t, s.Validate(parse(t, `null`)))
+	assert.Empty(t, s.Validate(parse(t, `{id: 1, name: tom, kind: Cat, lives: 9}`)))
+	assert.Nil(t, origins[0])
+
+	// the discriminator picks the branch, so only the dog is reported.
+	errs := s.Validate(parse(t, `{id: 1, name: rex, kind: Dog, lives: 9}`))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/oneOf/1/$ref/properties/lives/type", errs[0].SchemaPath)
+
+	// readOnly properties are not required in a request, and not allowed.
+	errs = s.ValidateWithOptions(parse(t, `{id: 1, name: tom, kind: Cat}`), &ValidateOptions{Request: true})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "readOnly", errs[0].Keyword)
+	assert.Equal(t, "/id", errs[0].InstancePath)
+	assert.Empty(t, s.ValidateWithOptions(parse(t, `{name: tom, kind: Cat}`), &ValidateOptions{Request: true}))
+
+	errs = s.ValidateWithOptions(parse(t, `{id: 1, name: tom, kind: Cat, secret: x}`), &ValidateOptions{Response: true})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "writeOnly", errs[0].Keyword)
+
+	// references that cannot be resolved are reported when they are used.
+	s, err = Compile(parse(t, `{properties: {a: {$ref: '#/nope'}}}`), &Options{OpenAPI: &OpenAPIOptions{
+		ResolveReference: func(*yaml.Node, any) (*yaml.Node, any) { return nil, nil },
+	}})
+	require.NoError(t, err)
+	errs = s.Validate(parse(t, `{a: 1}`))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "$ref", errs[0].Keyword)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	_, err := CompileBytes([]byte(`{$ref: '#/nope'}`), nil)
+	assert.ErrorContains(t, err, "'#/nope' cannot be resolved")
+
+	_, err = CompileBytes([]byte(`{$ref: 'https://example.com/nope'}`), nil)
+	assert.ErrorContains(t, err, "resource 'https://example.com/nope' is unknown")
+
+	_, err = CompileBytes([]byte(`{pattern: '(?=a)'}`), nil)
+	assert.ErrorContains(t, err, "pattern '(?=a)' is not supported")
+
+	_, err = CompileBytes([]byte(``), nil)
+	assert.Error(t, err)
+}
+
+func TestDefaultFormats(t *testing.T) {
+	valid := map[string][]string{
+		"date-time":     {"2023-01-01T10:00:00Z", "2023-01-01t10:00:00.5+01:00"},
+		"date":          {"2023-12-31"},
+		"email":         {"dave@pb33f.io"},
+		"ipv4":          {"127.0.0.1"},
+		"ipv6":          {"::1"},
+		"uri":           {"https://pb33f.io/{path}", "{scheme}://{host}:{port}"},
+		"uri-reference": {"../burgers#/Burger"},
+		"uuid":          {"c4e1b6f0-3d3a-4c2b-9b8a-1c2d3e4f5a6b"},
+		"regex":         {"^(?!x-)"},
+		"hostname":      {"api.pb33f.io"},
+	}
+	for format, values := range valid {
+		for _, v := range values {
+			assert.True(t, DefaultFormats[format](v), "%s: %s", format, v)
+		}
+	}
+	invalid := map[string][]string{
+		"date-time": {"2023-01-01"},
+		"email":     {"Dave <dave@pb33f.io>"},
+		"ipv4":      {"::1"},
+		"uri":       {"/relative"},
+		"regex":     {"(unclosed"},
+		"hostname":  {"-nope"},
+	}
+	for format, values := range invalid {
+		for _, v := range values {
+			assert.False(t, DefaultFormats[format](v), "%s: %s", format, v)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package jsonschema
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unwrap steps through document and alias nodes, to the node that holds the value.
+func unwrap(node *yaml.Node) *yaml.Node {
+	for node != nil {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			if len(node.Content) == 0 {
+				return nil
+			}
+			node = node.Content[0]
+		case yaml.AliasNode:
+			node = node.Alias
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a key in a map node.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return unwrap(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// stringValue returns the value of a key in a map node, if it is a string.
+func stringValue(node *yaml.Node, key string) (string, bool) {
+	v := lookup(node, key)
+	if v == nil || v.Kind != yaml.ScalarNode || v.ShortTag() != "!!str" {
+		return "", false
+	}
+	return v.Value, true
+}
+
+// typeOf returns the JSON type of a node, integers are reported as 'integer'.
+func typeOf(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		if f, ok := number(node); ok && f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
+
+// isType checks if a node is of a JSON type, every integer is also a number.
+func isType(node *yaml.Node, t string) bool {
+	actual := typeOf(node)
+	return actual == t || t == "number" && actual == "integer"
+}
+
+// number reads the numeric value of a node.
+func number(node *yaml.Node) (float64, bool) {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return 0, false
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		v := strings.ReplaceAll(node.Value, "_", "")
+		if i, err := strconv.ParseInt(v, 0, 64); err == nil {
+			return float64(i), true
+		}
+		if strings.HasPrefix(v, "0o") {
+			if i, err := strconv.ParseInt(v[2:], 8, 64); err == nil {
+				return float64(i), true
+			}
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	case "!!float":
+		switch strings.ToLower(node.Value) {
+		case ".inf", "+.inf":
+			return math.Inf(1), true
+		case "-.inf":
+			return math.Inf(-1), true
+		case ".nan":
+			return math.NaN(), true
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(node.Value, "_", ""), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// boolean reads the value of a boolean node.
+func boolean(node *yaml.Node) (bool, bool) {
+	if node == nil || node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+		return false, false
+	}
+	var b bool
+	if err := node.Decode(&b); err != nil {
+		return false, false
+	}
+	return b, true
+}
+
+// value converts a node into a plain value, so it can be compared with another.
+func value(node *yaml.Node) any {
+	node = unwrap(node)
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		m := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			m[node.Content[i].Value] = value(node.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		s := make([]any, len(node.Content))
+		for i := range node.Content {
+			s[i] = value(node.Content[i])
+		}
+		return s
+	}
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		f, _ := number(node)
+		return f
+	case "!!bool":
+		b, _ := boolean(node)
+		return b
+	case "!!null":
+		return nil
+	}
+	return node.Value
+}
+
+// equal checks if two nodes hold the same value.
+func equal(a, b *yaml.Node) bool {
+	return reflect.DeepEqual(value(a), value(b))
+}
+
+// TypeOf returns the JSON type of a node, integers (including floats with no fraction) are reported as 'integer'.
+func TypeOf(node *yaml.Node) string {
+	if node = unwrap(node); node == nil {
+		return "null"
+	}
+	return typeOf(node)
+}
+
+// Number reads the numeric value of a node, the second value is false if the node is not a number.
+func Number(node *yaml.Node) (float64, bool) {
+	return number(unwrap(node))
+}
+
+// Equal checks if two nodes hold the same JSON value, regardless of style or position.
+func Equal(a, b *yaml.Node) bool {
+	return equal(a, b)
+}
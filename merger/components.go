@@ -0,0 +1,416 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package merger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/datamodel/low"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// componentTypes are the keys of the components object, in the order they are rendered.
+var componentTypes = []string{
+	"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links",
+	"callbacks",
+}
+
+const componentsPrefix = "#/components/"
+
+// component is a component of the merged document, and the source (and name) it came from.
+type component struct {
+	name     string
+	owner    *source
+	original string
+}
+
+// duplicate is a component of a source that was found to be identical to a component of the merged document.
+type duplicate struct {
+	source   *source
+	cType    string
+	name     string
+	existing *component
+}
+
+// mergeComponents decides the name of every component in the merged document, renaming any components that
+// collide (depending on the strategy), and recording the new names in each source.
+func (m *merger) mergeComponents() {
+	m.components = make(map[string][]*component)
+	byName := make(map[string]map[string]*component)
+	var duplicates []*duplicate
+	for _, cType := range componentTypes {
+		byName[cType] = make(map[string]*component)
+		for _, s := range m.sources {
+			hashes := componentHashes(s.model.Components, cType)
+			for _, name := range componentNames(s, cType) {
+				existing := byName[cType][name]
+				if existing == nil {
+					c := &component{name: name, owner: s, original: name}
+					byName[cType][name] = c
+					m.components[cType] = append(m.components[cType], c)
+					continue
+				}
+				existingHashes := componentHashes(existing.owner.model.Components, cType)
+				if identical(existingHashes[existing.original], hashes[name],
+					existing.owner.components[cType][existing.original], s.components[cType][name]) {
+					duplicates = append(duplicates, &duplicate{source: s, cType: cType, name: name, existing: existing})
+					continue
+				}
+				m.collide(s, cType, name, existing, byName[cType])
+			}
+		}
+	}
+
+	// references are hashed without being resolved, so identical components that reference components that have
+	// been renamed are no longer identical. This is repeated until nothing else needs renaming.
+	for changed := true; changed; {
+		changed = false
+		var remaining []*duplicate
+		for _, d := range duplicates {
+			if m.sameReferences(d) {
+				remaining = append(remaining, d)
+				continue
+			}
+			m.collide(d.source, d.cType, d.name, d.existing, byName[d.cType])
+			changed = true
+		}
+		duplicates = remaining
+	}
+}
+
+// collide resolves a component of a source that has the same name as a different component in the merged document.
+func (m *merger) collide(s *source, cType, name string, existing *component, byName map[string]*component) {
+	pointer := []string{"components", cType, name}
+	existingPointer := []string{"components", cType, existing.original}
+	var renamed string
+	switch m.config.Components {
+	case ComponentPrefix:
+		renamed = s.prefix + name
+	case ComponentRename:
+		renamed = fmt.Sprintf("%s_2", name)
+	default:
+		m.conflict(ComponentConflict, s, existing.owner, false, pointer, existingPointer,
+			"component is not identical to the component of %s", existing.owner.name)
+		return
+	}
+	for i := 2; byName[renamed] != nil; i++ {
+		if m.config.Components == ComponentPrefix {
+			renamed = fmt.Sprintf("%s%s_%d", s.prefix, name, i)
+		} else {
+			renamed = fmt.Sprintf("%s_%d", name, i)
+		}
+	}
+	if s.renames[cType] == nil {
+		s.renames[cType] = make(map[string]string)
+	}
+	s.renames[cType][name] = renamed
+	c := &component{name: renamed, owner: s, original: name}
+	byName[renamed] = c
+	m.components[cType] = append(m.components[cType], c)
+	m.conflict(ComponentConflict, s, existing.owner, true, pointer, existingPointer,
+		"component is not identical to the component of %s, renamed to '%s'", existing.owner.name, renamed)
+}
+
+// sameReferences checks the references of a duplicate component point to the same merged components as the
+// references of the component it duplicates.
+func (m *merger) sameReferences(d *duplicate) bool {
+	refs := collectRefs(d.source.components[d.cType][d.name], nil)
+	existingRefs := collectRefs(d.existing.owner.components[d.cType][d.existing.original], nil)
+	if len(refs) != len(existingRefs) {
+		return false
+	}
+	for i := range refs {
+		if renameRef(d.source, refs[i]) != renameRef(d.existing.owner, existingRefs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderComponents creates the components object of the merged document.
+func (m *merger) renderComponents() *yaml.Node {
+	comps := utils.CreateEmptyMapNode()
+	for _, cType := range componentTypes {
+		if len(m.components[cType]) == 0 {
+			continue
+		}
+		entries := utils.CreateEmptyMapNode()
+		for _, c := range m.components[cType] {
+			addNode(entries, c.name, c.owner.components[cType][c.original])
+		}
+		addNode(comps, cType, entries)
+	}
+	for _, s := range m.sources {
+		_, sc := findKey(s.root, "components")
+		if sc == nil {
+			continue
+		}
+		for i := 0; i < len(sc.Content)-1; i += 2 {
+			if k, _ := findKey(comps, sc.Content[i].Value); k == nil && strings.HasPrefix(sc.Content[i].Value, "x-") {
+				addNode(comps, sc.Content[i].Value, sc.Content[i+1])
+			}
+		}
+	}
+	if len(comps.Content) == 0 {
+		return nil
+	}
+	return comps
+}
+
+func componentNames(s *source, cType string) []string {
+	_, comps := findKey(s.root, "components")
+	if comps == nil {
+		return nil
+	}
+	_, entries := findKey(comps, cType)
+	if !utils.IsNodeMap(entries) {
+		return nil
+	}
+	var names []string
+	for i := 0; i < len(entries.Content)-1; i += 2 {
+		names = append(names, entries.Content[i].Value)
+	}
+	return names
+}
+
+// componentHashes returns the low-level hash of every component of a type, by name.
+func componentHashes(c *v3high.Components, cType string) map[string]*[32]byte {
+	if c == nil {
+		return nil
+	}
+	switch cType {
+	case "schemas":
+		return hashes(c.Schemas)
+	case "responses":
+		return hashes(c.Responses)
+	case "parameters":
+		return hashes(c.Parameters)
+	case "examples":
+		return hashes(c.Examples)
+	case "requestBodies":
+		return hashes(c.RequestBodies)
+	case "headers":
+		return hashes(c.Headers)
+	case "securitySchemes":
+		return hashes(c.SecuritySchemes)
+	case "links":
+		return hashes(c.Links)
+	case "callbacks":
+		return hashes(c.Callbacks)
+	}
+	return nil
+}
+
+func hashes[T interface{ GoLowUntyped() any }](m *orderedmap.Map[string, T]) map[string]*[32]byte {
+	h := make(map[string]*[32]byte)
+	for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
+		// new components (that were not built from a document) have no low-level object to hash.
+		lowValue := pair.Value().GoLowUntyped()
+		if v := reflect.ValueOf(lowValue); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+			continue
+		}
+		if hashable, ok := lowValue.(low.Hashable); ok {
+			hash := hashable.Hash()
+			h[pair.Key()] = &hash
+		}
+	}
+	return h
+}
+
+// renameRef returns a local component reference of a source, using the new name of the component if it has been
+// renamed.
+func renameRef(s *source, ref string) string {
+	if !strings.HasPrefix(ref, componentsPrefix) {
+		return ref
+	}
+	segments := strings.SplitN(strings.TrimPrefix(ref, componentsPrefix), "/", 3)
+	if len(segments) < 2 {
+		return ref
+	}
+	if renamed, ok := s.renames[segments[0]][segments[1]]; ok {
+		segments[1] = renamed
+	}
+	return componentsPrefix + strings.Join(segments, "/")
+}
+
+// rewrite will re-write every reference to a renamed component of a source, as well as discriminator mappings
+// to renamed schemas and security requirements that use renamed security schemes.
+func rewrite(s *source) {
+	walk(s.root, func(node *yaml.Node) {
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch {
+			case key == "$ref" && utils.IsNodeStringValue(value):
+				value.Value = renameRef(s, value.Value)
+			case key == "discriminator" && utils.IsNodeMap(value):
+				if _, mapping := findKey(value, "mapping"); utils.IsNodeMap(mapping) {
+					for j := 1; j < len(mapping.Content); j += 2 {
+						mapping.Content[j].Value = renameMapping(s, mapping.Content[j].Value)
+					}
+				}
+			}
+		}
+	})
+	if len(s.renames["securitySchemes"]) == 0 {
+		return
+	}
+	requirements := operations(s.root)
+	if _, security := findKey(s.root, "security"); security != nil {
+		requirements = append(requirements, s.root)
+	}
+	for _, n := range requirements {
+		_, security := findKey(n, "security")
+		if security == nil {
+			continue
+		}
+		for _, requirement := range security.Content {
+			for j := 0; j < len(requirement.Content)-1; j += 2 {
+				if renamed, ok := s.renames["securitySchemes"][requirement.Content[j].Value]; ok {
+					requirement.Content[j] = utils.CreateStringNode(renamed)
+				}
+			}
+		}
+	}
+}
+
+// renameMapping re-writes a discriminator mapping value, which is either a reference or the name of a schema.
+func renameMapping(s *source, value string) string {
+	if strings.HasPrefix(value, "#") {
+		return renameRef(s, value)
+	}
+	if renamed, ok := s.renames["schemas"][value]; ok {
+		return renamed
+	}
+	return value
+}
+
+// collectRefs returns every reference (and discriminator mapping) found inside a node, in order.
+func collectRefs(node *yaml.Node, refs []string) []string {
+	walk(node, func(n *yaml.Node) {
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			switch n.Content[i].Value {
+			case "$ref":
+				refs = append(refs, n.Content[i+1].Value)
+			case "discriminator":
+				if _, mapping := findKey(n.Content[i+1], "mapping"); mapping != nil {
+					for j := 1; j < len(mapping.Content); j += 2 {
+						refs = append(refs, mapping.Content[j].Value)
+					}
+				}
+			}
+		}
+	})
+	return refs
+}
+
+// walk calls fn for every map node inside a node (including the node itself).
+func walk(node *yaml.Node, fn func(n *yaml.Node)) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.MappingNode {
+		fn(node)
+	}
+	for _, c := range node.Content {
+		walk(c, fn)
+	}
+}
+
+// nodesEqual checks two nodes hold the same values, ignoring styles and positions.
+func nodesEqual(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !nodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// locate finds the key (or sequence entry) node of a JSON pointer, split into segments, inside a root node.
+func locate(root *yaml.Node, segments ...string) *yaml.Node {
+	n := root
+	if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	var found *yaml.Node
+	for _, seg := range segments {
+		if n == nil {
+			return nil
+		}
+		switch n.Kind {
+		case yaml.MappingNode:
+			k, v := findKey(n, seg)
+			if k == nil {
+				return nil
+			}
+			found, n = k, v
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil
+			}
+			found, n = n.Content[i], n.Content[i]
+		default:
+			return nil
+		}
+	}
+	return found
+}
+
+func findKey(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// addNode adds a value node to a map node, nil values are skipped.
+func addNode(m *yaml.Node, key string, value *yaml.Node) {
+	if value != nil {
+		m.Content = append(m.Content, utils.CreateStringNode(key), value)
+	}
+}
+
+// copyKey copies the value of a key from one map node into another, if it exists.
+func copyKey(to, from *yaml.Node, key string) {
+	_, value := findKey(from, key)
+	addNode(to, key, value)
+}
+
+func escapeSegments(segments []string) []string {
+	escaped := make([]string, len(segments))
+	for i, seg := range segments {
+		escaped[i] = utils.EscapePointerSegment(seg)
+	}
+	return escaped
+}
@@ -0,0 +1,726 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package merger combines several OpenAPI 3+ documents into a single document, for example to publish a single
+// specification for a gateway that sits in front of many services. The merged document is rendered and then
+// re-loaded and built, in the same way as libopenapi.Document.RenderAndReload does. None of the source documents
+// are modified.
+//
+// Paths, webhooks and components that are defined by more than one document are conflicts, unless they are
+// structurally identical (which is checked using the low-level Hash() of each object). Conflicts are resolved
+// using the strategies set in the MergeConfig, every conflict is reported with the file and line it was found at.
+//
+// References to other files are not followed, so multi-file documents should be bundled (see the bundler
+// package) before they are merged.
+package merger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// PathStrategy decides what happens when more than one document defines the same path (or webhook) differently.
+type PathStrategy int
+
+const (
+	// PathError will fail the merge, this is the default.
+	PathError PathStrategy = iota
+
+	// PathKeepFirst keeps the path item of the first document that defines the path.
+	PathKeepFirst
+
+	// PathKeepLast keeps the path item of the last document that defines the path.
+	PathKeepLast
+
+	// PathMergeOperations combines the operations of every document into a single path item. The merge fails if the
+	// same operation is defined differently, everything else (like a summary, or path level parameters) is kept from
+	// the first document that defines it.
+	PathMergeOperations
+)
+
+// ComponentStrategy decides what happens when more than one document defines a component with the same name,
+// that is not structurally identical. Identical components are always merged into one.
+type ComponentStrategy int
+
+const (
+	// ComponentError will fail the merge, this is the default.
+	ComponentError ComponentStrategy = iota
+
+	// ComponentPrefix renames the colliding component by adding the Prefix of its Source to the front of the name.
+	ComponentPrefix
+
+	// ComponentRename renames the colliding component by adding a number to the end of the name, e.g. 'Pet_2'.
+	ComponentRename
+)
+
+// ConflictType is the kind of object a Conflict was found in.
+type ConflictType string
+
+const (
+	PathConflict      ConflictType = "path"
+	WebhookConflict   ConflictType = "webhook"
+	OperationConflict ConflictType = "operation"
+	ComponentConflict ConflictType = "component"
+	ServerConflict    ConflictType = "server"
+	TagConflict       ConflictType = "tag"
+	ExtensionConflict ConflictType = "extension"
+	VersionConflict   ConflictType = "version"
+)
+
+// MergeConfig is used to configure how documents are merged.
+type MergeConfig struct {
+	// Paths is the strategy used for paths and webhooks defined by more than one document, defaults to PathError.
+	Paths PathStrategy
+
+	// Components is the strategy used for colliding component names, defaults to ComponentError.
+	Components ComponentStrategy
+
+	// Info is used as the info object of the merged document, defaults to the info of the first document.
+	Info *base.Info
+
+	// DocumentConfiguration is used to load the merged document, a default configuration is used if it is nil.
+	DocumentConfiguration *datamodel.DocumentConfiguration
+}
+
+// Source is a document to merge, and where it came from.
+type Source struct {
+	// Document is an OpenAPI 3+ document.
+	Document libopenapi.Document
+
+	// Name of the document (like a file name or a URL), used when reporting conflicts. Defaults to the absolute
+	// path of the document, or 'document N' (where N is the position of the document) if it has none.
+	Name string
+
+	// Prefix is added to the front of colliding component names, when using ComponentPrefix. Defaults to the
+	// title of the document, with anything that is not a letter or a number removed.
+	Prefix string
+}
+
+// Conflict is an object defined by more than one document.
+type Conflict struct {
+	// Type of object the conflict was found in.
+	Type ConflictType
+
+	// Path is a JSON pointer to the object in the merged document, for example '/components/schemas/Pet'.
+	Path string
+
+	// Message explains the conflict, and how it was resolved.
+	Message string
+
+	// Source is the name of the document the conflicting object was found in, with its Line and Column.
+	Source string
+	Line   int
+	Column int
+
+	// ExistingSource is the name of the document that already defined the object, with its Line and Column.
+	ExistingSource string
+	ExistingLine   int
+	ExistingColumn int
+
+	// Resolved is true if the conflict was resolved by the configured strategy.
+	Resolved bool
+}
+
+func (c *Conflict) Error() string {
+	return fmt.Sprintf("%s: %s: %s (already defined in %s)", location(c.Source, c.Line, c.Column), c.Path,
+		c.Message, location(c.ExistingSource, c.ExistingLine, c.ExistingColumn))
+}
+
+func location(source string, line, column int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d:%d", source, line, column)
+	}
+	return source
+}
+
+// Result contains the outcome of a merge.
+type Result struct {
+	// Bytes are the rendered bytes of the merged document.
+	Bytes []byte
+
+	// Document is the new Document, created from the rendered Bytes.
+	Document libopenapi.Document
+
+	// Model is the OpenAPI 3+ model built from the new Document.
+	Model *libopenapi.DocumentModel[v3high.Document]
+
+	// Conflicts contains every conflict found, resolved or not, in the order they were found.
+	Conflicts []*Conflict
+}
+
+// MergeDocuments will merge the supplied documents, in order, using Merge. Every document is named after its
+// absolute path, or its position, when reporting conflicts.
+func MergeDocuments(docs []libopenapi.Document, config *MergeConfig) (*Result, error) {
+	sources := make([]*Source, len(docs))
+	for i := range docs {
+		sources[i] = &Source{Document: docs[i]}
+	}
+	return Merge(sources, config)
+}
+
+// Merge will merge the supplied sources into a single OpenAPI 3+ document. The first source always wins: the
+// version, info, external docs and json schema dialect of the merged document are taken from it.
+//
+//   - paths and webhooks are combined, using the configured PathStrategy when more than one document defines one.
+//   - components are combined, using the configured ComponentStrategy when names collide. All references to a
+//     renamed component (and for security schemes, all security requirements) are re-written.
+//   - servers (by URL), tags (by name) and extensions (by key) are combined, the first definition is kept.
+//   - if the documents do not share the same top level security requirements, every operation that relies on the
+//     top level security requirements of its document is given a copy of them, instead.
+//
+// If any conflict could not be resolved, the merged document is not created and an error is returned along with
+// a Result that contains the conflicts.
+func Merge(sources []*Source, config *MergeConfig) (*Result, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("unable to merge, no documents supplied")
+	}
+	if config == nil {
+		config = &MergeConfig{}
+	}
+	m := &merger{config: config}
+	for i, s := range sources {
+		src, err := prepareSource(s, i)
+		if err != nil {
+			return nil, err
+		}
+		m.sources = append(m.sources, src)
+	}
+
+	m.mergeComponents()
+	for _, s := range m.sources {
+		rewrite(s)
+	}
+	root := m.mergeDocument()
+
+	result := &Result{Conflicts: m.conflicts}
+	var unresolved []error
+	for _, c := range m.conflicts {
+		if !c.Resolved {
+			unresolved = append(unresolved, c)
+		}
+	}
+	if len(unresolved) > 0 {
+		return result, fmt.Errorf("unable to merge, %d conflicts could not be resolved: %w", len(unresolved),
+			joinErrors(unresolved))
+	}
+	return m.reload(root, result)
+}
+
+type merger struct {
+	config    *MergeConfig
+	sources   []*source
+	conflicts []*Conflict
+
+	// components are the components of the merged document, by type.
+	components map[string][]*component
+}
+
+// source is a prepared Source, with the document rendered into a node tree that can be re-written.
+type source struct {
+	name   string
+	prefix string
+	info   *datamodel.SpecInfo
+	model  *v3high.Document
+
+	// root is a copy of the rendered model, original is the root node of the specification (used for lines).
+	root     *yaml.Node
+	original *yaml.Node
+
+	// components are the rendered components by type and name, renames are the new names of any renamed ones.
+	components map[string]map[string]*yaml.Node
+	renames    map[string]map[string]string
+}
+
+func prepareSource(s *Source, i int) (*source, error) {
+	if s == nil || s.Document == nil || s.Document.GetSpecInfo() == nil {
+		return nil, fmt.Errorf("unable to merge, document %d has not been initialized", i+1)
+	}
+	info := s.Document.GetSpecInfo()
+	if info.SpecFormat != datamodel.OAS3 && info.SpecFormat != datamodel.OAS31 {
+		return nil, fmt.Errorf("unable to merge, document %d is not an OpenAPI 3+ document", i+1)
+	}
+	m, errs := s.Document.BuildV3Model()
+	if m == nil {
+		return nil, fmt.Errorf("unable to build OpenAPI model for document %d: %w", i+1, joinErrors(errs))
+	}
+	rendered, err := m.Model.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := rendered.(*yaml.Node)
+	if !ok || !utils.IsNodeMap(root) {
+		return nil, fmt.Errorf("unable to merge, document %d did not render into an object", i+1)
+	}
+
+	src := &source{
+		name:       s.Name,
+		prefix:     s.Prefix,
+		info:       info,
+		model:      &m.Model,
+		root:       utils.CopyNode(root),
+		original:   info.RootNode,
+		components: make(map[string]map[string]*yaml.Node),
+		renames:    make(map[string]map[string]string),
+	}
+	if src.name == "" && m.Index != nil {
+		src.name = m.Index.GetSpecAbsolutePath()
+	}
+	if src.name == "" {
+		src.name = fmt.Sprintf("document %d", i+1)
+	}
+	if src.prefix == "" && m.Model.Info != nil {
+		for _, word := range nonAlphanumeric.Split(m.Model.Info.Title, -1) {
+			if word != "" {
+				src.prefix += strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+	}
+	if src.prefix == "" {
+		src.prefix = fmt.Sprintf("Document%d", i+1)
+	}
+	if _, comps := findKey(src.root, "components"); comps != nil {
+		for _, cType := range componentTypes {
+			if _, entries := findKey(comps, cType); utils.IsNodeMap(entries) {
+				src.components[cType] = make(map[string]*yaml.Node)
+				for j := 0; j < len(entries.Content)-1; j += 2 {
+					src.components[cType][entries.Content[j].Value] = entries.Content[j+1]
+				}
+			}
+		}
+	}
+	return src, nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// conflict records a conflict between an object in a source, and the same object in an existing source.
+func (m *merger) conflict(cType ConflictType, s *source, existing *source, resolved bool, pointer []string,
+	existingPointer []string, message string, args ...any,
+) {
+	c := &Conflict{
+		Type:           cType,
+		Path:           "/" + strings.Join(escapeSegments(pointer), "/"),
+		Message:        fmt.Sprintf(message, args...),
+		Source:         s.name,
+		ExistingSource: existing.name,
+		Resolved:       resolved,
+	}
+	if n := locate(s.original, pointer...); n != nil {
+		c.Line, c.Column = n.Line, n.Column
+	}
+	if n := locate(existing.original, existingPointer...); n != nil {
+		c.ExistingLine, c.ExistingColumn = n.Line, n.Column
+	}
+	m.conflicts = append(m.conflicts, c)
+}
+
+// mergeDocument builds the root node of the merged document, from every (re-written) source.
+func (m *merger) mergeDocument() *yaml.Node {
+	first := m.sources[0]
+	root := utils.CreateEmptyMapNode()
+	for _, s := range m.sources[1:] {
+		if majorMinor(s.model.Version) != majorMinor(first.model.Version) {
+			m.conflict(VersionConflict, s, first, true, []string{"openapi"}, []string{"openapi"},
+				"version '%s' differs, version '%s' is used", s.model.Version, first.model.Version)
+		}
+	}
+	copyKey(root, first.root, "openapi")
+	if m.config.Info != nil {
+		if rendered, err := m.config.Info.MarshalYAML(); err == nil {
+			if n, ok := rendered.(*yaml.Node); ok {
+				addNode(root, "info", utils.CopyNode(n))
+			}
+		}
+	} else {
+		copyKey(root, first.root, "info")
+	}
+	copyKey(root, first.root, "jsonSchemaDialect")
+	addNode(root, "servers", m.mergeServers())
+
+	// security is reconciled first, as it can change the operations of every document.
+	security := m.mergeSecurity()
+	addNode(root, "paths", m.mergePaths("paths", PathConflict))
+	addNode(root, "webhooks", m.mergePaths("webhooks", WebhookConflict))
+	addNode(root, "components", m.renderComponents())
+	addNode(root, "security", security)
+	addNode(root, "tags", m.mergeTags())
+	copyKey(root, first.root, "externalDocs")
+	m.mergeExtensions(root)
+	return root
+}
+
+// pathEntry is a path (or webhook) of the merged document, and the source it came from.
+type pathEntry struct {
+	key   string
+	node  *yaml.Node
+	owner *source
+}
+
+func (m *merger) mergePaths(section string, cType ConflictType) *yaml.Node {
+	var entries []*pathEntry
+	byKey := make(map[string]*pathEntry)
+	for _, s := range m.sources {
+		_, items := findKey(s.root, section)
+		if !utils.IsNodeMap(items) {
+			continue
+		}
+		for i := 0; i < len(items.Content)-1; i += 2 {
+			key, node := items.Content[i].Value, items.Content[i+1]
+			if strings.HasPrefix(key, "x-") {
+				continue
+			}
+			existing := byKey[key]
+			if existing == nil {
+				byKey[key] = &pathEntry{key: key, node: node, owner: s}
+				entries = append(entries, byKey[key])
+				continue
+			}
+			if identical(pathItemHash(existing.owner, section, key), pathItemHash(s, section, key),
+				existing.node, node) {
+				continue
+			}
+			pointer := []string{section, key}
+			switch m.config.Paths {
+			case PathKeepFirst:
+				m.conflict(cType, s, existing.owner, true, pointer, pointer,
+					"defined differently, the %s of %s is kept", cType, existing.owner.name)
+			case PathKeepLast:
+				m.conflict(cType, s, existing.owner, true, pointer, pointer,
+					"defined differently, replaces the %s of %s", cType, existing.owner.name)
+				existing.node, existing.owner = node, s
+			case PathMergeOperations:
+				existing.node = m.mergeOperations(section, cType, key, existing, s, node)
+			default:
+				m.conflict(cType, s, existing.owner, false, pointer, pointer, "defined differently")
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	merged := utils.CreateEmptyMapNode()
+	for _, e := range entries {
+		addNode(merged, e.key, e.node)
+	}
+	return merged
+}
+
+// mergeOperations adds every operation of a path item into an existing path item, returning a new path item.
+func (m *merger) mergeOperations(section string, cType ConflictType, key string, existing *pathEntry, s *source,
+	node *yaml.Node,
+) *yaml.Node {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: existing.node.Tag, Content: append([]*yaml.Node{},
+		existing.node.Content...)}
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		field, value := node.Content[i].Value, node.Content[i+1]
+		_, current := findKey(merged, field)
+		if current == nil {
+			merged.Content = append(merged.Content, node.Content[i], value)
+			continue
+		}
+		pointer := []string{section, key, field}
+		if !httpMethods[field] {
+			if !nodesEqual(current, value) {
+				m.conflict(cType, s, existing.owner, true, pointer, pointer,
+					"'%s' is defined differently, the '%s' of %s is kept", field, field, existing.owner.name)
+			}
+			continue
+		}
+		owner := operationOwner(existing, m.sources, section, key, field)
+		if !identical(operationHash(owner, section, key, field), operationHash(s, section, key, field),
+			current, value) {
+			m.conflict(OperationConflict, s, owner, false, pointer, pointer, "operation defined differently")
+		}
+	}
+	return merged
+}
+
+// operationOwner finds the first source that defines an operation of a merged path item.
+func operationOwner(existing *pathEntry, sources []*source, section, key, method string) *source {
+	for _, s := range sources {
+		if _, items := findKey(s.root, section); items != nil {
+			if _, item := findKey(items, key); item != nil {
+				if _, op := findKey(item, method); op != nil {
+					return s
+				}
+			}
+		}
+	}
+	return existing.owner
+}
+
+func (m *merger) mergeServers() *yaml.Node {
+	merged := utils.CreateEmptySequenceNode()
+	urls := make(map[string]int)
+	for _, s := range m.sources {
+		_, servers := findKey(s.root, "servers")
+		if servers == nil {
+			continue
+		}
+		for i, server := range servers.Content {
+			_, url := findKey(server, "url")
+			if url == nil {
+				continue
+			}
+			first, exists := urls[url.Value]
+			if !exists {
+				urls[url.Value] = len(merged.Content)
+				merged.Content = append(merged.Content, server)
+				continue
+			}
+			if !nodesEqual(merged.Content[first], server) {
+				owner := m.sourceOf("servers", "url", url.Value)
+				m.conflict(ServerConflict, s, owner, true, []string{"servers", strconv.Itoa(i)},
+					[]string{"servers", strconv.Itoa(indexOf(owner, "servers", "url", url.Value))},
+					"server '%s' is defined differently, the server of %s is kept", url.Value, owner.name)
+			}
+		}
+	}
+	if len(merged.Content) == 0 {
+		return nil
+	}
+	return merged
+}
+
+func (m *merger) mergeTags() *yaml.Node {
+	merged := utils.CreateEmptySequenceNode()
+	names := make(map[string]int)
+	for _, s := range m.sources {
+		_, tags := findKey(s.root, "tags")
+		if tags == nil {
+			continue
+		}
+		for i, tag := range tags.Content {
+			_, name := findKey(tag, "name")
+			if name == nil {
+				continue
+			}
+			first, exists := names[name.Value]
+			if !exists {
+				names[name.Value] = len(merged.Content)
+				merged.Content = append(merged.Content, tag)
+				continue
+			}
+			if !nodesEqual(merged.Content[first], tag) {
+				owner := m.sourceOf("tags", "name", name.Value)
+				m.conflict(TagConflict, s, owner, true, []string{"tags", strconv.Itoa(i)},
+					[]string{"tags", strconv.Itoa(indexOf(owner, "tags", "name", name.Value))},
+					"tag '%s' is defined differently, the tag of %s is kept", name.Value, owner.name)
+			}
+		}
+	}
+	if len(merged.Content) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// sourceOf finds the first source with an entry in a top level sequence, that has a field with a value.
+func (m *merger) sourceOf(section, field, value string) *source {
+	for _, s := range m.sources {
+		if indexOf(s, section, field, value) >= 0 {
+			return s
+		}
+	}
+	return m.sources[0]
+}
+
+func indexOf(s *source, section, field, value string) int {
+	if _, entries := findKey(s.root, section); entries != nil {
+		for i, e := range entries.Content {
+			if _, v := findKey(e, field); v != nil && v.Value == value {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// mergeSecurity returns the top level security requirements, if every document shares the same ones. If not,
+// the top level security requirements of each document are copied into every operation that relies on them.
+func (m *merger) mergeSecurity() *yaml.Node {
+	_, shared := findKey(m.sources[0].root, "security")
+	for _, s := range m.sources[1:] {
+		_, security := findKey(s.root, "security")
+		if (shared == nil) != (security == nil) || (shared != nil && !nodesEqual(shared, security)) {
+			shared = nil
+			for _, src := range m.sources {
+				if _, security = findKey(src.root, "security"); security != nil {
+					for _, op := range operations(src.root) {
+						if k, _ := findKey(op, "security"); k == nil {
+							addNode(op, "security", utils.CopyNode(security))
+						}
+					}
+				}
+			}
+			break
+		}
+	}
+	return shared
+}
+
+func (m *merger) mergeExtensions(root *yaml.Node) {
+	for _, s := range m.sources {
+		for i := 0; i < len(s.root.Content)-1; i += 2 {
+			key := s.root.Content[i].Value
+			if !strings.HasPrefix(key, "x-") {
+				continue
+			}
+			_, existing := findKey(root, key)
+			if existing == nil {
+				addNode(root, key, s.root.Content[i+1])
+				continue
+			}
+			if !nodesEqual(existing, s.root.Content[i+1]) {
+				owner := m.sources[0]
+				for _, o := range m.sources {
+					if k, _ := findKey(o.root, key); k != nil {
+						owner = o
+						break
+					}
+				}
+				m.conflict(ExtensionConflict, s, owner, true, []string{key}, []string{key},
+					"extension is defined differently, the extension of %s is kept", owner.name)
+			}
+		}
+	}
+}
+
+// reload will render the merged document and create and build a new document from the rendered bytes.
+func (m *merger) reload(root *yaml.Node, result *Result) (*Result, error) {
+	first := m.sources[0].info
+	indent := first.OriginalIndentation
+	var err error
+	if result.Bytes, err = json.RenderNode(root, first.SpecFileType == datamodel.JSONFileType, indent); err != nil {
+		return result, fmt.Errorf("unable to render merged document: %w", err)
+	}
+	config := m.config.DocumentConfiguration
+	if config == nil {
+		config = datamodel.NewDocumentConfiguration()
+	}
+	doc, err := libopenapi.NewDocumentWithConfiguration(result.Bytes, config)
+	if err != nil {
+		return result, fmt.Errorf("unable to reload merged document: %w", err)
+	}
+	result.Document = doc
+	model, errs := doc.BuildV3Model()
+	result.Model = model
+	if len(errs) > 0 {
+		return result, fmt.Errorf("merged document built with errors: %w", joinErrors(errs))
+	}
+	return result, nil
+}
+
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+// operations returns every operation in the paths, webhooks and callbacks of a rendered document.
+func operations(root *yaml.Node) []*yaml.Node {
+	var ops []*yaml.Node
+	var collect func(items *yaml.Node)
+	collect = func(items *yaml.Node) {
+		if !utils.IsNodeMap(items) {
+			return
+		}
+		for i := 1; i < len(items.Content); i += 2 {
+			item := items.Content[i]
+			if !utils.IsNodeMap(item) {
+				continue
+			}
+			for j := 0; j < len(item.Content)-1; j += 2 {
+				if httpMethods[item.Content[j].Value] && utils.IsNodeMap(item.Content[j+1]) {
+					ops = append(ops, item.Content[j+1])
+					if _, callbacks := findKey(item.Content[j+1], "callbacks"); utils.IsNodeMap(callbacks) {
+						for k := 1; k < len(callbacks.Content); k += 2 {
+							collect(callbacks.Content[k])
+						}
+					}
+				}
+			}
+		}
+	}
+	_, paths := findKey(root, "paths")
+	collect(paths)
+	_, webhooks := findKey(root, "webhooks")
+	collect(webhooks)
+	if _, comps := findKey(root, "components"); comps != nil {
+		if _, callbacks := findKey(comps, "callbacks"); utils.IsNodeMap(callbacks) {
+			for k := 1; k < len(callbacks.Content); k += 2 {
+				collect(callbacks.Content[k])
+			}
+		}
+	}
+	return ops
+}
+
+func pathItemHash(s *source, section, key string) *[32]byte {
+	var item *v3high.PathItem
+	if section == "webhooks" {
+		item = s.model.Webhooks.GetOrZero(key)
+	} else if s.model.Paths != nil {
+		item = s.model.Paths.PathItems.GetOrZero(key)
+	}
+	if item == nil || item.GoLow() == nil {
+		return nil
+	}
+	h := item.GoLow().Hash()
+	return &h
+}
+
+func operationHash(s *source, section, key, method string) *[32]byte {
+	var item *v3high.PathItem
+	if section == "webhooks" {
+		item = s.model.Webhooks.GetOrZero(key)
+	} else if s.model.Paths != nil {
+		item = s.model.Paths.PathItems.GetOrZero(key)
+	}
+	if item == nil {
+		return nil
+	}
+	op := item.GetOperations().GetOrZero(method)
+	if op == nil || op.GoLow() == nil {
+		return nil
+	}
+	h := op.GoLow().Hash()
+	return &h
+}
+
+// identical checks two objects are structurally identical. The low-level hashes are compared when both are known,
+// references are not resolved by the hash, so the references inside both objects must also be the same. If either
+// hash is unknown, the rendered objects are compared.
+func identical(a, b *[32]byte, nodeA, nodeB *yaml.Node) bool {
+	if a == nil || b == nil {
+		return nodesEqual(nodeA, nodeB)
+	}
+	return *a == *b && equalStrings(collectRefs(nodeA, nil), collectRefs(nodeB, nil))
+}
+
+func majorMinor(version string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ".")
+}
+
+func joinErrors[E error](errs []E) error {
+	var msgs []string
+	for _, e := range errs {
+		if error(e) != nil {
+			msgs = append(msgs, e.Error())
+		}
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
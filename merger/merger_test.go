@@ -0,0 +1,269 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package merger
+
+import (
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var petsSpec = `openapi: 3.1.0
+info:
+  title: pet store
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+security:
+  - apiKey: []
+tags:
+  - name: pets
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: string
+    Error:
+      type: string
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Api-Key
+x-gateway: pets
+`
+
+var usersSpec = `openapi: 3.1.0
+info:
+  title: user service
+  version: 2.0.0
+servers:
+  - url: https://api.example.com
+  - url: https://users.example.com
+security:
+  - apiKey: []
+tags:
+  - name: users
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        "200":
+          description: users
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: integer
+    Error:
+      type: string
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: query
+      name: key
+`
+
+func newDocs(t *testing.T, specs ...string) []libopenapi.Document {
+	var docs []libopenapi.Document
+	for _, spec := range specs {
+		doc, err := libopenapi.NewDocument([]byte(spec))
+		require.NoError(t, err)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func sources(t *testing.T, specs ...string) []*Source {
+	var s []*Source
+	for i, doc := range newDocs(t, specs...) {
+		s = append(s, &Source{Document: doc, Name: []string{"pets.yaml", "users.yaml", "orders.yaml"}[i]})
+	}
+	return s
+}
+
+func TestMerge_ComponentError(t *testing.T) {
+	result, err := Merge(sources(t, petsSpec, usersSpec), nil)
+	require.Error(t, err)
+	assert.Nil(t, result.Model)
+
+	require.Len(t, result.Conflicts, 2)
+	owner := result.Conflicts[0]
+	assert.Equal(t, ComponentConflict, owner.Type)
+	assert.Equal(t, "/components/schemas/Owner", owner.Path)
+	assert.False(t, owner.Resolved)
+	assert.Equal(t, "users.yaml", owner.Source)
+	assert.Equal(t, 30, owner.Line)
+	assert.Equal(t, "pets.yaml", owner.ExistingSource)
+	assert.Equal(t, 29, owner.ExistingLine)
+	assert.Equal(t, "users.yaml:30:5: /components/schemas/Owner: component is not identical to the component "+
+		"of pets.yaml (already defined in pets.yaml:29:5)", owner.Error())
+	assert.Equal(t, "/components/securitySchemes/apiKey", result.Conflicts[1].Path)
+	assert.Contains(t, err.Error(), "unable to merge, 2 conflicts could not be resolved")
+}
+
+func TestMerge_ComponentRename(t *testing.T) {
+	result, err := Merge(sources(t, petsSpec, usersSpec), &MergeConfig{Components: ComponentRename})
+	require.NoError(t, err)
+	model := result.Model.Model
+
+	// Owner is different, so Pet (which references Owner) is too, Error is identical and is merged.
+	schemas := model.Components.Schemas
+	assert.Equal(t, []string{"Pet", "Owner", "Error", "Owner_2", "Pet_2"}, keys(schemas))
+	assert.Equal(t, "#/components/schemas/Owner_2",
+		schemas.GetOrZero("Pet_2").Schema().Properties.GetOrZero("owner").GetReference())
+	users := model.Paths.PathItems.GetOrZero("/users").Get
+	assert.Equal(t, "#/components/schemas/Pet_2",
+		users.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Schema.GetReference())
+	pets := model.Paths.PathItems.GetOrZero("/pets").Get
+	assert.Equal(t, "#/components/schemas/Pet",
+		pets.Responses.Codes.GetOrZero("200").Content.GetOrZero("application/json").Schema.GetReference())
+
+	// the security schemes are different, so the top level security requirements are moved into the operations.
+	assert.Equal(t, []string{"apiKey", "apiKey_2"}, keys(model.Components.SecuritySchemes))
+	assert.Empty(t, model.Security)
+	assert.Equal(t, []string{"apiKey"}, keys(pets.Security[0].Requirements))
+	assert.Equal(t, []string{"apiKey_2"}, keys(users.Security[0].Requirements))
+
+	assert.Len(t, model.Servers, 2)
+	assert.Len(t, model.Tags, 2)
+	assert.Equal(t, "pet store", model.Info.Title)
+	assert.Equal(t, "pets", model.Extensions.GetOrZero("x-gateway").Value)
+	assert.Len(t, result.Conflicts, 3)
+	for _, c := range result.Conflicts {
+		assert.True(t, c.Resolved)
+	}
+}
+
+func TestMerge_ComponentPrefix(t *testing.T) {
+	src := sources(t, petsSpec, usersSpec)
+	src[1].Prefix = "Users"
+	result, err := Merge(src, &MergeConfig{
+		Components: ComponentPrefix,
+		Info:       &base.Info{Title: "gateway", Version: "3.0.0"},
+	})
+	require.NoError(t, err)
+	model := result.Model.Model
+	assert.Equal(t, []string{"Pet", "Owner", "Error", "UsersOwner", "UsersPet"}, keys(model.Components.Schemas))
+	assert.Equal(t, []string{"apiKey", "UsersapiKey"}, keys(model.Components.SecuritySchemes))
+	assert.Equal(t, "gateway", model.Info.Title)
+
+	// without a prefix, the title of the document is used.
+	result, err = Merge(sources(t, petsSpec, usersSpec), &MergeConfig{Components: ComponentPrefix})
+	require.NoError(t, err)
+	assert.Contains(t, keys(result.Model.Model.Components.Schemas), "UserServiceOwner")
+}
+
+func TestMerge_PathStrategies(t *testing.T) {
+	first := `openapi: 3.1.0
+info:
+  title: first
+  version: 1.0.0
+paths:
+  /pets:
+    summary: pets
+    get:
+      description: list pets
+      responses:
+        "200":
+          description: ok
+`
+	second := `openapi: 3.1.0
+info:
+  title: second
+  version: 1.0.0
+paths:
+  /pets:
+    summary: all the pets
+    post:
+      description: add a pet
+      responses:
+        "201":
+          description: created
+`
+	_, err := Merge(sources(t, first, second), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "users.yaml:6:3: /paths/~1pets: defined differently (already defined in pets.yaml:6:3)")
+
+	result, err := Merge(sources(t, first, second), &MergeConfig{Paths: PathKeepFirst})
+	require.NoError(t, err)
+	pets := result.Model.Model.Paths.PathItems.GetOrZero("/pets")
+	assert.NotNil(t, pets.Get)
+	assert.Nil(t, pets.Post)
+
+	result, err = Merge(sources(t, first, second), &MergeConfig{Paths: PathKeepLast})
+	require.NoError(t, err)
+	pets = result.Model.Model.Paths.PathItems.GetOrZero("/pets")
+	assert.Nil(t, pets.Get)
+	assert.NotNil(t, pets.Post)
+
+	result, err = Merge(sources(t, first, second), &MergeConfig{Paths: PathMergeOperations})
+	require.NoError(t, err)
+	pets = result.Model.Model.Paths.PathItems.GetOrZero("/pets")
+	assert.Equal(t, "list pets", pets.Get.Description)
+	assert.Equal(t, "add a pet", pets.Post.Description)
+	assert.Equal(t, "pets", pets.Summary)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "/paths/~1pets/summary", result.Conflicts[0].Path)
+	assert.True(t, result.Conflicts[0].Resolved)
+
+	// the same operation, defined differently, cannot be merged.
+	_, err = Merge(sources(t, first, first, second), &MergeConfig{Paths: PathMergeOperations})
+	require.NoError(t, err)
+	_, err = Merge(sources(t, first, second, second[:len(second)-len("created\n")]+"made\n"),
+		&MergeConfig{Paths: PathMergeOperations})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "orders.yaml:8:5: /paths/~1pets/post: operation defined differently (already defined in users.yaml:8:5)")
+}
+
+func TestMergeDocuments(t *testing.T) {
+	result, err := MergeDocuments(newDocs(t, petsSpec, petsSpec), nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, []string{"apiKey"}, keys(result.Model.Model.Security[0].Requirements))
+	assert.Equal(t, 1, result.Model.Model.Paths.PathItems.Len())
+
+	_, err = MergeDocuments(nil, nil)
+	assert.Equal(t, "unable to merge, no documents supplied", err.Error())
+
+	swagger := newDocs(t, `swagger: "2.0"`)
+	_, err = MergeDocuments(swagger, nil)
+	assert.Equal(t, "unable to merge, document 1 is not an OpenAPI 3+ document", err.Error())
+}
+
+func keys[V any](m *orderedmap.Map[string, V]) []string {
+	var k []string
+	for pair := orderedmap.First(m); pair != nil; pair = pair.Next() {
+		k = append(k, pair.Key())
+	}
+	return k
+}
@@ -0,0 +1,314 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package overlay provides support for the OpenAPI Overlay Specification 1.0. An Overlay is a document that
+// contains an ordered list of actions, each action targets nodes in an OpenAPI document using JSONPath and
+// either updates (merges) or removes them.
+//
+//   - https://github.com/OAI/Overlay-Specification/blob/main/versions/1.0.0.md
+//
+// Overlays allow vendor supplied specifications to be patched in a repeatable way, without hand-editing them.
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/json"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay represents an OpenAPI Overlay document.
+type Overlay struct {
+	// Overlay is the version of the Overlay specification the document uses, 'overlay' in the document.
+	Overlay string `json:"overlay,omitempty" yaml:"overlay,omitempty"`
+
+	// Info provides metadata about the Overlay.
+	Info *Info `json:"info,omitempty" yaml:"info,omitempty"`
+
+	// Extends is a URL to the target document (such as an OpenAPI document) this overlay applies to.
+	Extends string `json:"extends,omitempty" yaml:"extends,omitempty"`
+
+	// Actions is an ordered list of actions to be applied to the target document.
+	Actions []*Action `json:"actions,omitempty" yaml:"actions,omitempty"`
+
+	// Extensions contains all custom extensions defined for the overlay.
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+
+	// RootNode is the root of the parsed Overlay document.
+	RootNode *yaml.Node `json:"-" yaml:"-"`
+}
+
+// Info represents the metadata of an Overlay document.
+type Info struct {
+	Title      string                              `json:"title,omitempty" yaml:"title,omitempty"`
+	Version    string                              `json:"version,omitempty" yaml:"version,omitempty"`
+	Extensions *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+}
+
+// Action represents a single Overlay action. The Target is a JSONPath expression that selects the nodes the
+// action applies to. If Remove is true, the selected nodes are removed from the document. Otherwise, the Update
+// node is merged into every selected node.
+type Action struct {
+	Target      string                              `json:"target,omitempty" yaml:"target,omitempty"`
+	Description string                              `json:"description,omitempty" yaml:"description,omitempty"`
+	Update      *yaml.Node                          `json:"-" yaml:"-"`
+	Remove      bool                                `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Extensions  *orderedmap.Map[string, *yaml.Node] `json:"-" yaml:"-"`
+
+	// Node is the node of the action in the Overlay document, used to report the line and column of problems.
+	Node *yaml.Node `json:"-" yaml:"-"`
+}
+
+// Warning is returned when an action could be applied, but did not behave as expected. For example, when the
+// target of an action does not select any nodes.
+type Warning struct {
+	Action  *Action
+	Message string
+}
+
+func (w *Warning) Error() string {
+	if w.Action != nil && w.Action.Node != nil {
+		return fmt.Sprintf("overlay action '%s' (line %d, column %d): %s", w.Action.Target,
+			w.Action.Node.Line, w.Action.Node.Column, w.Message)
+	}
+	return w.Message
+}
+
+// Result contains the outcome of applying an Overlay to a Document.
+type Result struct {
+	// Bytes are the rendered bytes of the document after every action has been applied.
+	Bytes []byte
+
+	// Document is the new Document, created from the rendered Bytes using the configuration of the original.
+	Document libopenapi.Document
+
+	// Warnings contains any actions that did not behave as expected.
+	Warnings []*Warning
+}
+
+// ParseOverlay will parse an Overlay document from a YAML or JSON byte slice. An error is returned if the
+// document cannot be parsed, or if it is not a valid Overlay 1.x document.
+func ParseOverlay(overlayBytes []byte) (*Overlay, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(overlayBytes, &root); err != nil {
+		return nil, fmt.Errorf("unable to parse overlay: %w", err)
+	}
+	if len(root.Content) == 0 || !utils.IsNodeMap(root.Content[0]) {
+		return nil, errors.New("unable to parse overlay: document is not an object")
+	}
+	var o Overlay
+	if err := root.Content[0].Decode(&o); err != nil {
+		return nil, fmt.Errorf("unable to parse overlay: %w", err)
+	}
+	o.RootNode = &root
+	o.Extensions = extractExtensions(root.Content[0])
+
+	_, infoNode := utils.FindKeyNodeTop("info", root.Content[0].Content)
+	if o.Info != nil && infoNode != nil {
+		o.Info.Extensions = extractExtensions(infoNode)
+	}
+	_, actionsNode := utils.FindKeyNodeTop("actions", root.Content[0].Content)
+	if actionsNode != nil && len(actionsNode.Content) == len(o.Actions) {
+		for i := range o.Actions {
+			o.Actions[i].Node = actionsNode.Content[i]
+			o.Actions[i].Extensions = extractExtensions(actionsNode.Content[i])
+			_, o.Actions[i].Update = utils.FindKeyNodeTop("update", actionsNode.Content[i].Content)
+		}
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (o *Overlay) validate() error {
+	var errs []error
+	if o.Overlay == "" {
+		errs = append(errs, errors.New("overlay version is missing, the 'overlay' property is required"))
+	} else if !strings.HasPrefix(o.Overlay, "1.") {
+		errs = append(errs, fmt.Errorf("overlay version '%s' is not supported, only 1.x is supported", o.Overlay))
+	}
+	if o.Info == nil || o.Info.Title == "" || o.Info.Version == "" {
+		errs = append(errs, errors.New("overlay info is invalid, 'info' requires a 'title' and a 'version'"))
+	}
+	if len(o.Actions) == 0 {
+		errs = append(errs, errors.New("overlay has no actions, at least one action is required"))
+	}
+	for i, a := range o.Actions {
+		if a == nil || a.Target == "" {
+			errs = append(errs, fmt.Errorf("overlay action %d has no target", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply will apply every action of the Overlay (in order) to a copy of the root node of the Document. The
+// original Document is never modified. The result is rendered (in the same format as the original document) and
+// then re-loaded as a new Document, in the same way as libopenapi.Document.RenderAndReload.
+func (o *Overlay) Apply(doc libopenapi.Document) (*Result, error) {
+	if doc == nil || doc.GetSpecInfo() == nil || doc.GetSpecInfo().RootNode == nil {
+		return nil, errors.New("unable to apply overlay, document has not been initialized")
+	}
+	info := doc.GetSpecInfo()
+	root, warnings, err := o.ApplyToNode(info.RootNode)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := json.RenderNode(root, info.SpecFileType == datamodel.JSONFileType, info.OriginalIndentation)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render document after applying overlay: %w", err)
+	}
+	newDoc, err := libopenapi.NewDocumentWithConfiguration(rendered, doc.GetConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("unable to reload document after applying overlay: %w", err)
+	}
+	return &Result{Bytes: rendered, Document: newDoc, Warnings: warnings}, nil
+}
+
+// ApplyToNode will apply every action of the Overlay (in order) to a copy of the supplied root node, returning
+// the modified copy. Any actions that did not select any nodes are returned as warnings. An error is returned
+// if a target is not a valid JSONPath expression.
+func (o *Overlay) ApplyToNode(root *yaml.Node) (*yaml.Node, []*Warning, error) {
+	if root == nil {
+		return nil, nil, errors.New("unable to apply overlay, root node is nil")
+	}
+	root = utils.CopyNode(root)
+	var warnings []*Warning
+	for _, action := range o.Actions {
+		found, err := utils.FindNodesWithoutDeserializing(root, action.Target)
+		if err != nil {
+			return nil, warnings, fmt.Errorf("overlay action target '%s' is not a valid JSONPath expression: %w",
+				action.Target, err)
+		}
+		if len(found) == 0 {
+			warnings = append(warnings, &Warning{Action: action, Message: "target did not select any nodes"})
+			continue
+		}
+		if action.Remove {
+			parents := mapParents(root)
+			for _, n := range found {
+				if !remove(parents[n], n) {
+					warnings = append(warnings, &Warning{Action: action, Message: "the document root cannot be removed"})
+				}
+			}
+			continue
+		}
+		if action.Update == nil {
+			warnings = append(warnings, &Warning{Action: action, Message: "action has no update and is not a removal"})
+			continue
+		}
+		for _, n := range found {
+			if err = merge(n, action.Update); err != nil {
+				warnings = append(warnings, &Warning{Action: action, Message: err.Error()})
+			}
+		}
+	}
+	return root, warnings, nil
+}
+
+// ApplyOverlay is a convenience function that parses an Overlay and applies it to a Document.
+func ApplyOverlay(doc libopenapi.Document, overlayBytes []byte) (*Result, error) {
+	o, err := ParseOverlay(overlayBytes)
+	if err != nil {
+		return nil, err
+	}
+	return o.Apply(doc)
+}
+
+// merge will merge the update node into the target node. Objects are merged recursively, values in arrays are
+// appended to the target array and any other values replace the target.
+func merge(target, update *yaml.Node) error {
+	switch {
+	case utils.IsNodeMap(target) && utils.IsNodeMap(update):
+		for i := 0; i < len(update.Content)-1; i += 2 {
+			key, value := update.Content[i], update.Content[i+1]
+			found := false
+			for j := 0; j < len(target.Content)-1; j += 2 {
+				if target.Content[j].Value == key.Value {
+					found = true
+					if (utils.IsNodeMap(target.Content[j+1]) && utils.IsNodeMap(value)) ||
+						(utils.IsNodeArray(target.Content[j+1]) && utils.IsNodeArray(value)) {
+						if err := merge(target.Content[j+1], value); err != nil {
+							return err
+						}
+					} else {
+						target.Content[j+1] = utils.CopyNode(value)
+					}
+					break
+				}
+			}
+			if !found {
+				target.Content = append(target.Content, utils.CopyNode(key), utils.CopyNode(value))
+			}
+		}
+	case utils.IsNodeArray(target):
+		if utils.IsNodeArray(update) {
+			for _, v := range update.Content {
+				target.Content = append(target.Content, utils.CopyNode(v))
+			}
+		} else {
+			target.Content = append(target.Content, utils.CopyNode(update))
+		}
+	case utils.IsNodeMap(target):
+		return fmt.Errorf("update is not an object and cannot be merged into an object (line %d, column %d)",
+			target.Line, target.Column)
+	default:
+		*target = *utils.CopyNode(update)
+	}
+	return nil
+}
+
+// remove will remove the node from its parent, returns false if the node has no parent.
+func remove(parent, node *yaml.Node) bool {
+	if parent == nil {
+		return false
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(parent.Content); i += 2 {
+			if parent.Content[i] == node {
+				parent.Content = append(parent.Content[:i-1], parent.Content[i+1:]...)
+				return true
+			}
+		}
+	case yaml.SequenceNode:
+		for i := range parent.Content {
+			if parent.Content[i] == node {
+				parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapParents maps every node in the tree to its parent node (document nodes have no parent).
+func mapParents(root *yaml.Node) map[*yaml.Node]*yaml.Node {
+	parents := make(map[*yaml.Node]*yaml.Node)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		for _, c := range n.Content {
+			if n.Kind != yaml.DocumentNode {
+				parents[c] = n
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return parents
+}
+
+func extractExtensions(node *yaml.Node) *orderedmap.Map[string, *yaml.Node] {
+	ext := orderedmap.New[string, *yaml.Node]()
+	for _, e := range utils.FindExtensionNodes(node.Content) {
+		ext.Set(e.Key.Value, e.Value)
+	}
+	return ext
+}
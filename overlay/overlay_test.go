@@ -0,0 +1,153 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package overlay
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOverlay = `overlay: 1.0.0
+info:
+  title: Burger shop overlay
+  version: 1.0.0
+  x-team: burgers
+actions:
+  - target: $.info
+    description: Rename the API
+    update:
+      title: The Burger Shop
+      x-overlaid: true
+  - target: $.tags
+    update:
+      name: Extras
+  - target: $.paths['/burgers'].post.responses
+    update:
+      "418":
+        description: I'm a teapot
+  - target: $.paths['/burgers/{burgerId}/dressings']
+    remove: true
+  - target: $.components.parameters.*[?(@.name == 'burgerId')]
+    update:
+      description: The ID of the burger
+  - target: $.components.schemas.Nothing
+    remove: true
+`
+
+func loadBurgerShop(t *testing.T) libopenapi.Document {
+	spec, err := os.ReadFile("../test_specs/burgershop.openapi.yaml")
+	require.NoError(t, err)
+	doc, err := libopenapi.NewDocument(spec)
+	require.NoError(t, err)
+	return doc
+}
+
+func TestParseOverlay(t *testing.T) {
+	o, err := ParseOverlay([]byte(testOverlay))
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", o.Overlay)
+	assert.Equal(t, "Burger shop overlay", o.Info.Title)
+	assert.Equal(t, "burgers", o.Info.Extensions.GetOrZero("x-team").Value)
+	require.Len(t, o.Actions, 6)
+	assert.Equal(t, "Rename the API", o.Actions[0].Description)
+	assert.True(t, o.Actions[3].Remove)
+	assert.Equal(t, 19, o.Actions[3].Node.Line)
+}
+
+func TestParseOverlay_Invalid(t *testing.T) {
+	_, err := ParseOverlay([]byte(`overlay: 2.0.0
+info:
+  title: bad
+actions:
+  - update: {}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'2.0.0' is not supported")
+	assert.Contains(t, err.Error(), "requires a 'title' and a 'version'")
+	assert.Contains(t, err.Error(), "action 0 has no target")
+
+	_, err = ParseOverlay([]byte(`- not an object`))
+	assert.Error(t, err)
+
+	_, err = ParseOverlay([]byte(`overlay: [`))
+	assert.Error(t, err)
+}
+
+func TestOverlay_Apply(t *testing.T) {
+	doc := loadBurgerShop(t)
+	original := string(*doc.GetSpecInfo().SpecBytes)
+
+	result, err := ApplyOverlay(doc, []byte(testOverlay))
+	require.NoError(t, err)
+
+	// the final action targets a schema that does not exist.
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0].Error(), "did not select any nodes")
+	assert.Contains(t, result.Warnings[0].Error(), "line 24")
+
+	m, errs := result.Document.BuildV3Model()
+	require.Empty(t, errs)
+
+	assert.Equal(t, "The Burger Shop", m.Model.Info.Title)
+	assert.Equal(t, "true", m.Model.Info.Extensions.GetOrZero("x-overlaid").Value)
+	assert.NotEmpty(t, m.Model.Info.Description)
+	assert.Equal(t, "Extras", m.Model.Tags[len(m.Model.Tags)-1].Name)
+
+	burgers := m.Model.Paths.PathItems.GetOrZero("/burgers")
+	assert.Equal(t, "I'm a teapot", burgers.Post.Responses.Codes.GetOrZero("418").Description)
+	assert.NotNil(t, burgers.Post.Responses.Codes.GetOrZero("200"))
+	assert.Nil(t, m.Model.Paths.PathItems.GetOrZero("/burgers/{burgerId}/dressings"))
+
+	assert.Equal(t, "The ID of the burger", m.Model.Components.Parameters.GetOrZero("BurgerId").Description)
+
+	// the original document is never modified.
+	assert.Equal(t, original, string(*doc.GetSpecInfo().SpecBytes))
+	_, info := utils.FindKeyNodeTop("info", doc.GetSpecInfo().RootNode.Content[0].Content)
+	_, title := utils.FindKeyNodeTop("title", info.Content)
+	assert.NotEqual(t, "The Burger Shop", title.Value)
+	assert.True(t, strings.Contains(string(result.Bytes), "title: The Burger Shop"))
+}
+
+func TestOverlay_Apply_JSON(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`{"openapi": "3.1.0", "info": {"title": "json", "version": "1"},
+"tags": [{"name": "a"}, {"name": "b"}]}`))
+	require.NoError(t, err)
+
+	o, err := ParseOverlay([]byte(`{"overlay": "1.0.0", "info": {"title": "json", "version": "1"},
+"actions": [{"target": "$.tags[?(@.name == 'a')]", "remove": true}, {"target": "$.info.title", "update": "updated"}]}`))
+	require.NoError(t, err)
+
+	result, err := o.Apply(doc)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	assert.True(t, strings.HasPrefix(string(result.Bytes), "{"))
+
+	m, errs := result.Document.BuildV3Model()
+	require.Empty(t, errs)
+	assert.Equal(t, "updated", m.Model.Info.Title)
+	require.Len(t, m.Model.Tags, 1)
+	assert.Equal(t, "b", m.Model.Tags[0].Name)
+}
+
+func TestOverlay_ApplyToNode_Errors(t *testing.T) {
+	o := &Overlay{Actions: []*Action{{Target: "$.info[", Update: nil}}}
+	_, _, err := o.ApplyToNode(loadBurgerShop(t).GetSpecInfo().RootNode)
+	assert.Error(t, err)
+
+	_, _, err = o.ApplyToNode(nil)
+	assert.Error(t, err)
+
+	_, err = o.Apply(nil)
+	assert.Error(t, err)
+
+	o = &Overlay{Actions: []*Action{{Target: "$"}, {Target: "$.info", Remove: false}}}
+	_, warnings, err := o.ApplyToNode(loadBurgerShop(t).GetSpecInfo().RootNode)
+	require.NoError(t, err)
+	assert.Len(t, warnings, 2)
+}
@@ -35,9 +35,10 @@ const (
 // The mock generator will attempt to generate a mock from a *base.Schema pointer.
 // Use NewMockGenerator or NewMockGeneratorWithDictionary to create a new mock generator.
 type MockGenerator struct {
-	renderer *SchemaRenderer
-	mockType MockType
-	pretty   bool
+	renderer         *SchemaRenderer
+	mockType         MockType
+	pretty           bool
+	validateExamples bool
 }
 
 // NewMockGeneratorWithDictionary creates a new mock generator using a custom dictionary. This is useful if you want to
@@ -61,6 +62,13 @@ func (mg *MockGenerator) SetPretty() {
 	mg.pretty = true
 }
 
+// SetValidateExamples makes the mock generator check examples against the schema of the mockable struct before using
+// them. Examples that do not match the schema are skipped, and if no example matches, the mock is generated from the
+// schema. By default, examples are used as they are.
+func (mg *MockGenerator) SetValidateExamples() {
+	mg.validateExamples = true
+}
+
 // GenerateMock generates a mock for a given high-level mockable struct. The mockable struct must contain the following fields:
 // Example: any type, this is the default example to use if no examples are present.
 // Examples: *orderedmap.Map[string, *base.Example], this is a map of examples keyed by name.
@@ -93,6 +101,28 @@ func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 			"fields (%s, %s)", fieldCount, Example, Examples)
 	}
 
+	// check if this is a SchemaProxy, if not, then see if it has a Schema, if not, then we can't generate a mock.
+	var schemaValue *highbase.Schema
+	switch reflect.TypeOf(mock) {
+	case reflect.TypeOf(&highbase.Schema{}):
+		schemaValue = mock.(*highbase.Schema)
+	default:
+		sf := v.FieldByName(Schema)
+		if !sf.IsValid() {
+			break
+		}
+		if sv, ok := sf.Interface().(*highbase.Schema); ok {
+			if sv != nil {
+				schemaValue = sv
+			}
+		}
+		if sv, ok := sf.Interface().(*highbase.SchemaProxy); ok {
+			if sv != nil {
+				schemaValue = sv.Schema()
+			}
+		}
+	}
+
 	// if the value has an example, try and render it out as is.
 	f := v.FieldByName(Example)
 	if !f.IsNil() {
@@ -105,7 +135,7 @@ func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 				ex = nil
 			}
 		}
-		if ex != nil {
+		if ex != nil && mg.usable(schemaValue, ex) {
 			// try and serialize the example value
 			return mg.renderMock(ex), nil
 		}
@@ -117,12 +147,12 @@ func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 	if examplesValue != nil && !examples.IsNil() {
 
 		// cast examples to *orderedmap.Map[string, *highbase.Example]
-		examplesMap := examplesValue.(*orderedmap.Map[string, *highbase.Example])
+		examplesMap, _ := examplesValue.(*orderedmap.Map[string, *highbase.Example])
 
 		// if the name is not empty, try and find the example by name
 		for pair := orderedmap.First(examplesMap); pair != nil; pair = pair.Next() {
 			k, exp := pair.Key(), pair.Value()
-			if k == name {
+			if k == name && mg.usable(schemaValue, exp.Value) {
 				return mg.renderMock(exp.Value), nil
 			}
 		}
@@ -130,29 +160,13 @@ func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 		// if the name is empty, just return the first example
 		for pair := orderedmap.First(examplesMap); pair != nil; pair = pair.Next() {
 			exp := pair.Value()
-			return mg.renderMock(exp.Value), nil
-		}
-	}
-
-	// no examples? no problem, we can try and generate a mock from the schema.
-	// check if this is a SchemaProxy, if not, then see if it has a Schema, if not, then we can't generate a mock.
-	var schemaValue *highbase.Schema
-	switch reflect.TypeOf(mock) {
-	case reflect.TypeOf(&highbase.Schema{}):
-		schemaValue = mock.(*highbase.Schema)
-	default:
-		if sv, ok := v.FieldByName(Schema).Interface().(*highbase.Schema); ok {
-			if sv != nil {
-				schemaValue = sv
-			}
-		}
-		if sv, ok := v.FieldByName(Schema).Interface().(*highbase.SchemaProxy); ok {
-			if sv != nil {
-				schemaValue = sv.Schema()
+			if mg.usable(schemaValue, exp.Value) {
+				return mg.renderMock(exp.Value), nil
 			}
 		}
 	}
 
+	// no examples? no problem, we can try and generate a mock from the schema.
 	if schemaValue != nil {
 		renderMap := mg.renderer.RenderSchema(schemaValue)
 		if renderMap != nil {
@@ -162,6 +176,18 @@ func (mg *MockGenerator) GenerateMock(mock any, name string) ([]byte, error) {
 	return nil, nil
 }
 
+// usable reports if an example can be used for a mock, examples are only checked when validateExamples is set.
+func (mg *MockGenerator) usable(schema *highbase.Schema, example any) bool {
+	if !mg.validateExamples || schema == nil {
+		return true
+	}
+	if node, ok := example.(*yaml.Node); ok {
+		return len(schema.ValidateNode(node, nil)) == 0
+	}
+	errs, err := schema.Validate(example)
+	return err == nil && len(errs) == 0
+}
+
 func (mg *MockGenerator) renderMock(v any) []byte {
 	switch {
 	case mg.mockType == YAML:
@@ -167,6 +167,28 @@ func TestMockGenerator_GenerateJSONMock_MultiExamples_NoName_JSON(t *testing.T)
 	assert.NotEmpty(t, string(mock))
 }
 
+func TestMockGenerator_GenerateJSONMock_ValidateExamples(t *testing.T) {
+	fakeExample := map[string]any{
+		"fish-and-chips": "cod-and-chips-twice",
+	}
+	fake := createFakeMock(simpleFakeMockSchema, nil, fakeExample)
+	mg := NewMockGenerator(JSON)
+	mg.SetValidateExamples()
+
+	// the example does not match the schema, so the mock comes from the schema.
+	mock, err := mg.GenerateMock(fake, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "magic-herbs", string(mock))
+
+	fake = createFakeMock(simpleFakeMockSchema, map[string]any{
+		"wrong": "cod-and-chips-twice",
+		"right": "magic-herbs",
+	}, nil)
+	mock, err = mg.GenerateMock(fake, "wrong")
+	assert.NoError(t, err)
+	assert.Equal(t, "magic-herbs", string(mock))
+}
+
 func TestMockGenerator_GenerateJSONMock_MultiExamples_JSON(t *testing.T) {
 	fakeExample := map[string]any{
 		"exampleOne": map[string]any{
@@ -4,6 +4,8 @@
 package utils
 
 import (
+	"strings"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -75,3 +77,28 @@ func CreateFloatNode(str string) *yaml.Node {
 	}
 	return n
 }
+
+// CopyNode creates a deep copy of a node, so the copy can be changed without changing the original.
+func CopyNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	c := *node
+	if node.Content != nil {
+		c.Content = make([]*yaml.Node, len(node.Content))
+		for i := range node.Content {
+			c.Content[i] = CopyNode(node.Content[i])
+		}
+	}
+	return &c
+}
+
+// EscapePointerSegment escapes a segment of a JSON pointer, '~' becomes '~0' and '/' becomes '~1'.
+func EscapePointerSegment(seg string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(seg, "~", "~0"), "/", "~1")
+}
+
+// UnescapePointerSegment reverses EscapePointerSegment.
+func UnescapePointerSegment(seg string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestCreateBoolNode(t *testing.T) {
@@ -54,3 +55,18 @@ func TestCreateYamlNode(t *testing.T) {
 	assert.Equal(t, "!!str", y.Tag)
 	assert.Equal(t, "foo", y.Value)
 }
+
+func TestCopyNode(t *testing.T) {
+	n := CreateEmptyMapNode()
+	n.Content = []*yaml.Node{CreateStringNode("name"), CreateStringNode("pizza")}
+	c := CopyNode(n)
+	c.Content[1].Value = "burger"
+	assert.Equal(t, "pizza", n.Content[1].Value)
+	assert.Equal(t, "burger", c.Content[1].Value)
+	assert.Nil(t, CopyNode(nil))
+}
+
+func TestEscapePointerSegment(t *testing.T) {
+	assert.Equal(t, "~1pets~1{id}~0x", EscapePointerSegment("/pets/{id}~x"))
+	assert.Equal(t, "/pets/{id}~x", UnescapePointerSegment("~1pets~1{id}~0x"))
+}
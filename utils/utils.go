@@ -14,6 +14,9 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// Arazzo is used by all Arazzo workflow docs.
+const Arazzo = "arazzo"
+
 type Case int8
 
 const (
@@ -0,0 +1,248 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package libopenapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/pb33f/libopenapi/index"
+	"github.com/pb33f/libopenapi/jsonschema"
+	"github.com/pb33f/libopenapi/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// draft04URI is the id of the draft-04 meta-schema, referenced by the Swagger schema.
+const draft04URI = "http://json-schema.org/draft-04/schema"
+
+// metaSchemas holds every compiled OpenAPI schema, keyed by spec format. They are only compiled once.
+var metaSchemas sync.Map
+
+func (d *document) Validate() ([]*jsonschema.ValidationError, error) {
+	if d.info == nil || d.info.RootNode == nil {
+		return nil, errors.New("unable to validate document, no specification has been loaded")
+	}
+	format := d.info.SpecFormat
+	if format == datamodel.OAS3 && strings.HasPrefix(d.info.Version, "3.1") {
+		format = datamodel.OAS31
+	}
+	schema, err := metaSchema(format)
+	if err != nil {
+		return nil, err
+	}
+
+	// a rolodex only exists once a model has been built, and it's needed to load any referenced files.
+	if d.rolodex == nil && d.config != nil && (d.config.BasePath != "" || d.config.BaseURL != nil ||
+		d.config.AllowFileReferences || d.config.AllowRemoteReferences) {
+		switch d.info.SpecFormat {
+		case datamodel.OAS2:
+			_, _ = d.BuildV2Model()
+		default:
+			_, _ = d.BuildV3Model()
+		}
+	}
+
+	v := &referenceValidator{
+		rolodex: d.rolodex,
+		files:   make(map[string]*yaml.Node),
+		origins: make(map[*yaml.Node]string),
+	}
+	if d.rolodex != nil && d.rolodex.GetRootIndex() != nil {
+		v.root = d.rolodex.GetRootIndex().GetSpecAbsolutePath()
+	}
+	validationErrors := schema.ValidateWithOptions(d.info.RootNode, &jsonschema.ValidateOptions{
+		ResolveInstance: v.resolve,
+	})
+
+	// the same file can be referenced many times, which means the same problem can be found many times.
+	seen := make(map[string]bool)
+	var results []*jsonschema.ValidationError
+	for _, e := range validationErrors {
+		e.Location = v.origins[e.Node]
+		key := fmt.Sprintf("%s:%d:%d:%s", e.Location, e.Line, e.Column, e.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		results = append(results, e)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Location != results[j].Location {
+			return results[i].Location < results[j].Location
+		}
+		if results[i].Line != results[j].Line {
+			return results[i].Line < results[j].Line
+		}
+		return results[i].Column < results[j].Column
+	})
+	return results, nil
+}
+
+// metaSchema returns the compiled schema for a spec format.
+func metaSchema(format string) (*jsonschema.Schema, error) {
+	if s, ok := metaSchemas.Load(format); ok {
+		return s.(*jsonschema.Schema), nil
+	}
+	opts := &jsonschema.Options{}
+	var data string
+	switch format {
+	case datamodel.OAS2:
+		var draft04 yaml.Node
+		if err := yaml.Unmarshal([]byte(datamodel.JSONSchemaDraft04Data), &draft04); err != nil {
+			return nil, fmt.Errorf("unable to parse the draft-04 schema: %w", err)
+		}
+		opts.Draft = jsonschema.Draft4
+		opts.Resources = map[string]*yaml.Node{draft04URI: &draft04}
+		data = datamodel.OpenAPI2SchemaData
+	case datamodel.OAS3:
+		opts.Draft = jsonschema.Draft4
+		data = datamodel.OpenAPI3SchemaData
+	case datamodel.OAS31:
+		data = datamodel.OpenAPI31SchemaData
+	default:
+		return nil, fmt.Errorf("unable to validate document, the specification type '%s' is unknown", format)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		return nil, fmt.Errorf("unable to validate document, the %s schema cannot be parsed: %w", format, err)
+	}
+	if format == datamodel.OAS31 {
+		fixLinkServer(&root)
+	}
+	s, err := jsonschema.Compile(&root, opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to validate document, the %s schema cannot be compiled: %w", format, err)
+	}
+	metaSchemas.Store(format, s)
+	return s, nil
+}
+
+// fixLinkServer corrects the published OpenAPI 3.1 schema, which declares the 'server' property of a link
+// object as 'body'. Left alone, every link with a server is reported as having an unevaluated property.
+// The embedded schema is kept identical to the published one, so the correction is made here.
+func fixLinkServer(root *yaml.Node) {
+	if len(root.Content) == 0 {
+		return
+	}
+	node := root.Content[0]
+	for _, seg := range []string{"$defs", "link", "properties"} {
+		if _, node = utils.FindKeyNodeTop(seg, node.Content); node == nil {
+			return
+		}
+	}
+	if k, _ := utils.FindKeyNodeTop("body", node.Content); k != nil {
+		if s, _ := utils.FindKeyNodeTop("server", node.Content); s == nil {
+			k.Value = "server"
+		}
+	}
+}
+
+// referenceValidator locates the content of references to other files, so they can be validated in place of the
+// reference. Local references are left alone, they are validated as references.
+type referenceValidator struct {
+	rolodex *index.Rolodex
+	root    string                // the location of the root document.
+	files   map[string]*yaml.Node // every file opened, by location.
+	origins map[*yaml.Node]string // every node in an opened file, to the location of that file.
+}
+
+func (v *referenceValidator) resolve(node *yaml.Node) *yaml.Node {
+	if v.rolodex == nil {
+		return nil
+	}
+	var ref string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "$ref" {
+			ref = node.Content[i+1].Value
+		}
+	}
+	location, fragment, _ := strings.Cut(ref, "#")
+	if location == "" {
+		return nil
+	}
+	current := v.origins[node]
+	if current == "" {
+		current = v.root
+	}
+	location = resolveLocation(current, location)
+	root, ok := v.files[location]
+	if !ok {
+		if f, err := v.rolodex.Open(location); err == nil {
+			root, _ = f.GetContentAsYAMLNode()
+		}
+		v.files[location] = root
+		v.track(root, location)
+	}
+	return locatePointer(root, fragment)
+}
+
+// track records the file every node of a file came from.
+func (v *referenceValidator) track(node *yaml.Node, location string) {
+	if node == nil {
+		return
+	}
+	v.origins[node] = location
+	for _, n := range node.Content {
+		v.track(n, location)
+	}
+}
+
+// resolveLocation resolves a reference location relative to the file (or URL) that contains the reference.
+func resolveLocation(current, location string) string {
+	if u, err := url.Parse(location); err == nil && u.IsAbs() {
+		return location
+	}
+	if base, err := url.Parse(current); err == nil && base.IsAbs() {
+		if rel, rErr := url.Parse(location); rErr == nil {
+			return base.ResolveReference(rel).String()
+		}
+	}
+	if filepath.IsAbs(location) || current == "" {
+		return location
+	}
+	return filepath.Join(filepath.Dir(current), location)
+}
+
+// locatePointer finds the node a JSON pointer fragment points to.
+func locatePointer(root *yaml.Node, fragment string) *yaml.Node {
+	node := root
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if fragment == "" || fragment == "/" || node == nil {
+		return node
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	for _, seg := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		seg = utils.UnescapePointerSegment(seg)
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == seg {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package libopenapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pb33f/libopenapi/datamodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Validate_Valid(t *testing.T) {
+	for _, spec := range []string{"test_specs/petstorev3.json", "test_specs/petstorev2.json", "test_specs/asana.yaml"} {
+		b, err := os.ReadFile(spec)
+		require.NoError(t, err)
+		doc, err := NewDocument(b)
+		require.NoError(t, err)
+		errs, err := doc.(DocumentValidator).Validate()
+		require.NoError(t, err)
+		assert.Empty(t, errs, spec)
+	}
+}
+
+func TestDocument_Validate_OpenAPI30(t *testing.T) {
+	spec := `openapi: 3.0.3
+info:
+  title: burgers
+paths:
+  /burgers:
+    get:
+      parameters:
+        - name: limit
+          in: cookies
+      responses:
+        "200":
+          description: ok
+          nope: true`
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	errs, err := doc.(DocumentValidator).Validate()
+	require.NoError(t, err)
+	require.Len(t, errs, 4)
+
+	assert.Equal(t, "/info", errs[0].InstancePath)
+	assert.Equal(t, "missing property 'version'", errs[0].Message)
+	assert.Equal(t, 3, errs[0].Line)
+
+	// a parameter needs a schema or content.
+	assert.Equal(t, "missing property 'schema'", errs[1].Message)
+	assert.Equal(t, 8, errs[1].Line)
+
+	assert.Equal(t, "/paths/~1burgers/get/parameters/0/in", errs[2].InstancePath)
+	assert.Equal(t, 9, errs[2].Line)
+	assert.Equal(t, 15, errs[2].Column)
+
+	assert.Equal(t, "/paths/~1burgers/get/responses/200/nope (line 13, column 11): property 'nope' is not allowed",
+		errs[3].Error())
+}
+
+func TestDocument_Validate_OpenAPI31(t *testing.T) {
+	spec := `openapi: 3.1.0
+info:
+  title: burgers
+  version: "1"
+  summary: 31 has summaries
+webhooks:
+  newBurger:
+    post:
+      requestBody:
+        content: {}
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Burger:
+      type: [object, "null"]
+  links:
+    Burger:
+      operationId: getBurger
+      server:
+        url: https://pb33f.io
+      nope: true`
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	errs, err := doc.(DocumentValidator).Validate()
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/components/links/Burger/nope", errs[0].InstancePath)
+	assert.Equal(t, "unevaluatedProperties", errs[0].Keyword)
+	assert.Equal(t, 23, errs[0].Line)
+}
+
+func TestDocument_Validate_Swagger(t *testing.T) {
+	spec := `swagger: "2.0"
+info:
+  title: burgers
+  version: "1"
+paths:
+  /burgers:
+    get:
+      responses:
+        200:
+          schema:
+            type: string
+definitions:
+  Burger:
+    type: object
+    maxProperties: -1`
+	doc, err := NewDocument([]byte(spec))
+	require.NoError(t, err)
+	errs, err := doc.(DocumentValidator).Validate()
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "missing property 'description'", errs[0].Message)
+	assert.Equal(t, 10, errs[0].Line)
+
+	// the draft-04 meta-schema is used to validate schemas.
+	assert.Equal(t, "/definitions/Burger/maxProperties", errs[1].InstancePath)
+	assert.Equal(t, "minimum", errs[1].Keyword)
+}
+
+func TestDocument_Validate_Rolodex(t *testing.T) {
+	dir := t.TempDir()
+	root := `openapi: 3.0.3
+info:
+  title: burgers
+  version: "1"
+paths:
+  /burgers:
+    $ref: paths/burgers.yaml
+components:
+  schemas:
+    Burger:
+      $ref: 'schemas.yaml#/Burger'
+    Fries:
+      $ref: 'schemas.yaml#/Fries'`
+	burgers := `get:
+  responses:
+    "200":
+      description: ok
+      content:
+        application/json:
+          schema:
+            $ref: '../schemas.yaml#/Burger'
+`
+	schemas := `Burger:
+  type: object
+  required: patties
+Fries:
+  type: object
+  properties:
+    salted:
+      type: boolean
+`
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "paths"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "root.yaml"), []byte(root), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "paths", "burgers.yaml"), []byte(burgers), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas.yaml"), []byte(schemas), 0o644))
+
+	config := datamodel.NewDocumentConfiguration()
+	config.BasePath = dir
+	doc, err := NewDocumentWithConfiguration([]byte(root), config)
+	require.NoError(t, err)
+	errs, err := doc.(DocumentValidator).Validate()
+	require.NoError(t, err)
+
+	// the Burger schema is referenced twice, but the problem is only reported once.
+	require.Len(t, errs, 1)
+	assert.Equal(t, filepath.Join(dir, "schemas.yaml"), errs[0].Location)
+	assert.Equal(t, "expected array, but got string", errs[0].Message)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, 13, errs[0].Column)
+	assert.Contains(t, errs[0].Error(), "schemas.yaml: /paths/~1burgers/get/responses/200/content/application~1json/schema/required (line 3, column 13)")
+}
+
+func TestDocument_Validate_Unknown(t *testing.T) {
+	doc, err := NewDocumentWithTypeCheck([]byte(`hello: there`), true)
+	require.NoError(t, err)
+	_, err = doc.(DocumentValidator).Validate()
+	assert.Error(t, err)
+
+	_, err = (&document{}).Validate()
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"gopkg.in/yaml.v3"
+)
+
+// readBody reads a body, and replaces it with a copy so it can be read again.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+// validateBody checks a request or response body against the media type that matches its content type.
+func validateBody(content *orderedmap.Map[string, *v3.MediaType], contentType string, data []byte,
+	opts *base.SchemaValidationOptions,
+) *ValidationError {
+	key, mt := mediaType(content, contentType)
+	if mt == nil {
+		var allowed []string
+		for pair := content.First(); pair != nil; pair = pair.Next() {
+			allowed = append(allowed, "'"+pair.Key()+"'")
+		}
+		problem := "content type is not allowed"
+		if contentType == "" {
+			problem = "content type is missing"
+		}
+		return &ValidationError{
+			In:      "body",
+			Name:    contentType,
+			Message: fmt.Sprintf("%s, expected %s", problem, strings.Join(allowed, " or ")),
+		}
+	}
+	if mt.Schema == nil {
+		return nil
+	}
+	schema, err := mt.Schema.BuildSchema()
+	if schema == nil {
+		if err != nil {
+			return &ValidationError{In: "body", Name: key, Message: fmt.Sprintf("schema cannot be built: %s", err)}
+		}
+		return nil
+	}
+
+	var node *yaml.Node
+	mediaRange, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case isJSON(mediaRange):
+		node = new(yaml.Node)
+		if !json.Valid(data) || yaml.Unmarshal(data, node) != nil {
+			return &ValidationError{In: "body", Name: key, Message: "body is not valid JSON"}
+		}
+	case mediaRange == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			return &ValidationError{In: "body", Name: key, Message: fmt.Sprintf("body cannot be parsed: %s", err)}
+		}
+		node = formNode(schema, form)
+	case strings.HasPrefix(mediaRange, "text/"):
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(data)}
+	default:
+		return nil // binary, multipart and XML bodies are not checked.
+	}
+	if errs := schema.ValidateNode(node, opts); len(errs) > 0 {
+		return &ValidationError{In: "body", Name: key, Message: "body does not match the schema", SchemaErrors: errs}
+	}
+	return nil
+}
+
+// mediaType finds the media type that matches a content type. An exact match is preferred, then a range like
+// 'application/*', then '*/*'.
+func mediaType(content *orderedmap.Map[string, *v3.MediaType], contentType string) (string, *v3.MediaType) {
+	if content == nil {
+		return "", nil
+	}
+	wanted, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		wanted = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	major, _, _ := strings.Cut(wanted, "/")
+	candidates := []string{wanted, major + "/*", "*/*"}
+	for _, candidate := range candidates {
+		for pair := content.First(); pair != nil; pair = pair.Next() {
+			key, _, err := mime.ParseMediaType(pair.Key())
+			if err != nil {
+				key = strings.ToLower(pair.Key())
+			}
+			if key == candidate {
+				return pair.Key(), pair.Value()
+			}
+		}
+	}
+	return "", nil
+}
+
+// isJSON checks if a media type is JSON, including structured syntax like 'application/problem+json'.
+func isJSON(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// formNode converts a form into an object, using the schema to decide which fields are arrays.
+func formNode(schema *base.Schema, form url.Values) *yaml.Node {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range keys {
+		ps := propertySchema(schema, k)
+		var value *yaml.Node
+		if kindOf(ps) == "array" {
+			value = arrayNode(ps, form[k], func(s string) string { return s })
+		} else {
+			value = scalarNode(ps, form.Get(k))
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, value)
+	}
+	return node
+}
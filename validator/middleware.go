@@ -0,0 +1,92 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// MiddlewareOptions are used to configure how the middleware handles problems.
+type MiddlewareOptions struct {
+	// RequestErrorHandler is called with the problems found in a request, and is responsible for writing the
+	// response. The handler is never called for an invalid request. If nil, invalid requests are rejected with a
+	// 400 Bad Request, and a JSON body that lists every problem.
+	RequestErrorHandler func(w http.ResponseWriter, r *http.Request, errs []*ValidationError)
+
+	// ResponseErrorHandler is called with the problems found in a response. When set, every response is captured
+	// and checked before it is sent (so responses are no longer streamed). The response is always sent.
+	ResponseErrorHandler func(r *http.Request, errs []*ValidationError)
+}
+
+// Middleware returns net/http middleware that checks every request before it reaches the handler. Invalid
+// requests are rejected with a 400 Bad Request, and a JSON body that lists every problem.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return v.MiddlewareWithOptions(next, nil)
+}
+
+// MiddlewareWithOptions returns net/http middleware that checks every request (and optionally every response),
+// using the supplied options.
+func (v *Validator) MiddlewareWithOptions(next http.Handler, opts *MiddlewareOptions) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOptions{}
+	}
+	onRequestError := opts.RequestErrorHandler
+	if onRequestError == nil {
+		onRequestError = writeErrors
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if errs := v.ValidateRequest(r); len(errs) > 0 {
+			onRequestError(w, r, errs)
+			return
+		}
+		if opts.ResponseErrorHandler == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		resp := &http.Response{
+			StatusCode: rec.status,
+			Header:     w.Header(),
+			Body:       io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+			Request:    r,
+		}
+		if errs := v.ValidateResponse(r, resp); len(errs) > 0 {
+			opts.ResponseErrorHandler(r, errs)
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// writeErrors rejects a request with a 400 Bad Request, and a JSON body that lists every problem.
+func writeErrors(w http.ResponseWriter, _ *http.Request, errs []*ValidationError) {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	body, _ := json.Marshal(map[string][]string{"errors": messages})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(body)
+}
+
+// responseRecorder captures the status and body of a response, so it can be checked before it is sent.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
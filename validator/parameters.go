@@ -0,0 +1,363 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// the default style of a parameter, by location.
+var defaultStyles = map[string]string{
+	"path":   "simple",
+	"query":  "form",
+	"header": "simple",
+	"cookie": "form",
+}
+
+// validateParameters checks every parameter of a route.
+func (v *Validator) validateParameters(r *http.Request, rt *route) []*ValidationError {
+	var errs []*ValidationError
+	query := r.URL.Query()
+	for _, param := range rt.parameters() {
+		in := strings.ToLower(param.In)
+		if in == "header" {
+			// these headers are described by other parts of an operation, so parameters are ignored.
+			switch http.CanonicalHeaderKey(param.Name) {
+			case "Accept", "Content-Type", "Authorization":
+				continue
+			}
+		}
+
+		var raw []string
+		var present bool
+		switch in {
+		case "path":
+			var value string
+			value, present = rt.pathValues[param.Name]
+			raw = []string{value}
+		case "query":
+			raw, present = queryValues(query, param)
+		case "header":
+			raw, present = r.Header.Values(param.Name), len(r.Header.Values(param.Name)) > 0
+			if present {
+				raw = []string{strings.Join(raw, ",")}
+			}
+		case "cookie":
+			if c, err := r.Cookie(param.Name); err == nil {
+				raw, present = []string{c.Value}, true
+			}
+		default:
+			continue
+		}
+
+		if !present {
+			if in == "path" || param.Required != nil && *param.Required {
+				errs = append(errs, &ValidationError{In: in, Name: param.Name, Message: "required parameter is missing"})
+			}
+			continue
+		}
+		if err := validateParameter(param, in, raw, query); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// queryValues returns the raw values of a query parameter. Exploded objects (and deepObject parameters) are
+// spread across many query parameters, so every parameter is returned for those.
+func queryValues(query url.Values, param *v3.Parameter) ([]string, bool) {
+	if values, ok := query[param.Name]; ok {
+		return values, true
+	}
+	schema := parameterSchema(param)
+	if kindOf(schema) != "object" {
+		return nil, false
+	}
+	style := parameterStyle(param, "query")
+	switch {
+	case style == "deepObject":
+		for key := range query {
+			if strings.HasPrefix(key, param.Name+"[") {
+				return nil, true
+			}
+		}
+	case style == "form" && parameterExplode(param, "query") && schema.Properties != nil:
+		for pair := schema.Properties.First(); pair != nil; pair = pair.Next() {
+			if _, ok := query[pair.Key()]; ok {
+				return nil, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// validateParameter deserializes a parameter and checks it against its schema (or content).
+func validateParameter(param *v3.Parameter, in string, raw []string, query url.Values) *ValidationError {
+	if param.Content != nil && param.Content.Len() > 0 {
+		return validateParameterContent(param, in, raw)
+	}
+	schema := parameterSchema(param)
+	if schema == nil {
+		return nil
+	}
+	decode := func(s string) string { return s }
+	if in == "path" {
+		decode = unescape // path values are split before they are decoded.
+	}
+	node := deserialize(schema, parameterStyle(param, in), parameterExplode(param, in), param.Name, raw, query, decode)
+	if errs := schema.ValidateNode(node, &base.SchemaValidationOptions{Request: true}); len(errs) > 0 {
+		return &ValidationError{In: in, Name: param.Name, Message: "value does not match the schema", SchemaErrors: errs}
+	}
+	return nil
+}
+
+// validateParameterContent checks a parameter that is serialized using a media type. Only JSON can be checked.
+func validateParameterContent(param *v3.Parameter, in string, raw []string) *ValidationError {
+	for pair := param.Content.First(); pair != nil; pair = pair.Next() {
+		if !isJSON(pair.Key()) || pair.Value() == nil || pair.Value().Schema == nil {
+			continue
+		}
+		schema := pair.Value().Schema.Schema()
+		if schema == nil || len(raw) == 0 {
+			return nil
+		}
+		var node yaml.Node
+		if !json.Valid([]byte(raw[0])) || yaml.Unmarshal([]byte(raw[0]), &node) != nil {
+			return &ValidationError{In: in, Name: param.Name, Message: "value is not valid JSON"}
+		}
+		if errs := schema.ValidateNode(&node, &base.SchemaValidationOptions{Request: true}); len(errs) > 0 {
+			return &ValidationError{In: in, Name: param.Name, Message: "value does not match the schema", SchemaErrors: errs}
+		}
+		return nil
+	}
+	return nil
+}
+
+func parameterSchema(param *v3.Parameter) *base.Schema {
+	if param.Schema == nil {
+		return nil
+	}
+	return param.Schema.Schema()
+}
+
+func parameterStyle(param *v3.Parameter, in string) string {
+	if param.Style != "" {
+		return param.Style
+	}
+	return defaultStyles[in]
+}
+
+// parameterExplode returns the explode setting of a parameter, which defaults to true for 'form' styles.
+func parameterExplode(param *v3.Parameter, in string) bool {
+	if param.Explode != nil {
+		return *param.Explode
+	}
+	return parameterStyle(param, in) == "form"
+}
+
+// kindOf returns how a schema is serialized: as an 'array', an 'object' or a 'primitive'.
+func kindOf(schema *base.Schema) string {
+	if schema == nil {
+		return "primitive"
+	}
+	for _, t := range schema.Type {
+		switch t {
+		case "array", "object":
+			return t
+		}
+	}
+	switch {
+	case schema.Items != nil || len(schema.PrefixItems) > 0:
+		return "array"
+	case schema.Properties != nil && schema.Properties.Len() > 0:
+		return "object"
+	}
+	return "primitive"
+}
+
+// deserialize turns the raw values of a parameter into a node, following the rules of its style.
+func deserialize(schema *base.Schema, style string, explode bool, name string, raw []string,
+	query url.Values, decode func(string) string,
+) *yaml.Node {
+	value := ""
+	if len(raw) > 0 {
+		value = raw[0]
+	}
+	kind := kindOf(schema)
+
+	// path styles add a prefix to the value.
+	switch style {
+	case "label":
+		value = strings.TrimPrefix(value, ".")
+	case "matrix":
+		value = strings.TrimPrefix(value, ";")
+		if kind != "object" || !explode {
+			value = strings.TrimPrefix(strings.TrimPrefix(value, name), "=")
+		}
+	}
+
+	switch kind {
+	case "array":
+		var items []string
+		switch {
+		case style == "form" && explode:
+			items = raw
+		case style == "spaceDelimited":
+			items = strings.Split(value, " ")
+		case style == "pipeDelimited":
+			items = strings.Split(value, "|")
+		case style == "label" && explode:
+			items = strings.Split(value, ".")
+		case style == "matrix" && explode:
+			for _, part := range strings.Split(value, ";") {
+				items = append(items, strings.TrimPrefix(strings.TrimPrefix(part, name), "="))
+			}
+		default:
+			items = strings.Split(value, ",")
+		}
+		if value == "" && len(raw) <= 1 {
+			items = nil
+		}
+		return arrayNode(schema, items, decode)
+
+	case "object":
+		fields := make(map[string]string)
+		var order []string
+		set := func(k, v string) {
+			if _, ok := fields[k]; !ok {
+				order = append(order, k)
+			}
+			fields[k] = v
+		}
+		switch {
+		case style == "deepObject":
+			var keys []string
+			for key := range query {
+				if strings.HasPrefix(key, name+"[") && strings.HasSuffix(key, "]") && len(query[key]) > 0 {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				set(key[len(name)+1:len(key)-1], query[key][0])
+			}
+		case style == "form" && explode:
+			if schema.Properties != nil {
+				for pair := schema.Properties.First(); pair != nil; pair = pair.Next() {
+					if values, ok := query[pair.Key()]; ok && len(values) > 0 {
+						set(pair.Key(), values[0])
+					}
+				}
+			}
+		case explode:
+			separator := ","
+			switch style {
+			case "label":
+				separator = "."
+			case "matrix":
+				separator = ";"
+			}
+			for _, part := range strings.Split(value, separator) {
+				if k, v, ok := strings.Cut(part, "="); ok {
+					set(k, v)
+				}
+			}
+		default:
+			parts := strings.Split(value, ",")
+			for i := 0; i+1 < len(parts); i += 2 {
+				set(parts[i], parts[i+1])
+			}
+		}
+		return objectNode(schema, order, fields, decode)
+	}
+	return scalarNode(schema, decode(value))
+}
+
+func arrayNode(schema *base.Schema, items []string, decode func(string) string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for i, item := range items {
+		node.Content = append(node.Content, scalarNode(itemSchema(schema, i), decode(item)))
+	}
+	return node
+}
+
+func objectNode(schema *base.Schema, order []string, fields map[string]string, decode func(string) string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range order {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: decode(k)},
+			scalarNode(propertySchema(schema, decode(k)), decode(fields[k])))
+	}
+	return node
+}
+
+// itemSchema returns the schema of an item in an array.
+func itemSchema(schema *base.Schema, i int) *base.Schema {
+	if schema == nil {
+		return nil
+	}
+	if i < len(schema.PrefixItems) {
+		return schema.PrefixItems[i].Schema()
+	}
+	if schema.Items != nil && schema.Items.IsA() && schema.Items.A != nil {
+		return schema.Items.A.Schema()
+	}
+	return nil
+}
+
+// propertySchema returns the schema of a property in an object.
+func propertySchema(schema *base.Schema, name string) *base.Schema {
+	if schema == nil || schema.Properties == nil {
+		return nil
+	}
+	if sp, ok := schema.Properties.Get(name); ok && sp != nil {
+		return sp.Schema()
+	}
+	return nil
+}
+
+// scalarNode converts a raw value into a scalar node, using the types of the schema to decide if it is a
+// number, a boolean or a string. A value that cannot be converted is left as a string, so the schema rejects it.
+func scalarNode(schema *base.Schema, value string) *yaml.Node {
+	if schema != nil {
+		for _, t := range schema.Type {
+			switch t {
+			case "integer":
+				if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+					return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value}
+				}
+			case "number":
+				if _, err := strconv.ParseFloat(value, 64); err == nil {
+					return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value}
+				}
+			case "boolean":
+				if value == "true" || value == "false" {
+					return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}
+				}
+			case "null":
+				if value == "" {
+					return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+				}
+			}
+		}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// unescape decodes a percent-encoded value.
+func unescape(value string) string {
+	if decoded, err := url.PathUnescape(value); err == nil {
+		return decoded
+	}
+	return value
+}
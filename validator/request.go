@@ -0,0 +1,47 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// ValidateRequest checks a request against the operation it matches. Every parameter is deserialized and checked
+// against its schema, required parameters must be present, and the body must use one of the media types of the
+// operation and match its schema. No errors means the request is valid.
+//
+// The body is read, and replaced with a copy, so handlers can still read it.
+func (v *Validator) ValidateRequest(r *http.Request) []*ValidationError {
+	rt, err := v.match(r)
+	if err != nil {
+		return []*ValidationError{err}
+	}
+	errs := v.validateParameters(r, rt)
+
+	body := rt.operation.RequestBody
+	if body == nil {
+		return errs
+	}
+	data, readErr := readBody(&r.Body)
+	if readErr != nil {
+		return append(errs, &ValidationError{In: "body", Message: fmt.Sprintf("body cannot be read: %s", readErr)})
+	}
+	if len(data) == 0 {
+		if body.Required != nil && *body.Required {
+			errs = append(errs, &ValidationError{In: "body", Message: "request body is required"})
+		}
+		return errs
+	}
+	if body.Content == nil {
+		return errs
+	}
+	if bodyErr := validateBody(body.Content, r.Header.Get("Content-Type"), data,
+		&base.SchemaValidationOptions{Request: true}); bodyErr != nil {
+		errs = append(errs, bodyErr)
+	}
+	return errs
+}
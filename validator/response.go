@@ -0,0 +1,110 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// ValidateResponse checks a response against the operation that matches the request it answers. The status code
+// must be defined by the operation (or covered by a range like '2XX', or a default), required headers must be
+// present, every header must match its schema, and the body must use one of the media types of the response and
+// match its schema. No errors means the response is valid.
+//
+// The body is read, and replaced with a copy, so it can still be read.
+func (v *Validator) ValidateResponse(r *http.Request, resp *http.Response) []*ValidationError {
+	rt, err := v.match(r)
+	if err != nil {
+		return []*ValidationError{err}
+	}
+	response := findResponse(rt.operation.Responses, resp.StatusCode)
+	if response == nil {
+		return []*ValidationError{{
+			In:      "response",
+			Message: fmt.Sprintf("status code %d is not defined by the operation", resp.StatusCode),
+		}}
+	}
+
+	var errs []*ValidationError
+	for pair := response.Headers.First(); pair != nil; pair = pair.Next() {
+		if http.CanonicalHeaderKey(pair.Key()) == "Content-Type" {
+			continue // the content type is described by the content of the response.
+		}
+		if headerErr := validateHeader(pair.Key(), pair.Value(), resp.Header); headerErr != nil {
+			errs = append(errs, headerErr)
+		}
+	}
+
+	if response.Content == nil || response.Content.Len() == 0 {
+		return errs
+	}
+	data, readErr := readBody(&resp.Body)
+	if readErr != nil {
+		return append(errs, &ValidationError{In: "body", Message: fmt.Sprintf("body cannot be read: %s", readErr)})
+	}
+	if len(data) == 0 {
+		return errs
+	}
+	if bodyErr := validateBody(response.Content, resp.Header.Get("Content-Type"), data,
+		&base.SchemaValidationOptions{Response: true}); bodyErr != nil {
+		errs = append(errs, bodyErr)
+	}
+	return errs
+}
+
+// findResponse finds the response for a status code. An exact code is preferred, then a range like '2XX', then
+// the default response.
+func findResponse(responses *v3.Responses, code int) *v3.Response {
+	if responses == nil {
+		return nil
+	}
+	exact := strconv.Itoa(code)
+	rng := exact[:1] + "XX"
+	var ranged *v3.Response
+	for pair := responses.Codes.First(); pair != nil; pair = pair.Next() {
+		switch strings.ToUpper(pair.Key()) {
+		case exact:
+			return pair.Value()
+		case rng:
+			ranged = pair.Value()
+		}
+	}
+	if ranged != nil {
+		return ranged
+	}
+	return responses.Default
+}
+
+// validateHeader checks a response header against its schema.
+func validateHeader(name string, header *v3.Header, values http.Header) *ValidationError {
+	if header == nil {
+		return nil
+	}
+	raw := values.Values(name)
+	if len(raw) == 0 {
+		if header.Required {
+			return &ValidationError{In: "header", Name: name, Message: "required header is missing"}
+		}
+		return nil
+	}
+	if header.Schema == nil {
+		return nil
+	}
+	schema := header.Schema.Schema()
+	if schema == nil {
+		return nil
+	}
+	node := deserialize(schema, "simple", header.Explode, name, []string{strings.Join(raw, ",")}, nil,
+		func(s string) string { return s })
+	if errs := schema.ValidateNode(node, &base.SchemaValidationOptions{Response: true}); len(errs) > 0 {
+		return &ValidationError{In: "header", Name: name, Message: "value does not match the schema", SchemaErrors: errs}
+	}
+	return nil
+}
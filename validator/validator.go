@@ -0,0 +1,242 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+// Package validator checks HTTP requests and responses against the operations of an OpenAPI 3 document.
+//
+// A Validator finds the path item and operation that match a request, deserializes every path, query, header
+// and cookie parameter according to its 'style' and 'explode' settings, and checks each value (and the body)
+// against the schemas of the document. It can be used directly, using ValidateRequest and ValidateResponse, or
+// wired in front of a handler as net/http middleware.
+package validator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/jsonschema"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// ValidationError is a problem found with a request or a response.
+type ValidationError struct {
+	// Message explains the problem.
+	Message string
+
+	// In is where the problem was found. This is 'path', 'query', 'header' or 'cookie' for parameters (and
+	// response headers), 'body' for a request or response body, or 'request' and 'response' when the request
+	// or response cannot be matched to the document at all.
+	In string
+
+	// Name is the name of the parameter or header, or the media type of the body.
+	Name string
+
+	// SchemaErrors are the problems found when the value was checked against its schema, if any.
+	SchemaErrors []*jsonschema.ValidationError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	switch {
+	case e.Name != "" && e.In == "body":
+		fmt.Fprintf(&b, "body (%s): %s", e.Name, e.Message)
+	case e.Name != "":
+		fmt.Fprintf(&b, "%s '%s': %s", e.In, e.Name, e.Message)
+	default:
+		fmt.Fprintf(&b, "%s: %s", e.In, e.Message)
+	}
+	for _, se := range e.SchemaErrors {
+		b.WriteString("; ")
+		b.WriteString(se.Error())
+	}
+	return b.String()
+}
+
+// Validator checks requests and responses against the operations of a document. It is safe to use from many
+// goroutines at once.
+type Validator struct {
+	document *v3.Document
+	paths    []*pathMatcher
+	bases    []string // the base path of every server, longest first.
+}
+
+// pathMatcher matches a request path to a path in the document.
+type pathMatcher struct {
+	path     string
+	item     *v3.PathItem
+	regex    *regexp.Regexp
+	names    []string // the name of every template expression, in order.
+	literals int      // the number of literal characters, concrete paths are preferred to templated ones.
+}
+
+// route is the path item and operation that match a request, along with the raw value of each path parameter.
+type route struct {
+	path       string
+	item       *v3.PathItem
+	operation  *v3.Operation
+	pathValues map[string]string
+}
+
+var templateRegex = regexp.MustCompile(`\{([^{}/]+)}`)
+
+// NewValidator creates a Validator for the operations of a document.
+func NewValidator(document *v3.Document) *Validator {
+	v := &Validator{document: document}
+	if document == nil {
+		return v
+	}
+	if document.Paths != nil {
+		for pair := document.Paths.PathItems.First(); pair != nil; pair = pair.Next() {
+			v.paths = append(v.paths, newPathMatcher(pair.Key(), pair.Value()))
+		}
+	}
+	sort.SliceStable(v.paths, func(i, j int) bool {
+		if v.paths[i].literals != v.paths[j].literals {
+			return v.paths[i].literals > v.paths[j].literals
+		}
+		return len(v.paths[i].names) < len(v.paths[j].names)
+	})
+
+	seen := make(map[string]bool)
+	for _, server := range document.Servers {
+		base := serverBasePath(server)
+		if !seen[base] {
+			seen[base] = true
+			v.bases = append(v.bases, base)
+		}
+	}
+	if !seen[""] {
+		v.bases = append(v.bases, "")
+	}
+	sort.SliceStable(v.bases, func(i, j int) bool { return len(v.bases[i]) > len(v.bases[j]) })
+	return v
+}
+
+func newPathMatcher(path string, item *v3.PathItem) *pathMatcher {
+	m := &pathMatcher{path: path, item: item}
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range templateRegex.FindAllStringSubmatchIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		pattern.WriteString("([^/]+)")
+		m.names = append(m.names, path[loc[2]:loc[3]])
+		m.literals += loc[0] - last
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(strings.TrimSuffix(path[last:], "/")))
+	m.literals += len(path) - last
+	pattern.WriteString("/?$")
+	m.regex = regexp.MustCompile(pattern.String())
+	return m
+}
+
+// serverBasePath returns the path of a server URL, with every variable replaced by its default.
+func serverBasePath(server *v3.Server) string {
+	raw := templateRegex.ReplaceAllStringFunc(server.URL, func(expr string) string {
+		if server.Variables != nil {
+			if variable, ok := server.Variables.Get(expr[1 : len(expr)-1]); ok && variable != nil {
+				return variable.Default
+			}
+		}
+		return ""
+	})
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// match finds the route of a request. Paths are tried with concrete paths ahead of templated ones, the first path
+// that matches and has an operation for the method wins. If paths match, but none of them has an operation for
+// the method, the method is not allowed.
+func (v *Validator) match(r *http.Request) (*route, *ValidationError) {
+	path := r.URL.EscapedPath()
+	var notAllowed *pathMatcher
+	for _, base := range v.bases {
+		if base != "" {
+			if !strings.HasPrefix(path, base) || len(path) > len(base) && path[len(base)] != '/' {
+				continue
+			}
+		}
+		relative := strings.TrimPrefix(path, base)
+		if relative == "" {
+			relative = "/"
+		}
+		for _, m := range v.paths {
+			values := m.regex.FindStringSubmatch(relative)
+			if values == nil {
+				continue
+			}
+			op := operation(m.item, r.Method)
+			if op == nil {
+				if notAllowed == nil {
+					notAllowed = m
+				}
+				continue
+			}
+			rt := &route{path: m.path, item: m.item, operation: op, pathValues: make(map[string]string)}
+			for i, name := range m.names {
+				rt.pathValues[name] = values[i+1]
+			}
+			return rt, nil
+		}
+	}
+	if notAllowed != nil {
+		return nil, &ValidationError{
+			In: "request",
+			Message: fmt.Sprintf("method '%s' is not allowed for path '%s'", strings.ToUpper(r.Method),
+				notAllowed.path),
+		}
+	}
+	return nil, &ValidationError{In: "request", Message: fmt.Sprintf("path '%s' cannot be found", r.URL.Path)}
+}
+
+// operation returns the operation of a path item for an HTTP method.
+func operation(item *v3.PathItem, method string) *v3.Operation {
+	if item == nil {
+		return nil
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPost:
+		return item.Post
+	case http.MethodDelete:
+		return item.Delete
+	case http.MethodOptions:
+		return item.Options
+	case http.MethodHead:
+		return item.Head
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodTrace:
+		return item.Trace
+	}
+	return nil
+}
+
+// parameters returns the parameters of a route. Operation parameters override path item parameters with the
+// same name and location.
+func (rt *route) parameters() []*v3.Parameter {
+	params := orderedmap.New[string, *v3.Parameter]()
+	for _, list := range [][]*v3.Parameter{rt.item.Parameters, rt.operation.Parameters} {
+		for _, p := range list {
+			if p != nil {
+				params.Set(strings.ToLower(p.In)+":"+p.Name, p)
+			}
+		}
+	}
+	var result []*v3.Parameter
+	for pair := params.First(); pair != nil; pair = pair.Next() {
+		result = append(result, pair.Value())
+	}
+	return result
+}
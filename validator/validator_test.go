@@ -0,0 +1,327 @@
+// Copyright 2023 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: MIT
+
+package validator
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var burgerSpec = `openapi: 3.1.0
+info:
+  title: burgers
+  version: "1"
+servers:
+  - url: https://api.pb33f.io/{version}
+    variables:
+      version:
+        default: v1
+paths:
+  /burgers/{burgerId}:
+    parameters:
+      - name: burgerId
+        in: path
+        required: true
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      parameters:
+        - name: sauces
+          in: query
+          schema:
+            type: array
+            maxItems: 2
+            items:
+              type: string
+              enum: [ketchup, mustard, mayo]
+        - name: filter
+          in: query
+          style: deepObject
+          schema:
+            type: object
+            properties:
+              patties:
+                type: integer
+        - name: X-Burger-Size
+          in: header
+          required: true
+          schema:
+            type: string
+            enum: [small, large]
+        - name: session
+          in: cookie
+          schema:
+            type: string
+            minLength: 4
+      responses:
+        "200":
+          description: ok
+          headers:
+            X-Patties:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Burger'
+        4XX:
+          description: client error
+  /burgers/special:
+    get:
+      responses:
+        default:
+          description: anything
+  /burgers:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Burger'
+          application/x-www-form-urlencoded:
+            schema:
+              $ref: '#/components/schemas/Burger'
+      responses:
+        "201":
+          description: created
+  /menu/{items}:
+    get:
+      parameters:
+        - name: items
+          in: path
+          required: true
+          style: matrix
+          explode: true
+          schema:
+            type: array
+            items:
+              type: integer
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    Burger:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+        patties:
+          type: integer
+          maximum: 3
+        secret:
+          type: string
+          writeOnly: true`
+
+func newValidator(t *testing.T) *Validator {
+	doc, err := libopenapi.NewDocument([]byte(burgerSpec))
+	require.NoError(t, err)
+	model, errs := doc.BuildV3Model()
+	require.Empty(t, errs)
+	return NewValidator(&model.Model)
+}
+
+func errorMessages(errs []*ValidationError) []string {
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	return messages
+}
+
+func TestValidator_ValidateRequest_Parameters(t *testing.T) {
+	v := newValidator(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/burgers/12?sauces=ketchup&sauces=mayo&filter[patties]=2", nil)
+	r.Header.Set("X-Burger-Size", "large")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abcdef"})
+	assert.Empty(t, v.ValidateRequest(r))
+
+	r = httptest.NewRequest(http.MethodGet,
+		"/v1/burgers/0?sauces=ketchup&sauces=mustard&sauces=bbq&filter[patties]=two", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	errs := v.ValidateRequest(r)
+	require.Len(t, errs, 5)
+	assert.Equal(t, "path", errs[0].In)
+	assert.Equal(t, "burgerId", errs[0].Name)
+	assert.Equal(t, "minimum", errs[0].SchemaErrors[0].Keyword)
+	assert.Equal(t, 18, errs[0].SchemaErrors[0].SchemaLine)
+
+	assert.Equal(t, "sauces", errs[1].Name)
+	require.Len(t, errs[1].SchemaErrors, 2)
+	assert.Equal(t, "/2", errs[1].SchemaErrors[0].InstancePath)
+	assert.Equal(t, "maxItems", errs[1].SchemaErrors[1].Keyword)
+
+	assert.Equal(t, "filter", errs[2].Name)
+	assert.Equal(t, "/patties", errs[2].SchemaErrors[0].InstancePath)
+	assert.Equal(t, "header 'X-Burger-Size': required parameter is missing", errs[3].Error())
+	assert.Equal(t, "cookie", errs[4].In)
+}
+
+func TestValidator_ValidateRequest_Routing(t *testing.T) {
+	v := newValidator(t)
+
+	// concrete paths are preferred to templated paths.
+	assert.Empty(t, v.ValidateRequest(httptest.NewRequest(http.MethodGet, "/v1/burgers/special", nil)))
+	assert.Empty(t, v.ValidateRequest(httptest.NewRequest(http.MethodGet, "/menu/;items=1;items=2", nil)))
+
+	errs := v.ValidateRequest(httptest.NewRequest(http.MethodGet, "/menu/;items=1;items=two", nil))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/1", errs[0].SchemaErrors[0].InstancePath)
+
+	errs = v.ValidateRequest(httptest.NewRequest(http.MethodDelete, "/v1/burgers/special", nil))
+	assert.Equal(t, []string{"request: method 'DELETE' is not allowed for path '/burgers/special'"}, errorMessages(errs))
+
+	errs = v.ValidateRequest(httptest.NewRequest(http.MethodGet, "/v1/fries", nil))
+	assert.Equal(t, []string{"request: path '/v1/fries' cannot be found"}, errorMessages(errs))
+}
+
+func TestValidator_ValidateRequest_RoutingMethods(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.1.0
+info:
+  title: users
+  version: "1"
+paths:
+  /users/me:
+    get:
+      responses:
+        "200":
+          description: me
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: string
+    delete:
+      responses:
+        "204":
+          description: deleted`))
+	require.NoError(t, err)
+	model, buildErrs := doc.BuildV3Model()
+	require.Empty(t, buildErrs)
+	v := NewValidator(&model.Model)
+
+	// a concrete path without the method does not hide a templated path that has it.
+	assert.Empty(t, v.ValidateRequest(httptest.NewRequest(http.MethodDelete, "/users/me", nil)))
+	assert.Empty(t, v.ValidateRequest(httptest.NewRequest(http.MethodGet, "/users/me", nil)))
+
+	errs := v.ValidateRequest(httptest.NewRequest(http.MethodPost, "/users/me", nil))
+	assert.Equal(t, []string{"request: method 'POST' is not allowed for path '/users/me'"}, errorMessages(errs))
+}
+
+func TestValidator_ValidateRequest_Body(t *testing.T) {
+	v := newValidator(t)
+
+	r := httptest.NewRequest(http.MethodPost, "/burgers", strings.NewReader(`{"name": "big mac", "secret": "sauce"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	assert.Empty(t, v.ValidateRequest(r))
+
+	// the body can still be read by the handler.
+	body, _ := io.ReadAll(r.Body)
+	assert.Equal(t, `{"name": "big mac", "secret": "sauce"}`, string(body))
+
+	r = httptest.NewRequest(http.MethodPost, "/burgers", strings.NewReader(`{"id": 1, "patties": 4}`))
+	r.Header.Set("Content-Type", "application/json")
+	errs := v.ValidateRequest(r)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "application/json", errs[0].Name)
+	var keywords []string
+	for _, e := range errs[0].SchemaErrors {
+		keywords = append(keywords, e.Keyword)
+	}
+	assert.Equal(t, []string{"readOnly", "maximum", "required"}, keywords)
+	assert.Equal(t, "missing property 'name'", errs[0].SchemaErrors[2].Message)
+
+	r = httptest.NewRequest(http.MethodPost, "/burgers", strings.NewReader(`name=whopper&patties=2`))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.Empty(t, v.ValidateRequest(r))
+
+	r = httptest.NewRequest(http.MethodPost, "/burgers", strings.NewReader(`<burger/>`))
+	r.Header.Set("Content-Type", "application/xml")
+	assert.Equal(t, []string{"body (application/xml): content type is not allowed, expected 'application/json' or " +
+		"'application/x-www-form-urlencoded'"}, errorMessages(v.ValidateRequest(r)))
+
+	r = httptest.NewRequest(http.MethodPost, "/burgers", strings.NewReader(`{nope`))
+	r.Header.Set("Content-Type", "application/json")
+	assert.Equal(t, []string{"body (application/json): body is not valid JSON"}, errorMessages(v.ValidateRequest(r)))
+
+	r = httptest.NewRequest(http.MethodPost, "/burgers", nil)
+	assert.Equal(t, []string{"body: request body is required"}, errorMessages(v.ValidateRequest(r)))
+}
+
+func TestValidator_ValidateResponse(t *testing.T) {
+	v := newValidator(t)
+	r := httptest.NewRequest(http.MethodGet, "/v1/burgers/1", nil)
+
+	newResponse := func(code int, body string, headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: code, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(body))}
+		for k, val := range headers {
+			resp.Header.Set(k, val)
+		}
+		return resp
+	}
+
+	resp := newResponse(200, `{"id": 1, "name": "big mac"}`,
+		map[string]string{"Content-Type": "application/json", "X-Patties": "2"})
+	assert.Empty(t, v.ValidateResponse(r, resp))
+
+	resp = newResponse(200, `{"id": 1, "name": "big mac", "secret": "sauce"}`,
+		map[string]string{"Content-Type": "application/json", "X-Patties": "two"})
+	errs := v.ValidateResponse(r, resp)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "X-Patties", errs[0].Name)
+	assert.Equal(t, "writeOnly", errs[1].SchemaErrors[0].Keyword)
+	assert.Equal(t, "/secret", errs[1].SchemaErrors[0].InstancePath)
+
+	// 404 is covered by '4XX'.
+	assert.Empty(t, v.ValidateResponse(r, newResponse(404, "", nil)))
+	assert.Equal(t, []string{"response: status code 500 is not defined by the operation"},
+		errorMessages(v.ValidateResponse(r, newResponse(500, "", nil))))
+}
+
+func TestValidator_Middleware(t *testing.T) {
+	v := newValidator(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1, "name": "big mac", "secret": "sauce"}`))
+	})
+
+	w := httptest.NewRecorder()
+	v.Middleware(handler).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/burgers", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"errors": ["body: request body is required"]}`, w.Body.String())
+
+	var responseErrs []*ValidationError
+	mw := v.MiddlewareWithOptions(handler, &MiddlewareOptions{
+		ResponseErrorHandler: func(r *http.Request, errs []*ValidationError) {
+			responseErrs = errs
+		},
+	})
+	r := httptest.NewRequest(http.MethodPost, "/burgers", strings.NewReader(`{"name": "big mac"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	mw.ServeHTTP(w, r)
+
+	// the response is checked, and still sent.
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id": 1, "name": "big mac", "secret": "sauce"}`, w.Body.String())
+	require.Len(t, responseErrs, 0) // the '201' response has no content to check.
+}